@@ -6,6 +6,7 @@
 package cryptor
 
 import (
+	"crypto"
 	"crypto/hmac"
 	"crypto/md5"
 	"crypto/sha1"
@@ -13,8 +14,6 @@ import (
 	"crypto/sha512"
 	"encoding/base64"
 	"encoding/hex"
-	"fmt"
-	"io"
 	"os"
 )
 
@@ -79,23 +78,7 @@ func Md5File(filename string) (string, error) {
 		return "", nil
 	}
 
-	hash := md5.New()
-	buf := make([]byte, 65536) // 64KB
-
-	for {
-		n, err := file.Read(buf)
-		if err != nil && err != io.EOF {
-			return "", err
-		}
-		if n > 0 {
-			hash.Write(buf[:n])
-		}
-		if err == io.EOF {
-			break
-		}
-	}
-
-	return fmt.Sprintf("%x", hash.Sum(nil)), nil
+	return HashReader(file, crypto.MD5)
 }
 
 // HmacMd5 return the hmac hash of string use md5.
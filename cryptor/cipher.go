@@ -0,0 +1,332 @@
+// Copyright 2023 dudaodong@gmail.com. All rights reserved.
+// Use of this source code is governed by MIT license
+
+package cryptor
+
+import (
+	"crypto/aes"
+	"crypto/cipher"
+	"crypto/des"
+	"crypto/rand"
+	"errors"
+	"io"
+)
+
+var (
+	// ErrInvalidKeySize is returned when the key length is not valid for the algorithm.
+	ErrInvalidKeySize = errors.New("invalid key size")
+	// ErrInvalidCiphertext is returned when the encrypted data is too short or not a multiple of block size.
+	ErrInvalidCiphertext = errors.New("invalid ciphertext")
+	// ErrInvalidPadding is returned when the PKCS#7 padding of decrypted data is malformed.
+	ErrInvalidPadding = errors.New("invalid padding")
+	// ErrInvalidNonce is returned when the length of caller supplied nonce is wrong.
+	ErrInvalidNonce = errors.New("invalid nonce size")
+	// ErrAuthFailed is returned when the authenticated data has been tampered with or the key is wrong.
+	ErrAuthFailed = errors.New("message authentication failed")
+)
+
+// CipherError records the failed operation of a cipher, use errors.Is to check the cause.
+type CipherError struct {
+	Algorithm string // aes or des
+	Mode      string // ecb, cbc, ctr, cfb, ofb or gcm
+	Op        string // encrypt or decrypt
+	Err       error
+}
+
+// Error implements the error interface.
+func (e *CipherError) Error() string {
+	name := e.Algorithm
+	if e.Mode != "" {
+		name += "-" + e.Mode
+	}
+	if e.Op != "" {
+		name += " " + e.Op
+	}
+	return name + ": " + e.Err.Error()
+}
+
+// Unwrap returns the cause of error.
+func (e *CipherError) Unwrap() error {
+	return e.Err
+}
+
+// Cipher encrypts and decrypts data, all errors are returned instead of panic.
+type Cipher interface {
+	// Encrypt returns the encrypted data, the random iv is prefixed to it except ECB mode.
+	Encrypt(data []byte) ([]byte, error)
+	// Decrypt returns the decrypted data of the result of Encrypt.
+	Decrypt(encrypted []byte) ([]byte, error)
+}
+
+// BlockCipher is a block cipher with key, use its mode methods to get a Cipher, eg.
+// cryptor.AES(key).CBC().Encrypt(data).
+type BlockCipher struct {
+	algorithm string
+	key       []byte
+	newBlock  func(key []byte) (cipher.Block, error)
+	err       error
+}
+
+// AES creates a AES block cipher, len(key) should be 16, 24 or 32.
+func AES(key []byte) *BlockCipher {
+	c := &BlockCipher{algorithm: "aes", key: key, newBlock: aes.NewCipher}
+	if !isAesKeyLengthValid(len(key)) {
+		c.err = ErrInvalidKeySize
+	}
+	return c
+}
+
+// DES creates a DES block cipher, len(key) should be 8.
+func DES(key []byte) *BlockCipher {
+	c := &BlockCipher{algorithm: "des", key: key, newBlock: des.NewCipher}
+	if len(key) != des.BlockSize {
+		c.err = ErrInvalidKeySize
+	}
+	return c
+}
+
+// ECB returns the cipher of ECB mode with PKCS#7 padding.
+// ECB leaks patterns of the data, it is kept for compatibility only.
+func (c *BlockCipher) ECB() Cipher {
+	return &blockMode{cipher: c, mode: "ecb"}
+}
+
+// CBC returns the cipher of CBC mode with PKCS#7 padding.
+func (c *BlockCipher) CBC() Cipher {
+	return &blockMode{cipher: c, mode: "cbc"}
+}
+
+// CTR returns the cipher of CTR mode.
+func (c *BlockCipher) CTR() Cipher {
+	return &blockMode{cipher: c, mode: "ctr"}
+}
+
+// CFB returns the cipher of CFB mode.
+func (c *BlockCipher) CFB() Cipher {
+	return &blockMode{cipher: c, mode: "cfb"}
+}
+
+// OFB returns the cipher of OFB mode.
+func (c *BlockCipher) OFB() Cipher {
+	return &blockMode{cipher: c, mode: "ofb"}
+}
+
+// GCM returns the authenticated cipher of GCM mode, only AES supports it.
+func (c *BlockCipher) GCM() *GCM {
+	g := &GCM{algorithm: c.algorithm}
+
+	block, err := c.block()
+	if err != nil {
+		g.err = err
+		return g
+	}
+
+	g.aead, g.err = cipher.NewGCM(block)
+
+	return g
+}
+
+func (c *BlockCipher) block() (cipher.Block, error) {
+	if c.err != nil {
+		return nil, c.err
+	}
+	return c.newBlock(c.key)
+}
+
+// blockMode implements Cipher for the non authenticated modes.
+type blockMode struct {
+	cipher *BlockCipher
+	mode   string
+}
+
+func (m *blockMode) Encrypt(data []byte) ([]byte, error) {
+	block, err := m.cipher.block()
+	if err != nil {
+		return nil, m.error("encrypt", err)
+	}
+	blockSize := block.BlockSize()
+
+	if m.mode == "ecb" {
+		padded := pkcs7Padding(data, blockSize)
+		for i := 0; i < len(padded); i += blockSize {
+			block.Encrypt(padded[i:], padded[i:])
+		}
+		return padded, nil
+	}
+
+	if m.mode == "cbc" {
+		data = pkcs7Padding(data, blockSize)
+	}
+
+	encrypted := make([]byte, blockSize+len(data))
+	iv := encrypted[:blockSize]
+	if _, err := io.ReadFull(rand.Reader, iv); err != nil {
+		return nil, m.error("encrypt", err)
+	}
+
+	if m.mode == "cbc" {
+		cipher.NewCBCEncrypter(block, iv).CryptBlocks(encrypted[blockSize:], data)
+	} else {
+		newStream(m.mode, block, iv, true).XORKeyStream(encrypted[blockSize:], data)
+	}
+
+	return encrypted, nil
+}
+
+func (m *blockMode) Decrypt(encrypted []byte) ([]byte, error) {
+	block, err := m.cipher.block()
+	if err != nil {
+		return nil, m.error("decrypt", err)
+	}
+	blockSize := block.BlockSize()
+
+	switch m.mode {
+	case "ecb":
+		if len(encrypted) == 0 || len(encrypted)%blockSize != 0 {
+			return nil, m.error("decrypt", ErrInvalidCiphertext)
+		}
+
+		decrypted := make([]byte, len(encrypted))
+		for i := 0; i < len(encrypted); i += blockSize {
+			block.Decrypt(decrypted[i:], encrypted[i:])
+		}
+
+		result, err := pkcs7Unpad(decrypted, blockSize)
+		if err != nil {
+			return nil, m.error("decrypt", err)
+		}
+		return result, nil
+
+	case "cbc":
+		if len(encrypted) < 2*blockSize || len(encrypted)%blockSize != 0 {
+			return nil, m.error("decrypt", ErrInvalidCiphertext)
+		}
+
+		decrypted := make([]byte, len(encrypted)-blockSize)
+		cipher.NewCBCDecrypter(block, encrypted[:blockSize]).CryptBlocks(decrypted, encrypted[blockSize:])
+
+		result, err := pkcs7Unpad(decrypted, blockSize)
+		if err != nil {
+			return nil, m.error("decrypt", err)
+		}
+		return result, nil
+	}
+
+	if len(encrypted) < blockSize {
+		return nil, m.error("decrypt", ErrInvalidCiphertext)
+	}
+
+	decrypted := make([]byte, len(encrypted)-blockSize)
+	newStream(m.mode, block, encrypted[:blockSize], false).XORKeyStream(decrypted, encrypted[blockSize:])
+
+	return decrypted, nil
+}
+
+func (m *blockMode) error(op string, err error) error {
+	return &CipherError{Algorithm: m.cipher.algorithm, Mode: m.mode, Op: op, Err: err}
+}
+
+func newStream(mode string, block cipher.Block, iv []byte, encrypt bool) cipher.Stream {
+	switch mode {
+	case "cfb":
+		if encrypt {
+			return cipher.NewCFBEncrypter(block, iv)
+		}
+		return cipher.NewCFBDecrypter(block, iv)
+	case "ofb":
+		return cipher.NewOFB(block, iv)
+	default:
+		return cipher.NewCTR(block, iv)
+	}
+}
+
+// GCM is the authenticated cipher of GCM mode, it supports associated data and caller supplied nonce.
+type GCM struct {
+	algorithm string
+	aead      cipher.AEAD
+	err       error
+}
+
+// NonceSize returns the nonce size of Seal and Open.
+func (g *GCM) NonceSize() int {
+	if g.aead == nil {
+		return 0
+	}
+	return g.aead.NonceSize()
+}
+
+// Encrypt encrypts data with a random nonce, the nonce is prefixed to the result.
+func (g *GCM) Encrypt(data []byte) ([]byte, error) {
+	return g.EncryptWithAAD(data, nil)
+}
+
+// Decrypt decrypts the result of Encrypt, ErrAuthFailed is returned if it has been tampered with.
+func (g *GCM) Decrypt(encrypted []byte) ([]byte, error) {
+	return g.DecryptWithAAD(encrypted, nil)
+}
+
+// EncryptWithAAD encrypts data with a random nonce and authenticates the additional data aad, which is not encrypted
+// and not included in the result. The nonce is prefixed to the result.
+func (g *GCM) EncryptWithAAD(data, aad []byte) ([]byte, error) {
+	if g.err != nil {
+		return nil, g.error("encrypt", g.err)
+	}
+
+	nonce := make([]byte, g.aead.NonceSize(), g.aead.NonceSize()+len(data)+g.aead.Overhead())
+	if _, err := io.ReadFull(rand.Reader, nonce); err != nil {
+		return nil, g.error("encrypt", err)
+	}
+
+	return g.aead.Seal(nonce, nonce, data, aad), nil
+}
+
+// DecryptWithAAD decrypts the result of EncryptWithAAD, the aad should be same as the one used in encryption.
+func (g *GCM) DecryptWithAAD(encrypted, aad []byte) ([]byte, error) {
+	if g.err != nil {
+		return nil, g.error("decrypt", g.err)
+	}
+
+	nonceSize := g.aead.NonceSize()
+	if len(encrypted) < nonceSize+g.aead.Overhead() {
+		return nil, g.error("decrypt", ErrInvalidCiphertext)
+	}
+
+	return g.Open(encrypted[:nonceSize], encrypted[nonceSize:], aad)
+}
+
+// Seal encrypts data with the caller supplied nonce and authenticates aad, the nonce is not included in the result.
+// len(nonce) should be NonceSize(), never reuse a nonce with the same key.
+func (g *GCM) Seal(nonce, data, aad []byte) ([]byte, error) {
+	if g.err != nil {
+		return nil, g.error("encrypt", g.err)
+	}
+	if len(nonce) != g.aead.NonceSize() {
+		return nil, g.error("encrypt", ErrInvalidNonce)
+	}
+
+	return g.aead.Seal(nil, nonce, data, aad), nil
+}
+
+// Open decrypts the result of Seal with the same nonce and aad.
+func (g *GCM) Open(nonce, encrypted, aad []byte) ([]byte, error) {
+	if g.err != nil {
+		return nil, g.error("decrypt", g.err)
+	}
+	if len(nonce) != g.aead.NonceSize() {
+		return nil, g.error("decrypt", ErrInvalidNonce)
+	}
+	if len(encrypted) < g.aead.Overhead() {
+		return nil, g.error("decrypt", ErrInvalidCiphertext)
+	}
+
+	plaintext, err := g.aead.Open(nil, nonce, encrypted, aad)
+	if err != nil {
+		return nil, g.error("decrypt", ErrAuthFailed)
+	}
+
+	return plaintext, nil
+}
+
+func (g *GCM) error(op string, err error) error {
+	return &CipherError{Algorithm: g.algorithm, Mode: "gcm", Op: op, Err: err}
+}
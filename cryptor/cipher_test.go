@@ -0,0 +1,125 @@
+package cryptor
+
+import (
+	"errors"
+	"testing"
+
+	"github.com/duke-git/lancet/v2/internal"
+)
+
+func TestBlockCipher(t *testing.T) {
+	t.Parallel()
+
+	assert := internal.NewAssert(t, "TestBlockCipher")
+
+	data := []byte("hello world")
+	ciphers := map[string]Cipher{
+		"aes-ecb": AES([]byte("abcdefghijklmnop")).ECB(),
+		"aes-cbc": AES([]byte("abcdefghijklmnopabcdefgh")).CBC(),
+		"aes-ctr": AES([]byte("abcdefghijklmnopabcdefghijklmnop")).CTR(),
+		"aes-cfb": AES([]byte("abcdefghijklmnop")).CFB(),
+		"aes-ofb": AES([]byte("abcdefghijklmnop")).OFB(),
+		"aes-gcm": AES([]byte("abcdefghijklmnop")).GCM(),
+		"des-ecb": DES([]byte("abcdefgh")).ECB(),
+		"des-cbc": DES([]byte("abcdefgh")).CBC(),
+		"des-ctr": DES([]byte("abcdefgh")).CTR(),
+		"des-cfb": DES([]byte("abcdefgh")).CFB(),
+		"des-ofb": DES([]byte("abcdefgh")).OFB(),
+	}
+
+	for _, c := range ciphers {
+		encrypted, err := c.Encrypt(data)
+		assert.IsNil(err)
+
+		decrypted, err := c.Decrypt(encrypted)
+		assert.IsNil(err)
+		assert.Equal(data, decrypted)
+
+		empty, err := c.Encrypt(nil)
+		assert.IsNil(err)
+		decrypted, err = c.Decrypt(empty)
+		assert.IsNil(err)
+		assert.Equal(0, len(decrypted))
+
+		_, err = c.Decrypt([]byte("short"))
+		assert.ShouldBeTrue(errors.Is(err, ErrInvalidCiphertext))
+	}
+}
+
+func TestBlockCipher_Errors(t *testing.T) {
+	t.Parallel()
+
+	assert := internal.NewAssert(t, "TestBlockCipher_Errors")
+
+	_, err := AES([]byte("short")).CBC().Encrypt([]byte("hello"))
+	assert.ShouldBeTrue(errors.Is(err, ErrInvalidKeySize))
+	assert.Equal("aes-cbc encrypt: invalid key size", err.Error())
+
+	var cipherErr *CipherError
+	assert.ShouldBeTrue(errors.As(err, &cipherErr))
+	assert.Equal("cbc", cipherErr.Mode)
+
+	_, err = DES([]byte("abcdefghijklmnop")).ECB().Decrypt(make([]byte, 8))
+	assert.ShouldBeTrue(errors.Is(err, ErrInvalidKeySize))
+
+	_, err = DES([]byte("abcdefgh")).GCM().Encrypt([]byte("hello"))
+	assert.IsNotNil(err)
+
+	key := []byte("abcdefghijklmnop")
+	encrypted, _ := AES(key).CBC().Encrypt([]byte("hello"))
+	encrypted[len(encrypted)-1] ^= 0xff
+	_, err = AES(key).CBC().Decrypt(encrypted)
+	assert.ShouldBeTrue(errors.Is(err, ErrInvalidPadding))
+
+	_, err = AES(key).ECB().Decrypt(make([]byte, 15))
+	assert.ShouldBeTrue(errors.Is(err, ErrInvalidCiphertext))
+}
+
+func TestGCM(t *testing.T) {
+	t.Parallel()
+
+	assert := internal.NewAssert(t, "TestGCM")
+
+	key := []byte("abcdefghijklmnopabcdefghijklmnop")
+	gcm := AES(key).GCM()
+	data := []byte("hello world")
+	aad := []byte("user:1")
+
+	encrypted, err := gcm.EncryptWithAAD(data, aad)
+	assert.IsNil(err)
+
+	decrypted, err := gcm.DecryptWithAAD(encrypted, aad)
+	assert.IsNil(err)
+	assert.Equal(data, decrypted)
+
+	_, err = gcm.DecryptWithAAD(encrypted, []byte("user:2"))
+	assert.ShouldBeTrue(errors.Is(err, ErrAuthFailed))
+
+	encrypted[len(encrypted)-1] ^= 0xff
+	_, err = gcm.DecryptWithAAD(encrypted, aad)
+	assert.ShouldBeTrue(errors.Is(err, ErrAuthFailed))
+
+	_, err = gcm.Decrypt([]byte("short"))
+	assert.ShouldBeTrue(errors.Is(err, ErrInvalidCiphertext))
+
+	assert.Equal(12, gcm.NonceSize())
+	nonce := []byte("0123456789ab")
+	sealed, err := gcm.Seal(nonce, data, aad)
+	assert.IsNil(err)
+
+	sealedAgain, _ := gcm.Seal(nonce, data, aad)
+	assert.Equal(sealed, sealedAgain)
+
+	opened, err := gcm.Open(nonce, sealed, aad)
+	assert.IsNil(err)
+	assert.Equal(data, opened)
+
+	_, err = gcm.Seal([]byte("short"), data, aad)
+	assert.ShouldBeTrue(errors.Is(err, ErrInvalidNonce))
+	_, err = gcm.Open(nonce, sealed, nil)
+	assert.ShouldBeTrue(errors.Is(err, ErrAuthFailed))
+
+	_, err = AES([]byte("short")).GCM().Decrypt(encrypted)
+	assert.ShouldBeTrue(errors.Is(err, ErrInvalidKeySize))
+	assert.Equal(0, AES([]byte("short")).GCM().NonceSize())
+}
@@ -4,6 +4,7 @@
 // Package cryptor implements some util functions to encrypt and decrypt.
 // Note:
 // 1. for aes crypt function, the `key` param length should be 16, 24 or 32. if not, will panic.
+// 2. the Aes* and Des* functions panic on invalid key or tampered data, use AES(key) and DES(key) to get errors instead.
 package cryptor
 
 import (
@@ -17,7 +18,7 @@ import (
 	"crypto/sha256"
 	"crypto/x509"
 	"encoding/pem"
-	"io"
+	"errors"
 	"os"
 )
 
@@ -25,131 +26,31 @@ import (
 // len(key) should be 16, 24 or 32.
 // Play: https://go.dev/play/p/jT5irszHx-j
 func AesEcbEncrypt(data, key []byte) []byte {
-	if !isAesKeyLengthValid(len(key)) {
-		panic("aes: invalid key length (must be 16, 24, or 32 bytes)")
-	}
-
-	blockSize := aes.BlockSize
-	dataLen := len(data)
-	padding := blockSize - (dataLen % blockSize)
-	paddedLen := dataLen + padding
-
-	paddedData := make([]byte, paddedLen)
-	copy(paddedData, data)
-
-	for i := dataLen; i < paddedLen; i++ {
-		paddedData[i] = byte(padding)
-	}
-
-	cipher, err := aes.NewCipher(generateAesKey(key, len(key)))
-	if err != nil {
-		panic("aes: failed to create cipher: " + err.Error())
-	}
-
-	encrypted := make([]byte, paddedLen)
-	for bs := 0; bs < paddedLen; bs += blockSize {
-		cipher.Encrypt(encrypted[bs:], paddedData[bs:])
-	}
-
-	return encrypted
+	return mustCrypt(AES(key).ECB().Encrypt(data))
 }
 
 // AesEcbDecrypt decrypt data with key use AES ECB algorithm
-// len(key) should be 16, 24 or 32.
+// len(key) should be 16, 24 or 32. It returns nil if encrypted is empty.
 // Play: https://go.dev/play/p/jT5irszHx-j
 func AesEcbDecrypt(encrypted, key []byte) []byte {
-	if !isAesKeyLengthValid(len(key)) {
-		panic("aes: invalid key length (must be 16, 24, or 32 bytes)")
-	}
-
-	blockSize := aes.BlockSize
-	if len(encrypted)%blockSize != 0 {
-		panic("aes: encrypted data length is not a multiple of block size")
-	}
-
-	cipher, err := aes.NewCipher(generateAesKey(key, len(key)))
-	if err != nil {
-		panic("aes: failed to create cipher: " + err.Error())
-	}
-
-	decrypted := make([]byte, len(encrypted))
-	for i := 0; i < len(encrypted); i += blockSize {
-		cipher.Decrypt(decrypted[i:], encrypted[i:])
-	}
-
-	if len(decrypted) == 0 {
+	if len(encrypted) == 0 {
 		return nil
 	}
-	padding := int(decrypted[len(decrypted)-1])
-	if padding == 0 || padding > blockSize {
-		panic("aes: invalid PKCS#7 padding")
-	}
-	for i := len(decrypted) - padding; i < len(decrypted); i++ {
-		if decrypted[i] != byte(padding) {
-			panic("aes: invalid PKCS#7 padding content")
-		}
-	}
-
-	return decrypted[:len(decrypted)-padding]
+	return mustCrypt(AES(key).ECB().Decrypt(encrypted))
 }
 
 // AesCbcEncrypt encrypt data with key use AES CBC algorithm
 // len(key) should be 16, 24 or 32.
 // Play: https://go.dev/play/p/IOq_g8_lKZD
 func AesCbcEncrypt(data, key []byte) []byte {
-	if !isAesKeyLengthValid(len(key)) {
-		panic("aes: invalid key length (must be 16, 24, or 32 bytes)")
-	}
-
-	block, err := aes.NewCipher(key)
-	if err != nil {
-		panic("aes: failed to create cipher: " + err.Error())
-	}
-
-	padding := aes.BlockSize - len(data)%aes.BlockSize
-	padded := append(data, bytes.Repeat([]byte{byte(padding)}, padding)...)
-
-	iv := make([]byte, aes.BlockSize)
-	if _, err := io.ReadFull(rand.Reader, iv); err != nil {
-		panic("aes: failed to generate IV: " + err.Error())
-	}
-
-	encrypted := make([]byte, len(padded))
-	mode := cipher.NewCBCEncrypter(block, iv)
-	mode.CryptBlocks(encrypted, padded)
-
-	return append(iv, encrypted...)
+	return mustCrypt(AES(key).CBC().Encrypt(data))
 }
 
 // AesCbcDecrypt decrypt data with key use AES CBC algorithm
 // len(key) should be 16, 24 or 32.
 // Play: https://go.dev/play/p/IOq_g8_lKZD
 func AesCbcDecrypt(encrypted, key []byte) []byte {
-	if !isAesKeyLengthValid(len(key)) {
-		panic("aes: invalid key length (must be 16, 24, or 32 bytes)")
-	}
-
-	if len(encrypted) < aes.BlockSize {
-		panic("aes: ciphertext too short")
-	}
-
-	if len(encrypted)%aes.BlockSize != 0 {
-		panic("aes: ciphertext is not a multiple of the block size")
-	}
-
-	iv := encrypted[:aes.BlockSize]
-	ciphertext := encrypted[aes.BlockSize:]
-
-	block, err := aes.NewCipher(key)
-	if err != nil {
-		panic("aes: failed to create cipher: " + err.Error())
-	}
-
-	decrypted := make([]byte, len(ciphertext))
-	mode := cipher.NewCBCDecrypter(block, iv)
-	mode.CryptBlocks(decrypted, ciphertext)
-
-	return pkcs7UnPadding(decrypted)
+	return mustCrypt(AES(key).CBC().Decrypt(encrypted))
 }
 
 // AesCtrCrypt encrypt data with key use AES CTR algorithm
@@ -176,312 +77,87 @@ func AesCtrCrypt(data, key []byte) []byte {
 // len(key) should be 16, 24 or 32.
 // Play: todo
 func AesCtrEncrypt(data, key []byte) []byte {
-	if !isAesKeyLengthValid(len(key)) {
-		panic("aes: invalid key length (must be 16, 24, or 32 bytes)")
-	}
-
-	block, err := aes.NewCipher(key)
-	if err != nil {
-		panic("aes: failed to create cipher: " + err.Error())
-	}
-
-	iv := make([]byte, aes.BlockSize)
-	if _, err := io.ReadFull(rand.Reader, iv); err != nil {
-		panic("aes: failed to generate IV: " + err.Error())
-	}
-
-	stream := cipher.NewCTR(block, iv)
-	ciphertext := make([]byte, len(data))
-	stream.XORKeyStream(ciphertext, data)
-
-	return append(iv, ciphertext...)
+	return mustCrypt(AES(key).CTR().Encrypt(data))
 }
 
 // AesCtrDecrypt decrypt data with key use AES CTR algorithm
 // len(key) should be 16, 24 or 32.
 // Play: todo
 func AesCtrDecrypt(encrypted, key []byte) []byte {
-	if !isAesKeyLengthValid(len(key)) {
-		panic("aes: invalid key length (must be 16, 24, or 32 bytes)")
-	}
-	if len(encrypted) < aes.BlockSize {
-		panic("aes: invalid ciphertext length")
-	}
-
-	iv := encrypted[:aes.BlockSize]
-	ciphertext := encrypted[aes.BlockSize:]
-
-	block, err := aes.NewCipher(key)
-	if err != nil {
-		panic("aes: failed to create cipher: " + err.Error())
-	}
-
-	stream := cipher.NewCTR(block, iv)
-	plaintext := make([]byte, len(ciphertext))
-	stream.XORKeyStream(plaintext, ciphertext)
-
-	return plaintext
+	return mustCrypt(AES(key).CTR().Decrypt(encrypted))
 }
 
 // AesCfbEncrypt encrypt data with key use AES CFB algorithm
 // len(key) should be 16, 24 or 32.
 // Play: https://go.dev/play/p/tfkF10B13kH
 func AesCfbEncrypt(data, key []byte) []byte {
-	if !isAesKeyLengthValid(len(key)) {
-		panic("aes: invalid key length (must be 16, 24, or 32 bytes)")
-	}
-
-	block, err := aes.NewCipher(key)
-	if err != nil {
-		panic("aes: failed to create cipher: " + err.Error())
-	}
-
-	iv := make([]byte, aes.BlockSize)
-	if _, err := io.ReadFull(rand.Reader, iv); err != nil {
-		panic("aes: failed to generate IV: " + err.Error())
-	}
-
-	ciphertext := make([]byte, len(data))
-	stream := cipher.NewCFBEncrypter(block, iv)
-	stream.XORKeyStream(ciphertext, data)
-
-	return append(iv, ciphertext...)
+	return mustCrypt(AES(key).CFB().Encrypt(data))
 }
 
 // AesCfbDecrypt decrypt data with key use AES CFB algorithm
 // len(encrypted) should be great than 16, len(key) should be 16, 24 or 32.
 // Play: https://go.dev/play/p/tfkF10B13kH
 func AesCfbDecrypt(encrypted, key []byte) []byte {
-	if !isAesKeyLengthValid(len(key)) {
-		panic("aes: invalid key length (must be 16, 24, or 32 bytes)")
-	}
-
-	if len(encrypted) < aes.BlockSize {
-		panic("aes: encrypted data too short")
-	}
-
-	iv := encrypted[:aes.BlockSize]
-	ciphertext := encrypted[aes.BlockSize:]
-
-	block, err := aes.NewCipher(key)
-	if err != nil {
-		panic("aes: failed to create cipher: " + err.Error())
-	}
-
-	plaintext := make([]byte, len(ciphertext))
-	stream := cipher.NewCFBDecrypter(block, iv)
-	stream.XORKeyStream(plaintext, ciphertext)
-
-	return plaintext
+	return mustCrypt(AES(key).CFB().Decrypt(encrypted))
 }
 
 // AesOfbEncrypt encrypt data with key use AES OFB algorithm
 // len(key) should be 16, 24 or 32.
 // Play: https://go.dev/play/p/VtHxtkUj-3F
 func AesOfbEncrypt(data, key []byte) []byte {
-	if !isAesKeyLengthValid(len(key)) {
-		panic("aes: invalid key length (must be 16, 24, or 32 bytes)")
-	}
-
-	block, err := aes.NewCipher(key)
-	if err != nil {
-		panic("aes: failed to create cipher: " + err.Error())
-	}
-
-	iv := make([]byte, aes.BlockSize)
-	if _, err := io.ReadFull(rand.Reader, iv); err != nil {
-		panic("aes: failed to generate IV: " + err.Error())
-	}
-
-	ciphertext := make([]byte, len(data))
-	stream := cipher.NewOFB(block, iv)
-	stream.XORKeyStream(ciphertext, data)
-
-	return append(iv, ciphertext...)
+	return mustCrypt(AES(key).OFB().Encrypt(data))
 }
 
 // AesOfbDecrypt decrypt data with key use AES OFB algorithm
 // len(key) should be 16, 24 or 32.
 // Play: https://go.dev/play/p/VtHxtkUj-3F
 func AesOfbDecrypt(data, key []byte) []byte {
-	if !isAesKeyLengthValid(len(key)) {
-		panic("aes: invalid key length (must be 16, 24, or 32 bytes)")
-	}
-
-	if len(data) < aes.BlockSize {
-		panic("aes: encrypted data too short")
-	}
-
-	iv := data[:aes.BlockSize]
-	ciphertext := data[aes.BlockSize:]
-
-	block, err := aes.NewCipher(key)
-	if err != nil {
-		panic("aes: failed to create cipher: " + err.Error())
-	}
-
-	plaintext := make([]byte, len(ciphertext))
-	stream := cipher.NewOFB(block, iv)
-	stream.XORKeyStream(plaintext, ciphertext)
-
-	return plaintext
+	return mustCrypt(AES(key).OFB().Decrypt(data))
 }
 
 // AesGcmEncrypt encrypt data with key use AES GCM algorithm
 // Play: https://go.dev/play/p/rUt0-DmsPCs
 func AesGcmEncrypt(data, key []byte) []byte {
-	if !isAesKeyLengthValid(len(key)) {
-		panic("aes: invalid key length (must be 16, 24, or 32 bytes)")
-	}
-
-	block, err := aes.NewCipher(key)
-	if err != nil {
-		panic("aes: failed to create cipher: " + err.Error())
-	}
-
-	gcm, err := cipher.NewGCM(block)
-	if err != nil {
-		panic("aes: failed to create GCM: " + err.Error())
-	}
-
-	nonce := make([]byte, gcm.NonceSize())
-	if _, err := io.ReadFull(rand.Reader, nonce); err != nil {
-		panic("aes: failed to generate nonce: " + err.Error())
-	}
-
-	ciphertext := gcm.Seal(nil, nonce, data, nil)
-
-	return append(nonce, ciphertext...)
+	return mustCrypt(AES(key).GCM().Encrypt(data))
 }
 
 // AesGcmDecrypt decrypt data with key use AES GCM algorithm
 // Play: https://go.dev/play/p/rUt0-DmsPCs
 func AesGcmDecrypt(data, key []byte) []byte {
-	if !isAesKeyLengthValid(len(key)) {
-		panic("aes: invalid key length (must be 16, 24, or 32 bytes)")
-	}
-
-	block, err := aes.NewCipher(key)
-	if err != nil {
-		panic("aes: failed to create cipher: " + err.Error())
-	}
-
-	gcm, err := cipher.NewGCM(block)
-	if err != nil {
-		panic("aes: failed to create GCM: " + err.Error())
-	}
-
-	nonceSize := gcm.NonceSize()
-	if len(data) < nonceSize {
-		panic("aes: ciphertext too short")
-	}
-
-	nonce, ciphertext := data[:nonceSize], data[nonceSize:]
-	plaintext, err := gcm.Open(nil, nonce, ciphertext, nil)
-	if err != nil {
-		panic("aes: decryption failed: " + err.Error())
-	}
-
-	return plaintext
+	return mustCrypt(AES(key).GCM().Decrypt(data))
 }
 
 // DesEcbEncrypt encrypt data with key use DES ECB algorithm
 // len(key) should be 8.
 // Play: https://go.dev/play/p/8qivmPeZy4P
 func DesEcbEncrypt(data, key []byte) []byte {
-	cipher, err := des.NewCipher(generateDesKey(key))
-	if err != nil {
-		panic("des: failed to create cipher: " + err.Error())
-	}
-
-	blockSize := cipher.BlockSize()
-	padded := pkcs5Padding(data, blockSize)
-	encrypted := make([]byte, len(padded))
-
-	for i := 0; i < len(padded); i += blockSize {
-		cipher.Encrypt(encrypted[i:], padded[i:])
-	}
-
-	return encrypted
+	return mustCrypt(DES(generateDesKey(key)).ECB().Encrypt(data))
 }
 
 // DesEcbDecrypt decrypt data with key use DES ECB algorithm
-// len(key) should be 8.
+// len(key) should be 8. It returns nil if encrypted is empty or its padding is malformed.
 // Play: https://go.dev/play/p/8qivmPeZy4P
 func DesEcbDecrypt(encrypted, key []byte) []byte {
-	cipher, err := des.NewCipher(generateDesKey(key))
-	if err != nil {
-		panic("des: failed to create cipher: " + err.Error())
-	}
-
-	blockSize := cipher.BlockSize()
-	if len(encrypted)%blockSize != 0 {
-		panic("des: invalid encrypted data length")
-	}
-
-	decrypted := make([]byte, len(encrypted))
-	for i := 0; i < len(encrypted); i += blockSize {
-		cipher.Decrypt(decrypted[i:], encrypted[i:])
+	decrypted, err := DES(generateDesKey(key)).ECB().Decrypt(encrypted)
+	if len(encrypted) == 0 || errors.Is(err, ErrInvalidPadding) {
+		return nil
 	}
 
-	// Remove padding
-	return pkcs5UnPadding(decrypted)
+	return mustCrypt(decrypted, err)
 }
 
 // DesCbcEncrypt encrypt data with key use DES CBC algorithm
 // len(key) should be 8.
 // Play: https://go.dev/play/p/4cC4QvWfe3_1
 func DesCbcEncrypt(data, key []byte) []byte {
-	if len(key) != 8 {
-		panic("des: key length must be 8 bytes")
-	}
-
-	block, err := des.NewCipher(key)
-	if err != nil {
-		panic("des: failed to create cipher: " + err.Error())
-	}
-
-	blockSize := block.BlockSize()
-	data = pkcs7Padding(data, blockSize)
-
-	encrypted := make([]byte, blockSize+len(data))
-	iv := encrypted[:blockSize]
-
-	if _, err := io.ReadFull(rand.Reader, iv); err != nil {
-		panic("des: failed to generate IV: " + err.Error())
-	}
-
-	mode := cipher.NewCBCEncrypter(block, iv)
-	mode.CryptBlocks(encrypted[blockSize:], data)
-
-	return encrypted
+	return mustCrypt(DES(key).CBC().Encrypt(data))
 }
 
 // DesCbcDecrypt decrypt data with key use DES CBC algorithm
 // len(key) should be 8.
 // Play: https://go.dev/play/p/4cC4QvWfe3_1
 func DesCbcDecrypt(encrypted, key []byte) []byte {
-	if len(key) != 8 {
-		panic("des: key length must be 8 bytes")
-	}
-
-	block, err := des.NewCipher(key)
-	if err != nil {
-		panic("des: failed to create cipher: " + err.Error())
-	}
-
-	blockSize := block.BlockSize()
-	if len(encrypted) < blockSize || len(encrypted)%blockSize != 0 {
-		panic("des: invalid encrypted data length")
-	}
-
-	iv := encrypted[:blockSize]
-	ciphertext := encrypted[blockSize:]
-
-	mode := cipher.NewCBCDecrypter(block, iv)
-	mode.CryptBlocks(ciphertext, ciphertext)
-
-	return pkcs7UnPadding(ciphertext)
+	return mustCrypt(DES(key).CBC().Decrypt(encrypted))
 }
 
 // DesCtrCrypt encrypt data with key use DES CTR algorithm
@@ -509,168 +185,49 @@ func DesCtrCrypt(data, key []byte) []byte {
 // len(key) should be 8.
 // Play: todo
 func DesCtrEncrypt(data, key []byte) []byte {
-	if len(key) != 8 {
-		panic("des: key length must be 8 bytes")
-	}
-
-	block, err := des.NewCipher(key)
-	if err != nil {
-		panic("des: failed to create cipher: " + err.Error())
-	}
-
-	iv := make([]byte, block.BlockSize())
-	if _, err := io.ReadFull(rand.Reader, iv); err != nil {
-		panic("des: failed to generate IV: " + err.Error())
-	}
-
-	stream := cipher.NewCTR(block, iv)
-
-	encrypted := make([]byte, len(data))
-	stream.XORKeyStream(encrypted, data)
-
-	// 返回前缀包含 IV，便于解密
-	return append(iv, encrypted...)
+	return mustCrypt(DES(key).CTR().Encrypt(data))
 }
 
 // DesCtrDecrypt decrypt data with key use DES CTR algorithm
 // len(key) should be 8.
 // Play: todo
 func DesCtrDecrypt(encrypted, key []byte) []byte {
-	if len(key) != 8 {
-		panic("des: key length must be 8 bytes")
-	}
-
-	block, err := des.NewCipher(key)
-	if err != nil {
-		panic("des: failed to create cipher: " + err.Error())
-	}
-
-	blockSize := block.BlockSize()
-	if len(encrypted) < blockSize {
-		panic("des: ciphertext too short")
-	}
-
-	iv := encrypted[:blockSize]
-	ciphertext := encrypted[blockSize:]
-
-	stream := cipher.NewCTR(block, iv)
-
-	decrypted := make([]byte, len(ciphertext))
-	stream.XORKeyStream(decrypted, ciphertext)
-
-	return decrypted
+	return mustCrypt(DES(key).CTR().Decrypt(encrypted))
 }
 
 // DesCfbEncrypt encrypt data with key use DES CFB algorithm
 // len(key) should be 8.
 // Play: https://go.dev/play/p/y-eNxcFBlxL
 func DesCfbEncrypt(data, key []byte) []byte {
-	if len(key) != 8 {
-		panic("des: key length must be 8 bytes")
-	}
-
-	block, err := des.NewCipher(key)
-	if err != nil {
-		panic("des: failed to create cipher: " + err.Error())
-	}
-
-	iv := make([]byte, des.BlockSize)
-	if _, err := io.ReadFull(rand.Reader, iv); err != nil {
-		panic("des: failed to generate IV: " + err.Error())
-	}
-
-	encrypted := make([]byte, des.BlockSize+len(data))
-
-	copy(encrypted[:des.BlockSize], iv)
-
-	stream := cipher.NewCFBEncrypter(block, iv)
-	stream.XORKeyStream(encrypted[des.BlockSize:], data)
-
-	return encrypted
+	return mustCrypt(DES(key).CFB().Encrypt(data))
 }
 
 // DesCfbDecrypt decrypt data with key use DES CFB algorithm
 // len(encrypted) should be great than 16, len(key) should be 8.
 // Play: https://go.dev/play/p/y-eNxcFBlxL
 func DesCfbDecrypt(encrypted, key []byte) []byte {
-	if len(key) != 8 {
-		panic("des: key length must be 8 bytes")
-	}
-
-	block, err := des.NewCipher(key)
-	if err != nil {
-		panic("des: failed to create cipher: " + err.Error())
-	}
-
-	if len(encrypted) < des.BlockSize {
-		panic("des: encrypted data too short")
-	}
-
-	iv := encrypted[:des.BlockSize]
-	ciphertext := encrypted[des.BlockSize:]
-
-	stream := cipher.NewCFBDecrypter(block, iv)
-	stream.XORKeyStream(ciphertext, ciphertext)
-
-	return ciphertext
+	return mustCrypt(DES(key).CFB().Decrypt(encrypted))
 }
 
 // DesOfbEncrypt encrypt data with key use DES OFB algorithm
 // len(key) should be 8.
 // Play: https://go.dev/play/p/74KmNadjN1J
 func DesOfbEncrypt(data, key []byte) []byte {
-	if len(key) != 8 {
-		panic("des: key length must be 8 bytes")
-	}
-
-	block, err := des.NewCipher(key)
-	if err != nil {
-		panic("des: failed to create cipher: " + err.Error())
-	}
-
-	data = pkcs7Padding(data, des.BlockSize)
-
-	iv := make([]byte, des.BlockSize)
-	if _, err := io.ReadFull(rand.Reader, iv); err != nil {
-		panic("des: failed to generate IV: " + err.Error())
-	}
-
-	encrypted := make([]byte, des.BlockSize+len(data))
-	copy(encrypted[:des.BlockSize], iv)
-
-	stream := cipher.NewOFB(block, iv)
-	stream.XORKeyStream(encrypted[des.BlockSize:], data)
-
-	return encrypted
+	return mustCrypt(DES(key).OFB().Encrypt(pkcs7Padding(data, des.BlockSize)))
 }
 
 // DesOfbDecrypt decrypt data with key use DES OFB algorithm
-// len(key) should be 8.
+// len(key) should be 8. It returns nil if the padding of decrypted data is malformed.
 // Play: https://go.dev/play/p/74KmNadjN1J
 func DesOfbDecrypt(data, key []byte) []byte {
-	if len(key) != 8 {
-		panic("des: key length must be 8 bytes")
-	}
+	decrypted := mustCrypt(DES(key).OFB().Decrypt(data))
 
-	block, err := des.NewCipher(key)
+	unpadded, err := pkcs7Unpad(decrypted, des.BlockSize)
 	if err != nil {
-		panic("des: failed to create cipher: " + err.Error())
-	}
-
-	if len(data) < des.BlockSize {
-		panic("des: encrypted data too short")
+		return nil
 	}
 
-	iv := data[:des.BlockSize]
-	ciphertext := data[des.BlockSize:]
-
-	stream := cipher.NewOFB(block, iv)
-	decrypted := make([]byte, len(ciphertext))
-	stream.XORKeyStream(decrypted, ciphertext)
-
-	decrypted = pkcs7UnPadding(decrypted)
-
-	return decrypted
+	return unpadded
 }
 
 // GenerateRsaKey create rsa private and public pemo file.
@@ -731,70 +288,33 @@ func GenerateRsaKey(keySize int, priKeyFile, pubKeyFile string) error {
 // RsaEncrypt encrypt data with ras algorithm.
 // Play: https://go.dev/play/p/7_zo6mrx-eX
 func RsaEncrypt(data []byte, pubKeyFileName string) []byte {
-	file, err := os.Open(pubKeyFileName)
-	if err != nil {
-		panic(err)
-	}
-	fileInfo, err := file.Stat()
-	if err != nil {
-		panic(err)
-	}
-	defer file.Close()
-	buf := make([]byte, fileInfo.Size())
-
-	_, err = file.Read(buf)
-	if err != nil {
-		panic(err)
-	}
-
-	block, _ := pem.Decode(buf)
-
-	pubInterface, err := x509.ParsePKIXPublicKey(block.Bytes)
-	if err != nil {
-		panic(err)
-	}
-	pubKey := pubInterface.(*rsa.PublicKey)
-
-	cipherText, err := rsa.EncryptPKCS1v15(rand.Reader, pubKey, data)
+	pubKey, err := loadRsaPublicKey(pubKeyFileName)
 	if err != nil {
 		panic(err)
 	}
 
-	return cipherText
+	return mustCrypt(RsaEncryptWithKey(data, pubKey))
 }
 
 // RsaDecrypt decrypt data with ras algorithm.
 // Play: https://go.dev/play/p/7_zo6mrx-eX
 func RsaDecrypt(data []byte, privateKeyFileName string) []byte {
-	file, err := os.Open(privateKeyFileName)
-	if err != nil {
-		panic(err)
-	}
-	fileInfo, err := file.Stat()
-	if err != nil {
-		panic(err)
-	}
-	buf := make([]byte, fileInfo.Size())
-	defer file.Close()
-
-	_, err = file.Read(buf)
+	priKey, err := loadRasPrivateKey(privateKeyFileName)
 	if err != nil {
 		panic(err)
 	}
 
-	block, _ := pem.Decode(buf)
-
-	priKey, err := x509.ParsePKCS1PrivateKey(block.Bytes)
-	if err != nil {
-		panic(err)
-	}
+	return mustCrypt(RsaDecryptWithKey(data, priKey))
+}
 
-	plainText, err := rsa.DecryptPKCS1v15(rand.Reader, priKey, data)
-	if err != nil {
-		panic(err)
-	}
+// RsaEncryptWithKey encrypt data with ras public key, the key could be parsed from memory by ParsePublicKeyPEM.
+func RsaEncryptWithKey(data []byte, pubKey *rsa.PublicKey) ([]byte, error) {
+	return rsa.EncryptPKCS1v15(rand.Reader, pubKey, data)
+}
 
-	return plainText
+// RsaDecryptWithKey decrypt data with ras private key, the key could be parsed from memory by ParsePrivateKeyPEM.
+func RsaDecryptWithKey(data []byte, priKey *rsa.PrivateKey) ([]byte, error) {
+	return rsa.DecryptPKCS1v15(rand.Reader, priKey, data)
 }
 
 // GenerateRsaKeyPair create rsa private and public key.
@@ -834,12 +354,7 @@ func RsaSign(hash crypto.Hash, data []byte, privateKeyFileName string) ([]byte,
 		return nil, err
 	}
 
-	hashed, err := hashData(hash, data)
-	if err != nil {
-		return nil, err
-	}
-
-	return rsa.SignPKCS1v15(rand.Reader, privateKey, hash, hashed)
+	return RsaSignWithKey(hash, data, privateKey)
 }
 
 // RsaVerifySign verifies the signature of the data with RSA.
@@ -850,6 +365,21 @@ func RsaVerifySign(hash crypto.Hash, data, signature []byte, pubKeyFileName stri
 		return err
 	}
 
+	return RsaVerifySignWithKey(hash, data, signature, publicKey)
+}
+
+// RsaSignWithKey signs the data with RSA private key, the key could be parsed from memory by ParsePrivateKeyPEM.
+func RsaSignWithKey(hash crypto.Hash, data []byte, privateKey *rsa.PrivateKey) ([]byte, error) {
+	hashed, err := hashData(hash, data)
+	if err != nil {
+		return nil, err
+	}
+
+	return rsa.SignPKCS1v15(rand.Reader, privateKey, hash, hashed)
+}
+
+// RsaVerifySignWithKey verifies the signature of the data with RSA public key.
+func RsaVerifySignWithKey(hash crypto.Hash, data, signature []byte, publicKey *rsa.PublicKey) error {
 	hashed, err := hashData(hash, data)
 	if err != nil {
 		return err
@@ -1,8 +1,13 @@
 package cryptor
 
 import (
+	"bytes"
 	"crypto"
+	"crypto/ed25519"
 	"fmt"
+	"io"
+	"strings"
+	"time"
 )
 
 func ExampleAesEcbEncrypt() {
@@ -583,3 +588,210 @@ func ExampleRsaVerifySign() {
 	// Output:
 	// ok
 }
+
+func ExampleAES() {
+	key := []byte("abcdefghijklmnop")
+
+	encrypted, err := AES(key).CBC().Encrypt([]byte("hello"))
+	if err != nil {
+		return
+	}
+
+	decrypted, err := AES(key).CBC().Decrypt(encrypted)
+	if err != nil {
+		return
+	}
+
+	_, err = AES([]byte("invalid key")).CBC().Encrypt([]byte("hello"))
+
+	fmt.Println(string(decrypted))
+	fmt.Println(err)
+
+	// Output:
+	// hello
+	// aes-cbc encrypt: invalid key size
+}
+
+func ExampleGCM_EncryptWithAAD() {
+	key := []byte("abcdefghijklmnop")
+	gcm := AES(key).GCM()
+
+	encrypted, err := gcm.EncryptWithAAD([]byte("hello"), []byte("user:1"))
+	if err != nil {
+		return
+	}
+
+	decrypted, err := gcm.DecryptWithAAD(encrypted, []byte("user:1"))
+	if err != nil {
+		return
+	}
+
+	_, err = gcm.DecryptWithAAD(encrypted, []byte("user:2"))
+
+	fmt.Println(string(decrypted))
+	fmt.Println(err)
+
+	// Output:
+	// hello
+	// aes-gcm decrypt: message authentication failed
+}
+
+func ExampleNewEncryptWriter() {
+	key := []byte("abcdefghijklmnop")
+
+	var encrypted bytes.Buffer
+	w, err := NewEncryptWriter(&encrypted, key, StreamModeGCM)
+	if err != nil {
+		return
+	}
+	io.Copy(w, strings.NewReader("hello"))
+	w.Close()
+
+	r, err := NewDecryptReader(&encrypted, key, StreamModeGCM)
+	if err != nil {
+		return
+	}
+	decrypted, err := io.ReadAll(r)
+
+	fmt.Println(string(decrypted))
+	fmt.Println(err)
+
+	// Output:
+	// hello
+	// <nil>
+}
+
+func ExampleHashReader() {
+	sha, err := HashReader(strings.NewReader("hello"), crypto.SHA256)
+
+	fmt.Println(sha)
+	fmt.Println(err)
+
+	// Output:
+	// 2cf24dba5fb0a30e26e83b2ac5b9e29e1b161e5c1fa7425e73043362938b9824
+	// <nil>
+}
+
+func ExampleHashPassword() {
+	hash, err := HashPassword("secret")
+	if err != nil {
+		return
+	}
+
+	ok, err := VerifyPassword("secret", hash)
+
+	fmt.Println(ok)
+	fmt.Println(err)
+	fmt.Println(NeedsRehash(hash, DefaultArgon2idParams))
+	fmt.Println(NeedsRehash(hash, DefaultScryptParams))
+
+	// Output:
+	// true
+	// <nil>
+	// false
+	// true
+}
+
+func ExampleParsePrivateKeyPEM() {
+	privateKey, publicKey, err := GenerateEd25519Key()
+	if err != nil {
+		return
+	}
+
+	// the PEM data could be loaded from secret store
+	privatePEM, _ := MarshalPrivateKeyPEM(privateKey)
+	publicPEM, _ := MarshalPublicKeyPEM(publicKey)
+
+	priKey, err := ParsePrivateKeyPEM(privatePEM)
+	if err != nil {
+		return
+	}
+	pubKey, err := ParsePublicKeyPEM(publicPEM)
+	if err != nil {
+		return
+	}
+
+	signature, _ := Ed25519Sign(priKey.(ed25519.PrivateKey), []byte("hello"))
+	err = Ed25519Verify(pubKey.(ed25519.PublicKey), []byte("hello"), signature)
+
+	fmt.Println(err)
+
+	// Output:
+	// <nil>
+}
+
+func ExampleX25519SharedSecret() {
+	alicePrivate, alicePublic, _ := GenerateX25519Key()
+	bobPrivate, bobPublic, _ := GenerateX25519Key()
+
+	secret1, _ := X25519SharedSecret(alicePrivate, bobPublic)
+	secret2, _ := X25519SharedSecret(bobPrivate, alicePublic)
+
+	key, _ := DeriveKeyHKDF(secret1, nil, []byte("aes key"), 32)
+
+	fmt.Println(bytes.Equal(secret1, secret2))
+	fmt.Println(len(key))
+
+	// Output:
+	// true
+	// 32
+}
+
+func ExampleVerifyJWT() {
+	type UserClaims struct {
+		RegisteredClaims
+		Role string `json:"role"`
+	}
+
+	secret := []byte("0123456789abcdef0123456789abcdef")
+	claims := UserClaims{
+		RegisteredClaims: RegisteredClaims{Subject: "lancet", Audience: Audience{"api"}, ExpiresAt: 1700003600},
+		Role:             "admin",
+	}
+
+	token, err := SignJWT(claims, HS256, secret, "")
+	if err != nil {
+		return
+	}
+
+	result, err := VerifyJWT[UserClaims](token, secret, JWTVerifyOptions{
+		Algorithms: []JWTAlgorithm{HS256},
+		Audience:   "api",
+		ClockSkew:  time.Minute,
+		Now:        func() time.Time { return time.Unix(1700000000, 0) },
+	})
+
+	fmt.Println(result.Subject, result.Role, err)
+
+	// Output:
+	// lancet admin <nil>
+}
+
+func ExampleEnvelopeEncrypt() {
+	oldKey, _ := NewAesMasterKey("2023-01", []byte("0123456789abcdef0123456789abcdef"))
+	ring, _ := NewKeyRing(oldKey)
+
+	envelope, err := EnvelopeEncrypt([]byte("hello"), nil, ring)
+	if err != nil {
+		return
+	}
+
+	// rotate the master key, the old envelope is still decryptable
+	newKey, _ := NewAesMasterKey("2023-02", []byte("abcdefghijklmnopabcdefghijklmnop"))
+	ring.Rotate(newKey)
+
+	envelope, err = EnvelopeReEncrypt(envelope, nil, ring)
+	if err != nil {
+		return
+	}
+
+	header, _ := ParseEnvelopeHeader(envelope)
+	data, err := EnvelopeDecrypt(envelope, nil, ring)
+
+	fmt.Println(header.KeyID)
+	fmt.Println(string(data), err)
+
+	// Output:
+	// 2023-02
+	// hello <nil>
+}
@@ -6,24 +6,9 @@ import (
 	"crypto/rsa"
 	"crypto/sha256"
 	"crypto/sha512"
-	"crypto/x509"
-	"encoding/pem"
 	"errors"
-	"os"
-	"strings"
 )
 
-func generateAesKey(key []byte, size int) []byte {
-	genKey := make([]byte, size)
-	copy(genKey, key)
-	for i := size; i < len(key); {
-		for j := 0; j < size && i < len(key); j, i = j+1, i+1 {
-			genKey[j] ^= key[i]
-		}
-	}
-	return genKey
-}
-
 func generateDesKey(key []byte) []byte {
 	genKey := make([]byte, 8)
 	copy(genKey, key)
@@ -35,124 +20,54 @@ func generateDesKey(key []byte) []byte {
 	return genKey
 }
 
+// pkcs7Padding returns a padded copy of src, src is not modified.
 func pkcs7Padding(src []byte, blockSize int) []byte {
 	padding := blockSize - len(src)%blockSize
-	padText := bytes.Repeat([]byte{byte(padding)}, padding)
-	return append(src, padText...)
+	padded := make([]byte, len(src), len(src)+padding)
+	copy(padded, src)
+	return append(padded, bytes.Repeat([]byte{byte(padding)}, padding)...)
 }
 
-func pkcs7UnPadding(src []byte) []byte {
+// pkcs7Unpad removes the padding of src, ErrInvalidPadding is returned if it is malformed.
+func pkcs7Unpad(src []byte, blockSize int) ([]byte, error) {
 	length := len(src)
-	unPadding := int(src[length-1])
-	return src[:(length - unPadding)]
-}
+	if length == 0 || length%blockSize != 0 {
+		return nil, ErrInvalidPadding
+	}
 
-func pkcs5Padding(data []byte, blockSize int) []byte {
-	padding := blockSize - len(data)%blockSize
-	padText := bytes.Repeat([]byte{byte(padding)}, padding)
-	return append(data, padText...)
+	padding := int(src[length-1])
+	if padding == 0 || padding > blockSize {
+		return nil, ErrInvalidPadding
+	}
+	for _, b := range src[length-padding:] {
+		if int(b) != padding {
+			return nil, ErrInvalidPadding
+		}
+	}
+
+	return src[:length-padding], nil
 }
 
-func pkcs5UnPadding(data []byte) []byte {
-	length := len(data)
-	if length == 0 {
-		return nil
-	}
-	padLen := int(data[length-1])
-	if padLen == 0 || padLen > length {
-		return nil
+// mustCrypt returns the result of Cipher, it panics if err is not nil.
+func mustCrypt(data []byte, err error) []byte {
+	if err != nil {
+		panic(err)
 	}
-	return data[:length-padLen]
+	return data
 }
 
 func isAesKeyLengthValid(n int) bool {
 	return n == 16 || n == 24 || n == 32
 }
 
-// loadRsaPrivateKey loads and parses a PEM encoded private key file.
+// loadRsaPublicKey loads and parses a PEM encoded public key file.
 func loadRsaPublicKey(filename string) (*rsa.PublicKey, error) {
-	pubKeyData, err := os.ReadFile(filename)
-	if err != nil {
-		return nil, err
-	}
-
-	block, _ := pem.Decode(pubKeyData)
-	if block == nil {
-		return nil, errors.New("failed to decode PEM block containing the public key")
-	}
-
-	var pubKey *rsa.PublicKey
-	blockType := strings.ToUpper(block.Type)
-
-	if blockType == "RSA PUBLIC KEY" {
-		pubKey, err = x509.ParsePKCS1PublicKey(block.Bytes)
-		if err != nil {
-			key, err := x509.ParsePKIXPublicKey(block.Bytes)
-			if err != nil {
-				return nil, err
-			}
-
-			var ok bool
-			pubKey, ok = key.(*rsa.PublicKey)
-			if !ok {
-				return nil, errors.New("failed to parse RSA private key")
-			}
-		}
-	} else if blockType == "PUBLIC KEY" {
-		key, err := x509.ParsePKIXPublicKey(block.Bytes)
-		if err != nil {
-			return nil, err
-		}
-
-		var ok bool
-		pubKey, ok = key.(*rsa.PublicKey)
-		if !ok {
-			return nil, errors.New("failed to parse RSA private key")
-		}
-
-	} else {
-		return nil, errors.New("unsupported key type")
-	}
-
-	return pubKey, nil
+	return rsaPublicKey(LoadPublicKey(filename))
 }
 
 // loadRsaPrivateKey loads and parses a PEM encoded private key file.
 func loadRasPrivateKey(filename string) (*rsa.PrivateKey, error) {
-	priKeyData, err := os.ReadFile(filename)
-	if err != nil {
-		return nil, err
-	}
-
-	block, _ := pem.Decode(priKeyData)
-	if block == nil {
-		return nil, errors.New("failed to decode PEM block containing the private key")
-	}
-
-	var privateKey *rsa.PrivateKey
-	blockType := strings.ToUpper(block.Type)
-
-	// PKCS#1 format
-	if blockType == "RSA PRIVATE KEY" {
-		privateKey, err = x509.ParsePKCS1PrivateKey(block.Bytes)
-		if err != nil {
-			return nil, err
-		}
-	} else if blockType == "PRIVATE KEY" { // PKCS#8 format
-		priKey, err := x509.ParsePKCS8PrivateKey(block.Bytes)
-		if err != nil {
-			return nil, err
-		}
-		var ok bool
-		privateKey, ok = priKey.(*rsa.PrivateKey)
-		if !ok {
-			return nil, errors.New("failed to parse RSA private key")
-		}
-	} else {
-		return nil, errors.New("unsupported key type")
-	}
-
-	return privateKey, nil
+	return rsaPrivateKey(LoadPrivateKey(filename))
 }
 
 // hashData returns the hash value of the data, using the specified hash function
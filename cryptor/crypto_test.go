@@ -2,6 +2,7 @@ package cryptor
 
 import (
 	"crypto"
+	"crypto/des"
 	"testing"
 
 	"github.com/duke-git/lancet/v2/internal"
@@ -18,6 +19,7 @@ func TestAesEcbCrypt(t *testing.T) {
 
 	assert := internal.NewAssert(t, "TestAesEcbCrypt")
 	assert.Equal(data, string(aesEcbDecrypt))
+	assert.Equal([]byte(nil), AesEcbDecrypt(nil, []byte(key)))
 }
 
 func TestAesCbcCrypt(t *testing.T) {
@@ -83,6 +85,13 @@ func TestDesEcbCrypt(t *testing.T) {
 
 	assert := internal.NewAssert(t, "TestDesEcbEncrypt")
 	assert.Equal(data, string(desEcbDecrypt))
+
+	// the legacy function tolerates empty data and malformed padding
+	block, _ := des.NewCipher([]byte(key))
+	malformed := make([]byte, des.BlockSize)
+	block.Encrypt(malformed, []byte("abcdefg\x00"))
+	assert.Equal([]byte(nil), DesEcbDecrypt(nil, []byte(key)))
+	assert.Equal([]byte(nil), DesEcbDecrypt(malformed, []byte(key)))
 }
 
 func TestDesCbcCrypt(t *testing.T) {
@@ -135,6 +144,7 @@ func TestDesOfbCrypt(t *testing.T) {
 
 	assert := internal.NewAssert(t, "TestDesOfbEncrypt")
 	assert.Equal(data, string(desOfbDecrypt))
+	assert.Equal([]byte(nil), DesOfbDecrypt(desOfbEncrypt[:12], []byte(key)))
 }
 
 func TestRsaEncrypt(t *testing.T) {
@@ -0,0 +1,399 @@
+// Copyright 2023 dudaodong@gmail.com. All rights reserved.
+// Use of this source code is governed by MIT license
+
+package cryptor
+
+import (
+	"crypto/rand"
+	"crypto/rsa"
+	"encoding/binary"
+	"errors"
+	"fmt"
+	"io"
+	"sync"
+)
+
+const (
+	// EnvelopeVersion is the version of envelope header written by EnvelopeEncrypt.
+	EnvelopeVersion = 1
+	// EnvelopeAlgorithm is the content encryption algorithm of envelope, the data key is 32 bytes.
+	EnvelopeAlgorithm = "A256GCM"
+
+	// envelopeMagic is the first byte of envelope, it distinguishes envelope from other ciphertext
+	envelopeMagic = 0xe7
+)
+
+var (
+	// ErrKeyNotFound is returned when the key id of envelope is not in the key ring.
+	ErrKeyNotFound = errors.New("key not found")
+	// ErrInvalidEnvelope is returned when the envelope header is malformed or not supported.
+	ErrInvalidEnvelope = errors.New("invalid envelope")
+)
+
+// MasterKey wraps and unwraps the data keys of envelope encryption, it is called key encryption key (KEK) also.
+type MasterKey interface {
+	// ID is the unique id of the key, it is stored in the envelope header.
+	ID() string
+	// Algorithm is the key wrapping algorithm, it is stored in the envelope header.
+	Algorithm() string
+	// WrapKey encrypts the data key.
+	WrapKey(dataKey []byte) ([]byte, error)
+	// UnwrapKey decrypts the result of WrapKey.
+	UnwrapKey(wrappedKey []byte) ([]byte, error)
+}
+
+// KeyRing holds the master keys, new data is encrypted with the primary key,
+// and the old data is decrypted with the key of id in the envelope header.
+type KeyRing interface {
+	// Primary returns the master key to encrypt new data.
+	Primary() (MasterKey, error)
+	// Key returns the master key of id, ErrKeyNotFound should be returned if it doesn't exist.
+	Key(id string) (MasterKey, error)
+}
+
+// NewAesMasterKey creates a master key which wraps data keys with AES-GCM, len(key) should be 16, 24 or 32.
+func NewAesMasterKey(id string, key []byte) (MasterKey, error) {
+	if err := validateMasterKeyID(id); err != nil {
+		return nil, err
+	}
+
+	gcm := AES(key).GCM()
+	if gcm.err != nil {
+		return nil, gcm.error("encrypt", gcm.err)
+	}
+
+	return &aesMasterKey{id: id, gcm: gcm, algorithm: fmt.Sprintf("A%dGCMKW", len(key)*8)}, nil
+}
+
+// NewRsaMasterKey creates a master key which wraps data keys with RSA-OAEP-256, the private key is required
+// only for decryption, so the encrypting side could hold the public key only (privateKey is nil).
+func NewRsaMasterKey(id string, publicKey *rsa.PublicKey, privateKey *rsa.PrivateKey) (MasterKey, error) {
+	if err := validateMasterKeyID(id); err != nil {
+		return nil, err
+	}
+
+	if publicKey == nil {
+		if privateKey == nil {
+			return nil, errors.New("rsa master key requires public key or private key")
+		}
+		publicKey = &privateKey.PublicKey
+	}
+
+	return &rsaMasterKey{id: id, publicKey: publicKey, privateKey: privateKey}, nil
+}
+
+type aesMasterKey struct {
+	id        string
+	gcm       *GCM
+	algorithm string
+}
+
+func (k *aesMasterKey) ID() string {
+	return k.id
+}
+
+func (k *aesMasterKey) Algorithm() string {
+	return k.algorithm
+}
+
+// WrapKey binds the wrapped key to the key id
+func (k *aesMasterKey) WrapKey(dataKey []byte) ([]byte, error) {
+	return k.gcm.EncryptWithAAD(dataKey, []byte(k.id))
+}
+
+func (k *aesMasterKey) UnwrapKey(wrappedKey []byte) ([]byte, error) {
+	return k.gcm.DecryptWithAAD(wrappedKey, []byte(k.id))
+}
+
+type rsaMasterKey struct {
+	id         string
+	publicKey  *rsa.PublicKey
+	privateKey *rsa.PrivateKey
+}
+
+func (k *rsaMasterKey) ID() string {
+	return k.id
+}
+
+func (k *rsaMasterKey) Algorithm() string {
+	return string(RSAOAEP256)
+}
+
+func (k *rsaMasterKey) WrapKey(dataKey []byte) ([]byte, error) {
+	return RsaEncryptOAEP(dataKey, []byte(k.id), *k.publicKey)
+}
+
+func (k *rsaMasterKey) UnwrapKey(wrappedKey []byte) ([]byte, error) {
+	if k.privateKey == nil {
+		return nil, fmt.Errorf("rsa master key %q has no private key", k.id)
+	}
+	return RsaDecryptOAEP(wrappedKey, []byte(k.id), *k.privateKey)
+}
+
+// StaticKeyRing is a concurrency safe in-memory KeyRing, the last rotated key is the primary key.
+type StaticKeyRing struct {
+	mu      sync.RWMutex
+	keys    map[string]MasterKey
+	primary string
+}
+
+// NewKeyRing creates a StaticKeyRing with keys, the last key is the primary key.
+func NewKeyRing(keys ...MasterKey) (*StaticKeyRing, error) {
+	ring := &StaticKeyRing{keys: make(map[string]MasterKey)}
+	for _, key := range keys {
+		if err := ring.Rotate(key); err != nil {
+			return nil, err
+		}
+	}
+	return ring, nil
+}
+
+// Primary returns the primary key.
+func (ring *StaticKeyRing) Primary() (MasterKey, error) {
+	ring.mu.RLock()
+	defer ring.mu.RUnlock()
+
+	if ring.primary == "" {
+		return nil, fmt.Errorf("%w: key ring is empty", ErrKeyNotFound)
+	}
+	return ring.keys[ring.primary], nil
+}
+
+// Key returns the key of id.
+func (ring *StaticKeyRing) Key(id string) (MasterKey, error) {
+	ring.mu.RLock()
+	defer ring.mu.RUnlock()
+
+	key, ok := ring.keys[id]
+	if !ok {
+		return nil, fmt.Errorf("%w: %q", ErrKeyNotFound, id)
+	}
+	return key, nil
+}
+
+// Add adds key for decryption of old data without changing the primary key.
+func (ring *StaticKeyRing) Add(key MasterKey) error {
+	ring.mu.Lock()
+	defer ring.mu.Unlock()
+
+	return ring.add(key, false)
+}
+
+// Rotate adds key and makes it the primary key, the old keys are kept for decryption.
+func (ring *StaticKeyRing) Rotate(key MasterKey) error {
+	ring.mu.Lock()
+	defer ring.mu.Unlock()
+
+	return ring.add(key, true)
+}
+
+func (ring *StaticKeyRing) add(key MasterKey, primary bool) error {
+	if _, ok := ring.keys[key.ID()]; ok {
+		return fmt.Errorf("duplicate master key id %q", key.ID())
+	}
+
+	ring.keys[key.ID()] = key
+	if primary || ring.primary == "" {
+		ring.primary = key.ID()
+	}
+
+	return nil
+}
+
+// Remove removes the key of id, data encrypted with it can not be decrypted anymore.
+// The primary key can not be removed.
+func (ring *StaticKeyRing) Remove(id string) error {
+	ring.mu.Lock()
+	defer ring.mu.Unlock()
+
+	if id == ring.primary {
+		return errors.New("can not remove the primary key")
+	}
+	if _, ok := ring.keys[id]; !ok {
+		return fmt.Errorf("%w: %q", ErrKeyNotFound, id)
+	}
+	delete(ring.keys, id)
+
+	return nil
+}
+
+// EnvelopeHeader is the self-describing header of envelope.
+type EnvelopeHeader struct {
+	Version       uint8
+	KeyID         string
+	WrapAlgorithm string
+	Algorithm     string
+	WrappedKey    []byte
+	Nonce         []byte
+}
+
+// EnvelopeEncrypt encrypts data with a random data key by AES-256-GCM, wraps the data key with the primary key
+// of ring and returns the envelope which is the header followed by the ciphertext.
+// The aad is authenticated but not stored, the same aad must be passed to EnvelopeDecrypt, it could be nil.
+func EnvelopeEncrypt(data, aad []byte, ring KeyRing) ([]byte, error) {
+	masterKey, err := ring.Primary()
+	if err != nil {
+		return nil, err
+	}
+
+	dataKey := make([]byte, 32)
+	if _, err := io.ReadFull(rand.Reader, dataKey); err != nil {
+		return nil, err
+	}
+
+	wrappedKey, err := masterKey.WrapKey(dataKey)
+	if err != nil {
+		return nil, fmt.Errorf("wrap data key with %q: %w", masterKey.ID(), err)
+	}
+
+	gcm := AES(dataKey).GCM()
+	nonce := make([]byte, gcm.NonceSize())
+	if _, err := io.ReadFull(rand.Reader, nonce); err != nil {
+		return nil, err
+	}
+
+	header := EnvelopeHeader{
+		Version:       EnvelopeVersion,
+		KeyID:         masterKey.ID(),
+		WrapAlgorithm: masterKey.Algorithm(),
+		Algorithm:     EnvelopeAlgorithm,
+		WrappedKey:    wrappedKey,
+		Nonce:         nonce,
+	}
+
+	envelope, err := header.marshal()
+	if err != nil {
+		return nil, err
+	}
+
+	// the header is authenticated together with aad
+	ciphertext, err := gcm.Seal(nonce, data, append(envelope[:len(envelope):len(envelope)], aad...))
+	if err != nil {
+		return nil, err
+	}
+
+	return append(envelope, ciphertext...), nil
+}
+
+// EnvelopeDecrypt decrypts the envelope created by EnvelopeEncrypt with the key of ring by the key id in header.
+func EnvelopeDecrypt(envelope, aad []byte, ring KeyRing) ([]byte, error) {
+	header, headerSize, err := parseEnvelopeHeader(envelope)
+	if err != nil {
+		return nil, err
+	}
+
+	masterKey, err := ring.Key(header.KeyID)
+	if err != nil {
+		return nil, err
+	}
+	if masterKey.Algorithm() != header.WrapAlgorithm {
+		return nil, fmt.Errorf("%w: wrap algorithm %q doesn't match key %q", ErrInvalidEnvelope,
+			header.WrapAlgorithm, header.KeyID)
+	}
+
+	dataKey, err := masterKey.UnwrapKey(header.WrappedKey)
+	if err != nil {
+		return nil, fmt.Errorf("unwrap data key with %q: %w", header.KeyID, err)
+	}
+	if len(dataKey) != 32 {
+		return nil, fmt.Errorf("%w: invalid data key size", ErrInvalidEnvelope)
+	}
+
+	aadWithHeader := append(envelope[:headerSize:headerSize], aad...)
+
+	return AES(dataKey).GCM().Open(header.Nonce, envelope[headerSize:], aadWithHeader)
+}
+
+// EnvelopeReEncrypt decrypts the envelope and encrypts it again with the primary key of ring and a new data key.
+// It is used to migrate the old data after key rotation, the envelope which has been encrypted with
+// the primary key is re-encrypted also, use ParseEnvelopeHeader to skip it.
+func EnvelopeReEncrypt(envelope, aad []byte, ring KeyRing) ([]byte, error) {
+	data, err := EnvelopeDecrypt(envelope, aad, ring)
+	if err != nil {
+		return nil, err
+	}
+	return EnvelopeEncrypt(data, aad, ring)
+}
+
+// ParseEnvelopeHeader parses the header of envelope without decryption.
+func ParseEnvelopeHeader(envelope []byte) (EnvelopeHeader, error) {
+	header, _, err := parseEnvelopeHeader(envelope)
+	return header, err
+}
+
+// marshal encodes header as: magic(1) version(1) then the length prefixed fields
+// key id(1), wrap algorithm(1), algorithm(1), wrapped key(2) and nonce(1)
+func (h EnvelopeHeader) marshal() ([]byte, error) {
+	if len(h.KeyID) > 255 || len(h.WrapAlgorithm) > 255 || len(h.Algorithm) > 255 ||
+		len(h.WrappedKey) > 65535 || len(h.Nonce) > 255 {
+		return nil, fmt.Errorf("%w: header field is too long", ErrInvalidEnvelope)
+	}
+
+	buf := []byte{envelopeMagic, h.Version}
+	for _, field := range []string{h.KeyID, h.WrapAlgorithm, h.Algorithm} {
+		buf = append(buf, byte(len(field)))
+		buf = append(buf, field...)
+	}
+	buf = append(buf, byte(len(h.WrappedKey)>>8), byte(len(h.WrappedKey)))
+	buf = append(buf, h.WrappedKey...)
+	buf = append(buf, byte(len(h.Nonce)))
+	buf = append(buf, h.Nonce...)
+
+	return buf, nil
+}
+
+// parseEnvelopeHeader returns the header and its size
+func parseEnvelopeHeader(envelope []byte) (EnvelopeHeader, int, error) {
+	var header EnvelopeHeader
+
+	if len(envelope) < 2 || envelope[0] != envelopeMagic {
+		return header, 0, ErrInvalidEnvelope
+	}
+	header.Version = envelope[1]
+	if header.Version != EnvelopeVersion {
+		return header, 0, fmt.Errorf("%w: unsupported version %d", ErrInvalidEnvelope, header.Version)
+	}
+
+	offset := 2
+	next := func(lengthSize int) ([]byte, bool) {
+		if len(envelope) < offset+lengthSize {
+			return nil, false
+		}
+		n := int(envelope[offset])
+		if lengthSize == 2 {
+			n = int(binary.BigEndian.Uint16(envelope[offset:]))
+		}
+		offset += lengthSize
+		if len(envelope) < offset+n {
+			return nil, false
+		}
+		field := envelope[offset : offset+n]
+		offset += n
+		return field, true
+	}
+
+	fields := make([][]byte, 5)
+	for i, lengthSize := range []int{1, 1, 1, 2, 1} {
+		field, ok := next(lengthSize)
+		if !ok {
+			return header, 0, ErrInvalidEnvelope
+		}
+		fields[i] = field
+	}
+
+	header.KeyID, header.WrapAlgorithm, header.Algorithm = string(fields[0]), string(fields[1]), string(fields[2])
+	header.WrappedKey, header.Nonce = fields[3], fields[4]
+
+	if header.Algorithm != EnvelopeAlgorithm {
+		return header, 0, fmt.Errorf("%w: unsupported algorithm %q", ErrInvalidEnvelope, header.Algorithm)
+	}
+
+	return header, offset, nil
+}
+
+func validateMasterKeyID(id string) error {
+	if id == "" || len(id) > 255 {
+		return errors.New("master key id should be 1 to 255 bytes")
+	}
+	return nil
+}
@@ -0,0 +1,144 @@
+package cryptor
+
+import (
+	"crypto/rand"
+	"crypto/rsa"
+	"errors"
+	"testing"
+
+	"github.com/duke-git/lancet/v2/internal"
+)
+
+func TestEnvelopeEncrypt(t *testing.T) {
+	t.Parallel()
+	assert := internal.NewAssert(t, "TestEnvelopeEncrypt")
+
+	aesKey, err := NewAesMasterKey("aes-1", []byte("0123456789abcdef0123456789abcdef"))
+	assert.IsNil(err)
+	assert.Equal("A256GCMKW", aesKey.Algorithm())
+
+	rsaPrivateKey, _ := rsa.GenerateKey(rand.Reader, 2048)
+	rsaKey, err := NewRsaMasterKey("rsa-1", nil, rsaPrivateKey)
+	assert.IsNil(err)
+
+	for _, masterKey := range []MasterKey{aesKey, rsaKey} {
+		ring, err := NewKeyRing(masterKey)
+		assert.IsNil(err)
+
+		data := []byte("hello lancet")
+		aad := []byte("user:1")
+
+		envelope, err := EnvelopeEncrypt(data, aad, ring)
+		assert.IsNil(err)
+
+		header, err := ParseEnvelopeHeader(envelope)
+		assert.IsNil(err)
+		assert.Equal(uint8(EnvelopeVersion), header.Version)
+		assert.Equal(masterKey.ID(), header.KeyID)
+		assert.Equal(masterKey.Algorithm(), header.WrapAlgorithm)
+		assert.Equal(EnvelopeAlgorithm, header.Algorithm)
+		assert.Equal(12, len(header.Nonce))
+
+		decrypted, err := EnvelopeDecrypt(envelope, aad, ring)
+		assert.IsNil(err)
+		assert.Equal(data, decrypted)
+
+		_, err = EnvelopeDecrypt(envelope, []byte("user:2"), ring)
+		assert.Equal(true, errors.Is(err, ErrAuthFailed))
+
+		// every byte of header and ciphertext is authenticated
+		for i := 0; i < len(envelope); i += 7 {
+			tampered := append([]byte{}, envelope...)
+			tampered[i] ^= 1
+			_, err = EnvelopeDecrypt(tampered, aad, ring)
+			assert.IsNotNil(err)
+		}
+
+		_, err = EnvelopeDecrypt(envelope[:len(envelope)-1], aad, ring)
+		assert.IsNotNil(err)
+	}
+}
+
+func TestEnvelopeKeyRotation(t *testing.T) {
+	t.Parallel()
+	assert := internal.NewAssert(t, "TestEnvelopeKeyRotation")
+
+	oldKey, _ := NewAesMasterKey("v1", []byte("0123456789abcdef"))
+	newKey, _ := NewAesMasterKey("v2", []byte("fedcba9876543210fedcba9876543210"))
+
+	ring, err := NewKeyRing(oldKey)
+	assert.IsNil(err)
+
+	oldEnvelope, err := EnvelopeEncrypt([]byte("old data"), nil, ring)
+	assert.IsNil(err)
+
+	assert.IsNil(ring.Rotate(newKey))
+	primary, _ := ring.Primary()
+	assert.Equal("v2", primary.ID())
+
+	// the old data is still decryptable by key id
+	decrypted, err := EnvelopeDecrypt(oldEnvelope, nil, ring)
+	assert.IsNil(err)
+	assert.Equal("old data", string(decrypted))
+
+	newEnvelope, err := EnvelopeReEncrypt(oldEnvelope, nil, ring)
+	assert.IsNil(err)
+	header, _ := ParseEnvelopeHeader(newEnvelope)
+	assert.Equal("v2", header.KeyID)
+
+	// the old key could be removed after all data is re-encrypted
+	assert.IsNil(ring.Remove("v1"))
+	_, err = EnvelopeDecrypt(oldEnvelope, nil, ring)
+	assert.Equal(true, errors.Is(err, ErrKeyNotFound))
+
+	decrypted, err = EnvelopeDecrypt(newEnvelope, nil, ring)
+	assert.IsNil(err)
+	assert.Equal("old data", string(decrypted))
+
+	assert.IsNotNil(ring.Remove("v2"))
+	assert.IsNotNil(ring.Add(newKey))
+}
+
+func TestEnvelopeErrors(t *testing.T) {
+	t.Parallel()
+	assert := internal.NewAssert(t, "TestEnvelopeErrors")
+
+	_, err := NewAesMasterKey("k", []byte("short"))
+	assert.Equal(true, errors.Is(err, ErrInvalidKeySize))
+
+	_, err = NewAesMasterKey("", []byte("0123456789abcdef"))
+	assert.IsNotNil(err)
+
+	_, err = NewRsaMasterKey("k", nil, nil)
+	assert.IsNotNil(err)
+
+	ring, _ := NewKeyRing()
+	_, err = EnvelopeEncrypt([]byte("data"), nil, ring)
+	assert.Equal(true, errors.Is(err, ErrKeyNotFound))
+
+	_, err = EnvelopeDecrypt([]byte("not an envelope"), nil, ring)
+	assert.Equal(true, errors.Is(err, ErrInvalidEnvelope))
+
+	// the encrypting side holds the public key only
+	rsaPrivateKey, _ := rsa.GenerateKey(rand.Reader, 2048)
+	publicKey, _ := NewRsaMasterKey("rsa", &rsaPrivateKey.PublicKey, nil)
+	privateKey, _ := NewRsaMasterKey("rsa", nil, rsaPrivateKey)
+
+	encryptRing, _ := NewKeyRing(publicKey)
+	envelope, err := EnvelopeEncrypt([]byte("data"), nil, encryptRing)
+	assert.IsNil(err)
+
+	_, err = EnvelopeDecrypt(envelope, nil, encryptRing)
+	assert.IsNotNil(err)
+
+	decryptRing, _ := NewKeyRing(privateKey)
+	decrypted, err := EnvelopeDecrypt(envelope, nil, decryptRing)
+	assert.IsNil(err)
+	assert.Equal("data", string(decrypted))
+
+	// the key id is same but the wrap algorithm is different
+	aesKey, _ := NewAesMasterKey("rsa", []byte("0123456789abcdef"))
+	aesRing, _ := NewKeyRing(aesKey)
+	_, err = EnvelopeDecrypt(envelope, nil, aesRing)
+	assert.Equal(true, errors.Is(err, ErrInvalidEnvelope))
+}
@@ -0,0 +1,127 @@
+// Copyright 2023 dudaodong@gmail.com. All rights reserved.
+// Use of this source code is governed by MIT license
+
+package cryptor
+
+import (
+	"crypto/rand"
+	"crypto/rsa"
+	"crypto/sha1"
+	"encoding/base64"
+	"encoding/json"
+	"errors"
+	"io"
+	"strings"
+)
+
+const (
+	// RSAOAEP is the JWE key management algorithm RSAES-OAEP with SHA-1.
+	RSAOAEP JWTAlgorithm = "RSA-OAEP"
+	// RSAOAEP256 is the JWE key management algorithm RSAES-OAEP with SHA-256.
+	RSAOAEP256 JWTAlgorithm = "RSA-OAEP-256"
+
+	// A256GCM is the JWE content encryption algorithm AES-256-GCM.
+	A256GCM = "A256GCM"
+)
+
+// ErrJWEDecryption is returned when the JWE can not be decrypted, the reason is not exposed on purpose.
+var ErrJWEDecryption = errors.New("jwe: decryption failed")
+
+// EncryptJWE encrypts plaintext to compact JWE with RSA-OAEP (or RSA-OAEP-256) + A256GCM.
+// `cty` is put in header if it is not empty, eg. "JWT" for nested signed token.
+func EncryptJWE(plaintext []byte, alg JWTAlgorithm, publicKey *rsa.PublicKey, kid, cty string) (string, error) {
+	cek := make([]byte, 32)
+	if _, err := io.ReadFull(rand.Reader, cek); err != nil {
+		return "", err
+	}
+
+	var encryptedKey []byte
+	var err error
+	switch alg {
+	case RSAOAEP:
+		encryptedKey, err = rsa.EncryptOAEP(sha1.New(), rand.Reader, publicKey, cek, nil)
+	case RSAOAEP256:
+		encryptedKey, err = RsaEncryptOAEP(cek, nil, *publicKey)
+	default:
+		return "", ErrJWTUnsupportedAlg
+	}
+	if err != nil {
+		return "", err
+	}
+
+	headerJSON, err := json.Marshal(JWTHeader{Alg: alg, Enc: A256GCM, Kid: kid, Cty: cty})
+	if err != nil {
+		return "", err
+	}
+	protected := encodeJWKBytes(headerJSON)
+
+	gcm := AES(cek).GCM()
+	iv := make([]byte, gcm.NonceSize())
+	if _, err := io.ReadFull(rand.Reader, iv); err != nil {
+		return "", err
+	}
+
+	// the additional authenticated data is the ascii of encoded protected header
+	sealed, err := gcm.Seal(iv, plaintext, []byte(protected))
+	if err != nil {
+		return "", err
+	}
+	tagIndex := len(sealed) - gcm.aead.Overhead()
+
+	return strings.Join([]string{
+		protected,
+		encodeJWKBytes(encryptedKey),
+		encodeJWKBytes(iv),
+		encodeJWKBytes(sealed[:tagIndex]),
+		encodeJWKBytes(sealed[tagIndex:]),
+	}, "."), nil
+}
+
+// DecryptJWE decrypts the compact JWE created by EncryptJWE and returns the header and plaintext.
+func DecryptJWE(token string, privateKey *rsa.PrivateKey) (JWTHeader, []byte, error) {
+	parts := strings.Split(token, ".")
+	if len(parts) != 5 {
+		return JWTHeader{}, nil, ErrJWTMalformed
+	}
+
+	header, err := decodeJWTHeader(parts[0])
+	if err != nil {
+		return header, nil, err
+	}
+	if header.Enc != A256GCM {
+		return header, nil, ErrJWTUnsupportedAlg
+	}
+
+	decoded := make([][]byte, 4)
+	for i, part := range parts[1:] {
+		if decoded[i], err = base64.RawURLEncoding.DecodeString(part); err != nil {
+			return header, nil, ErrJWTMalformed
+		}
+	}
+	encryptedKey, iv, ciphertext, tag := decoded[0], decoded[1], decoded[2], decoded[3]
+
+	var cek []byte
+	switch header.Alg {
+	case RSAOAEP:
+		cek, err = rsa.DecryptOAEP(sha1.New(), rand.Reader, privateKey, encryptedKey, nil)
+	case RSAOAEP256:
+		cek, err = RsaDecryptOAEP(encryptedKey, nil, *privateKey)
+	default:
+		return header, nil, ErrJWTUnsupportedAlg
+	}
+	if err != nil || len(cek) != 32 {
+		return header, nil, ErrJWEDecryption
+	}
+
+	gcm := AES(cek).GCM()
+	if len(tag) != gcm.aead.Overhead() {
+		return header, nil, ErrJWEDecryption
+	}
+
+	plaintext, err := gcm.Open(iv, append(ciphertext, tag...), []byte(parts[0]))
+	if err != nil {
+		return header, nil, ErrJWEDecryption
+	}
+
+	return header, plaintext, nil
+}
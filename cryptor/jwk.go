@@ -0,0 +1,464 @@
+// Copyright 2023 dudaodong@gmail.com. All rights reserved.
+// Use of this source code is governed by MIT license
+
+package cryptor
+
+import (
+	"crypto"
+	"crypto/ecdsa"
+	"crypto/ed25519"
+	"crypto/elliptic"
+	"crypto/rsa"
+	"encoding/base64"
+	"encoding/json"
+	"errors"
+	"fmt"
+	"math/big"
+
+	"golang.org/x/crypto/curve25519"
+)
+
+// JWK is the JSON Web Key of RFC 7517, it supports RSA, EC (P-256, P-384, P-521),
+// OKP (Ed25519, X25519) and oct (symmetric) keys.
+type JWK struct {
+	Kty string `json:"kty"`
+	Kid string `json:"kid,omitempty"`
+	Use string `json:"use,omitempty"`
+	Alg string `json:"alg,omitempty"`
+	Crv string `json:"crv,omitempty"`
+
+	// rsa
+	N  string `json:"n,omitempty"`
+	E  string `json:"e,omitempty"`
+	P  string `json:"p,omitempty"`
+	Q  string `json:"q,omitempty"`
+	DP string `json:"dp,omitempty"`
+	DQ string `json:"dq,omitempty"`
+	QI string `json:"qi,omitempty"`
+
+	// ec and okp
+	X string `json:"x,omitempty"`
+	Y string `json:"y,omitempty"`
+
+	// private part of rsa, ec and okp
+	D string `json:"d,omitempty"`
+
+	// oct
+	K string `json:"k,omitempty"`
+}
+
+// NewJWK creates the JWK of key, the key could be *rsa.PrivateKey, *rsa.PublicKey, *ecdsa.PrivateKey,
+// *ecdsa.PublicKey, ed25519.PrivateKey, ed25519.PublicKey, X25519PrivateKey, X25519PublicKey or []byte (oct).
+func NewJWK(key any) (*JWK, error) {
+	switch k := key.(type) {
+	case *rsa.PrivateKey:
+		jwk, _ := NewJWK(&k.PublicKey)
+		jwk.D = encodeJWKInt(k.D, 0)
+		if len(k.Primes) == 2 {
+			// compute the crt values locally, k.Precompute would write to the key of caller
+			p, q := k.Primes[0], k.Primes[1]
+			one := big.NewInt(1)
+			jwk.P = encodeJWKInt(p, 0)
+			jwk.Q = encodeJWKInt(q, 0)
+			jwk.DP = encodeJWKInt(new(big.Int).Mod(k.D, new(big.Int).Sub(p, one)), 0)
+			jwk.DQ = encodeJWKInt(new(big.Int).Mod(k.D, new(big.Int).Sub(q, one)), 0)
+			jwk.QI = encodeJWKInt(new(big.Int).ModInverse(q, p), 0)
+		}
+		return jwk, nil
+
+	case *rsa.PublicKey:
+		return &JWK{Kty: "RSA", N: encodeJWKInt(k.N, 0), E: encodeJWKInt(big.NewInt(int64(k.E)), 0)}, nil
+
+	case *ecdsa.PrivateKey:
+		jwk, err := NewJWK(&k.PublicKey)
+		if err != nil {
+			return nil, err
+		}
+		jwk.D = encodeJWKInt(k.D, (k.Curve.Params().BitSize+7)/8)
+		return jwk, nil
+
+	case *ecdsa.PublicKey:
+		crv, err := jwkCurveName(k.Curve)
+		if err != nil {
+			return nil, err
+		}
+		size := (k.Curve.Params().BitSize + 7) / 8
+		return &JWK{Kty: "EC", Crv: crv, X: encodeJWKInt(k.X, size), Y: encodeJWKInt(k.Y, size)}, nil
+
+	case ed25519.PrivateKey:
+		if len(k) != ed25519.PrivateKeySize {
+			return nil, errors.New("invalid ed25519 private key size")
+		}
+		return &JWK{Kty: "OKP", Crv: "Ed25519", X: encodeJWKBytes(k.Public().(ed25519.PublicKey)),
+			D: encodeJWKBytes(k.Seed())}, nil
+
+	case ed25519.PublicKey:
+		if len(k) != ed25519.PublicKeySize {
+			return nil, errors.New("invalid ed25519 public key size")
+		}
+		return &JWK{Kty: "OKP", Crv: "Ed25519", X: encodeJWKBytes(k)}, nil
+
+	case X25519PrivateKey:
+		publicKey, err := k.PublicKey()
+		if err != nil {
+			return nil, err
+		}
+		return &JWK{Kty: "OKP", Crv: "X25519", X: encodeJWKBytes(publicKey), D: encodeJWKBytes(k)}, nil
+
+	case X25519PublicKey:
+		if len(k) != curve25519.PointSize {
+			return nil, errors.New("invalid x25519 public key size")
+		}
+		return &JWK{Kty: "OKP", Crv: "X25519", X: encodeJWKBytes(k)}, nil
+
+	case []byte:
+		return &JWK{Kty: "oct", K: encodeJWKBytes(k)}, nil
+	}
+
+	return nil, fmt.Errorf("unsupported key type %T", key)
+}
+
+// ParseJWK parses the JSON encoded JWK.
+func ParseJWK(data []byte) (*JWK, error) {
+	var jwk JWK
+	if err := json.Unmarshal(data, &jwk); err != nil {
+		return nil, err
+	}
+	if jwk.Kty == "" {
+		return nil, errors.New("missing kty of JWK")
+	}
+	return &jwk, nil
+}
+
+// MarshalJWK encodes key to JWK JSON, see NewJWK for the supported key types.
+func MarshalJWK(key any) ([]byte, error) {
+	jwk, err := NewJWK(key)
+	if err != nil {
+		return nil, err
+	}
+	return json.Marshal(jwk)
+}
+
+// IsPrivate checks if the JWK contains private key.
+func (jwk *JWK) IsPrivate() bool {
+	return jwk.D != "" || jwk.Kty == "oct"
+}
+
+// Public returns the JWK of public key without private part, kid, use and alg are kept.
+func (jwk *JWK) Public() *JWK {
+	public := *jwk
+	public.D, public.P, public.Q, public.DP, public.DQ, public.QI, public.K = "", "", "", "", "", "", ""
+	return &public
+}
+
+// Key returns the key of JWK, it is one of the types of NewJWK.
+func (jwk *JWK) Key() (any, error) {
+	switch jwk.Kty {
+	case "RSA":
+		return jwk.rsaKey()
+	case "EC":
+		return jwk.ecdsaKey()
+	case "OKP":
+		return jwk.okpKey()
+	case "oct":
+		return decodeJWKBytes(jwk.K, "k", -1)
+	}
+
+	return nil, fmt.Errorf("unsupported JWK kty %q", jwk.Kty)
+}
+
+// PublicKey returns the public key of JWK, error is returned for oct key.
+func (jwk *JWK) PublicKey() (crypto.PublicKey, error) {
+	if jwk.Kty == "oct" {
+		return nil, errors.New("oct JWK has no public key")
+	}
+	return jwk.Public().Key()
+}
+
+func (jwk *JWK) rsaKey() (any, error) {
+	n, err := decodeJWKInt(jwk.N, "n")
+	if err != nil {
+		return nil, err
+	}
+	e, err := decodeJWKInt(jwk.E, "e")
+	if err != nil {
+		return nil, err
+	}
+	if !e.IsInt64() || e.Int64() > 1<<31-1 || e.Int64() < 3 {
+		return nil, errors.New("invalid JWK rsa exponent")
+	}
+
+	publicKey := &rsa.PublicKey{N: n, E: int(e.Int64())}
+	if jwk.D == "" {
+		return publicKey, nil
+	}
+
+	d, err := decodeJWKInt(jwk.D, "d")
+	if err != nil {
+		return nil, err
+	}
+
+	// the crt members are optional as a group (RFC 7518 6.3.2), p and q are recovered from n, e and d if absent
+	var p, q *big.Int
+	if jwk.P == "" && jwk.Q == "" {
+		if p, q, err = recoverRsaPrimes(n, e, d); err != nil {
+			return nil, err
+		}
+	} else {
+		if p, err = decodeJWKInt(jwk.P, "p"); err != nil {
+			return nil, err
+		}
+		if q, err = decodeJWKInt(jwk.Q, "q"); err != nil {
+			return nil, err
+		}
+	}
+
+	privateKey := &rsa.PrivateKey{PublicKey: *publicKey, D: d, Primes: []*big.Int{p, q}}
+	if err := privateKey.Validate(); err != nil {
+		return nil, err
+	}
+	privateKey.Precompute()
+
+	return privateKey, nil
+}
+
+// recoverRsaPrimes recovers the prime factors of n from the exponents, see NIST SP 800-56B appendix C.
+func recoverRsaPrimes(n, e, d *big.Int) (*big.Int, *big.Int, error) {
+	one := big.NewInt(1)
+	nMinusOne := new(big.Int).Sub(n, one)
+
+	// k = d*e - 1 = 2^t * r, r is odd
+	k := new(big.Int).Mul(d, e)
+	k.Sub(k, one)
+	if k.Sign() <= 0 || k.Bit(0) != 0 {
+		return nil, nil, errors.New("invalid JWK rsa private exponent")
+	}
+	t := 0
+	r := new(big.Int).Set(k)
+	for r.Bit(0) == 0 {
+		r.Rsh(r, 1)
+		t++
+	}
+
+	for g := int64(2); g < 100; g++ {
+		y := new(big.Int).Exp(big.NewInt(g), r, n)
+		if y.Cmp(one) == 0 || y.Cmp(nMinusOne) == 0 {
+			continue
+		}
+
+		for i := 0; i < t; i++ {
+			x := new(big.Int).Exp(y, big.NewInt(2), n)
+			if x.Cmp(one) == 0 {
+				// y is a nontrivial square root of 1
+				p := new(big.Int).GCD(nil, nil, new(big.Int).Sub(y, one), n)
+				q := new(big.Int).Div(n, p)
+				if p.Cmp(q) < 0 {
+					p, q = q, p
+				}
+				return p, q, nil
+			}
+			if x.Cmp(nMinusOne) == 0 {
+				break
+			}
+			y = x
+		}
+	}
+
+	return nil, nil, errors.New("can not recover JWK rsa primes")
+}
+
+func (jwk *JWK) ecdsaKey() (any, error) {
+	var curve elliptic.Curve
+	switch jwk.Crv {
+	case "P-256":
+		curve = elliptic.P256()
+	case "P-384":
+		curve = elliptic.P384()
+	case "P-521":
+		curve = elliptic.P521()
+	default:
+		return nil, fmt.Errorf("unsupported JWK crv %q", jwk.Crv)
+	}
+	size := (curve.Params().BitSize + 7) / 8
+
+	xBytes, err := decodeJWKBytes(jwk.X, "x", size)
+	if err != nil {
+		return nil, err
+	}
+	yBytes, err := decodeJWKBytes(jwk.Y, "y", size)
+	if err != nil {
+		return nil, err
+	}
+
+	publicKey := &ecdsa.PublicKey{Curve: curve, X: new(big.Int).SetBytes(xBytes), Y: new(big.Int).SetBytes(yBytes)}
+	if !curve.IsOnCurve(publicKey.X, publicKey.Y) {
+		return nil, errors.New("invalid JWK ec point")
+	}
+	if jwk.D == "" {
+		return publicKey, nil
+	}
+
+	dBytes, err := decodeJWKBytes(jwk.D, "d", size)
+	if err != nil {
+		return nil, err
+	}
+
+	privateKey := &ecdsa.PrivateKey{PublicKey: *publicKey, D: new(big.Int).SetBytes(dBytes)}
+	x, y := curve.ScalarBaseMult(dBytes)
+	if x.Cmp(publicKey.X) != 0 || y.Cmp(publicKey.Y) != 0 {
+		return nil, errors.New("JWK ec private key does not match public key")
+	}
+
+	return privateKey, nil
+}
+
+func (jwk *JWK) okpKey() (any, error) {
+	switch jwk.Crv {
+	case "Ed25519":
+		x, err := decodeJWKBytes(jwk.X, "x", ed25519.PublicKeySize)
+		if err != nil {
+			return nil, err
+		}
+		if jwk.D == "" {
+			return ed25519.PublicKey(x), nil
+		}
+
+		d, err := decodeJWKBytes(jwk.D, "d", ed25519.SeedSize)
+		if err != nil {
+			return nil, err
+		}
+		privateKey := ed25519.NewKeyFromSeed(d)
+		if !privateKey.Public().(ed25519.PublicKey).Equal(ed25519.PublicKey(x)) {
+			return nil, errors.New("JWK ed25519 private key does not match public key")
+		}
+		return privateKey, nil
+
+	case "X25519":
+		x, err := decodeJWKBytes(jwk.X, "x", curve25519.PointSize)
+		if err != nil {
+			return nil, err
+		}
+		if jwk.D == "" {
+			return X25519PublicKey(x), nil
+		}
+
+		d, err := decodeJWKBytes(jwk.D, "d", curve25519.ScalarSize)
+		if err != nil {
+			return nil, err
+		}
+		return X25519PrivateKey(d), nil
+	}
+
+	return nil, fmt.Errorf("unsupported JWK crv %q", jwk.Crv)
+}
+
+// encodeJWKInt encodes n in base64url, it is left padded with zero to size bytes if size > 0
+func encodeJWKInt(n *big.Int, size int) string {
+	b := n.Bytes()
+	if len(b) < size {
+		b = append(make([]byte, size-len(b)), b...)
+	}
+	return encodeJWKBytes(b)
+}
+
+func encodeJWKBytes(b []byte) string {
+	return base64.RawURLEncoding.EncodeToString(b)
+}
+
+func decodeJWKInt(s, name string) (*big.Int, error) {
+	b, err := decodeJWKBytes(s, name, -1)
+	if err != nil {
+		return nil, err
+	}
+	return new(big.Int).SetBytes(b), nil
+}
+
+// decodeJWKBytes decodes the base64url member, the size is checked if it is not negative
+func decodeJWKBytes(s, name string, size int) ([]byte, error) {
+	if s == "" {
+		return nil, fmt.Errorf("missing JWK member %q", name)
+	}
+
+	b, err := base64.RawURLEncoding.DecodeString(s)
+	if err != nil {
+		return nil, fmt.Errorf("invalid JWK member %q: %w", name, err)
+	}
+	if size >= 0 && len(b) != size {
+		return nil, fmt.Errorf("invalid JWK member %q size", name)
+	}
+
+	return b, nil
+}
+
+func jwkCurveName(curve elliptic.Curve) (string, error) {
+	switch curve {
+	case elliptic.P256():
+		return "P-256", nil
+	case elliptic.P384():
+		return "P-384", nil
+	case elliptic.P521():
+		return "P-521", nil
+	}
+	return "", errors.New("unsupported ec curve")
+}
+
+// JWKS is the JSON Web Key Set of RFC 7517, it is used to select the verification key of JWT by `kid`.
+type JWKS struct {
+	Keys []JWK `json:"keys"`
+}
+
+// ParseJWKS parses the JSON encoded JWK set.
+func ParseJWKS(data []byte) (*JWKS, error) {
+	var set JWKS
+	if err := json.Unmarshal(data, &set); err != nil {
+		return nil, err
+	}
+	for i := range set.Keys {
+		if set.Keys[i].Kty == "" {
+			return nil, errors.New("missing kty of JWK")
+		}
+	}
+	return &set, nil
+}
+
+// Public returns the JWK set of public keys, oct keys are removed.
+func (set *JWKS) Public() *JWKS {
+	public := &JWKS{Keys: []JWK{}}
+	for i := range set.Keys {
+		if set.Keys[i].Kty != "oct" {
+			public.Keys = append(public.Keys, *set.Keys[i].Public())
+		}
+	}
+	return public
+}
+
+// Lookup returns the key of kid, the key whose `alg` is different from alg is skipped.
+// If kid is empty, the only matched key is returned. ErrJWTKeyNotFound is returned if no key matches.
+func (set *JWKS) Lookup(kid string, alg JWTAlgorithm) (*JWK, error) {
+	var found *JWK
+
+	for i := range set.Keys {
+		jwk := &set.Keys[i]
+		if (kid != "" && jwk.Kid != kid) || (alg != "" && jwk.Alg != "" && jwk.Alg != string(alg)) {
+			continue
+		}
+		if found != nil {
+			return nil, ErrJWTKeyNotFound
+		}
+		found = jwk
+	}
+
+	if found == nil {
+		return nil, ErrJWTKeyNotFound
+	}
+
+	return found, nil
+}
+
+// verificationKey returns the secret of oct key or the public key
+func (jwk *JWK) verificationKey() (any, error) {
+	if jwk.Kty == "oct" {
+		return jwk.Key()
+	}
+	return jwk.PublicKey()
+}
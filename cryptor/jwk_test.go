@@ -0,0 +1,130 @@
+package cryptor
+
+import (
+	"crypto"
+	"crypto/ecdsa"
+	"crypto/ed25519"
+	"crypto/elliptic"
+	"crypto/rsa"
+	"encoding/json"
+	"math/big"
+	"testing"
+
+	"github.com/duke-git/lancet/v2/internal"
+)
+
+func TestJWK(t *testing.T) {
+	t.Parallel()
+
+	assert := internal.NewAssert(t, "TestJWK")
+
+	edPri, _, _ := GenerateEd25519Key()
+	ecPri, _ := GenerateEcdsaKey(elliptic.P384())
+	xPri, _, _ := GenerateX25519Key()
+	rsaPri, _ := GenerateRsaKeyPair(1024)
+
+	for _, key := range []any{edPri, ecPri, xPri, rsaPri, []byte("secret")} {
+		data, err := MarshalJWK(key)
+		assert.IsNil(err)
+
+		jwk, err := ParseJWK(data)
+		assert.IsNil(err)
+		assert.Equal(true, jwk.IsPrivate())
+
+		actual, err := jwk.Key()
+		assert.IsNil(err)
+
+		switch k := key.(type) {
+		case *rsa.PrivateKey:
+			assert.Equal(true, k.Equal(actual))
+		case *ecdsa.PrivateKey:
+			assert.Equal(true, k.Equal(actual))
+		default:
+			assert.Equal(key, actual)
+		}
+	}
+
+	jwk, _ := NewJWK(ecPri)
+	public := jwk.Public()
+	assert.Equal(false, public.IsPrivate())
+	assert.Equal("", public.D)
+	publicKey, err := jwk.PublicKey()
+	assert.IsNil(err)
+	assert.Equal(true, ecPri.PublicKey.Equal(publicKey))
+
+	_, err = NewJWK("invalid")
+	assert.IsNotNil(err)
+	_, err = ParseJWK([]byte(`{"x":"abc"}`))
+	assert.IsNotNil(err)
+}
+
+func TestJWK_RsaWithoutPrimes(t *testing.T) {
+	t.Parallel()
+
+	assert := internal.NewAssert(t, "TestJWK_RsaWithoutPrimes")
+
+	generated, _ := GenerateRsaKeyPair(1024)
+	rsaPri := &rsa.PrivateKey{PublicKey: generated.PublicKey, D: generated.D, Primes: generated.Primes}
+
+	jwk, err := NewJWK(rsaPri)
+	assert.IsNil(err)
+	// NewJWK does not write to the key
+	assert.IsNil(rsaPri.Precomputed.Dp)
+
+	// the crt members are the same as Precompute
+	assert.Equal(encodeJWKInt(generated.Precomputed.Dp, 0), jwk.DP)
+	assert.Equal(encodeJWKInt(generated.Precomputed.Dq, 0), jwk.DQ)
+	assert.Equal(encodeJWKInt(generated.Precomputed.Qinv, 0), jwk.QI)
+
+	// d only key
+	jwk.P, jwk.Q, jwk.DP, jwk.DQ, jwk.QI = "", "", "", "", ""
+	key, err := jwk.Key()
+	assert.IsNil(err)
+	assert.Equal(true, rsaPri.PublicKey.Equal(key.(*rsa.PrivateKey).Public()))
+	assert.Equal(0, rsaPri.D.Cmp(key.(*rsa.PrivateKey).D))
+
+	signature, err := key.(*rsa.PrivateKey).Sign(nil, make([]byte, 32), crypto.SHA256)
+	assert.IsNil(err)
+	assert.IsNil(rsa.VerifyPKCS1v15(&rsaPri.PublicKey, crypto.SHA256, make([]byte, 32), signature))
+
+	// one of p and q is missing
+	jwk, _ = NewJWK(rsaPri)
+	jwk.Q = ""
+	_, err = jwk.Key()
+	assert.IsNotNil(err)
+
+	jwk.P, jwk.D = "", encodeJWKInt(big.NewInt(12345), 0)
+	_, err = jwk.Key()
+	assert.IsNotNil(err)
+}
+
+func TestJWK_RFC8037(t *testing.T) {
+	t.Parallel()
+
+	assert := internal.NewAssert(t, "TestJWK_RFC8037")
+
+	// RFC 8037 appendix A.1 and A.4
+	jwk, err := ParseJWK([]byte(`{"kty":"OKP","crv":"Ed25519",
+		"d":"nWGxne_9WmC6hEr0kuwsxERJxWl7MmkZcDusAxyuf2A",
+		"x":"11qYAYKxCrfVS_7TyWQHOg7hcvPapiMlrwIaaPcHURo"}`))
+	assert.IsNil(err)
+
+	key, err := jwk.Key()
+	assert.IsNil(err)
+
+	signature, _ := Ed25519Sign(key.(ed25519.PrivateKey), []byte("eyJhbGciOiJFZERTQSJ9.RXhhbXBsZSBvZiBFZDI1NTE5IHNpZ25pbmc"))
+	assert.Equal("hgyY0il_MGCjP0JzlnLWG1PPOt7-09PGcvMg3AIbQR6dWbhijcNR4ki4iylGjg5BhVsPt9g7sVvpAr_MuM0KAg",
+		encodeJWKBytes(signature))
+
+	// tampered public key
+	jwk.X = "11qYAYKxCrfVS_7TyWQHOg7hcvPapiMlrwIaaPcHUAo"
+	_, err = jwk.Key()
+	assert.IsNotNil(err)
+
+	// point not on curve
+	data, _ := MarshalJWK(&ecdsa.PublicKey{Curve: elliptic.P256(), X: big.NewInt(1), Y: big.NewInt(1)})
+	var ecJWK JWK
+	json.Unmarshal(data, &ecJWK)
+	_, err = ecJWK.Key()
+	assert.IsNotNil(err)
+}
@@ -0,0 +1,479 @@
+// Copyright 2023 dudaodong@gmail.com. All rights reserved.
+// Use of this source code is governed by MIT license
+
+package cryptor
+
+import (
+	"crypto"
+	"crypto/ecdsa"
+	"crypto/ed25519"
+	"crypto/elliptic"
+	"crypto/hmac"
+	"crypto/rand"
+	"crypto/rsa"
+	"encoding/base64"
+	"encoding/json"
+	"errors"
+	"fmt"
+	"math/big"
+	"strings"
+	"time"
+)
+
+// JWTAlgorithm is the `alg` of JWS and JWE header.
+type JWTAlgorithm string
+
+const (
+	HS256 JWTAlgorithm = "HS256"
+	HS384 JWTAlgorithm = "HS384"
+	HS512 JWTAlgorithm = "HS512"
+	RS256 JWTAlgorithm = "RS256"
+	RS384 JWTAlgorithm = "RS384"
+	RS512 JWTAlgorithm = "RS512"
+	PS256 JWTAlgorithm = "PS256"
+	PS384 JWTAlgorithm = "PS384"
+	PS512 JWTAlgorithm = "PS512"
+	ES256 JWTAlgorithm = "ES256"
+	ES384 JWTAlgorithm = "ES384"
+	EdDSA JWTAlgorithm = "EdDSA"
+)
+
+var (
+	ErrJWTMalformed        = errors.New("jwt: malformed token")
+	ErrJWTUnsupportedAlg   = errors.New("jwt: unsupported or disallowed algorithm")
+	ErrJWTInvalidKey       = errors.New("jwt: key is invalid for the algorithm")
+	ErrJWTKeyNotFound      = errors.New("jwt: key not found")
+	ErrJWTSignatureInvalid = errors.New("jwt: signature is invalid")
+	ErrJWTExpired          = errors.New("jwt: token is expired")
+	ErrJWTNotValidYet      = errors.New("jwt: token is not valid yet")
+	ErrJWTIssuedInFuture   = errors.New("jwt: token is issued in the future")
+	ErrJWTInvalidIssuer    = errors.New("jwt: invalid issuer")
+	ErrJWTInvalidAudience  = errors.New("jwt: invalid audience")
+	ErrJWTInvalidSubject   = errors.New("jwt: invalid subject")
+)
+
+// JWTHeader is the JOSE header of JWS and JWE.
+type JWTHeader struct {
+	Alg JWTAlgorithm `json:"alg"`
+	Enc string       `json:"enc,omitempty"`
+	Typ string       `json:"typ,omitempty"`
+	Cty string       `json:"cty,omitempty"`
+	Kid string       `json:"kid,omitempty"`
+}
+
+// Audience is the `aud` claim, it is a single string or an array of strings in JSON.
+type Audience []string
+
+// MarshalJSON encodes single audience as string.
+func (a Audience) MarshalJSON() ([]byte, error) {
+	if len(a) == 1 {
+		return json.Marshal(a[0])
+	}
+	return json.Marshal([]string(a))
+}
+
+// UnmarshalJSON decodes string or array of strings.
+func (a *Audience) UnmarshalJSON(data []byte) error {
+	var s string
+	if err := json.Unmarshal(data, &s); err == nil {
+		*a = Audience{s}
+		return nil
+	}
+
+	var items []string
+	if err := json.Unmarshal(data, &items); err != nil {
+		return err
+	}
+	*a = items
+
+	return nil
+}
+
+// Contains checks if aud is in the audience.
+func (a Audience) Contains(aud string) bool {
+	for _, item := range a {
+		if item == aud {
+			return true
+		}
+	}
+	return false
+}
+
+// RegisteredClaims is the registered claims of RFC 7519, the time claims are unix seconds.
+// Embed it in struct to define typed claims, eg.
+//
+//	type UserClaims struct {
+//		cryptor.RegisteredClaims
+//		Role string `json:"role"`
+//	}
+type RegisteredClaims struct {
+	Issuer    string   `json:"iss,omitempty"`
+	Subject   string   `json:"sub,omitempty"`
+	Audience  Audience `json:"aud,omitempty"`
+	ExpiresAt int64    `json:"exp,omitempty"`
+	NotBefore int64    `json:"nbf,omitempty"`
+	IssuedAt  int64    `json:"iat,omitempty"`
+	ID        string   `json:"jti,omitempty"`
+}
+
+// Registered returns the registered claims, it is promoted to the struct which embeds RegisteredClaims.
+func (c RegisteredClaims) Registered() RegisteredClaims {
+	return c
+}
+
+// JWTClaims is the constraint of VerifyJWT claims type.
+type JWTClaims interface {
+	Registered() RegisteredClaims
+}
+
+// JWTKeyFunc returns the verification key by the header of token, it is useful for key rotation.
+type JWTKeyFunc func(header JWTHeader) (any, error)
+
+// JWTVerifyOptions is the options of VerifyJWT.
+type JWTVerifyOptions struct {
+	// Algorithms is the allowed algorithms, empty means any algorithm which is valid for the key.
+	Algorithms []JWTAlgorithm
+	// Issuer, Audience and Subject are checked if they are not empty.
+	Issuer   string
+	Audience string
+	Subject  string
+	// RequireExp rejects the token without `exp` claim.
+	RequireExp bool
+	// ClockSkew is the tolerance of exp, nbf and iat.
+	ClockSkew time.Duration
+	// Now returns the current time, default is time.Now.
+	Now func() time.Time
+}
+
+// SignJWT signs claims and returns the compact JWS. param `key` is []byte for HS*, *rsa.PrivateKey for RS* and PS*,
+// *ecdsa.PrivateKey for ES* and ed25519.PrivateKey for EdDSA, `kid` is put in header if it is not empty.
+func SignJWT(claims any, alg JWTAlgorithm, key any, kid string) (string, error) {
+	payload, err := json.Marshal(claims)
+	if err != nil {
+		return "", err
+	}
+
+	return SignJWS(payload, JWTHeader{Alg: alg, Typ: "JWT", Kid: kid}, key)
+}
+
+// SignJWS signs payload with the algorithm of header and returns the compact JWS.
+func SignJWS(payload []byte, header JWTHeader, key any) (string, error) {
+	headerJSON, err := json.Marshal(header)
+	if err != nil {
+		return "", err
+	}
+
+	signingInput := encodeJWKBytes(headerJSON) + "." + encodeJWKBytes(payload)
+
+	signature, err := jwsSign(header.Alg, []byte(signingInput), key)
+	if err != nil {
+		return "", err
+	}
+
+	return signingInput + "." + encodeJWKBytes(signature), nil
+}
+
+// VerifyJWT verifies the signature of token and validates the registered claims, then decodes the claims into T.
+// param `key` is the verification key (see SignJWT, the public key is used for asymmetric algorithms),
+// *JWK, *JWKS which selects key by `kid`, or JWTKeyFunc.
+func VerifyJWT[T JWTClaims](token string, key any, opts ...JWTVerifyOptions) (T, error) {
+	var claims T
+
+	var opt JWTVerifyOptions
+	if len(opts) > 0 {
+		opt = opts[0]
+	}
+
+	_, payload, err := VerifyJWS(token, key, opt.Algorithms...)
+	if err != nil {
+		return claims, err
+	}
+
+	if err := json.Unmarshal(payload, &claims); err != nil {
+		return claims, fmt.Errorf("%w: %v", ErrJWTMalformed, err)
+	}
+
+	now := time.Now
+	if opt.Now != nil {
+		now = opt.Now
+	}
+
+	return claims, validateJWTClaims(claims.Registered(), opt, now())
+}
+
+// VerifyJWS verifies the signature of compact JWS and returns the header and payload,
+// param `key` is same as VerifyJWT, param `algorithms` is the allowed algorithms.
+func VerifyJWS(token string, key any, algorithms ...JWTAlgorithm) (JWTHeader, []byte, error) {
+	parts := strings.Split(token, ".")
+	if len(parts) != 3 {
+		return JWTHeader{}, nil, ErrJWTMalformed
+	}
+
+	header, err := decodeJWTHeader(parts[0])
+	if err != nil {
+		return header, nil, err
+	}
+
+	if len(algorithms) > 0 && !containsJWTAlgorithm(algorithms, header.Alg) {
+		return header, nil, ErrJWTUnsupportedAlg
+	}
+
+	verifyKey, err := resolveJWTKey(key, header)
+	if err != nil {
+		return header, nil, err
+	}
+
+	signature, err := base64.RawURLEncoding.DecodeString(parts[2])
+	if err != nil {
+		return header, nil, ErrJWTMalformed
+	}
+
+	if err := jwsVerify(header.Alg, []byte(parts[0]+"."+parts[1]), signature, verifyKey); err != nil {
+		return header, nil, err
+	}
+
+	payload, err := base64.RawURLEncoding.DecodeString(parts[1])
+	if err != nil {
+		return header, nil, ErrJWTMalformed
+	}
+
+	return header, payload, nil
+}
+
+// ParseJWTHeader decodes the header of JWS or JWE without verification, eg. to get the `kid`.
+func ParseJWTHeader(token string) (JWTHeader, error) {
+	encoded, _, ok := strings.Cut(token, ".")
+	if !ok {
+		return JWTHeader{}, ErrJWTMalformed
+	}
+	return decodeJWTHeader(encoded)
+}
+
+func decodeJWTHeader(encoded string) (JWTHeader, error) {
+	var header JWTHeader
+
+	data, err := base64.RawURLEncoding.DecodeString(encoded)
+	if err != nil {
+		return header, ErrJWTMalformed
+	}
+	if err := json.Unmarshal(data, &header); err != nil || header.Alg == "" {
+		return header, ErrJWTMalformed
+	}
+
+	return header, nil
+}
+
+func validateJWTClaims(claims RegisteredClaims, opt JWTVerifyOptions, now time.Time) error {
+	skew := int64(opt.ClockSkew / time.Second)
+	unix := now.Unix()
+
+	if claims.ExpiresAt == 0 && opt.RequireExp {
+		return ErrJWTExpired
+	}
+	if claims.ExpiresAt != 0 && unix-skew >= claims.ExpiresAt {
+		return ErrJWTExpired
+	}
+	if claims.NotBefore != 0 && unix+skew < claims.NotBefore {
+		return ErrJWTNotValidYet
+	}
+	if claims.IssuedAt != 0 && unix+skew < claims.IssuedAt {
+		return ErrJWTIssuedInFuture
+	}
+
+	if opt.Issuer != "" && claims.Issuer != opt.Issuer {
+		return ErrJWTInvalidIssuer
+	}
+	if opt.Audience != "" && !claims.Audience.Contains(opt.Audience) {
+		return ErrJWTInvalidAudience
+	}
+	if opt.Subject != "" && claims.Subject != opt.Subject {
+		return ErrJWTInvalidSubject
+	}
+
+	return nil
+}
+
+func resolveJWTKey(key any, header JWTHeader) (any, error) {
+	switch k := key.(type) {
+	case JWTKeyFunc:
+		return k(header)
+	case func(header JWTHeader) (any, error):
+		return k(header)
+	case *JWKS:
+		jwk, err := k.Lookup(header.Kid, header.Alg)
+		if err != nil {
+			return nil, err
+		}
+		return jwk.verificationKey()
+	case *JWK:
+		return k.verificationKey()
+	}
+
+	return key, nil
+}
+
+func containsJWTAlgorithm(algorithms []JWTAlgorithm, alg JWTAlgorithm) bool {
+	for _, item := range algorithms {
+		if item == alg {
+			return true
+		}
+	}
+	return false
+}
+
+// jwsHash returns the hash of algorithm
+func jwsHash(alg JWTAlgorithm) (crypto.Hash, error) {
+	switch alg {
+	case HS256, RS256, PS256, ES256:
+		return crypto.SHA256, nil
+	case HS384, RS384, PS384, ES384:
+		return crypto.SHA384, nil
+	case HS512, RS512, PS512:
+		return crypto.SHA512, nil
+	}
+	return 0, ErrJWTUnsupportedAlg
+}
+
+func jwsSign(alg JWTAlgorithm, signingInput []byte, key any) ([]byte, error) {
+	if alg == EdDSA {
+		k, ok := key.(ed25519.PrivateKey)
+		if !ok {
+			return nil, ErrJWTInvalidKey
+		}
+		return Ed25519Sign(k, signingInput)
+	}
+
+	hash, err := jwsHash(alg)
+	if err != nil {
+		return nil, err
+	}
+
+	switch alg[0] {
+	case 'H':
+		k, ok := key.([]byte)
+		if !ok || len(k) == 0 {
+			return nil, ErrJWTInvalidKey
+		}
+		mac := hmac.New(hash.New, k)
+		mac.Write(signingInput)
+		return mac.Sum(nil), nil
+
+	case 'R':
+		k, ok := key.(*rsa.PrivateKey)
+		if !ok {
+			return nil, ErrJWTInvalidKey
+		}
+		return RsaSignWithKey(hash, signingInput, k)
+
+	case 'P':
+		k, ok := key.(*rsa.PrivateKey)
+		if !ok {
+			return nil, ErrJWTInvalidKey
+		}
+		hashed, err := hashData(hash, signingInput)
+		if err != nil {
+			return nil, err
+		}
+		return rsa.SignPSS(rand.Reader, k, hash, hashed, &rsa.PSSOptions{SaltLength: rsa.PSSSaltLengthEqualsHash})
+
+	default:
+		k, ok := key.(*ecdsa.PrivateKey)
+		if !ok || k.Curve != jwsCurve(alg) {
+			return nil, ErrJWTInvalidKey
+		}
+		hashed, err := hashData(hash, signingInput)
+		if err != nil {
+			return nil, err
+		}
+		r, s, err := ecdsa.Sign(rand.Reader, k, hashed)
+		if err != nil {
+			return nil, err
+		}
+
+		// the signature is r || s of fixed size, not ASN.1
+		size := (k.Curve.Params().BitSize + 7) / 8
+		signature := make([]byte, 2*size)
+		r.FillBytes(signature[:size])
+		s.FillBytes(signature[size:])
+		return signature, nil
+	}
+}
+
+func jwsVerify(alg JWTAlgorithm, signingInput, signature []byte, key any) error {
+	if alg == EdDSA {
+		k, ok := key.(ed25519.PublicKey)
+		if !ok {
+			return ErrJWTInvalidKey
+		}
+		if Ed25519Verify(k, signingInput, signature) != nil {
+			return ErrJWTSignatureInvalid
+		}
+		return nil
+	}
+
+	hash, err := jwsHash(alg)
+	if err != nil {
+		return err
+	}
+
+	switch alg[0] {
+	case 'H':
+		expected, err := jwsSign(alg, signingInput, key)
+		if err != nil {
+			return err
+		}
+		if !hmac.Equal(expected, signature) {
+			return ErrJWTSignatureInvalid
+		}
+
+	case 'R':
+		k, ok := key.(*rsa.PublicKey)
+		if !ok {
+			return ErrJWTInvalidKey
+		}
+		if RsaVerifySignWithKey(hash, signingInput, signature, k) != nil {
+			return ErrJWTSignatureInvalid
+		}
+
+	case 'P':
+		k, ok := key.(*rsa.PublicKey)
+		if !ok {
+			return ErrJWTInvalidKey
+		}
+		hashed, err := hashData(hash, signingInput)
+		if err != nil {
+			return err
+		}
+		if rsa.VerifyPSS(k, hash, hashed, signature, &rsa.PSSOptions{SaltLength: rsa.PSSSaltLengthAuto}) != nil {
+			return ErrJWTSignatureInvalid
+		}
+
+	default:
+		k, ok := key.(*ecdsa.PublicKey)
+		if !ok || k.Curve != jwsCurve(alg) {
+			return ErrJWTInvalidKey
+		}
+		size := (k.Curve.Params().BitSize + 7) / 8
+		if len(signature) != 2*size {
+			return ErrJWTSignatureInvalid
+		}
+		hashed, err := hashData(hash, signingInput)
+		if err != nil {
+			return err
+		}
+		r := new(big.Int).SetBytes(signature[:size])
+		s := new(big.Int).SetBytes(signature[size:])
+		if !ecdsa.Verify(k, hashed, r, s) {
+			return ErrJWTSignatureInvalid
+		}
+	}
+
+	return nil
+}
+
+func jwsCurve(alg JWTAlgorithm) elliptic.Curve {
+	if alg == ES384 {
+		return elliptic.P384()
+	}
+	return elliptic.P256()
+}
@@ -0,0 +1,320 @@
+package cryptor
+
+import (
+	"crypto/ecdsa"
+	"crypto/ed25519"
+	"crypto/elliptic"
+	"crypto/rand"
+	"crypto/rsa"
+	"encoding/json"
+	"errors"
+	"strings"
+	"testing"
+	"time"
+
+	"github.com/duke-git/lancet/v2/internal"
+)
+
+type testUserClaims struct {
+	RegisteredClaims
+	Role string `json:"role"`
+}
+
+func TestJWTSignAndVerify(t *testing.T) {
+	t.Parallel()
+	assert := internal.NewAssert(t, "TestJWTSignAndVerify")
+
+	rsaKey, _ := rsa.GenerateKey(rand.Reader, 2048)
+	p256Key, _ := GenerateEcdsaKey(elliptic.P256())
+	p384Key, _ := GenerateEcdsaKey(elliptic.P384())
+	edKey, edPublicKey, _ := GenerateEd25519Key()
+	secret := []byte("0123456789abcdef0123456789abcdef")
+
+	tests := []struct {
+		alg        JWTAlgorithm
+		signKey    any
+		verifyKey  any
+		signLength int
+	}{
+		{HS256, secret, secret, 32},
+		{HS384, secret, secret, 48},
+		{HS512, secret, secret, 64},
+		{RS256, rsaKey, &rsaKey.PublicKey, 256},
+		{RS512, rsaKey, &rsaKey.PublicKey, 256},
+		{PS256, rsaKey, &rsaKey.PublicKey, 256},
+		{PS384, rsaKey, &rsaKey.PublicKey, 256},
+		{ES256, p256Key, &p256Key.PublicKey, 64},
+		{ES384, p384Key, &p384Key.PublicKey, 96},
+		{EdDSA, edKey, edPublicKey, 64},
+	}
+
+	claims := testUserClaims{
+		RegisteredClaims: RegisteredClaims{Subject: "lancet", ExpiresAt: time.Now().Add(time.Hour).Unix()},
+		Role:             "admin",
+	}
+
+	for _, tt := range tests {
+		token, err := SignJWT(claims, tt.alg, tt.signKey, "key-1")
+		assert.IsNil(err)
+
+		header, err := ParseJWTHeader(token)
+		assert.IsNil(err)
+		assert.Equal(JWTHeader{Alg: tt.alg, Typ: "JWT", Kid: "key-1"}, header)
+
+		signature, err := decodeJWKBytes(token[strings.LastIndex(token, ".")+1:], "signature", tt.signLength)
+		assert.IsNil(err)
+
+		result, err := VerifyJWT[testUserClaims](token, tt.verifyKey)
+		assert.IsNil(err)
+		assert.Equal(claims, result)
+
+		// tamper the signature
+		signature[0] ^= 1
+		tampered := token[:strings.LastIndex(token, ".")+1] + encodeJWKBytes(signature)
+		_, err = VerifyJWT[testUserClaims](tampered, tt.verifyKey)
+		assert.Equal(true, errors.Is(err, ErrJWTSignatureInvalid))
+
+		_, err = VerifyJWT[testUserClaims](token, tt.verifyKey, JWTVerifyOptions{Algorithms: []JWTAlgorithm{"none"}})
+		assert.Equal(true, errors.Is(err, ErrJWTUnsupportedAlg))
+	}
+}
+
+func TestJWTAlgorithmConfusion(t *testing.T) {
+	t.Parallel()
+	assert := internal.NewAssert(t, "TestJWTAlgorithmConfusion")
+
+	rsaKey, _ := rsa.GenerateKey(rand.Reader, 2048)
+	publicKeyDER, _ := MarshalPublicKeyDER(&rsaKey.PublicKey)
+
+	// HS256 signed with the rsa public key as secret must not be verified by the rsa public key
+	token, err := SignJWT(RegisteredClaims{Subject: "attacker"}, HS256, publicKeyDER, "")
+	assert.IsNil(err)
+	_, err = VerifyJWT[RegisteredClaims](token, &rsaKey.PublicKey)
+	assert.Equal(true, errors.Is(err, ErrJWTInvalidKey))
+
+	p384Key, _ := GenerateEcdsaKey(elliptic.P384())
+	_, err = SignJWT(RegisteredClaims{}, ES256, p384Key, "")
+	assert.Equal(true, errors.Is(err, ErrJWTInvalidKey))
+
+	// alg none is never accepted
+	none := encodeJWKBytes([]byte(`{"alg":"none"}`)) + "." + encodeJWKBytes([]byte(`{}`)) + "."
+	_, err = VerifyJWT[RegisteredClaims](none, []byte("secret"))
+	assert.Equal(true, errors.Is(err, ErrJWTUnsupportedAlg))
+
+	_, err = VerifyJWT[RegisteredClaims]("a.b", []byte("secret"))
+	assert.Equal(true, errors.Is(err, ErrJWTMalformed))
+}
+
+func TestJWTValidateClaims(t *testing.T) {
+	t.Parallel()
+	assert := internal.NewAssert(t, "TestJWTValidateClaims")
+
+	secret := []byte("secret")
+	now := time.Unix(1700000000, 0)
+
+	tests := []struct {
+		claims RegisteredClaims
+		opts   JWTVerifyOptions
+		err    error
+	}{
+		{RegisteredClaims{ExpiresAt: now.Unix() + 1}, JWTVerifyOptions{}, nil},
+		{RegisteredClaims{ExpiresAt: now.Unix()}, JWTVerifyOptions{}, ErrJWTExpired},
+		{RegisteredClaims{ExpiresAt: now.Unix() - 30}, JWTVerifyOptions{ClockSkew: time.Minute}, nil},
+		{RegisteredClaims{ExpiresAt: now.Unix() - 90}, JWTVerifyOptions{ClockSkew: time.Minute}, ErrJWTExpired},
+		{RegisteredClaims{}, JWTVerifyOptions{RequireExp: true}, ErrJWTExpired},
+		{RegisteredClaims{NotBefore: now.Unix() + 10}, JWTVerifyOptions{}, ErrJWTNotValidYet},
+		{RegisteredClaims{NotBefore: now.Unix() + 10}, JWTVerifyOptions{ClockSkew: 10 * time.Second}, nil},
+		{RegisteredClaims{IssuedAt: now.Unix() + 10}, JWTVerifyOptions{}, ErrJWTIssuedInFuture},
+		{RegisteredClaims{IssuedAt: now.Unix() - 10}, JWTVerifyOptions{}, nil},
+		{RegisteredClaims{Issuer: "a"}, JWTVerifyOptions{Issuer: "a"}, nil},
+		{RegisteredClaims{Issuer: "a"}, JWTVerifyOptions{Issuer: "b"}, ErrJWTInvalidIssuer},
+		{RegisteredClaims{Audience: Audience{"a", "b"}}, JWTVerifyOptions{Audience: "b"}, nil},
+		{RegisteredClaims{Audience: Audience{"a"}}, JWTVerifyOptions{Audience: "b"}, ErrJWTInvalidAudience},
+		{RegisteredClaims{}, JWTVerifyOptions{Audience: "b"}, ErrJWTInvalidAudience},
+		{RegisteredClaims{Subject: "a"}, JWTVerifyOptions{Subject: "b"}, ErrJWTInvalidSubject},
+	}
+
+	for _, tt := range tests {
+		token, err := SignJWT(tt.claims, HS256, secret, "")
+		assert.IsNil(err)
+
+		tt.opts.Now = func() time.Time { return now }
+		_, err = VerifyJWT[RegisteredClaims](token, secret, tt.opts)
+		if tt.err == nil {
+			assert.IsNil(err)
+		} else {
+			assert.Equal(tt.err, err)
+		}
+	}
+}
+
+func TestJWTRFC7515(t *testing.T) {
+	t.Parallel()
+	assert := internal.NewAssert(t, "TestJWTRFC7515")
+
+	// RFC 7515 appendix A.1
+	jwk, err := ParseJWK([]byte(`{"kty":"oct",
+		"k":"AyM1SysPpbyDfgZld3umj1qzKObwVMkoqQ-EstJQLr_T-1qS0gZH75aKtMN3Yj0iPS4hcgUuTwjAzZr1Z9CAow"}`))
+	assert.IsNil(err)
+
+	token := "eyJ0eXAiOiJKV1QiLA0KICJhbGciOiJIUzI1NiJ9." +
+		"eyJpc3MiOiJqb2UiLA0KICJleHAiOjEzMDA4MTkzODAsDQogImh0dHA6Ly9leGFtcGxlLmNvbS9pc19yb290Ijp0cnVlfQ." +
+		"dBjftJeZ4CVP-mB92K27uhbUJU1p1r_wW1gFWFOEjXk"
+
+	opts := JWTVerifyOptions{Issuer: "joe", Now: func() time.Time { return time.Unix(1300819000, 0) }}
+	claims, err := VerifyJWT[RegisteredClaims](token, jwk, opts)
+	assert.IsNil(err)
+	assert.Equal(int64(1300819380), claims.ExpiresAt)
+
+	opts.Now = time.Now
+	_, err = VerifyJWT[RegisteredClaims](token, jwk, opts)
+	assert.Equal(ErrJWTExpired, err)
+
+	// RFC 8037 appendix A.4
+	jwk, err = ParseJWK([]byte(`{"kty":"OKP","crv":"Ed25519",
+		"d":"nWGxne_9WmC6hEr0kuwsxERJxWl7MmkZcDusAxyuf2A","x":"11qYAYKxCrfVS_7TyWQHOg7hcvPapiMlrwIaaPcHURo"}`))
+	assert.IsNil(err)
+	key, err := jwk.Key()
+	assert.IsNil(err)
+
+	jws, err := SignJWS([]byte("Example of Ed25519 signing"), JWTHeader{Alg: EdDSA}, key)
+	assert.IsNil(err)
+	assert.Equal("eyJhbGciOiJFZERTQSJ9.RXhhbXBsZSBvZiBFZDI1NTE5IHNpZ25pbmc."+
+		"hgyY0il_MGCjP0JzlnLWG1PPOt7-09PGcvMg3AIbQR6dWbhijcNR4ki4iylGjg5BhVsPt9g7sVvpAr_MuM0KAg", jws)
+
+	_, payload, err := VerifyJWS(jws, jwk.Public())
+	assert.IsNil(err)
+	assert.Equal("Example of Ed25519 signing", string(payload))
+}
+
+func TestJWTWithJWKS(t *testing.T) {
+	t.Parallel()
+	assert := internal.NewAssert(t, "TestJWTWithJWKS")
+
+	oldKey, _ := GenerateEcdsaKey(elliptic.P256())
+	newKey, _, _ := GenerateEd25519Key()
+
+	oldJWK, _ := NewJWK(oldKey)
+	oldJWK.Kid, oldJWK.Alg = "old", string(ES256)
+	newJWK, _ := NewJWK(newKey)
+	newJWK.Kid = "new"
+
+	data, err := json.Marshal((&JWKS{Keys: []JWK{*oldJWK, *newJWK}}).Public())
+	assert.IsNil(err)
+	assert.Equal(false, strings.Contains(string(data), `"d"`))
+
+	keySet, err := ParseJWKS(data)
+	assert.IsNil(err)
+
+	oldToken, _ := SignJWT(RegisteredClaims{Subject: "old"}, ES256, oldKey, "old")
+	newToken, _ := SignJWT(RegisteredClaims{Subject: "new"}, EdDSA, newKey, "new")
+
+	claims, err := VerifyJWT[RegisteredClaims](oldToken, keySet)
+	assert.IsNil(err)
+	assert.Equal("old", claims.Subject)
+
+	claims, err = VerifyJWT[RegisteredClaims](newToken, keySet)
+	assert.IsNil(err)
+	assert.Equal("new", claims.Subject)
+
+	unknownToken, _ := SignJWT(RegisteredClaims{}, EdDSA, newKey, "unknown")
+	_, err = VerifyJWT[RegisteredClaims](unknownToken, keySet)
+	assert.Equal(ErrJWTKeyNotFound, err)
+
+	// the alg of key doesn't match
+	_, err = keySet.Lookup("old", ES384)
+	assert.Equal(ErrJWTKeyNotFound, err)
+
+	// the only key is selected if kid is missing
+	noKidToken, _ := SignJWT(RegisteredClaims{}, ES256, oldKey, "")
+	_, err = VerifyJWT[RegisteredClaims](noKidToken, &JWKS{Keys: keySet.Keys[:1]})
+	assert.IsNil(err)
+	_, err = VerifyJWT[RegisteredClaims](noKidToken, keySet)
+	assert.Equal(ErrJWTKeyNotFound, err)
+
+	keyFunc := JWTKeyFunc(func(header JWTHeader) (any, error) {
+		return &oldKey.PublicKey, nil
+	})
+	_, err = VerifyJWT[RegisteredClaims](oldToken, keyFunc)
+	assert.IsNil(err)
+}
+
+func TestAudienceJSON(t *testing.T) {
+	t.Parallel()
+	assert := internal.NewAssert(t, "TestAudienceJSON")
+
+	data, _ := json.Marshal(RegisteredClaims{Audience: Audience{"a"}})
+	assert.Equal(`{"aud":"a"}`, string(data))
+
+	data, _ = json.Marshal(RegisteredClaims{Audience: Audience{"a", "b"}})
+	assert.Equal(`{"aud":["a","b"]}`, string(data))
+
+	var claims RegisteredClaims
+	assert.IsNil(json.Unmarshal([]byte(`{"aud":"a"}`), &claims))
+	assert.Equal(Audience{"a"}, claims.Audience)
+	assert.IsNil(json.Unmarshal([]byte(`{"aud":["a","b"]}`), &claims))
+	assert.Equal(Audience{"a", "b"}, claims.Audience)
+	assert.IsNotNil(json.Unmarshal([]byte(`{"aud":1}`), &claims))
+}
+
+func TestJWE(t *testing.T) {
+	t.Parallel()
+	assert := internal.NewAssert(t, "TestJWE")
+
+	key, _ := rsa.GenerateKey(rand.Reader, 2048)
+	otherKey, _ := rsa.GenerateKey(rand.Reader, 2048)
+
+	for _, alg := range []JWTAlgorithm{RSAOAEP, RSAOAEP256} {
+		token, err := EncryptJWE([]byte("hello lancet"), alg, &key.PublicKey, "k1", "")
+		assert.IsNil(err)
+		assert.Equal(5, len(strings.Split(token, ".")))
+
+		header, plaintext, err := DecryptJWE(token, key)
+		assert.IsNil(err)
+		assert.Equal(JWTHeader{Alg: alg, Enc: A256GCM, Kid: "k1"}, header)
+		assert.Equal("hello lancet", string(plaintext))
+
+		_, _, err = DecryptJWE(token, otherKey)
+		assert.Equal(ErrJWEDecryption, err)
+
+		// the protected header is authenticated
+		parts := strings.Split(token, ".")
+		parts[0] = encodeJWKBytes([]byte(`{"alg":"` + string(alg) + `","enc":"A256GCM"}`))
+		_, _, err = DecryptJWE(strings.Join(parts, "."), key)
+		assert.Equal(ErrJWEDecryption, err)
+	}
+
+	_, err := EncryptJWE([]byte("hello"), RS256, &key.PublicKey, "", "")
+	assert.Equal(ErrJWTUnsupportedAlg, err)
+
+	_, _, err = DecryptJWE("a.b.c", key)
+	assert.Equal(ErrJWTMalformed, err)
+
+	// nested jwt: sign then encrypt
+	signed, _ := SignJWT(RegisteredClaims{Subject: "nested"}, RS256, key, "")
+	token, err := EncryptJWE([]byte(signed), RSAOAEP256, &otherKey.PublicKey, "", "JWT")
+	assert.IsNil(err)
+
+	header, plaintext, err := DecryptJWE(token, otherKey)
+	assert.IsNil(err)
+	assert.Equal("JWT", header.Cty)
+
+	claims, err := VerifyJWT[RegisteredClaims](string(plaintext), &key.PublicKey)
+	assert.IsNil(err)
+	assert.Equal("nested", claims.Subject)
+}
+
+func TestJWTVerifyECDSAPublicKeyOnly(t *testing.T) {
+	t.Parallel()
+	assert := internal.NewAssert(t, "TestJWTVerifyECDSAPublicKeyOnly")
+
+	key, _ := ecdsa.GenerateKey(elliptic.P256(), rand.Reader)
+	token, _ := SignJWT(RegisteredClaims{}, ES256, key, "")
+
+	// private key is not accepted for verification
+	_, err := VerifyJWT[RegisteredClaims](token, key)
+	assert.Equal(ErrJWTInvalidKey, err)
+
+	_, err = VerifyJWT[RegisteredClaims](token, ed25519.PublicKey(make([]byte, 32)))
+	assert.Equal(ErrJWTInvalidKey, err)
+}
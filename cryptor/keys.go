@@ -0,0 +1,362 @@
+// Copyright 2023 dudaodong@gmail.com. All rights reserved.
+// Use of this source code is governed by MIT license
+
+package cryptor
+
+import (
+	"crypto"
+	"crypto/ecdsa"
+	"crypto/ed25519"
+	"crypto/elliptic"
+	"crypto/rand"
+	"crypto/rsa"
+	"crypto/x509"
+	"crypto/x509/pkix"
+	"encoding/asn1"
+	"encoding/pem"
+	"errors"
+	"fmt"
+	"io"
+	"os"
+	"strings"
+
+	"golang.org/x/crypto/curve25519"
+)
+
+// X25519PrivateKey is the 32 bytes private key of X25519 key agreement.
+type X25519PrivateKey []byte
+
+// X25519PublicKey is the 32 bytes public key of X25519 key agreement.
+type X25519PublicKey []byte
+
+var oidX25519 = asn1.ObjectIdentifier{1, 3, 101, 110}
+
+// GenerateEd25519Key creates an ed25519 key pair.
+func GenerateEd25519Key() (ed25519.PrivateKey, ed25519.PublicKey, error) {
+	publicKey, privateKey, err := ed25519.GenerateKey(rand.Reader)
+	return privateKey, publicKey, err
+}
+
+// GenerateEcdsaKey creates an ecdsa key on curve, the curve should be elliptic.P256(), elliptic.P384() or elliptic.P521().
+func GenerateEcdsaKey(curve elliptic.Curve) (*ecdsa.PrivateKey, error) {
+	if _, err := ecdsaHash(curve); err != nil {
+		return nil, err
+	}
+	return ecdsa.GenerateKey(curve, rand.Reader)
+}
+
+// GenerateX25519Key creates a x25519 key pair.
+func GenerateX25519Key() (X25519PrivateKey, X25519PublicKey, error) {
+	privateKey := make(X25519PrivateKey, curve25519.ScalarSize)
+	if _, err := io.ReadFull(rand.Reader, privateKey); err != nil {
+		return nil, nil, err
+	}
+
+	publicKey, err := privateKey.PublicKey()
+	if err != nil {
+		return nil, nil, err
+	}
+
+	return privateKey, publicKey, nil
+}
+
+// PublicKey returns the public key of x25519 private key.
+func (k X25519PrivateKey) PublicKey() (X25519PublicKey, error) {
+	if len(k) != curve25519.ScalarSize {
+		return nil, errors.New("invalid x25519 private key size")
+	}
+
+	publicKey, err := curve25519.X25519(k, curve25519.Basepoint)
+	if err != nil {
+		return nil, err
+	}
+
+	return publicKey, nil
+}
+
+// X25519SharedSecret computes the shared secret of privateKey and the public key of peer, both sides get the same
+// secret. The secret should not be used as key directly, derive keys from it with DeriveKeyHKDF.
+func X25519SharedSecret(privateKey X25519PrivateKey, peerPublicKey X25519PublicKey) ([]byte, error) {
+	if len(privateKey) != curve25519.ScalarSize || len(peerPublicKey) != curve25519.PointSize {
+		return nil, errors.New("invalid x25519 key size")
+	}
+
+	// low order public key which results in all zero secret is rejected by X25519
+	return curve25519.X25519(privateKey, peerPublicKey)
+}
+
+// ParsePrivateKeyDER parses the PKCS#8, PKCS#1 (rsa) or SEC 1 (ecdsa) DER encoded private key,
+// the result is *rsa.PrivateKey, *ecdsa.PrivateKey, ed25519.PrivateKey or X25519PrivateKey.
+func ParsePrivateKeyDER(der []byte) (crypto.PrivateKey, error) {
+	if key, ok := parseX25519PrivateKey(der); ok {
+		return key, nil
+	}
+
+	if key, err := x509.ParsePKCS8PrivateKey(der); err == nil {
+		return key, nil
+	}
+	if key, err := x509.ParsePKCS1PrivateKey(der); err == nil {
+		return key, nil
+	}
+	if key, err := x509.ParseECPrivateKey(der); err == nil {
+		return key, nil
+	}
+
+	return nil, errors.New("unsupported private key format")
+}
+
+// ParsePublicKeyDER parses the PKIX or PKCS#1 (rsa) DER encoded public key,
+// the result is *rsa.PublicKey, *ecdsa.PublicKey, ed25519.PublicKey or X25519PublicKey.
+func ParsePublicKeyDER(der []byte) (crypto.PublicKey, error) {
+	if key, ok := parseX25519PublicKey(der); ok {
+		return key, nil
+	}
+
+	if key, err := x509.ParsePKIXPublicKey(der); err == nil {
+		return key, nil
+	}
+	if key, err := x509.ParsePKCS1PublicKey(der); err == nil {
+		return key, nil
+	}
+
+	return nil, errors.New("unsupported public key format")
+}
+
+// ParsePrivateKeyPEM parses the first PEM block of data as private key, see ParsePrivateKeyDER.
+func ParsePrivateKeyPEM(data []byte) (crypto.PrivateKey, error) {
+	der, err := decodePEM(data, "private key")
+	if err != nil {
+		return nil, err
+	}
+	return ParsePrivateKeyDER(der)
+}
+
+// ParsePublicKeyPEM parses the first PEM block of data as public key, see ParsePublicKeyDER.
+// The public key of certificate is returned if the block is a certificate.
+func ParsePublicKeyPEM(data []byte) (crypto.PublicKey, error) {
+	block, _ := pem.Decode(data)
+	if block != nil && block.Type == "CERTIFICATE" {
+		cert, err := x509.ParseCertificate(block.Bytes)
+		if err != nil {
+			return nil, err
+		}
+		return cert.PublicKey, nil
+	}
+
+	der, err := decodePEM(data, "public key")
+	if err != nil {
+		return nil, err
+	}
+	return ParsePublicKeyDER(der)
+}
+
+// LoadPrivateKey reads the PEM encoded private key file, see ParsePrivateKeyPEM.
+func LoadPrivateKey(filename string) (crypto.PrivateKey, error) {
+	data, err := os.ReadFile(filename)
+	if err != nil {
+		return nil, err
+	}
+	return ParsePrivateKeyPEM(data)
+}
+
+// LoadPublicKey reads the PEM encoded public key file, see ParsePublicKeyPEM.
+func LoadPublicKey(filename string) (crypto.PublicKey, error) {
+	data, err := os.ReadFile(filename)
+	if err != nil {
+		return nil, err
+	}
+	return ParsePublicKeyPEM(data)
+}
+
+// MarshalPrivateKeyDER encodes private key in PKCS#8 DER form.
+func MarshalPrivateKeyDER(key crypto.PrivateKey) ([]byte, error) {
+	if k, ok := key.(X25519PrivateKey); ok {
+		if len(k) != curve25519.ScalarSize {
+			return nil, errors.New("invalid x25519 private key size")
+		}
+
+		privateKey, err := asn1.Marshal([]byte(k))
+		if err != nil {
+			return nil, err
+		}
+
+		return asn1.Marshal(pkcs8PrivateKey{Algorithm: pkix.AlgorithmIdentifier{Algorithm: oidX25519}, PrivateKey: privateKey})
+	}
+
+	return x509.MarshalPKCS8PrivateKey(key)
+}
+
+// MarshalPublicKeyDER encodes public key in PKIX DER form.
+func MarshalPublicKeyDER(key crypto.PublicKey) ([]byte, error) {
+	if k, ok := key.(X25519PublicKey); ok {
+		if len(k) != curve25519.PointSize {
+			return nil, errors.New("invalid x25519 public key size")
+		}
+
+		return asn1.Marshal(publicKeyInfo{
+			Algorithm: pkix.AlgorithmIdentifier{Algorithm: oidX25519},
+			PublicKey: asn1.BitString{Bytes: k, BitLength: 8 * len(k)},
+		})
+	}
+
+	return x509.MarshalPKIXPublicKey(key)
+}
+
+// MarshalPrivateKeyPEM encodes private key in PKCS#8 PEM form.
+func MarshalPrivateKeyPEM(key crypto.PrivateKey) ([]byte, error) {
+	der, err := MarshalPrivateKeyDER(key)
+	if err != nil {
+		return nil, err
+	}
+	return pem.EncodeToMemory(&pem.Block{Type: "PRIVATE KEY", Bytes: der}), nil
+}
+
+// MarshalPublicKeyPEM encodes public key in PKIX PEM form.
+func MarshalPublicKeyPEM(key crypto.PublicKey) ([]byte, error) {
+	der, err := MarshalPublicKeyDER(key)
+	if err != nil {
+		return nil, err
+	}
+	return pem.EncodeToMemory(&pem.Block{Type: "PUBLIC KEY", Bytes: der}), nil
+}
+
+type pkcs8PrivateKey struct {
+	Version    int
+	Algorithm  pkix.AlgorithmIdentifier
+	PrivateKey []byte
+}
+
+type publicKeyInfo struct {
+	Algorithm pkix.AlgorithmIdentifier
+	PublicKey asn1.BitString
+}
+
+func parseX25519PrivateKey(der []byte) (X25519PrivateKey, bool) {
+	var pkcs8 pkcs8PrivateKey
+	if rest, err := asn1.Unmarshal(der, &pkcs8); err != nil || len(rest) > 0 || !pkcs8.Algorithm.Algorithm.Equal(oidX25519) {
+		return nil, false
+	}
+
+	var key []byte
+	if rest, err := asn1.Unmarshal(pkcs8.PrivateKey, &key); err != nil || len(rest) > 0 || len(key) != curve25519.ScalarSize {
+		return nil, false
+	}
+
+	return key, true
+}
+
+func parseX25519PublicKey(der []byte) (X25519PublicKey, bool) {
+	var info publicKeyInfo
+	if rest, err := asn1.Unmarshal(der, &info); err != nil || len(rest) > 0 || !info.Algorithm.Algorithm.Equal(oidX25519) {
+		return nil, false
+	}
+
+	key := info.PublicKey.RightAlign()
+	if len(key) != curve25519.PointSize {
+		return nil, false
+	}
+
+	return key, true
+}
+
+// decodePEM returns the bytes of first PEM block, the encrypted PEM block is not supported.
+func decodePEM(data []byte, name string) ([]byte, error) {
+	block, _ := pem.Decode(data)
+	if block == nil {
+		return nil, fmt.Errorf("failed to decode PEM block containing the %s", name)
+	}
+	if strings.Contains(strings.ToUpper(block.Type), "ENCRYPTED") || block.Headers["Proc-Type"] != "" {
+		return nil, errors.New("encrypted PEM block is not supported")
+	}
+	return block.Bytes, nil
+}
+
+// EcdsaSign signs data with ecdsa private key and returns the ASN.1 DER encoded signature,
+// the data is hashed with SHA-256 for P-256 and SHA-384 for P-384.
+func EcdsaSign(privateKey *ecdsa.PrivateKey, data []byte) ([]byte, error) {
+	hash, err := ecdsaHash(privateKey.Curve)
+	if err != nil {
+		return nil, err
+	}
+
+	hashed, err := hashData(hash, data)
+	if err != nil {
+		return nil, err
+	}
+
+	return ecdsa.SignASN1(rand.Reader, privateKey, hashed)
+}
+
+// EcdsaVerify verifies the signature of data returned by EcdsaSign.
+func EcdsaVerify(publicKey *ecdsa.PublicKey, data, signature []byte) error {
+	hash, err := ecdsaHash(publicKey.Curve)
+	if err != nil {
+		return err
+	}
+
+	hashed, err := hashData(hash, data)
+	if err != nil {
+		return err
+	}
+
+	if !ecdsa.VerifyASN1(publicKey, hashed, signature) {
+		return errors.New("ecdsa: verification error")
+	}
+
+	return nil
+}
+
+// Ed25519Sign signs data with ed25519 private key.
+func Ed25519Sign(privateKey ed25519.PrivateKey, data []byte) ([]byte, error) {
+	if len(privateKey) != ed25519.PrivateKeySize {
+		return nil, errors.New("ed25519: invalid private key size")
+	}
+	return ed25519.Sign(privateKey, data), nil
+}
+
+// Ed25519Verify verifies the signature of data returned by Ed25519Sign.
+func Ed25519Verify(publicKey ed25519.PublicKey, data, signature []byte) error {
+	if len(publicKey) != ed25519.PublicKeySize {
+		return errors.New("ed25519: invalid public key size")
+	}
+	if !ed25519.Verify(publicKey, data, signature) {
+		return errors.New("ed25519: verification error")
+	}
+	return nil
+}
+
+func ecdsaHash(curve elliptic.Curve) (crypto.Hash, error) {
+	switch curve {
+	case elliptic.P256():
+		return crypto.SHA256, nil
+	case elliptic.P384():
+		return crypto.SHA384, nil
+	case elliptic.P521():
+		return crypto.SHA512, nil
+	}
+	return 0, errors.New("ecdsa: unsupported curve")
+}
+
+// rsaPublicKey asserts key is rsa public key
+func rsaPublicKey(key crypto.PublicKey, err error) (*rsa.PublicKey, error) {
+	if err != nil {
+		return nil, err
+	}
+	pubKey, ok := key.(*rsa.PublicKey)
+	if !ok {
+		return nil, errors.New("failed to parse RSA public key")
+	}
+	return pubKey, nil
+}
+
+// rsaPrivateKey asserts key is rsa private key
+func rsaPrivateKey(key crypto.PrivateKey, err error) (*rsa.PrivateKey, error) {
+	if err != nil {
+		return nil, err
+	}
+	priKey, ok := key.(*rsa.PrivateKey)
+	if !ok {
+		return nil, errors.New("failed to parse RSA private key")
+	}
+	return priKey, nil
+}
@@ -0,0 +1,146 @@
+package cryptor
+
+import (
+	"crypto"
+	"crypto/ecdsa"
+	"crypto/ed25519"
+	"crypto/elliptic"
+	"crypto/rsa"
+	"encoding/hex"
+	"testing"
+
+	"github.com/duke-git/lancet/v2/internal"
+)
+
+func TestKeysPEM(t *testing.T) {
+	t.Parallel()
+
+	assert := internal.NewAssert(t, "TestKeysPEM")
+
+	edPri, edPub, err := GenerateEd25519Key()
+	assert.IsNil(err)
+	ecPri, err := GenerateEcdsaKey(elliptic.P256())
+	assert.IsNil(err)
+	xPri, xPub, err := GenerateX25519Key()
+	assert.IsNil(err)
+	rsaPri, rsaPub := GenerateRsaKeyPair(1024)
+
+	pairs := []struct {
+		private crypto.PrivateKey
+		public  crypto.PublicKey
+	}{
+		{edPri, edPub},
+		{ecPri, &ecPri.PublicKey},
+		{xPri, xPub},
+		{rsaPri, rsaPub},
+	}
+
+	for _, pair := range pairs {
+		priPEM, err := MarshalPrivateKeyPEM(pair.private)
+		assert.IsNil(err)
+		pubPEM, err := MarshalPublicKeyPEM(pair.public)
+		assert.IsNil(err)
+
+		private, err := ParsePrivateKeyPEM(priPEM)
+		assert.IsNil(err)
+		assert.Equal(pair.private, private)
+
+		public, err := ParsePublicKeyPEM(pubPEM)
+		assert.IsNil(err)
+		assert.Equal(pair.public, public)
+	}
+
+	_, err = GenerateEcdsaKey(elliptic.P224())
+	assert.IsNotNil(err)
+	_, err = ParsePrivateKeyPEM([]byte("invalid"))
+	assert.IsNotNil(err)
+	_, err = ParsePublicKeyDER([]byte("invalid"))
+	assert.IsNotNil(err)
+	_, err = MarshalPublicKeyDER(X25519PublicKey("short"))
+	assert.IsNotNil(err)
+
+	// the key files created by GenerateRsaKey
+	priKey, err := LoadPrivateKey("./rsa_private.pem")
+	assert.IsNil(err)
+	_, ok := priKey.(*rsa.PrivateKey)
+	assert.Equal(true, ok)
+
+	pubKey, err := LoadPublicKey("./rsa_public.pem")
+	assert.IsNil(err)
+	_, ok = pubKey.(*rsa.PublicKey)
+	assert.Equal(true, ok)
+}
+
+func TestSignAndVerify(t *testing.T) {
+	t.Parallel()
+
+	assert := internal.NewAssert(t, "TestSignAndVerify")
+
+	data := []byte("hello")
+
+	for _, curve := range []elliptic.Curve{elliptic.P256(), elliptic.P384()} {
+		key, _ := GenerateEcdsaKey(curve)
+		signature, err := EcdsaSign(key, data)
+		assert.IsNil(err)
+		assert.IsNil(EcdsaVerify(&key.PublicKey, data, signature))
+		assert.IsNotNil(EcdsaVerify(&key.PublicKey, []byte("hello!"), signature))
+	}
+
+	edPri, edPub, _ := GenerateEd25519Key()
+	signature, err := Ed25519Sign(edPri, data)
+	assert.IsNil(err)
+	assert.IsNil(Ed25519Verify(edPub, data, signature))
+	assert.IsNotNil(Ed25519Verify(edPub, []byte("hello!"), signature))
+	assert.IsNotNil(Ed25519Verify(ed25519.PublicKey("short"), data, signature))
+
+	// RFC 8032 section 7.1 test 1
+	seed, _ := hex.DecodeString("9d61b19deffd5a60ba844af492ec2cc44449c5697b326919703bac031cae7f60")
+	signature, _ = Ed25519Sign(ed25519.NewKeyFromSeed(seed), nil)
+	assert.Equal("e5564300c360ac729086e2cc806e828a84877f1eb8e5d974d873e065224901555fb8821590a33bacc61e39701cf9b46bd25bf5f0595bbe24655141438e7a100b",
+		hex.EncodeToString(signature))
+
+	rsaPri, rsaPub := GenerateRsaKeyPair(1024)
+	signature, err = RsaSignWithKey(crypto.SHA256, data, rsaPri)
+	assert.IsNil(err)
+	assert.IsNil(RsaVerifySignWithKey(crypto.SHA256, data, signature, rsaPub))
+
+	encrypted, err := RsaEncryptWithKey(data, rsaPub)
+	assert.IsNil(err)
+	decrypted, err := RsaDecryptWithKey(encrypted, rsaPri)
+	assert.IsNil(err)
+	assert.Equal(data, decrypted)
+
+	_, err = EcdsaSign(&ecdsa.PrivateKey{PublicKey: ecdsa.PublicKey{Curve: elliptic.P224()}}, data)
+	assert.IsNotNil(err)
+}
+
+func TestX25519SharedSecret(t *testing.T) {
+	t.Parallel()
+
+	assert := internal.NewAssert(t, "TestX25519SharedSecret")
+
+	// RFC 7748 section 6.1
+	decode := func(s string) []byte {
+		b, _ := hex.DecodeString(s)
+		return b
+	}
+	alicePri := X25519PrivateKey(decode("77076d0a7318a57d3c16c17251b26645df4c2f87ebc0992ab177fba51db92c2a"))
+	bobPri := X25519PrivateKey(decode("5dab087e624a8a4b79e17f8b83800ee66f3bb1292618b6fd1c2f8b27ff88e0eb"))
+
+	alicePub, err := alicePri.PublicKey()
+	assert.IsNil(err)
+	assert.Equal("8520f0098930a754748b7ddcb43ef75a0dbf3a0d26381af4eba4a98eaa9b4e6a", hex.EncodeToString(alicePub))
+	bobPub, _ := bobPri.PublicKey()
+
+	secret1, err := X25519SharedSecret(alicePri, bobPub)
+	assert.IsNil(err)
+	secret2, err := X25519SharedSecret(bobPri, alicePub)
+	assert.IsNil(err)
+	assert.Equal(secret1, secret2)
+	assert.Equal("4a5d9d5ba4ce2de1728e3bf480350f25e07e21c947d19e3376f09b3c1e161742", hex.EncodeToString(secret1))
+
+	_, err = X25519SharedSecret(alicePri, make(X25519PublicKey, 32))
+	assert.IsNotNil(err)
+	_, err = X25519SharedSecret(alicePri, X25519PublicKey("short"))
+	assert.IsNotNil(err)
+}
@@ -0,0 +1,301 @@
+// Copyright 2023 dudaodong@gmail.com. All rights reserved.
+// Use of this source code is governed by MIT license
+
+package cryptor
+
+import (
+	"crypto/rand"
+	"crypto/sha256"
+	"crypto/subtle"
+	"encoding/base64"
+	"errors"
+	"fmt"
+	"io"
+	"strconv"
+	"strings"
+
+	"golang.org/x/crypto/argon2"
+	"golang.org/x/crypto/hkdf"
+	"golang.org/x/crypto/pbkdf2"
+	"golang.org/x/crypto/scrypt"
+)
+
+// PasswordHashAlgorithm is the algorithm of password hashing.
+type PasswordHashAlgorithm string
+
+const (
+	Argon2id     PasswordHashAlgorithm = "argon2id"
+	PBKDF2SHA256 PasswordHashAlgorithm = "pbkdf2-sha256"
+	Scrypt       PasswordHashAlgorithm = "scrypt"
+)
+
+// ErrInvalidPasswordHash is returned when the encoded password hash is malformed.
+var ErrInvalidPasswordHash = errors.New("invalid password hash")
+
+// the max params accepted from encoded hash, so a crafted hash can not exhaust memory or cpu on verification
+const (
+	maxArgon2idMemory     = 1 << 20 // KiB, 1 GiB
+	maxArgon2idIterations = 64
+	maxPBKDF2Iterations   = 10_000_000
+	maxScryptMemory       = 1 << 30 // bytes, scrypt uses 128 * N * r bytes
+	maxScryptParallelism  = 16
+)
+
+// PasswordHashParams is the parameters of password hashing, only the fields of Algorithm are used.
+type PasswordHashParams struct {
+	Algorithm PasswordHashAlgorithm
+	// Memory is the memory cost of argon2id in KiB.
+	Memory uint32
+	// Iterations is the time cost of argon2id or the iteration count of pbkdf2.
+	Iterations uint32
+	// Parallelism is the threads of argon2id or the parallelization parameter p of scrypt.
+	Parallelism uint8
+	// CostLog2 is the log2 of scrypt cost parameter N.
+	CostLog2 uint8
+	// BlockSize is the block size parameter r of scrypt.
+	BlockSize  uint32
+	SaltLength uint32
+	KeyLength  uint32
+}
+
+var (
+	// DefaultArgon2idParams is the OWASP recommended argon2id parameters, it is the default of HashPassword.
+	DefaultArgon2idParams = PasswordHashParams{Algorithm: Argon2id, Memory: 19 * 1024, Iterations: 2, Parallelism: 1,
+		SaltLength: 16, KeyLength: 32}
+	// DefaultPBKDF2Params is the OWASP recommended pbkdf2-sha256 parameters.
+	DefaultPBKDF2Params = PasswordHashParams{Algorithm: PBKDF2SHA256, Iterations: 600000, SaltLength: 16, KeyLength: 32}
+	// DefaultScryptParams is the OWASP recommended scrypt parameters.
+	DefaultScryptParams = PasswordHashParams{Algorithm: Scrypt, CostLog2: 17, BlockSize: 8, Parallelism: 1,
+		SaltLength: 16, KeyLength: 32}
+)
+
+// HashPassword hashes password and returns it in PHC string format, eg. `$argon2id$v=19$m=19456,t=2,p=1$salt$hash`,
+// `$pbkdf2-sha256$i=600000$salt$hash` or `$scrypt$ln=17,r=8,p=1$salt$hash`.
+// A random salt is generated for every hash, param `params` is DefaultArgon2idParams if it is not given.
+func HashPassword(password string, params ...PasswordHashParams) (string, error) {
+	p := DefaultArgon2idParams
+	if len(params) > 0 {
+		p = params[0]
+	}
+
+	if err := p.validate(); err != nil {
+		return "", err
+	}
+
+	salt := make([]byte, p.SaltLength)
+	if _, err := io.ReadFull(rand.Reader, salt); err != nil {
+		return "", err
+	}
+
+	key, err := p.deriveKey([]byte(password), salt, p.KeyLength)
+	if err != nil {
+		return "", err
+	}
+
+	return p.encode(salt, key), nil
+}
+
+// VerifyPassword checks if password matches the encoded hash returned by HashPassword in constant time.
+// Error is returned only if the encoded hash is malformed, the hash whose params exceed 1 GiB memory of
+// argon2id or scrypt, 64 iterations of argon2id, 10,000,000 iterations of pbkdf2 or parallelism 16 of scrypt
+// is also rejected.
+func VerifyPassword(password, encodedHash string) (bool, error) {
+	p, salt, key, err := decodePasswordHash(encodedHash)
+	if err != nil {
+		return false, err
+	}
+
+	actual, err := p.deriveKey([]byte(password), salt, uint32(len(key)))
+	if err != nil {
+		return false, err
+	}
+
+	return subtle.ConstantTimeCompare(actual, key) == 1, nil
+}
+
+// NeedsRehash checks if the encoded hash is not created with params, the password should be hashed again
+// with params after it is verified. It returns true if the encoded hash is malformed.
+func NeedsRehash(encodedHash string, params PasswordHashParams) bool {
+	p, _, _, err := decodePasswordHash(encodedHash)
+	if err != nil {
+		return true
+	}
+
+	return p != params.normalize()
+}
+
+// DeriveKeyHKDF derives a key of keyLength bytes from high entropy secret with HKDF-SHA256,
+// the salt is optional and info binds the key to the context, eg. DeriveKeyHKDF(masterKey, nil, []byte("aes"), 32).
+func DeriveKeyHKDF(secret, salt, info []byte, keyLength int) ([]byte, error) {
+	if keyLength <= 0 || keyLength > 255*sha256.Size {
+		return nil, errors.New("invalid key length")
+	}
+
+	key := make([]byte, keyLength)
+	if _, err := io.ReadFull(hkdf.New(sha256.New, secret, salt, info), key); err != nil {
+		return nil, err
+	}
+
+	return key, nil
+}
+
+// DeriveKeyPBKDF2 derives a key of keyLength bytes from passphrase with PBKDF2-SHA256, the salt should be random
+// and stored with the encrypted data. eg. key for AesGcmEncrypt: DeriveKeyPBKDF2(passphrase, salt, 600000, 32).
+func DeriveKeyPBKDF2(passphrase, salt []byte, iterations, keyLength int) ([]byte, error) {
+	if iterations <= 0 {
+		return nil, errors.New("invalid iterations")
+	}
+	if keyLength <= 0 {
+		return nil, errors.New("invalid key length")
+	}
+
+	return pbkdf2.Key(passphrase, salt, iterations, keyLength, sha256.New), nil
+}
+
+// normalize clears the fields which are not used by the algorithm
+func (p PasswordHashParams) normalize() PasswordHashParams {
+	result := PasswordHashParams{Algorithm: p.Algorithm, SaltLength: p.SaltLength, KeyLength: p.KeyLength}
+
+	switch p.Algorithm {
+	case Argon2id:
+		result.Memory, result.Iterations, result.Parallelism = p.Memory, p.Iterations, p.Parallelism
+	case PBKDF2SHA256:
+		result.Iterations = p.Iterations
+	case Scrypt:
+		result.CostLog2, result.BlockSize, result.Parallelism = p.CostLog2, p.BlockSize, p.Parallelism
+	}
+
+	return result
+}
+
+func (p PasswordHashParams) validate() error {
+	if p.SaltLength < 8 || p.KeyLength < 16 {
+		return errors.New("salt length should be at least 8 and key length should be at least 16")
+	}
+
+	switch p.Algorithm {
+	case Argon2id:
+		if p.Iterations == 0 || p.Parallelism == 0 || p.Memory < 8*uint32(p.Parallelism) {
+			return errors.New("invalid argon2id params")
+		}
+	case PBKDF2SHA256:
+		if p.Iterations == 0 {
+			return errors.New("invalid pbkdf2 params")
+		}
+	case Scrypt:
+		if p.CostLog2 == 0 || p.CostLog2 > 30 || p.BlockSize == 0 || p.Parallelism == 0 {
+			return errors.New("invalid scrypt params")
+		}
+	default:
+		return fmt.Errorf("unsupported password hash algorithm %q", p.Algorithm)
+	}
+
+	return nil
+}
+
+func (p PasswordHashParams) deriveKey(password, salt []byte, keyLength uint32) ([]byte, error) {
+	switch p.Algorithm {
+	case Argon2id:
+		return argon2.IDKey(password, salt, p.Iterations, p.Memory, p.Parallelism, keyLength), nil
+	case PBKDF2SHA256:
+		return pbkdf2.Key(password, salt, int(p.Iterations), int(keyLength), sha256.New), nil
+	case Scrypt:
+		return scrypt.Key(password, salt, 1<<p.CostLog2, int(p.BlockSize), int(p.Parallelism), int(keyLength))
+	}
+
+	return nil, fmt.Errorf("unsupported password hash algorithm %q", p.Algorithm)
+}
+
+func (p PasswordHashParams) encode(salt, key []byte) string {
+	var params string
+
+	switch p.Algorithm {
+	case Argon2id:
+		params = fmt.Sprintf("v=%d$m=%d,t=%d,p=%d", argon2.Version, p.Memory, p.Iterations, p.Parallelism)
+	case PBKDF2SHA256:
+		params = fmt.Sprintf("i=%d", p.Iterations)
+	case Scrypt:
+		params = fmt.Sprintf("ln=%d,r=%d,p=%d", p.CostLog2, p.BlockSize, p.Parallelism)
+	}
+
+	return "$" + string(p.Algorithm) + "$" + params + "$" +
+		base64.RawStdEncoding.EncodeToString(salt) + "$" + base64.RawStdEncoding.EncodeToString(key)
+}
+
+// decodePasswordHash parses the PHC string into params, salt and key.
+func decodePasswordHash(encodedHash string) (PasswordHashParams, []byte, []byte, error) {
+	p := PasswordHashParams{}
+
+	parts := strings.Split(encodedHash, "$")
+	if len(parts) < 5 || parts[0] != "" {
+		return p, nil, nil, ErrInvalidPasswordHash
+	}
+	p.Algorithm = PasswordHashAlgorithm(parts[1])
+
+	if p.Algorithm == Argon2id {
+		if len(parts) != 6 || parts[2] != "v="+strconv.Itoa(argon2.Version) {
+			return p, nil, nil, ErrInvalidPasswordHash
+		}
+		parts = append(parts[:2], parts[3:]...)
+	}
+	if len(parts) != 5 {
+		return p, nil, nil, ErrInvalidPasswordHash
+	}
+
+	values := map[string]uint64{}
+	for _, item := range strings.Split(parts[2], ",") {
+		name, value, ok := strings.Cut(item, "=")
+		if !ok {
+			return p, nil, nil, ErrInvalidPasswordHash
+		}
+		n, err := strconv.ParseUint(value, 10, 32)
+		if err != nil {
+			return p, nil, nil, ErrInvalidPasswordHash
+		}
+		values[name] = n
+	}
+
+	switch p.Algorithm {
+	case Argon2id:
+		p.Memory, p.Iterations, p.Parallelism = uint32(values["m"]), uint32(values["t"]), uint8(values["p"])
+		if values["p"] > 255 {
+			return p, nil, nil, ErrInvalidPasswordHash
+		}
+	case PBKDF2SHA256:
+		p.Iterations = uint32(values["i"])
+	case Scrypt:
+		p.CostLog2, p.BlockSize, p.Parallelism = uint8(values["ln"]), uint32(values["r"]), uint8(values["p"])
+		if values["ln"] > 30 || values["p"] > 255 {
+			return p, nil, nil, ErrInvalidPasswordHash
+		}
+	}
+
+	salt, err := base64.RawStdEncoding.DecodeString(parts[3])
+	if err != nil {
+		return p, nil, nil, ErrInvalidPasswordHash
+	}
+	key, err := base64.RawStdEncoding.DecodeString(parts[4])
+	if err != nil {
+		return p, nil, nil, ErrInvalidPasswordHash
+	}
+
+	p.SaltLength, p.KeyLength = uint32(len(salt)), uint32(len(key))
+	if err := p.validate(); err != nil || p.exceedsLimits() {
+		return p, nil, nil, ErrInvalidPasswordHash
+	}
+
+	return p, salt, key, nil
+}
+
+// exceedsLimits checks if the params decoded from hash are too expensive to verify
+func (p PasswordHashParams) exceedsLimits() bool {
+	switch p.Algorithm {
+	case Argon2id:
+		return p.Memory > maxArgon2idMemory || p.Iterations > maxArgon2idIterations
+	case PBKDF2SHA256:
+		return p.Iterations > maxPBKDF2Iterations
+	case Scrypt:
+		return uint64(p.BlockSize) > maxScryptMemory/(uint64(128)<<p.CostLog2) || p.Parallelism > maxScryptParallelism
+	}
+	return false
+}
@@ -0,0 +1,152 @@
+package cryptor
+
+import (
+	"encoding/hex"
+	"strings"
+	"testing"
+
+	"github.com/duke-git/lancet/v2/internal"
+)
+
+var (
+	testArgon2idParams = PasswordHashParams{Algorithm: Argon2id, Memory: 1024, Iterations: 1, Parallelism: 1,
+		SaltLength: 16, KeyLength: 32}
+	testPBKDF2Params = PasswordHashParams{Algorithm: PBKDF2SHA256, Iterations: 1000, SaltLength: 16, KeyLength: 32}
+	testScryptParams = PasswordHashParams{Algorithm: Scrypt, CostLog2: 10, BlockSize: 8, Parallelism: 1,
+		SaltLength: 16, KeyLength: 32}
+)
+
+func TestHashPassword(t *testing.T) {
+	t.Parallel()
+
+	assert := internal.NewAssert(t, "TestHashPassword")
+
+	prefixes := []string{"$argon2id$v=19$m=1024,t=1,p=1$", "$pbkdf2-sha256$i=1000$", "$scrypt$ln=10,r=8,p=1$"}
+	for i, params := range []PasswordHashParams{testArgon2idParams, testPBKDF2Params, testScryptParams} {
+		hash, err := HashPassword("secret", params)
+		assert.IsNil(err)
+		assert.ShouldBeTrue(strings.HasPrefix(hash, prefixes[i]))
+
+		other, _ := HashPassword("secret", params)
+		assert.ShouldBeTrue(hash != other)
+
+		ok, err := VerifyPassword("secret", hash)
+		assert.IsNil(err)
+		assert.Equal(true, ok)
+
+		ok, err = VerifyPassword("Secret", hash)
+		assert.IsNil(err)
+		assert.Equal(false, ok)
+
+		assert.Equal(false, NeedsRehash(hash, params))
+	}
+
+	hash, err := HashPassword("secret")
+	assert.IsNil(err)
+	assert.ShouldBeTrue(strings.HasPrefix(hash, "$argon2id$v=19$m=19456,t=2,p=1$"))
+
+	_, err = HashPassword("secret", PasswordHashParams{Algorithm: "md5", SaltLength: 16, KeyLength: 32})
+	assert.IsNotNil(err)
+	_, err = HashPassword("secret", PasswordHashParams{Algorithm: Argon2id, SaltLength: 16, KeyLength: 32})
+	assert.IsNotNil(err)
+}
+
+func TestVerifyPassword(t *testing.T) {
+	t.Parallel()
+
+	assert := internal.NewAssert(t, "TestVerifyPassword")
+
+	// generated by the reference argon2 implementation
+	ok, err := VerifyPassword("password",
+		"$argon2id$v=19$m=65536,t=2,p=1$c29tZXNhbHQ$CTFhFdXPJO1aFaMaO6Mm5c8y7cJHAph8ArZWb2GRPPc")
+	assert.IsNil(err)
+	assert.Equal(true, ok)
+
+	for _, malformed := range []string{
+		"",
+		"plain",
+		"$argon2id$v=18$m=65536,t=2,p=1$c29tZXNhbHQ$CTFhFdXPJO1aFaMaO6Mm5c8y7cJHAph8ArZWb2GRPPc",
+		"$argon2id$v=19$m=65536,t=2$c29tZXNhbHQ$CTFhFdXPJO1aFaMaO6Mm5c8y7cJHAph8ArZWb2GRPPc",
+		"$argon2id$v=19$m=65536,t=2,p=1$c29tZXNhbHQ$!!!",
+		"$pbkdf2-sha256$i=abc$c29tZXNhbHQ$CTFhFdXPJO1aFaMaO6Mm5c8y7cJHAph8ArZWb2GRPPc",
+		"$scrypt$ln=40,r=8,p=1$c29tZXNhbHQ$CTFhFdXPJO1aFaMaO6Mm5c8y7cJHAph8ArZWb2GRPPc",
+		"$md5$i=1$c29tZXNhbHQ$CTFhFdXPJO1aFaMaO6Mm5c8y7cJHAph8ArZWb2GRPPc",
+	} {
+		ok, err := VerifyPassword("password", malformed)
+		assert.Equal(false, ok)
+		assert.IsNotNil(err)
+	}
+
+	// the params exceeding the limits are rejected before deriving the key
+	for _, expensive := range []string{
+		"$argon2id$v=19$m=4194304,t=2,p=1$c29tZXNhbHQ$CTFhFdXPJO1aFaMaO6Mm5c8y7cJHAph8ArZWb2GRPPc",
+		"$argon2id$v=19$m=65536,t=4294967295,p=1$c29tZXNhbHQ$CTFhFdXPJO1aFaMaO6Mm5c8y7cJHAph8ArZWb2GRPPc",
+		"$pbkdf2-sha256$i=4294967295$c29tZXNhbHQ$CTFhFdXPJO1aFaMaO6Mm5c8y7cJHAph8ArZWb2GRPPc",
+		"$scrypt$ln=20,r=16,p=1$c29tZXNhbHQ$CTFhFdXPJO1aFaMaO6Mm5c8y7cJHAph8ArZWb2GRPPc",
+		"$scrypt$ln=10,r=4294967295,p=1$c29tZXNhbHQ$CTFhFdXPJO1aFaMaO6Mm5c8y7cJHAph8ArZWb2GRPPc",
+		"$scrypt$ln=10,r=8,p=255$c29tZXNhbHQ$CTFhFdXPJO1aFaMaO6Mm5c8y7cJHAph8ArZWb2GRPPc",
+	} {
+		ok, err := VerifyPassword("password", expensive)
+		assert.Equal(false, ok)
+		assert.Equal(ErrInvalidPasswordHash, err)
+	}
+
+	// scrypt with 1 GiB memory is accepted
+	_, _, _, err = decodePasswordHash("$scrypt$ln=20,r=8,p=1$c29tZXNhbHQ$CTFhFdXPJO1aFaMaO6Mm5c8y7cJHAph8ArZWb2GRPPc")
+	assert.IsNil(err)
+}
+
+func TestNeedsRehash(t *testing.T) {
+	t.Parallel()
+
+	assert := internal.NewAssert(t, "TestNeedsRehash")
+
+	hash, _ := HashPassword("secret", testPBKDF2Params)
+	assert.Equal(false, NeedsRehash(hash, testPBKDF2Params))
+
+	upgraded := testPBKDF2Params
+	upgraded.Iterations = 2000
+	assert.Equal(true, NeedsRehash(hash, upgraded))
+
+	longer := testPBKDF2Params
+	longer.KeyLength = 64
+	assert.Equal(true, NeedsRehash(hash, longer))
+
+	assert.Equal(true, NeedsRehash(hash, testArgon2idParams))
+	assert.Equal(true, NeedsRehash("invalid", testArgon2idParams))
+
+	// the unused fields of other algorithms are ignored
+	extra := testPBKDF2Params
+	extra.Memory = 1024
+	assert.Equal(false, NeedsRehash(hash, extra))
+}
+
+func TestDeriveKey(t *testing.T) {
+	t.Parallel()
+
+	assert := internal.NewAssert(t, "TestDeriveKey")
+
+	// RFC 5869 test case 1
+	ikm, _ := hex.DecodeString("0b0b0b0b0b0b0b0b0b0b0b0b0b0b0b0b0b0b0b0b0b0b")
+	salt, _ := hex.DecodeString("000102030405060708090a0b0c")
+	info, _ := hex.DecodeString("f0f1f2f3f4f5f6f7f8f9")
+	key, err := DeriveKeyHKDF(ikm, salt, info, 42)
+	assert.IsNil(err)
+	assert.Equal("3cb25f25faacd57a90434f64d0362f2a2d2d0a90cf1a5a4c5db02d56ecc4c5bf34007208d5b887185865", hex.EncodeToString(key))
+
+	_, err = DeriveKeyHKDF(ikm, salt, info, 0)
+	assert.IsNotNil(err)
+
+	// RFC 7914 section 11 vector of PBKDF2-HMAC-SHA256
+	key, err = DeriveKeyPBKDF2([]byte("passwd"), []byte("salt"), 1, 64)
+	assert.IsNil(err)
+	assert.Equal("55ac046e56e3089fec1691c22544b605f94185216dde0465e68b9d57c20dacbc"+
+		"49ca9cccf179b645991664b39d77ef317c71b845b1e30bd509112041d3a19783", hex.EncodeToString(key))
+
+	_, err = DeriveKeyPBKDF2([]byte("passwd"), []byte("salt"), 0, 32)
+	assert.IsNotNil(err)
+
+	key, _ = DeriveKeyPBKDF2([]byte("passphrase"), []byte("salt"), 1000, 32)
+	encrypted := AesGcmEncrypt([]byte("hello"), key)
+	assert.Equal("hello", string(AesGcmDecrypt(encrypted, key)))
+}
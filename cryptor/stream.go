@@ -0,0 +1,362 @@
+// Copyright 2023 dudaodong@gmail.com. All rights reserved.
+// Use of this source code is governed by MIT license
+
+package cryptor
+
+import (
+	"bufio"
+	"crypto"
+	"crypto/aes"
+	"crypto/cipher"
+	"crypto/rand"
+	"encoding/binary"
+	"encoding/hex"
+	"errors"
+	"io"
+)
+
+// StreamMode is the aes mode of streaming encryption.
+type StreamMode int
+
+const (
+	// StreamModeCTR encrypts the stream with AES-CTR, the output is the random iv followed by the ciphertext.
+	// It is not authenticated, tampered data can not be detected.
+	StreamModeCTR StreamMode = iota
+	// StreamModeGCM splits the stream into chunks and encrypts each chunk with AES-GCM like the STREAM construction,
+	// every chunk is authenticated, reordered, truncated or tampered chunks are detected.
+	StreamModeGCM
+)
+
+const (
+	// streamChunkSize is the plaintext size of each chunk of StreamModeGCM
+	streamChunkSize = 64 * 1024
+	// streamNoncePrefixSize is the size of the random nonce prefix, the nonce of chunk is
+	// prefix(7 bytes) || counter(4 bytes) || last chunk flag(1 byte)
+	streamNoncePrefixSize = 7
+)
+
+// NewEncryptWriter returns a writer which encrypts the data written to it with aes key and writes to w.
+// len(key) should be 16, 24 or 32. Close must be called to flush the last chunk, it doesn't close w.
+func NewEncryptWriter(w io.Writer, key []byte, mode StreamMode) (io.WriteCloser, error) {
+	switch mode {
+	case StreamModeCTR:
+		block, err := AES(key).block()
+		if err != nil {
+			return nil, &CipherError{Algorithm: "aes", Mode: "ctr", Op: "encrypt", Err: err}
+		}
+
+		iv := make([]byte, aes.BlockSize)
+		if _, err := io.ReadFull(rand.Reader, iv); err != nil {
+			return nil, &CipherError{Algorithm: "aes", Mode: "ctr", Op: "encrypt", Err: err}
+		}
+
+		return &ctrWriter{w: w, header: iv, stream: cipher.NewCTR(block, iv)}, nil
+
+	case StreamModeGCM:
+		gcm := AES(key).GCM()
+		if gcm.err != nil {
+			return nil, gcm.error("encrypt", gcm.err)
+		}
+
+		prefix := make([]byte, streamNoncePrefixSize)
+		if _, err := io.ReadFull(rand.Reader, prefix); err != nil {
+			return nil, gcm.error("encrypt", err)
+		}
+
+		return &gcmWriter{
+			w:      w,
+			aead:   gcm.aead,
+			header: prefix,
+			nonce:  newStreamNonce(prefix),
+			buf:    make([]byte, 0, streamChunkSize),
+		}, nil
+	}
+
+	return nil, errors.New("cryptor: unsupported stream mode")
+}
+
+// NewDecryptReader returns a reader which decrypts the data encrypted by NewEncryptWriter from r.
+// For StreamModeGCM, a CipherError wrapping ErrAuthFailed is returned by Read if the data has been tampered with.
+func NewDecryptReader(r io.Reader, key []byte, mode StreamMode) (io.Reader, error) {
+	switch mode {
+	case StreamModeCTR:
+		block, err := AES(key).block()
+		if err != nil {
+			return nil, &CipherError{Algorithm: "aes", Mode: "ctr", Op: "decrypt", Err: err}
+		}
+
+		return &ctrReader{r: r, block: block}, nil
+
+	case StreamModeGCM:
+		gcm := AES(key).GCM()
+		if gcm.err != nil {
+			return nil, gcm.error("decrypt", gcm.err)
+		}
+
+		return &gcmReader{
+			r:    bufio.NewReaderSize(r, streamChunkSize+gcm.aead.Overhead()+1),
+			aead: gcm.aead,
+			buf:  make([]byte, streamChunkSize+gcm.aead.Overhead()),
+		}, nil
+	}
+
+	return nil, errors.New("cryptor: unsupported stream mode")
+}
+
+// ctrWriter writes the iv before the first data
+type ctrWriter struct {
+	w      io.Writer
+	header []byte
+	stream cipher.Stream
+	buf    []byte
+}
+
+func (cw *ctrWriter) Write(p []byte) (int, error) {
+	if err := cw.writeHeader(); err != nil {
+		return 0, err
+	}
+
+	if cap(cw.buf) < len(p) {
+		cw.buf = make([]byte, len(p))
+	}
+	buf := cw.buf[:len(p)]
+	cw.stream.XORKeyStream(buf, p)
+
+	n, err := cw.w.Write(buf)
+	if err == nil && n < len(p) {
+		err = io.ErrShortWrite
+	}
+	return n, err
+}
+
+func (cw *ctrWriter) Close() error {
+	return cw.writeHeader()
+}
+
+func (cw *ctrWriter) writeHeader() error {
+	if cw.header == nil {
+		return nil
+	}
+	_, err := cw.w.Write(cw.header)
+	cw.header = nil
+	return err
+}
+
+type ctrReader struct {
+	r      io.Reader
+	block  cipher.Block
+	stream cipher.Stream
+}
+
+func (cr *ctrReader) Read(p []byte) (int, error) {
+	if cr.stream == nil {
+		iv := make([]byte, aes.BlockSize)
+		if _, err := io.ReadFull(cr.r, iv); err != nil {
+			if err == io.EOF || err == io.ErrUnexpectedEOF {
+				err = &CipherError{Algorithm: "aes", Mode: "ctr", Op: "decrypt", Err: ErrInvalidCiphertext}
+			}
+			return 0, err
+		}
+		cr.stream = cipher.NewCTR(cr.block, iv)
+	}
+
+	n, err := cr.r.Read(p)
+	cr.stream.XORKeyStream(p[:n], p[:n])
+
+	return n, err
+}
+
+type gcmWriter struct {
+	w      io.Writer
+	aead   cipher.AEAD
+	header []byte
+	nonce  *streamNonce
+	buf    []byte
+	out    []byte
+	err    error
+	closed bool
+}
+
+func (gw *gcmWriter) Write(p []byte) (int, error) {
+	if gw.closed {
+		return 0, errors.New("cryptor: write to closed encrypt writer")
+	}
+
+	written := 0
+	for len(p) > 0 {
+		// the full chunk is flushed only if more data comes, so the last chunk is always flushed by Close
+		if len(gw.buf) == streamChunkSize {
+			if err := gw.flush(false); err != nil {
+				return written, err
+			}
+		}
+
+		n := copy(gw.buf[len(gw.buf):streamChunkSize], p)
+		gw.buf = gw.buf[:len(gw.buf)+n]
+		p = p[n:]
+		written += n
+	}
+
+	return written, nil
+}
+
+func (gw *gcmWriter) Close() error {
+	if gw.closed {
+		return gw.err
+	}
+	gw.closed = true
+
+	return gw.flush(true)
+}
+
+func (gw *gcmWriter) flush(last bool) error {
+	if gw.err != nil {
+		return gw.err
+	}
+
+	if gw.header != nil {
+		if _, gw.err = gw.w.Write(gw.header); gw.err != nil {
+			return gw.err
+		}
+		gw.header = nil
+	}
+
+	nonce, err := gw.nonce.next(last)
+	if err != nil {
+		gw.err = &CipherError{Algorithm: "aes", Mode: "gcm", Op: "encrypt", Err: err}
+		return gw.err
+	}
+
+	gw.out = gw.aead.Seal(gw.out[:0], nonce, gw.buf, nil)
+	gw.buf = gw.buf[:0]
+
+	_, gw.err = gw.w.Write(gw.out)
+
+	return gw.err
+}
+
+type gcmReader struct {
+	r     *bufio.Reader
+	aead  cipher.AEAD
+	nonce *streamNonce
+	buf   []byte
+	plain []byte
+	done  bool
+	err   error
+}
+
+func (gr *gcmReader) Read(p []byte) (int, error) {
+	for len(gr.plain) == 0 {
+		if gr.err != nil {
+			return 0, gr.err
+		}
+		if gr.done {
+			return 0, io.EOF
+		}
+		gr.err = gr.next()
+	}
+
+	n := copy(p, gr.plain)
+	gr.plain = gr.plain[n:]
+
+	return n, nil
+}
+
+// next decrypts the next chunk, the chunk is the last one if the underlying reader has no more data after it.
+func (gr *gcmReader) next() error {
+	if gr.nonce == nil {
+		prefix := make([]byte, streamNoncePrefixSize)
+		if _, err := io.ReadFull(gr.r, prefix); err != nil {
+			return gr.error(err)
+		}
+		gr.nonce = newStreamNonce(prefix)
+	}
+
+	n, err := io.ReadFull(gr.r, gr.buf)
+	last := false
+	switch {
+	case err == io.EOF || err == io.ErrUnexpectedEOF:
+		last = true
+	case err != nil:
+		return err
+	default:
+		if _, err := gr.r.Peek(1); err == io.EOF {
+			last = true
+		} else if err != nil {
+			return err
+		}
+	}
+
+	if n < gr.aead.Overhead() {
+		return gr.error(ErrInvalidCiphertext)
+	}
+
+	nonce, err := gr.nonce.next(last)
+	if err != nil {
+		return gr.error(err)
+	}
+
+	gr.plain, err = gr.aead.Open(gr.buf[:0], nonce, gr.buf[:n], nil)
+	if err != nil {
+		return gr.error(ErrAuthFailed)
+	}
+	gr.done = last
+
+	return nil
+}
+
+func (gr *gcmReader) error(err error) error {
+	if err == io.EOF || err == io.ErrUnexpectedEOF {
+		err = ErrInvalidCiphertext
+	}
+	return &CipherError{Algorithm: "aes", Mode: "gcm", Op: "decrypt", Err: err}
+}
+
+// streamNonce generates the nonce of each chunk
+type streamNonce struct {
+	nonce   []byte
+	counter uint32
+	end     bool
+}
+
+func newStreamNonce(prefix []byte) *streamNonce {
+	nonce := make([]byte, streamNoncePrefixSize+5)
+	copy(nonce, prefix)
+	return &streamNonce{nonce: nonce}
+}
+
+func (sn *streamNonce) next(last bool) ([]byte, error) {
+	if sn.end {
+		return nil, errors.New("too many chunks")
+	}
+
+	binary.BigEndian.PutUint32(sn.nonce[streamNoncePrefixSize:], sn.counter)
+	sn.nonce[len(sn.nonce)-1] = 0
+	if last {
+		sn.nonce[len(sn.nonce)-1] = 1
+	}
+
+	sn.counter++
+	if sn.counter == 0 {
+		sn.end = true
+	}
+
+	return sn.nonce, nil
+}
+
+// HashReader reads r until EOF and returns the hex encoded hash value of the data,
+// param `hash` should be crypto.MD5, crypto.SHA1, crypto.SHA224, crypto.SHA256, crypto.SHA384 or crypto.SHA512.
+func HashReader(r io.Reader, hash crypto.Hash) (string, error) {
+	switch hash {
+	case crypto.MD5, crypto.SHA1, crypto.SHA224, crypto.SHA256, crypto.SHA384, crypto.SHA512:
+	default:
+		return "", errors.New("unsupported hash algorithm")
+	}
+
+	h := hash.New()
+	if _, err := io.Copy(h, r); err != nil {
+		return "", err
+	}
+
+	return hex.EncodeToString(h.Sum(nil)), nil
+}
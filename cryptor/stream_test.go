@@ -0,0 +1,137 @@
+package cryptor
+
+import (
+	"bytes"
+	"crypto"
+	"crypto/rand"
+	"errors"
+	"io"
+	"strings"
+	"testing"
+
+	"github.com/duke-git/lancet/v2/internal"
+)
+
+func encryptStream(t *testing.T, data, key []byte, mode StreamMode) []byte {
+	var buf bytes.Buffer
+	w, err := NewEncryptWriter(&buf, key, mode)
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	// write in odd sized pieces to cross the chunk boundaries
+	for len(data) > 0 {
+		n := 10007
+		if n > len(data) {
+			n = len(data)
+		}
+		if _, err := w.Write(data[:n]); err != nil {
+			t.Fatal(err)
+		}
+		data = data[n:]
+	}
+	if err := w.Close(); err != nil {
+		t.Fatal(err)
+	}
+
+	return buf.Bytes()
+}
+
+func decryptStream(encrypted, key []byte, mode StreamMode) ([]byte, error) {
+	r, err := NewDecryptReader(bytes.NewReader(encrypted), key, mode)
+	if err != nil {
+		return nil, err
+	}
+	return io.ReadAll(r)
+}
+
+func TestStreamCrypt(t *testing.T) {
+	t.Parallel()
+
+	assert := internal.NewAssert(t, "TestStreamCrypt")
+
+	key := []byte("abcdefghijklmnop")
+	for _, size := range []int{0, 1, streamChunkSize - 1, streamChunkSize, streamChunkSize + 1, 3*streamChunkSize + 100} {
+		data := make([]byte, size)
+		rand.Read(data)
+
+		for _, mode := range []StreamMode{StreamModeCTR, StreamModeGCM} {
+			encrypted := encryptStream(t, data, key, mode)
+
+			decrypted, err := decryptStream(encrypted, key, mode)
+			assert.IsNil(err)
+			assert.Equal(true, bytes.Equal(data, decrypted))
+		}
+	}
+
+	_, err := NewEncryptWriter(io.Discard, []byte("short"), StreamModeGCM)
+	assert.ShouldBeTrue(errors.Is(err, ErrInvalidKeySize))
+	_, err = NewDecryptReader(strings.NewReader(""), []byte("short"), StreamModeCTR)
+	assert.ShouldBeTrue(errors.Is(err, ErrInvalidKeySize))
+	_, err = NewEncryptWriter(io.Discard, key, StreamMode(100))
+	assert.IsNotNil(err)
+}
+
+func TestStreamCrypt_Tampered(t *testing.T) {
+	t.Parallel()
+
+	assert := internal.NewAssert(t, "TestStreamCrypt_Tampered")
+
+	key := []byte("abcdefghijklmnopabcdefghijklmnop")
+	data := make([]byte, 2*streamChunkSize+10)
+	rand.Read(data)
+
+	encrypted := encryptStream(t, data, key, StreamModeGCM)
+	chunk := streamChunkSize + 16
+
+	tampered := append([]byte(nil), encrypted...)
+	tampered[len(tampered)-1] ^= 1
+	_, err := decryptStream(tampered, key, StreamModeGCM)
+	assert.ShouldBeTrue(errors.Is(err, ErrAuthFailed))
+
+	// truncated at the chunk boundary
+	_, err = decryptStream(encrypted[:streamNoncePrefixSize+2*chunk], key, StreamModeGCM)
+	assert.ShouldBeTrue(errors.Is(err, ErrAuthFailed))
+
+	// reordered chunks
+	reordered := append([]byte(nil), encrypted[:streamNoncePrefixSize]...)
+	reordered = append(reordered, encrypted[streamNoncePrefixSize+chunk:streamNoncePrefixSize+2*chunk]...)
+	reordered = append(reordered, encrypted[streamNoncePrefixSize:streamNoncePrefixSize+chunk]...)
+	reordered = append(reordered, encrypted[streamNoncePrefixSize+2*chunk:]...)
+	_, err = decryptStream(reordered, key, StreamModeGCM)
+	assert.ShouldBeTrue(errors.Is(err, ErrAuthFailed))
+
+	_, err = decryptStream(encrypted, []byte("abcdefghijklmnop"), StreamModeGCM)
+	assert.ShouldBeTrue(errors.Is(err, ErrAuthFailed))
+
+	_, err = decryptStream(encrypted[:streamNoncePrefixSize], key, StreamModeGCM)
+	assert.ShouldBeTrue(errors.Is(err, ErrInvalidCiphertext))
+
+	_, err = decryptStream([]byte("short"), key, StreamModeCTR)
+	assert.ShouldBeTrue(errors.Is(err, ErrInvalidCiphertext))
+}
+
+func TestHashReader(t *testing.T) {
+	t.Parallel()
+
+	assert := internal.NewAssert(t, "TestHashReader")
+
+	tests := []struct {
+		hash     crypto.Hash
+		expected string
+	}{
+		{crypto.MD5, Md5String("hello")},
+		{crypto.SHA1, Sha1("hello")},
+		{crypto.SHA256, Sha256("hello")},
+		{crypto.SHA512, Sha512("hello")},
+	}
+
+	for _, tt := range tests {
+		actual, err := HashReader(strings.NewReader("hello"), tt.hash)
+		assert.IsNil(err)
+		assert.Equal(tt.expected, actual)
+	}
+
+	_, err := HashReader(strings.NewReader("hello"), crypto.MD4)
+	assert.IsNotNil(err)
+}
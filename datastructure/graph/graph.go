@@ -0,0 +1,630 @@
+// Copyright 2023 dudaodong@gmail.com. All rights reserved.
+// Use of this source code is governed by MIT license
+
+// Package datastructure contains some data structure. Graph is a generic directed or undirected, weighted or unweighted graph.
+package datastructure
+
+import (
+	"container/heap"
+	"errors"
+	"fmt"
+	"sort"
+	"strings"
+
+	"golang.org/x/exp/constraints"
+)
+
+// Number is the constraint of graph edge weight.
+type Number interface {
+	constraints.Integer | constraints.Float
+}
+
+// Edge is a connection from one vertex to another with a weight.
+// For unweighted graph, weight of edge is always 1.
+type Edge[K comparable, W Number] struct {
+	From   K
+	To     K
+	Weight W
+}
+
+// Graph is a set of vertices connected by edges.
+// Vertices and edges are kept in insertion order, so all traversals are deterministic.
+type Graph[K comparable, W Number] struct {
+	directed bool
+	weighted bool
+	vertices []K
+	index    map[K]int
+	adjacent map[K][]Edge[K, W]
+}
+
+// NewGraph return a empty Graph pointer.
+// param `directed` decides the graph is directed or not, param `weighted` decides the graph is weighted or not.
+func NewGraph[K comparable, W Number](directed, weighted bool) *Graph[K, W] {
+	return &Graph[K, W]{
+		directed: directed,
+		weighted: weighted,
+		vertices: []K{},
+		index:    map[K]int{},
+		adjacent: map[K][]Edge[K, W]{},
+	}
+}
+
+// IsDirected checks if the graph is directed or not.
+func (g *Graph[K, W]) IsDirected() bool {
+	return g.directed
+}
+
+// IsWeighted checks if the graph is weighted or not.
+func (g *Graph[K, W]) IsWeighted() bool {
+	return g.weighted
+}
+
+// AddVertex add vertices into the graph, the existed vertex will be ignored.
+func (g *Graph[K, W]) AddVertex(vertices ...K) {
+	for _, v := range vertices {
+		if _, ok := g.index[v]; ok {
+			continue
+		}
+		g.index[v] = len(g.vertices)
+		g.vertices = append(g.vertices, v)
+		g.adjacent[v] = []Edge[K, W]{}
+	}
+}
+
+// HasVertex checks if the graph contains the vertex or not.
+func (g *Graph[K, W]) HasVertex(v K) bool {
+	_, ok := g.index[v]
+	return ok
+}
+
+// RemoveVertex remove the vertex and all edges connected to it.
+// return false if the vertex not exist.
+func (g *Graph[K, W]) RemoveVertex(v K) bool {
+	i, ok := g.index[v]
+	if !ok {
+		return false
+	}
+
+	delete(g.adjacent, v)
+	for from, edges := range g.adjacent {
+		g.adjacent[from] = filterEdges(edges, func(e Edge[K, W]) bool { return e.To != v })
+	}
+
+	g.vertices = append(g.vertices[:i], g.vertices[i+1:]...)
+	delete(g.index, v)
+	for j := i; j < len(g.vertices); j++ {
+		g.index[g.vertices[j]] = j
+	}
+
+	return true
+}
+
+// AddEdge add an edge with weight 1 between from and to.
+// The vertices will be added into the graph if they are not existed.
+func (g *Graph[K, W]) AddEdge(from, to K) {
+	g.AddWeightedEdge(from, to, 1)
+}
+
+// AddWeightedEdge add an edge with weight between from and to, if the edge existed, update its weight.
+// For unweighted graph, the weight is ignored and set to 1.
+// The vertices will be added into the graph if they are not existed.
+func (g *Graph[K, W]) AddWeightedEdge(from, to K, weight W) {
+	if !g.weighted {
+		weight = 1
+	}
+
+	g.AddVertex(from, to)
+	g.setEdge(from, to, weight)
+	if !g.directed && from != to {
+		g.setEdge(to, from, weight)
+	}
+}
+
+// RemoveEdge remove the edge between from and to.
+// return false if the edge not exist.
+func (g *Graph[K, W]) RemoveEdge(from, to K) bool {
+	if !g.HasEdge(from, to) {
+		return false
+	}
+
+	g.adjacent[from] = filterEdges(g.adjacent[from], func(e Edge[K, W]) bool { return e.To != to })
+	if !g.directed {
+		g.adjacent[to] = filterEdges(g.adjacent[to], func(e Edge[K, W]) bool { return e.To != from })
+	}
+
+	return true
+}
+
+// HasEdge checks if there is an edge from `from` to `to`.
+func (g *Graph[K, W]) HasEdge(from, to K) bool {
+	_, ok := g.Weight(from, to)
+	return ok
+}
+
+// Weight return the weight of edge between from and to.
+// if the edge not exist, return zero value and false.
+func (g *Graph[K, W]) Weight(from, to K) (W, bool) {
+	for _, e := range g.adjacent[from] {
+		if e.To == to {
+			return e.Weight, true
+		}
+	}
+
+	var zero W
+	return zero, false
+}
+
+// Vertices return all vertices of the graph in insertion order.
+func (g *Graph[K, W]) Vertices() []K {
+	result := make([]K, len(g.vertices))
+	copy(result, g.vertices)
+	return result
+}
+
+// Edges return all edges of the graph.
+// For undirected graph, each edge is returned only once.
+func (g *Graph[K, W]) Edges() []Edge[K, W] {
+	result := []Edge[K, W]{}
+	for _, v := range g.vertices {
+		for _, e := range g.adjacent[v] {
+			if !g.directed && g.index[e.To] < g.index[e.From] {
+				continue
+			}
+			result = append(result, e)
+		}
+	}
+	return result
+}
+
+// Neighbors return the vertices which can be reached from v by one edge.
+func (g *Graph[K, W]) Neighbors(v K) []K {
+	edges := g.adjacent[v]
+	result := make([]K, 0, len(edges))
+	for _, e := range edges {
+		result = append(result, e.To)
+	}
+	return result
+}
+
+// OutDegree return the number of edges starting from v.
+// For undirected graph, it equals to InDegree.
+func (g *Graph[K, W]) OutDegree(v K) int {
+	return len(g.adjacent[v])
+}
+
+// InDegree return the number of edges ending at v.
+// For undirected graph, it equals to OutDegree.
+func (g *Graph[K, W]) InDegree(v K) int {
+	if !g.directed {
+		return g.OutDegree(v)
+	}
+
+	degree := 0
+	for _, edges := range g.adjacent {
+		for _, e := range edges {
+			if e.To == v {
+				degree++
+			}
+		}
+	}
+	return degree
+}
+
+// VertexCount return the number of vertices in the graph.
+func (g *Graph[K, W]) VertexCount() int {
+	return len(g.vertices)
+}
+
+// EdgeCount return the number of edges in the graph.
+func (g *Graph[K, W]) EdgeCount() int {
+	return len(g.Edges())
+}
+
+// BFS traverse the graph in breadth first order from start vertex, return the visited vertices.
+func (g *Graph[K, W]) BFS(start K) []K {
+	if !g.HasVertex(start) {
+		return []K{}
+	}
+
+	result := []K{}
+	visited := map[K]bool{start: true}
+	queue := []K{start}
+
+	for len(queue) > 0 {
+		v := queue[0]
+		queue = queue[1:]
+		result = append(result, v)
+
+		for _, e := range g.adjacent[v] {
+			if !visited[e.To] {
+				visited[e.To] = true
+				queue = append(queue, e.To)
+			}
+		}
+	}
+
+	return result
+}
+
+// DFS traverse the graph in depth first order from start vertex, return the visited vertices.
+func (g *Graph[K, W]) DFS(start K) []K {
+	if !g.HasVertex(start) {
+		return []K{}
+	}
+
+	result := []K{}
+	visited := map[K]bool{}
+
+	var visit func(v K)
+	visit = func(v K) {
+		visited[v] = true
+		result = append(result, v)
+		for _, e := range g.adjacent[v] {
+			if !visited[e.To] {
+				visit(e.To)
+			}
+		}
+	}
+	visit(start)
+
+	return result
+}
+
+// Dijkstra computes the shortest distance from source to every reachable vertex.
+// it returns the distances and the previous vertex of each vertex in the shortest path tree.
+// Edge weight should not be negative.
+func (g *Graph[K, W]) Dijkstra(source K) (map[K]W, map[K]K, error) {
+	if !g.HasVertex(source) {
+		return nil, nil, errors.New("source vertex not exist")
+	}
+
+	for _, e := range g.Edges() {
+		if e.Weight < 0 {
+			return nil, nil, errors.New("dijkstra does not support negative edge weight")
+		}
+	}
+
+	dist := map[K]W{source: 0}
+	prev := map[K]K{}
+	done := map[K]bool{}
+
+	pq := &priorityQueue[K, W]{}
+	heap.Push(pq, &pqItem[K, W]{vertex: source, priority: 0})
+
+	for pq.Len() > 0 {
+		item := heap.Pop(pq).(*pqItem[K, W])
+		v := item.vertex
+		if done[v] {
+			continue
+		}
+		done[v] = true
+
+		for _, e := range g.adjacent[v] {
+			alt := dist[v] + e.Weight
+			if d, ok := dist[e.To]; !ok || alt < d {
+				dist[e.To] = alt
+				prev[e.To] = v
+				heap.Push(pq, &pqItem[K, W]{vertex: e.To, priority: alt})
+			}
+		}
+	}
+
+	return dist, prev, nil
+}
+
+// ShortestPath return the shortest path and its total weight from source to target, using dijkstra algorithm.
+func (g *Graph[K, W]) ShortestPath(source, target K) ([]K, W, error) {
+	var zero W
+	if !g.HasVertex(target) {
+		return nil, zero, errors.New("target vertex not exist")
+	}
+
+	dist, prev, err := g.Dijkstra(source)
+	if err != nil {
+		return nil, zero, err
+	}
+
+	d, ok := dist[target]
+	if !ok {
+		return nil, zero, errors.New("target is unreachable from source")
+	}
+
+	return buildPath(prev, source, target), d, nil
+}
+
+// BellmanFord computes the shortest distance from source to every reachable vertex, negative weight is allowed.
+// it returns the distances and the previous vertex of each vertex in the shortest path tree.
+// if there is a negative cycle reachable from source, it returns error.
+func (g *Graph[K, W]) BellmanFord(source K) (map[K]W, map[K]K, error) {
+	if !g.HasVertex(source) {
+		return nil, nil, errors.New("source vertex not exist")
+	}
+
+	dist := map[K]W{source: 0}
+	prev := map[K]K{}
+	edges := g.allDirectedEdges()
+
+	for i := 0; i < len(g.vertices)-1; i++ {
+		changed := false
+		for _, e := range edges {
+			d, ok := dist[e.From]
+			if !ok {
+				continue
+			}
+			if old, ok := dist[e.To]; !ok || d+e.Weight < old {
+				dist[e.To] = d + e.Weight
+				prev[e.To] = e.From
+				changed = true
+			}
+		}
+		if !changed {
+			break
+		}
+	}
+
+	for _, e := range edges {
+		d, ok := dist[e.From]
+		if !ok {
+			continue
+		}
+		if d+e.Weight < dist[e.To] {
+			return nil, nil, errors.New("graph contains a negative weight cycle")
+		}
+	}
+
+	return dist, prev, nil
+}
+
+// AStar finds the shortest path from source to target with A* search algorithm.
+// param `heuristic` estimates the cost from a vertex to target, it should never overestimate the real cost.
+func (g *Graph[K, W]) AStar(source, target K, heuristic func(v K) W) ([]K, W, error) {
+	var zero W
+	if !g.HasVertex(source) {
+		return nil, zero, errors.New("source vertex not exist")
+	}
+	if !g.HasVertex(target) {
+		return nil, zero, errors.New("target vertex not exist")
+	}
+
+	gScore := map[K]W{source: 0}
+	prev := map[K]K{}
+	closed := map[K]bool{}
+
+	pq := &priorityQueue[K, W]{}
+	heap.Push(pq, &pqItem[K, W]{vertex: source, priority: heuristic(source)})
+
+	for pq.Len() > 0 {
+		v := heap.Pop(pq).(*pqItem[K, W]).vertex
+		if v == target {
+			return buildPath(prev, source, target), gScore[target], nil
+		}
+		if closed[v] {
+			continue
+		}
+		closed[v] = true
+
+		for _, e := range g.adjacent[v] {
+			if e.Weight < 0 {
+				return nil, zero, errors.New("a* does not support negative edge weight")
+			}
+			tentative := gScore[v] + e.Weight
+			if old, ok := gScore[e.To]; !ok || tentative < old {
+				gScore[e.To] = tentative
+				prev[e.To] = v
+				heap.Push(pq, &pqItem[K, W]{vertex: e.To, priority: tentative + heuristic(e.To)})
+			}
+		}
+	}
+
+	return nil, zero, errors.New("target is unreachable from source")
+}
+
+// TopologicalSort return a linear ordering of vertices of a directed acyclic graph,
+// for every edge from u to v, u comes before v in the ordering.
+// if the graph is undirected or contains a cycle, it returns error.
+func (g *Graph[K, W]) TopologicalSort() ([]K, error) {
+	if !g.directed {
+		return nil, errors.New("topological sort requires a directed graph")
+	}
+
+	inDegree := make(map[K]int, len(g.vertices))
+	for _, v := range g.vertices {
+		for _, e := range g.adjacent[v] {
+			inDegree[e.To]++
+		}
+	}
+
+	queue := []K{}
+	for _, v := range g.vertices {
+		if inDegree[v] == 0 {
+			queue = append(queue, v)
+		}
+	}
+
+	result := make([]K, 0, len(g.vertices))
+	for len(queue) > 0 {
+		v := queue[0]
+		queue = queue[1:]
+		result = append(result, v)
+
+		for _, e := range g.adjacent[v] {
+			inDegree[e.To]--
+			if inDegree[e.To] == 0 {
+				queue = append(queue, e.To)
+			}
+		}
+	}
+
+	if len(result) != len(g.vertices) {
+		return nil, errors.New("graph contains a cycle")
+	}
+
+	return result, nil
+}
+
+// HasCycle checks if the graph contains a cycle or not.
+// For undirected graph, an edge and its reverse edge is not regarded as a cycle.
+func (g *Graph[K, W]) HasCycle() bool {
+	if g.directed {
+		_, err := g.TopologicalSort()
+		return err != nil
+	}
+
+	uf := newUnionFind(g.vertices)
+	for _, e := range g.Edges() {
+		if !uf.union(e.From, e.To) {
+			return true
+		}
+	}
+	return false
+}
+
+// StronglyConnectedComponents return all strongly connected components of the graph with tarjan algorithm.
+// For undirected graph, it returns the connected components.
+func (g *Graph[K, W]) StronglyConnectedComponents() [][]K {
+	index := 0
+	indices := map[K]int{}
+	lowLink := map[K]int{}
+	onStack := map[K]bool{}
+	stack := []K{}
+	result := [][]K{}
+
+	var strongConnect func(v K)
+	strongConnect = func(v K) {
+		indices[v] = index
+		lowLink[v] = index
+		index++
+		stack = append(stack, v)
+		onStack[v] = true
+
+		for _, e := range g.adjacent[v] {
+			if _, ok := indices[e.To]; !ok {
+				strongConnect(e.To)
+				if lowLink[e.To] < lowLink[v] {
+					lowLink[v] = lowLink[e.To]
+				}
+			} else if onStack[e.To] && indices[e.To] < lowLink[v] {
+				lowLink[v] = indices[e.To]
+			}
+		}
+
+		if lowLink[v] == indices[v] {
+			component := []K{}
+			for {
+				w := stack[len(stack)-1]
+				stack = stack[:len(stack)-1]
+				onStack[w] = false
+				component = append(component, w)
+				if w == v {
+					break
+				}
+			}
+			g.sortByIndex(component)
+			result = append(result, component)
+		}
+	}
+
+	for _, v := range g.vertices {
+		if _, ok := indices[v]; !ok {
+			strongConnect(v)
+		}
+	}
+
+	return result
+}
+
+// Kruskal return the edges of minimum spanning tree and its total weight with kruskal algorithm.
+// if the graph is not connected, it returns the minimum spanning forest.
+// The graph should be undirected.
+func (g *Graph[K, W]) Kruskal() ([]Edge[K, W], W, error) {
+	var total W
+	if g.directed {
+		return nil, total, errors.New("minimum spanning tree requires an undirected graph")
+	}
+
+	edges := g.Edges()
+	sort.SliceStable(edges, func(i, j int) bool {
+		return edges[i].Weight < edges[j].Weight
+	})
+
+	uf := newUnionFind(g.vertices)
+	result := []Edge[K, W]{}
+	for _, e := range edges {
+		if uf.union(e.From, e.To) {
+			result = append(result, e)
+			total += e.Weight
+		}
+	}
+
+	return result, total, nil
+}
+
+// Prim return the edges of minimum spanning tree and its total weight with prim algorithm.
+// if the graph is not connected, it returns the minimum spanning forest.
+// The graph should be undirected.
+func (g *Graph[K, W]) Prim() ([]Edge[K, W], W, error) {
+	var total W
+	if g.directed {
+		return nil, total, errors.New("minimum spanning tree requires an undirected graph")
+	}
+
+	result := []Edge[K, W]{}
+	visited := map[K]bool{}
+
+	for _, root := range g.vertices {
+		if visited[root] {
+			continue
+		}
+		visited[root] = true
+
+		pq := &edgeQueue[K, W]{}
+		for _, e := range g.adjacent[root] {
+			heap.Push(pq, e)
+		}
+
+		for pq.Len() > 0 {
+			e := heap.Pop(pq).(Edge[K, W])
+			if visited[e.To] {
+				continue
+			}
+			visited[e.To] = true
+			result = append(result, e)
+			total += e.Weight
+
+			for _, next := range g.adjacent[e.To] {
+				if !visited[next.To] {
+					heap.Push(pq, next)
+				}
+			}
+		}
+	}
+
+	return result, total, nil
+}
+
+// DOT return the graph description in graphviz DOT language.
+func (g *Graph[K, W]) DOT(name string) string {
+	graphType, edgeOp := "graph", "--"
+	if g.directed {
+		graphType, edgeOp = "digraph", "->"
+	}
+
+	var sb strings.Builder
+	sb.WriteString(fmt.Sprintf("%s %q {\n", graphType, name))
+
+	for _, v := range g.vertices {
+		sb.WriteString(fmt.Sprintf("\t%q;\n", fmt.Sprint(v)))
+	}
+
+	for _, e := range g.Edges() {
+		sb.WriteString(fmt.Sprintf("\t%q %s %q", fmt.Sprint(e.From), edgeOp, fmt.Sprint(e.To)))
+		if g.weighted {
+			sb.WriteString(fmt.Sprintf(" [label=%q]", fmt.Sprint(e.Weight)))
+		}
+		sb.WriteString(";\n")
+	}
+
+	sb.WriteString("}\n")
+
+	return sb.String()
+}
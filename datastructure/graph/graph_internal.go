@@ -0,0 +1,147 @@
+package datastructure
+
+import "sort"
+
+// setEdge add or update the edge from `from` to `to`
+func (g *Graph[K, W]) setEdge(from, to K, weight W) {
+	edges := g.adjacent[from]
+	for i := range edges {
+		if edges[i].To == to {
+			edges[i].Weight = weight
+			return
+		}
+	}
+	g.adjacent[from] = append(edges, Edge[K, W]{From: from, To: to, Weight: weight})
+}
+
+// allDirectedEdges return edges of both directions for undirected graph
+func (g *Graph[K, W]) allDirectedEdges() []Edge[K, W] {
+	result := []Edge[K, W]{}
+	for _, v := range g.vertices {
+		result = append(result, g.adjacent[v]...)
+	}
+	return result
+}
+
+// sortByIndex sort vertices by their insertion order
+func (g *Graph[K, W]) sortByIndex(vertices []K) {
+	sort.Slice(vertices, func(i, j int) bool {
+		return g.index[vertices[i]] < g.index[vertices[j]]
+	})
+}
+
+func filterEdges[K comparable, W Number](edges []Edge[K, W], predicate func(e Edge[K, W]) bool) []Edge[K, W] {
+	result := make([]Edge[K, W], 0, len(edges))
+	for _, e := range edges {
+		if predicate(e) {
+			result = append(result, e)
+		}
+	}
+	return result
+}
+
+func buildPath[K comparable](prev map[K]K, source, target K) []K {
+	path := []K{target}
+	for v := target; v != source; {
+		v = prev[v]
+		path = append(path, v)
+	}
+
+	for i, j := 0, len(path)-1; i < j; i, j = i+1, j-1 {
+		path[i], path[j] = path[j], path[i]
+	}
+	return path
+}
+
+// pqItem is an item of priorityQueue
+type pqItem[K comparable, W Number] struct {
+	vertex   K
+	priority W
+}
+
+// priorityQueue is a min heap of vertices ordered by priority, implements heap.Interface
+type priorityQueue[K comparable, W Number] []*pqItem[K, W]
+
+func (pq priorityQueue[K, W]) Len() int { return len(pq) }
+
+func (pq priorityQueue[K, W]) Less(i, j int) bool { return pq[i].priority < pq[j].priority }
+
+func (pq priorityQueue[K, W]) Swap(i, j int) { pq[i], pq[j] = pq[j], pq[i] }
+
+func (pq *priorityQueue[K, W]) Push(x any) {
+	*pq = append(*pq, x.(*pqItem[K, W]))
+}
+
+func (pq *priorityQueue[K, W]) Pop() any {
+	old := *pq
+	n := len(old)
+	item := old[n-1]
+	old[n-1] = nil
+	*pq = old[:n-1]
+	return item
+}
+
+// edgeQueue is a min heap of edges ordered by weight, implements heap.Interface
+type edgeQueue[K comparable, W Number] []Edge[K, W]
+
+func (eq edgeQueue[K, W]) Len() int { return len(eq) }
+
+func (eq edgeQueue[K, W]) Less(i, j int) bool { return eq[i].Weight < eq[j].Weight }
+
+func (eq edgeQueue[K, W]) Swap(i, j int) { eq[i], eq[j] = eq[j], eq[i] }
+
+func (eq *edgeQueue[K, W]) Push(x any) {
+	*eq = append(*eq, x.(Edge[K, W]))
+}
+
+func (eq *edgeQueue[K, W]) Pop() any {
+	old := *eq
+	n := len(old)
+	item := old[n-1]
+	*eq = old[:n-1]
+	return item
+}
+
+// unionFind is a disjoint set used to detect cycle and build spanning tree
+type unionFind[K comparable] struct {
+	parent map[K]K
+	rank   map[K]int
+}
+
+func newUnionFind[K comparable](vertices []K) *unionFind[K] {
+	uf := &unionFind[K]{
+		parent: make(map[K]K, len(vertices)),
+		rank:   make(map[K]int, len(vertices)),
+	}
+	for _, v := range vertices {
+		uf.parent[v] = v
+	}
+	return uf
+}
+
+func (uf *unionFind[K]) find(v K) K {
+	for uf.parent[v] != v {
+		uf.parent[v] = uf.parent[uf.parent[v]]
+		v = uf.parent[v]
+	}
+	return v
+}
+
+// union merge the sets of a and b, return false if they are already in the same set
+func (uf *unionFind[K]) union(a, b K) bool {
+	rootA, rootB := uf.find(a), uf.find(b)
+	if rootA == rootB {
+		return false
+	}
+
+	switch {
+	case uf.rank[rootA] < uf.rank[rootB]:
+		uf.parent[rootA] = rootB
+	case uf.rank[rootA] > uf.rank[rootB]:
+		uf.parent[rootB] = rootA
+	default:
+		uf.parent[rootB] = rootA
+		uf.rank[rootA]++
+	}
+	return true
+}
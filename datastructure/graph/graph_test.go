@@ -0,0 +1,292 @@
+package datastructure
+
+import (
+	"testing"
+
+	"github.com/duke-git/lancet/v2/internal"
+)
+
+func TestGraph_AddAndRemove(t *testing.T) {
+	t.Parallel()
+
+	assert := internal.NewAssert(t, "TestGraph_AddAndRemove")
+
+	g := NewGraph[string, int](false, false)
+	g.AddEdge("a", "b")
+	g.AddEdge("b", "c")
+	g.AddVertex("d")
+
+	assert.Equal([]string{"a", "b", "c", "d"}, g.Vertices())
+	assert.Equal(2, g.EdgeCount())
+	assert.Equal(true, g.HasEdge("b", "a"))
+	assert.Equal([]string{"a", "c"}, g.Neighbors("b"))
+	assert.Equal(2, g.InDegree("b"))
+
+	w, ok := g.Weight("a", "b")
+	assert.Equal(1, w)
+	assert.Equal(true, ok)
+
+	assert.Equal(true, g.RemoveEdge("b", "a"))
+	assert.Equal(false, g.HasEdge("a", "b"))
+	assert.Equal(false, g.RemoveEdge("a", "b"))
+
+	assert.Equal(true, g.RemoveVertex("c"))
+	assert.Equal([]string{"a", "b", "d"}, g.Vertices())
+	assert.Equal(0, g.EdgeCount())
+	assert.Equal(false, g.RemoveVertex("c"))
+}
+
+func TestGraph_Directed(t *testing.T) {
+	t.Parallel()
+
+	assert := internal.NewAssert(t, "TestGraph_Directed")
+
+	g := NewGraph[int, float64](true, true)
+	g.AddWeightedEdge(1, 2, 1.5)
+	g.AddWeightedEdge(1, 3, 2)
+	g.AddWeightedEdge(3, 2, 0.5)
+	g.AddWeightedEdge(1, 2, 3)
+
+	assert.Equal(true, g.IsDirected())
+	assert.Equal(true, g.IsWeighted())
+	assert.Equal(false, g.HasEdge(2, 1))
+	assert.Equal(2, g.OutDegree(1))
+	assert.Equal(2, g.InDegree(2))
+
+	w, _ := g.Weight(1, 2)
+	assert.Equal(3.0, w)
+
+	expected := []Edge[int, float64]{
+		{From: 1, To: 2, Weight: 3},
+		{From: 1, To: 3, Weight: 2},
+		{From: 3, To: 2, Weight: 0.5},
+	}
+	assert.Equal(expected, g.Edges())
+}
+
+func TestGraph_BFSAndDFS(t *testing.T) {
+	t.Parallel()
+
+	assert := internal.NewAssert(t, "TestGraph_BFSAndDFS")
+
+	g := NewGraph[int, int](true, false)
+	g.AddEdge(1, 2)
+	g.AddEdge(1, 3)
+	g.AddEdge(2, 4)
+	g.AddEdge(3, 4)
+	g.AddEdge(4, 5)
+	g.AddVertex(6)
+
+	assert.Equal([]int{1, 2, 3, 4, 5}, g.BFS(1))
+	assert.Equal([]int{1, 2, 4, 5, 3}, g.DFS(1))
+	assert.Equal([]int{6}, g.BFS(6))
+	assert.Equal([]int{}, g.DFS(7))
+}
+
+func TestGraph_Dijkstra(t *testing.T) {
+	t.Parallel()
+
+	assert := internal.NewAssert(t, "TestGraph_Dijkstra")
+
+	g := NewGraph[string, int](true, true)
+	g.AddWeightedEdge("a", "b", 4)
+	g.AddWeightedEdge("a", "c", 1)
+	g.AddWeightedEdge("c", "b", 2)
+	g.AddWeightedEdge("b", "d", 1)
+	g.AddWeightedEdge("c", "d", 5)
+	g.AddVertex("e")
+
+	dist, _, err := g.Dijkstra("a")
+	assert.IsNil(err)
+	assert.Equal(map[string]int{"a": 0, "b": 3, "c": 1, "d": 4}, dist)
+
+	path, total, err := g.ShortestPath("a", "d")
+	assert.IsNil(err)
+	assert.Equal([]string{"a", "c", "b", "d"}, path)
+	assert.Equal(4, total)
+
+	_, _, err = g.ShortestPath("a", "e")
+	assert.IsNotNil(err)
+
+	_, _, err = g.Dijkstra("x")
+	assert.IsNotNil(err)
+
+	g.AddWeightedEdge("d", "e", -1)
+	_, _, err = g.Dijkstra("a")
+	assert.IsNotNil(err)
+}
+
+func TestGraph_BellmanFord(t *testing.T) {
+	t.Parallel()
+
+	assert := internal.NewAssert(t, "TestGraph_BellmanFord")
+
+	g := NewGraph[string, int](true, true)
+	g.AddWeightedEdge("a", "b", 4)
+	g.AddWeightedEdge("a", "c", 5)
+	g.AddWeightedEdge("c", "b", -3)
+	g.AddWeightedEdge("b", "d", 2)
+
+	dist, prev, err := g.BellmanFord("a")
+	assert.IsNil(err)
+	assert.Equal(map[string]int{"a": 0, "b": 2, "c": 5, "d": 4}, dist)
+	assert.Equal("c", prev["b"])
+
+	g.AddWeightedEdge("d", "c", -5)
+	_, _, err = g.BellmanFord("a")
+	assert.IsNotNil(err)
+}
+
+func TestGraph_AStar(t *testing.T) {
+	t.Parallel()
+
+	assert := internal.NewAssert(t, "TestGraph_AStar")
+
+	type point struct{ x, y int }
+
+	// 3x3 grid without the center cell
+	g := NewGraph[point, int](false, true)
+	for x := 0; x < 3; x++ {
+		for y := 0; y < 3; y++ {
+			if x == 1 && y == 1 {
+				continue
+			}
+			if x+1 < 3 && !(x+1 == 1 && y == 1) {
+				g.AddWeightedEdge(point{x, y}, point{x + 1, y}, 1)
+			}
+			if y+1 < 3 && !(x == 1 && y+1 == 1) {
+				g.AddWeightedEdge(point{x, y}, point{x, y + 1}, 1)
+			}
+		}
+	}
+
+	target := point{2, 2}
+	manhattan := func(p point) int {
+		return (target.x - p.x) + (target.y - p.y)
+	}
+
+	path, total, err := g.AStar(point{0, 0}, target, manhattan)
+	assert.IsNil(err)
+	assert.Equal(4, total)
+	assert.Equal(5, len(path))
+	assert.Equal(point{0, 0}, path[0])
+	assert.Equal(target, path[4])
+
+	g.AddVertex(point{5, 5})
+	_, _, err = g.AStar(point{0, 0}, point{5, 5}, func(p point) int { return 0 })
+	assert.IsNotNil(err)
+}
+
+func TestGraph_TopologicalSort(t *testing.T) {
+	t.Parallel()
+
+	assert := internal.NewAssert(t, "TestGraph_TopologicalSort")
+
+	g := NewGraph[string, int](true, false)
+	g.AddEdge("compile", "test")
+	g.AddEdge("fetch", "compile")
+	g.AddEdge("test", "deploy")
+	g.AddEdge("compile", "package")
+	g.AddEdge("package", "deploy")
+
+	order, err := g.TopologicalSort()
+	assert.IsNil(err)
+	assert.Equal([]string{"fetch", "compile", "test", "package", "deploy"}, order)
+	assert.Equal(false, g.HasCycle())
+
+	g.AddEdge("deploy", "fetch")
+	_, err = g.TopologicalSort()
+	assert.IsNotNil(err)
+	assert.Equal(true, g.HasCycle())
+
+	undirected := NewGraph[int, int](false, false)
+	undirected.AddEdge(1, 2)
+	undirected.AddEdge(2, 3)
+	_, err = undirected.TopologicalSort()
+	assert.IsNotNil(err)
+	assert.Equal(false, undirected.HasCycle())
+
+	undirected.AddEdge(3, 1)
+	assert.Equal(true, undirected.HasCycle())
+}
+
+func TestGraph_StronglyConnectedComponents(t *testing.T) {
+	t.Parallel()
+
+	assert := internal.NewAssert(t, "TestGraph_StronglyConnectedComponents")
+
+	g := NewGraph[int, int](true, false)
+	g.AddEdge(1, 2)
+	g.AddEdge(2, 3)
+	g.AddEdge(3, 1)
+	g.AddEdge(3, 4)
+	g.AddEdge(4, 5)
+	g.AddEdge(5, 4)
+	g.AddVertex(6)
+
+	expected := [][]int{{4, 5}, {1, 2, 3}, {6}}
+	assert.Equal(expected, g.StronglyConnectedComponents())
+}
+
+func TestGraph_MinimumSpanningTree(t *testing.T) {
+	t.Parallel()
+
+	assert := internal.NewAssert(t, "TestGraph_MinimumSpanningTree")
+
+	g := NewGraph[string, int](false, true)
+	g.AddWeightedEdge("a", "b", 7)
+	g.AddWeightedEdge("a", "d", 5)
+	g.AddWeightedEdge("b", "c", 8)
+	g.AddWeightedEdge("b", "d", 9)
+	g.AddWeightedEdge("b", "e", 7)
+	g.AddWeightedEdge("c", "e", 5)
+	g.AddWeightedEdge("d", "e", 15)
+	g.AddWeightedEdge("d", "f", 6)
+	g.AddWeightedEdge("e", "f", 8)
+	g.AddWeightedEdge("e", "g", 9)
+	g.AddWeightedEdge("f", "g", 11)
+
+	edges, total, err := g.Kruskal()
+	assert.IsNil(err)
+	assert.Equal(39, total)
+	assert.Equal(6, len(edges))
+
+	edges, total, err = g.Prim()
+	assert.IsNil(err)
+	assert.Equal(39, total)
+	assert.Equal(6, len(edges))
+
+	directed := NewGraph[int, int](true, true)
+	_, _, err = directed.Kruskal()
+	assert.IsNotNil(err)
+	_, _, err = directed.Prim()
+	assert.IsNotNil(err)
+}
+
+func TestGraph_DOT(t *testing.T) {
+	t.Parallel()
+
+	assert := internal.NewAssert(t, "TestGraph_DOT")
+
+	g := NewGraph[string, int](true, true)
+	g.AddWeightedEdge("a", "b", 3)
+	g.AddVertex("c")
+
+	expected := "digraph \"deps\" {\n" +
+		"\t\"a\";\n" +
+		"\t\"b\";\n" +
+		"\t\"c\";\n" +
+		"\t\"a\" -> \"b\" [label=\"3\"];\n" +
+		"}\n"
+	assert.Equal(expected, g.DOT("deps"))
+
+	undirected := NewGraph[int, int](false, false)
+	undirected.AddEdge(1, 2)
+
+	expected = "graph \"g\" {\n" +
+		"\t\"1\";\n" +
+		"\t\"2\";\n" +
+		"\t\"1\" -- \"2\";\n" +
+		"}\n"
+	assert.Equal(expected, undirected.DOT("g"))
+}
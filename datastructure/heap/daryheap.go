@@ -0,0 +1,284 @@
+// Copyright 2023 dudaodong@gmail.com. All rights reserved.
+// Use of this source code is governed by MIT license
+
+// Package datastructure implements some data structure. DaryHeap is a d-ary heap which also works as an indexed priority queue.
+package datastructure
+
+import (
+	"container/heap"
+
+	"github.com/duke-git/lancet/v2/constraints"
+)
+
+// Item is a handle of the value stored in DaryHeap, it could be used to update or remove the value.
+type Item[T any] struct {
+	value T
+	index int
+}
+
+// Value return the value of the item.
+func (item *Item[T]) Value() T {
+	return item.value
+}
+
+// DaryHeap implements a d-ary heap without fixed capacity.
+// The root is the smallest element ordered by comparator, pass a descending comparator to get a max heap.
+// Push returns an item handle, the priority of value could be changed in place with Update, or deleted with Remove.
+// type T should implements Compare function in constraints.Comparator interface.
+type DaryHeap[T any] struct {
+	items      []*Item[T]
+	arity      int
+	comparator constraints.Comparator
+}
+
+// NewDaryHeap returns a DaryHeap instance with the given arity and comparator.
+// if arity is less than 2, binary heap is used.
+func NewDaryHeap[T any](arity int, comparator constraints.Comparator) *DaryHeap[T] {
+	if arity < 2 {
+		arity = 2
+	}
+
+	return &DaryHeap[T]{
+		items:      make([]*Item[T], 0),
+		arity:      arity,
+		comparator: comparator,
+	}
+}
+
+// BuildDaryHeap builds a DaryHeap instance with data, arity and given comparator.
+func BuildDaryHeap[T any](data []T, arity int, comparator constraints.Comparator) *DaryHeap[T] {
+	h := NewDaryHeap[T](arity, comparator)
+	h.items = make([]*Item[T], len(data))
+	for i, v := range data {
+		h.items[i] = &Item[T]{value: v, index: i}
+	}
+
+	for i := h.parent(len(h.items) - 1); i >= 0; i-- {
+		h.down(i)
+	}
+
+	return h
+}
+
+// Push value into the heap, return the item handle of the value
+func (h *DaryHeap[T]) Push(value T) *Item[T] {
+	item := &Item[T]{value: value, index: len(h.items)}
+	h.items = append(h.items, item)
+	h.up(item.index)
+
+	return item
+}
+
+// Pop return the top value, and remove it from the heap
+// if heap is empty, return zero value and false
+func (h *DaryHeap[T]) Pop() (T, bool) {
+	if h.Size() == 0 {
+		var val T
+		return val, false
+	}
+
+	return h.removeAt(0), true
+}
+
+// Peek returns the top value from the heap without removing it.
+// if heap is empty, it returns zero value and false.
+func (h *DaryHeap[T]) Peek() (T, bool) {
+	if h.Size() == 0 {
+		var val T
+		return val, false
+	}
+
+	return h.items[0].value, true
+}
+
+// Update change the value of item and restore the heap order.
+// return false if the item does not belong to the heap.
+func (h *DaryHeap[T]) Update(item *Item[T], value T) bool {
+	if !h.Contain(item) {
+		return false
+	}
+
+	item.value = value
+	h.fix(item.index)
+
+	return true
+}
+
+// Fix restore the heap order after the value of item changed in place, eg. T is a pointer.
+// return false if the item does not belong to the heap.
+func (h *DaryHeap[T]) Fix(item *Item[T]) bool {
+	if !h.Contain(item) {
+		return false
+	}
+
+	h.fix(item.index)
+
+	return true
+}
+
+// Remove delete the item from the heap and return its value.
+// if the item does not belong to the heap, return zero value and false.
+func (h *DaryHeap[T]) Remove(item *Item[T]) (T, bool) {
+	if !h.Contain(item) {
+		var val T
+		return val, false
+	}
+
+	return h.removeAt(item.index), true
+}
+
+// Contain checks if the item is in the heap or not
+func (h *DaryHeap[T]) Contain(item *Item[T]) bool {
+	return item != nil && item.index >= 0 && item.index < len(h.items) && h.items[item.index] == item
+}
+
+// Size return the number of elements in the heap
+func (h *DaryHeap[T]) Size() int {
+	return len(h.items)
+}
+
+// IsEmpty checks if the heap is empty or not
+func (h *DaryHeap[T]) IsEmpty() bool {
+	return len(h.items) == 0
+}
+
+// Clear remove all elements of the heap
+func (h *DaryHeap[T]) Clear() {
+	for _, item := range h.items {
+		item.index = -1
+	}
+	h.items = make([]*Item[T], 0)
+}
+
+// Data return data of the heap in layout order
+func (h *DaryHeap[T]) Data() []T {
+	data := make([]T, len(h.items))
+	for i, item := range h.items {
+		data[i] = item.value
+	}
+	return data
+}
+
+// Interface returns a view of the heap which implements heap.Interface, so the functions of container/heap could be used.
+// The Push and Pop of the view accept and return *Item[T].
+// container/heap only supports binary layout, so it panics if the arity of heap is not 2.
+func (h *DaryHeap[T]) Interface() heap.Interface {
+	if h.arity != 2 {
+		panic("heap.Interface only supports binary heap")
+	}
+	return &heapInterface[T]{h}
+}
+
+// heapInterface implements heap.Interface on top of DaryHeap
+type heapInterface[T any] struct {
+	h *DaryHeap[T]
+}
+
+func (hi *heapInterface[T]) Len() int {
+	return len(hi.h.items)
+}
+
+func (hi *heapInterface[T]) Less(i, j int) bool {
+	return hi.h.less(i, j)
+}
+
+func (hi *heapInterface[T]) Swap(i, j int) {
+	hi.h.swap(i, j)
+}
+
+func (hi *heapInterface[T]) Push(x any) {
+	item := x.(*Item[T])
+	item.index = len(hi.h.items)
+	hi.h.items = append(hi.h.items, item)
+}
+
+func (hi *heapInterface[T]) Pop() any {
+	n := len(hi.h.items) - 1
+	item := hi.h.items[n]
+	hi.h.items[n] = nil
+	hi.h.items = hi.h.items[:n]
+	item.index = -1
+	return item
+}
+
+// removeAt remove the item at index i, and return its value
+func (h *DaryHeap[T]) removeAt(i int) T {
+	item := h.items[i]
+	last := len(h.items) - 1
+
+	if i != last {
+		h.swap(i, last)
+	}
+	h.items[last] = nil
+	h.items = h.items[:last]
+	if i != last {
+		h.fix(i)
+	}
+
+	item.index = -1
+	return item.value
+}
+
+// fix move the item at index i up or down to restore the heap order
+func (h *DaryHeap[T]) fix(i int) {
+	if !h.down(i) {
+		h.up(i)
+	}
+}
+
+// up move the item at index i to top until its parent is not larger than it
+func (h *DaryHeap[T]) up(i int) {
+	for i > 0 {
+		p := h.parent(i)
+		if !h.less(i, p) {
+			break
+		}
+		h.swap(i, p)
+		i = p
+	}
+}
+
+// down move the item at index i to bottom until it is not larger than its children.
+// return true if the item is moved.
+func (h *DaryHeap[T]) down(i int) bool {
+	start := i
+	n := len(h.items)
+
+	for {
+		first := h.arity*i + 1
+		if first >= n || first < 0 {
+			break
+		}
+
+		smallest := first
+		for c := first + 1; c < first+h.arity && c < n; c++ {
+			if h.less(c, smallest) {
+				smallest = c
+			}
+		}
+
+		if !h.less(smallest, i) {
+			break
+		}
+		h.swap(i, smallest)
+		i = smallest
+	}
+
+	return i > start
+}
+
+// parent get parent index of the given index
+func (h *DaryHeap[T]) parent(i int) int {
+	return (i - 1) / h.arity
+}
+
+func (h *DaryHeap[T]) less(i, j int) bool {
+	return h.comparator.Compare(h.items[i].value, h.items[j].value) < 0
+}
+
+// swap two items in the heap and update their index
+func (h *DaryHeap[T]) swap(i, j int) {
+	h.items[i], h.items[j] = h.items[j], h.items[i]
+	h.items[i].index = i
+	h.items[j].index = j
+}
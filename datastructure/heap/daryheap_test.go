@@ -0,0 +1,162 @@
+package datastructure
+
+import (
+	"container/heap"
+	"testing"
+
+	"github.com/duke-git/lancet/v2/internal"
+)
+
+type descIntComparator struct{}
+
+func (c *descIntComparator) Compare(v1, v2 any) int {
+	return -(&intComparator{}).Compare(v1, v2)
+}
+
+func popAll[T any](h *DaryHeap[T]) []T {
+	result := []T{}
+	for !h.IsEmpty() {
+		val, _ := h.Pop()
+		result = append(result, val)
+	}
+	return result
+}
+
+func TestDaryHeap_PushAndPop(t *testing.T) {
+	t.Parallel()
+
+	assert := internal.NewAssert(t, "TestDaryHeap_PushAndPop")
+
+	values := []int{6, 5, 2, 4, 7, 10, 12, 1, 3, 8, 9, 11}
+	expected := []int{1, 2, 3, 4, 5, 6, 7, 8, 9, 10, 11, 12}
+
+	for _, arity := range []int{0, 2, 3, 4, 8} {
+		h := NewDaryHeap[int](arity, &intComparator{})
+		_, ok := h.Pop()
+		assert.Equal(false, ok)
+
+		for _, v := range values {
+			h.Push(v)
+		}
+		assert.Equal(12, h.Size())
+
+		top, ok := h.Peek()
+		assert.Equal(1, top)
+		assert.Equal(true, ok)
+
+		assert.Equal(expected, popAll(h))
+	}
+
+	maxHeap := BuildDaryHeap(values, 3, &descIntComparator{})
+	assert.Equal([]int{12, 11, 10, 9, 8, 7, 6, 5, 4, 3, 2, 1}, popAll(maxHeap))
+
+	empty := BuildDaryHeap([]int{}, 3, &intComparator{})
+	assert.Equal(true, empty.IsEmpty())
+}
+
+func TestDaryHeap_Update(t *testing.T) {
+	t.Parallel()
+
+	assert := internal.NewAssert(t, "TestDaryHeap_Update")
+
+	h := NewDaryHeap[int](4, &intComparator{})
+	items := map[int]*Item[int]{}
+	for _, v := range []int{5, 8, 3, 9, 7} {
+		items[v] = h.Push(v)
+	}
+
+	// decrease key
+	assert.Equal(true, h.Update(items[9], 1))
+	top, _ := h.Peek()
+	assert.Equal(1, top)
+	assert.Equal(1, items[9].Value())
+
+	// increase key
+	assert.Equal(true, h.Update(items[3], 10))
+
+	assert.Equal([]int{1, 5, 7, 8, 10}, popAll(h))
+	assert.Equal(false, h.Update(items[5], 2))
+}
+
+func TestDaryHeap_Remove(t *testing.T) {
+	t.Parallel()
+
+	assert := internal.NewAssert(t, "TestDaryHeap_Remove")
+
+	h := NewDaryHeap[int](2, &intComparator{})
+	items := []*Item[int]{}
+	for _, v := range []int{5, 8, 3, 9, 7, 1} {
+		items = append(items, h.Push(v))
+	}
+
+	val, ok := h.Remove(items[2])
+	assert.Equal(3, val)
+	assert.Equal(true, ok)
+	assert.Equal(false, h.Contain(items[2]))
+
+	_, ok = h.Remove(items[2])
+	assert.Equal(false, ok)
+
+	other := NewDaryHeap[int](2, &intComparator{})
+	_, ok = other.Remove(items[0])
+	assert.Equal(false, ok)
+
+	assert.Equal([]int{1, 5, 7, 8, 9}, popAll(h))
+}
+
+func TestDaryHeap_Fix(t *testing.T) {
+	t.Parallel()
+
+	assert := internal.NewAssert(t, "TestDaryHeap_Fix")
+
+	h := NewDaryHeap[*task](3, &taskComparator{})
+	t1 := h.Push(&task{3})
+	h.Push(&task{2})
+	h.Push(&task{5})
+
+	t1.Value().priority = 1
+	assert.Equal(true, h.Fix(t1))
+
+	top, _ := h.Peek()
+	assert.Equal(1, top.priority)
+
+	h.Clear()
+	assert.Equal(0, h.Size())
+	assert.Equal(false, h.Fix(t1))
+}
+
+type task struct {
+	priority int
+}
+
+type taskComparator struct{}
+
+func (c *taskComparator) Compare(v1, v2 any) int {
+	return (&intComparator{}).Compare(v1.(*task).priority, v2.(*task).priority)
+}
+
+func TestDaryHeap_Interface(t *testing.T) {
+	t.Parallel()
+
+	assert := internal.NewAssert(t, "TestDaryHeap_Interface")
+
+	h := NewDaryHeap[int](2, &intComparator{})
+	hi := h.Interface()
+
+	for _, v := range []int{4, 1, 3, 2} {
+		heap.Push(hi, &Item[int]{value: v})
+	}
+	assert.Equal(4, h.Size())
+
+	item := heap.Pop(hi).(*Item[int])
+	assert.Equal(1, item.Value())
+
+	h.Push(0)
+	assert.Equal(0, heap.Pop(hi).(*Item[int]).Value())
+	assert.Equal([]int{2, 3, 4}, popAll(h))
+
+	defer func() {
+		assert.IsNotNil(recover())
+	}()
+	NewDaryHeap[int](3, &intComparator{}).Interface()
+}
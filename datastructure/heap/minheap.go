@@ -0,0 +1,188 @@
+// Copyright 2023 dudaodong@gmail.com. All rights reserved.
+// Use of this source code is governed by MIT license
+
+// Package datastructure implements some data structure. MinHeap is a binary min heap.
+package datastructure
+
+import (
+	"fmt"
+
+	"github.com/duke-git/lancet/v2/constraints"
+)
+
+// MinHeap implements a binary min heap
+// type T should implements Compare function in constraints.Comparator interface.
+type MinHeap[T any] struct {
+	data       []T
+	comparator constraints.Comparator
+}
+
+// NewMinHeap returns a MinHeap instance with the given comparator.
+func NewMinHeap[T any](comparator constraints.Comparator) *MinHeap[T] {
+	return &MinHeap[T]{
+		data:       make([]T, 0),
+		comparator: comparator,
+	}
+}
+
+// BuildMinHeap builds a MinHeap instance with data and given comparator.
+func BuildMinHeap[T any](data []T, comparator constraints.Comparator) *MinHeap[T] {
+	heap := &MinHeap[T]{
+		data:       make([]T, 0, len(data)),
+		comparator: comparator,
+	}
+
+	for _, v := range data {
+		heap.Push(v)
+	}
+
+	return heap
+}
+
+// Push value into the heap
+func (h *MinHeap[T]) Push(value T) {
+	h.data = append(h.data, value)
+	h.heapifyUp(len(h.data) - 1)
+}
+
+// heapifyUp heapify the data from bottom to top
+func (h *MinHeap[T]) heapifyUp(i int) {
+	for h.comparator.Compare(h.data[parentIndex(i)], h.data[i]) > 0 {
+		h.swap(parentIndex(i), i)
+		i = parentIndex(i)
+	}
+}
+
+// Pop return the smallest value, and remove it from the heap
+// if heap is empty, return zero value and false
+func (h *MinHeap[T]) Pop() (T, bool) {
+	var val T
+	if h.Size() == 0 {
+		return val, false
+	}
+
+	val = h.data[0]
+	l := len(h.data) - 1
+
+	h.data[0] = h.data[l]
+	h.data = h.data[:l]
+	h.heapifyDown(0)
+
+	return val, true
+}
+
+// heapifyDown heapify the data from top to bottom
+func (h *MinHeap[T]) heapifyDown(i int) {
+	lastIndex := len(h.data) - 1
+	l, r := leftChildIndex(i), rightChildIndex(i)
+	childToCompare := 0
+
+	for l <= lastIndex {
+		if l == lastIndex {
+			childToCompare = l
+		} else if h.comparator.Compare(h.data[l], h.data[r]) < 0 {
+			childToCompare = l
+		} else {
+			childToCompare = r
+		}
+
+		if h.comparator.Compare(h.data[i], h.data[childToCompare]) > 0 {
+			h.swap(i, childToCompare)
+			i = childToCompare
+			l, r = leftChildIndex(i), rightChildIndex(i)
+		} else {
+			break
+		}
+	}
+}
+
+// Peek returns the smallest element from the heap without removing it.
+// if heap is empty, it returns zero value and false.
+func (h *MinHeap[T]) Peek() (T, bool) {
+	if h.Size() == 0 {
+		var val T
+		return val, false
+	}
+
+	return h.data[0], true
+}
+
+// Size return the number of elements in the heap
+func (h *MinHeap[T]) Size() int {
+	return len(h.data)
+}
+
+// Data return data of the heap
+func (h *MinHeap[T]) Data() []T {
+	return h.data
+}
+
+// PrintStructure print the structure of the heap
+func (h *MinHeap[T]) PrintStructure() {
+	printStructure(h.data)
+}
+
+// swap two elements in the heap
+func (h *MinHeap[T]) swap(i, j int) {
+	h.data[i], h.data[j] = h.data[j], h.data[i]
+}
+
+// printStructure print the structure of heap data
+func printStructure[T any](data []T) {
+	level := 1
+	length := len(data)
+	index := 0
+
+	list := [][]string{}
+	temp := []string{}
+	for index < length {
+		start := powerTwo(level-1) - 1
+		end := start + powerTwo(level-1) - 1
+
+		temp = append(temp, fmt.Sprintf("%v", data[index]))
+		index++
+
+		if index > end || index >= length {
+			list = append(list, temp)
+			temp = []string{}
+
+			if index < length {
+				level++
+			}
+		}
+	}
+
+	lastNum := powerTwo(level - 1)
+	lastLen := lastNum + (lastNum - 1)
+
+	heapTree := make([][]string, level)
+	for i := 0; i < level; i++ {
+		heapTree[i] = make([]string, lastLen)
+		for j := 0; j < lastLen; j++ {
+			heapTree[i][j] = ""
+		}
+	}
+
+	for k := 0; k < len(list); k++ {
+		vals := list[k]
+		tempLevel := level - k
+		st := powerTwo(tempLevel-1) - 1
+		for _, v := range vals {
+			heapTree[k][st] = v
+			gap := powerTwo(tempLevel)
+			st = st + gap
+		}
+	}
+
+	for m := 0; m < level; m++ {
+		for n := 0; n < lastLen; n++ {
+			val := heapTree[m][n]
+			if val == "" {
+				fmt.Print(" ")
+			} else {
+				fmt.Print(val)
+			}
+		}
+		fmt.Println()
+	}
+}
@@ -0,0 +1,69 @@
+package datastructure
+
+import (
+	"testing"
+
+	"github.com/duke-git/lancet/v2/internal"
+)
+
+func TestMinHeap_BuildMinHeap(t *testing.T) {
+	t.Parallel()
+
+	assert := internal.NewAssert(t, "TestMinHeap_BuildMinHeap")
+
+	values := []int{6, 5, 2, 4, 7, 10, 12, 1, 3, 8, 9, 11}
+	heap := BuildMinHeap(values, &intComparator{})
+
+	expected := []int{1, 2, 5, 3, 7, 10, 12, 6, 4, 8, 9, 11}
+	assert.Equal(expected, heap.data)
+
+	assert.Equal(12, heap.Size())
+
+	heap.PrintStructure()
+}
+
+func TestMinHeap_Pop(t *testing.T) {
+	t.Parallel()
+
+	assert := internal.NewAssert(t, "TestMinHeap_Pop")
+
+	heap := NewMinHeap[int](&intComparator{})
+
+	_, ok := heap.Pop()
+	assert.Equal(false, ok)
+
+	values := []int{6, 5, 2, 4, 7, 10, 12, 1, 3, 8, 9, 11}
+	for _, v := range values {
+		heap.Push(v)
+	}
+
+	result := []int{}
+	for heap.Size() > 0 {
+		val, _ := heap.Pop()
+		result = append(result, val)
+	}
+
+	assert.Equal([]int{1, 2, 3, 4, 5, 6, 7, 8, 9, 10, 11, 12}, result)
+}
+
+func TestMinHeap_Peek(t *testing.T) {
+	t.Parallel()
+
+	assert := internal.NewAssert(t, "TestMinHeap_Peek")
+
+	heap := NewMinHeap[int](&intComparator{})
+
+	_, ok := heap.Peek()
+	assert.Equal(false, ok)
+
+	values := []int{6, 5, 2, 4, 7, 10, 12, 1, 3, 8, 9, 11}
+	for _, v := range values {
+		heap.Push(v)
+	}
+
+	val, ok := heap.Peek()
+	assert.Equal(1, val)
+	assert.Equal(true, ok)
+
+	assert.Equal(12, heap.Size())
+}
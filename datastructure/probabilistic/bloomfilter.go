@@ -0,0 +1,158 @@
+// Copyright 2023 dudaodong@gmail.com. All rights reserved.
+// Use of this source code is governed by MIT license
+
+// Package datastructure contains some data structure.
+// Probabilistic structure contains BloomFilter, ScalableBloomFilter, CountMinSketch and HyperLogLog.
+package datastructure
+
+import (
+	"bytes"
+	"encoding/binary"
+	"errors"
+	"math"
+)
+
+// BloomFilter is a space-efficient probabilistic data structure to test whether an element is a member of a set.
+// False positive matches are possible, but false negatives are not.
+type BloomFilter struct {
+	bits      []uint64
+	m         uint64
+	k         uint64
+	count     uint64
+	itemLimit uint64
+}
+
+// NewBloomFilter return a BloomFilter pointer sized from the expected number of items and the false positive rate.
+func NewBloomFilter(expectedItems uint64, falsePositiveRate float64) *BloomFilter {
+	m, k := optimalBloomFilterSize(expectedItems, falsePositiveRate)
+	bf := NewBloomFilterWithSize(m, k)
+	bf.itemLimit = expectedItems
+
+	return bf
+}
+
+// NewBloomFilterWithSize return a BloomFilter pointer with m bits and k hash functions.
+func NewBloomFilterWithSize(m, k uint64) *BloomFilter {
+	if m == 0 {
+		m = 1
+	}
+	if k == 0 {
+		k = 1
+	}
+
+	return &BloomFilter{
+		bits: make([]uint64, (m+63)/64),
+		m:    m,
+		k:    k,
+	}
+}
+
+// Add data into the filter
+func (bf *BloomFilter) Add(data []byte) {
+	h1, h2 := baseHashes(data)
+	for i := uint64(0); i < bf.k; i++ {
+		pos := bitLocation(h1, h2, i, bf.m)
+		bf.bits[pos/64] |= 1 << (pos % 64)
+	}
+	bf.count++
+}
+
+// AddString add string into the filter
+func (bf *BloomFilter) AddString(s string) {
+	bf.Add([]byte(s))
+}
+
+// Contain checks if data may be in the filter, false means data is definitely not in the filter.
+func (bf *BloomFilter) Contain(data []byte) bool {
+	h1, h2 := baseHashes(data)
+	for i := uint64(0); i < bf.k; i++ {
+		pos := bitLocation(h1, h2, i, bf.m)
+		if bf.bits[pos/64]&(1<<(pos%64)) == 0 {
+			return false
+		}
+	}
+	return true
+}
+
+// ContainString checks if string may be in the filter
+func (bf *BloomFilter) ContainString(s string) bool {
+	return bf.Contain([]byte(s))
+}
+
+// Count return the number of added items, duplicated items are counted repeatedly.
+func (bf *BloomFilter) Count() uint64 {
+	return bf.count
+}
+
+// Cap return the number of bits of the filter
+func (bf *BloomFilter) Cap() uint64 {
+	return bf.m
+}
+
+// HashCount return the number of hash functions of the filter
+func (bf *BloomFilter) HashCount() uint64 {
+	return bf.k
+}
+
+// FalsePositiveRate return the estimated false positive rate with current number of items.
+func (bf *BloomFilter) FalsePositiveRate() float64 {
+	return math.Pow(1-math.Exp(-float64(bf.k)*float64(bf.count)/float64(bf.m)), float64(bf.k))
+}
+
+// Merge other filter into the filter, the two filters should have the same size and hash count.
+func (bf *BloomFilter) Merge(other *BloomFilter) error {
+	if bf.m != other.m || bf.k != other.k {
+		return errors.New("bloom filters with different size or hash count can not be merged")
+	}
+
+	for i := range bf.bits {
+		bf.bits[i] |= other.bits[i]
+	}
+	bf.count += other.count
+
+	return nil
+}
+
+// Clear remove all items of the filter
+func (bf *BloomFilter) Clear() {
+	for i := range bf.bits {
+		bf.bits[i] = 0
+	}
+	bf.count = 0
+}
+
+// MarshalBinary implements the encoding.BinaryMarshaler interface.
+func (bf *BloomFilter) MarshalBinary() ([]byte, error) {
+	var buf bytes.Buffer
+	buf.WriteByte(bloomFilterMagic)
+
+	err := binary.Write(&buf, binary.BigEndian, []uint64{bf.m, bf.k, bf.count, bf.itemLimit})
+	if err != nil {
+		return nil, err
+	}
+	if err = binary.Write(&buf, binary.BigEndian, bf.bits); err != nil {
+		return nil, err
+	}
+
+	return buf.Bytes(), nil
+}
+
+// UnmarshalBinary implements the encoding.BinaryUnmarshaler interface.
+func (bf *BloomFilter) UnmarshalBinary(data []byte) error {
+	return bf.readFrom(bytes.NewReader(data))
+}
+
+// optimalBloomFilterSize calculate number of bits and hash functions
+func optimalBloomFilterSize(n uint64, p float64) (uint64, uint64) {
+	if n == 0 {
+		n = 1
+	}
+	if p <= 0 || p >= 1 {
+		p = 0.01
+	}
+
+	m := math.Ceil(-float64(n) * math.Log(p) / (math.Ln2 * math.Ln2))
+	k := math.Round(m / float64(n) * math.Ln2)
+
+	return uint64(m), uint64(math.Max(k, 1))
+}
@@ -0,0 +1,211 @@
+package datastructure
+
+import (
+	"encoding/binary"
+	"fmt"
+	"math"
+	"testing"
+
+	"github.com/duke-git/lancet/v2/internal"
+)
+
+func TestBloomFilter(t *testing.T) {
+	t.Parallel()
+
+	assert := internal.NewAssert(t, "TestBloomFilter")
+
+	bf := NewBloomFilter(1000, 0.01)
+	assert.Equal(uint64(9586), bf.Cap())
+	assert.Equal(uint64(7), bf.HashCount())
+
+	for i := 0; i < 1000; i++ {
+		bf.AddString(fmt.Sprintf("item-%d", i))
+	}
+	assert.Equal(uint64(1000), bf.Count())
+
+	for i := 0; i < 1000; i++ {
+		assert.Equal(true, bf.ContainString(fmt.Sprintf("item-%d", i)))
+	}
+
+	falsePositive := 0
+	for i := 0; i < 10000; i++ {
+		if bf.Contain([]byte(fmt.Sprintf("other-%d", i))) {
+			falsePositive++
+		}
+	}
+	assert.Less(falsePositive, 200)
+	assert.Less(bf.FalsePositiveRate(), 0.02)
+
+	bf.Clear()
+	assert.Equal(false, bf.ContainString("item-1"))
+	assert.Equal(uint64(0), bf.Count())
+}
+
+func TestBloomFilter_Merge(t *testing.T) {
+	t.Parallel()
+
+	assert := internal.NewAssert(t, "TestBloomFilter_Merge")
+
+	bf1 := NewBloomFilter(100, 0.01)
+	bf2 := NewBloomFilter(100, 0.01)
+	bf1.AddString("a")
+	bf2.AddString("b")
+
+	assert.IsNil(bf1.Merge(bf2))
+	assert.Equal(true, bf1.ContainString("a"))
+	assert.Equal(true, bf1.ContainString("b"))
+	assert.Equal(uint64(2), bf1.Count())
+
+	bf3 := NewBloomFilter(1000, 0.01)
+	assert.IsNotNil(bf1.Merge(bf3))
+}
+
+func TestBloomFilter_Binary(t *testing.T) {
+	t.Parallel()
+
+	assert := internal.NewAssert(t, "TestBloomFilter_Binary")
+
+	bf := NewBloomFilterWithSize(100, 3)
+	bf.AddString("hello")
+	bf.AddString("world")
+
+	data, err := bf.MarshalBinary()
+	assert.IsNil(err)
+
+	decoded := &BloomFilter{}
+	assert.IsNil(decoded.UnmarshalBinary(data))
+	assert.Equal(bf, decoded)
+	assert.Equal(true, decoded.ContainString("hello"))
+
+	assert.IsNotNil(decoded.UnmarshalBinary(data[:10]))
+	assert.IsNotNil(decoded.UnmarshalBinary([]byte{0x00}))
+}
+
+func TestScalableBloomFilter(t *testing.T) {
+	t.Parallel()
+
+	assert := internal.NewAssert(t, "TestScalableBloomFilter")
+
+	sbf := NewScalableBloomFilter(100, 0.01)
+	for i := 0; i < 2000; i++ {
+		sbf.AddString(fmt.Sprintf("item-%d", i))
+	}
+	assert.Greater(sbf.FilterCount(), 1)
+	assert.LessOrEqual(sbf.Count(), uint64(2000))
+
+	for i := 0; i < 2000; i++ {
+		assert.Equal(true, sbf.ContainString(fmt.Sprintf("item-%d", i)))
+	}
+
+	falsePositive := 0
+	for i := 0; i < 10000; i++ {
+		if sbf.ContainString(fmt.Sprintf("other-%d", i)) {
+			falsePositive++
+		}
+	}
+	assert.Less(falsePositive, 200)
+	assert.Less(sbf.FalsePositiveRate(), 0.02)
+
+	sbf.Clear()
+	assert.Equal(0, sbf.FilterCount())
+	assert.Equal(false, sbf.ContainString("item-1"))
+}
+
+func TestScalableBloomFilter_MergeAndBinary(t *testing.T) {
+	t.Parallel()
+
+	assert := internal.NewAssert(t, "TestScalableBloomFilter_MergeAndBinary")
+
+	sbf1 := NewScalableBloomFilter(10, 0.01)
+	sbf2 := NewScalableBloomFilter(10, 0.01)
+	for i := 0; i < 50; i++ {
+		sbf1.AddString(fmt.Sprintf("a-%d", i))
+		sbf2.AddString(fmt.Sprintf("b-%d", i))
+	}
+
+	assert.IsNil(sbf1.Merge(sbf2))
+	assert.Equal(true, sbf1.ContainString("a-1"))
+	assert.Equal(true, sbf1.ContainString("b-1"))
+	assert.IsNotNil(sbf1.Merge(NewScalableBloomFilter(20, 0.01)))
+
+	data, err := sbf1.MarshalBinary()
+	assert.IsNil(err)
+
+	decoded := &ScalableBloomFilter{}
+	assert.IsNil(decoded.UnmarshalBinary(data))
+	assert.Equal(sbf1, decoded)
+
+	assert.IsNotNil(decoded.UnmarshalBinary(data[:len(data)-1]))
+}
+
+// encodeHeader encodes magic byte and header words like MarshalBinary
+func encodeHeader(magic byte, words ...uint64) []byte {
+	data := make([]byte, 1+8*len(words))
+	data[0] = magic
+	for i, w := range words {
+		binary.BigEndian.PutUint64(data[1+8*i:], w)
+	}
+	return data
+}
+
+func TestBloomFilter_UnmarshalCorrupted(t *testing.T) {
+	t.Parallel()
+
+	assert := internal.NewAssert(t, "TestBloomFilter_UnmarshalCorrupted")
+
+	cases := [][]byte{
+		// (m+63)/64 overflows to 0 word
+		encodeHeader(bloomFilterMagic, math.MaxUint64, 3, 0, 0),
+		// words*8 overflows
+		encodeHeader(bloomFilterMagic, 1<<63, 3, 0, 0, 0),
+		// k is larger than m
+		encodeHeader(bloomFilterMagic, 64, math.MaxUint64, 0, 0, 0),
+		encodeHeader(bloomFilterMagic, 0, 3, 0, 0),
+	}
+	for _, data := range cases {
+		bf := &BloomFilter{}
+		assert.IsNotNil(bf.UnmarshalBinary(data))
+	}
+
+	sbfCases := [][]byte{
+		// the number of filters is larger than the data
+		encodeHeader(scalableBloomFilterMagic, 10, math.Float64bits(0.01), 2, math.Float64bits(0.8), math.MaxUint64),
+		append(encodeHeader(scalableBloomFilterMagic, 10, math.Float64bits(0.01), 2, math.Float64bits(0.8), 2),
+			encodeHeader(bloomFilterMagic, math.MaxUint64, 3, 0, 0)...),
+	}
+	for _, data := range sbfCases {
+		sbf := &ScalableBloomFilter{}
+		assert.IsNotNil(sbf.UnmarshalBinary(data))
+	}
+}
+
+func FuzzBloomFilter_UnmarshalBinary(f *testing.F) {
+	bf := NewBloomFilter(10, 0.01)
+	bf.AddString("a")
+	data, _ := bf.MarshalBinary()
+	f.Add(data)
+	f.Add(encodeHeader(bloomFilterMagic, math.MaxUint64, 3, 0, 0))
+
+	f.Fuzz(func(t *testing.T, data []byte) {
+		decoded := &BloomFilter{}
+		if decoded.UnmarshalBinary(data) == nil {
+			decoded.AddString("b")
+			decoded.ContainString("a")
+		}
+	})
+}
+
+func FuzzScalableBloomFilter_UnmarshalBinary(f *testing.F) {
+	sbf := NewScalableBloomFilter(10, 0.01)
+	sbf.AddString("a")
+	data, _ := sbf.MarshalBinary()
+	f.Add(data)
+	f.Add(encodeHeader(scalableBloomFilterMagic, 10, math.Float64bits(0.01), 2, math.Float64bits(0.8), math.MaxUint64))
+
+	f.Fuzz(func(t *testing.T, data []byte) {
+		decoded := &ScalableBloomFilter{}
+		if decoded.UnmarshalBinary(data) == nil {
+			decoded.ContainString("a")
+		}
+	})
+}
@@ -0,0 +1,183 @@
+// Copyright 2023 dudaodong@gmail.com. All rights reserved.
+// Use of this source code is governed by MIT license
+
+// Package datastructure contains some data structure.
+// Probabilistic structure contains BloomFilter, ScalableBloomFilter, CountMinSketch and HyperLogLog.
+package datastructure
+
+import (
+	"bytes"
+	"encoding/binary"
+	"errors"
+	"math"
+)
+
+// CountMinSketch is a probabilistic data structure to estimate the frequency of items in a stream.
+// The estimated count is never less than the real count.
+type CountMinSketch struct {
+	width  uint64
+	depth  uint64
+	total  uint64
+	counts [][]uint64
+}
+
+// NewCountMinSketch return a CountMinSketch pointer.
+// The estimated count exceeds the real count by at most epsilon * total count, with probability 1 - delta.
+func NewCountMinSketch(epsilon, delta float64) *CountMinSketch {
+	if epsilon <= 0 || epsilon >= 1 {
+		epsilon = 0.001
+	}
+	if delta <= 0 || delta >= 1 {
+		delta = 0.01
+	}
+
+	width := uint64(math.Ceil(math.E / epsilon))
+	depth := uint64(math.Ceil(math.Log(1 / delta)))
+
+	return NewCountMinSketchWithSize(width, depth)
+}
+
+// NewCountMinSketchWithSize return a CountMinSketch pointer with width counters per row and depth rows.
+func NewCountMinSketchWithSize(width, depth uint64) *CountMinSketch {
+	if width == 0 {
+		width = 1
+	}
+	if depth == 0 {
+		depth = 1
+	}
+
+	counts := make([][]uint64, depth)
+	for i := range counts {
+		counts[i] = make([]uint64, width)
+	}
+
+	return &CountMinSketch{
+		width:  width,
+		depth:  depth,
+		counts: counts,
+	}
+}
+
+// Add increase the count of data by count
+func (cms *CountMinSketch) Add(data []byte, count uint64) {
+	h1, h2 := baseHashes(data)
+	for i := uint64(0); i < cms.depth; i++ {
+		cms.counts[i][bitLocation(h1, h2, i, cms.width)] += count
+	}
+	cms.total += count
+}
+
+// AddString increase the count of string by count
+func (cms *CountMinSketch) AddString(s string, count uint64) {
+	cms.Add([]byte(s), count)
+}
+
+// Estimate return the estimated count of data
+func (cms *CountMinSketch) Estimate(data []byte) uint64 {
+	h1, h2 := baseHashes(data)
+
+	min := uint64(math.MaxUint64)
+	for i := uint64(0); i < cms.depth; i++ {
+		if c := cms.counts[i][bitLocation(h1, h2, i, cms.width)]; c < min {
+			min = c
+		}
+	}
+	return min
+}
+
+// EstimateString return the estimated count of string
+func (cms *CountMinSketch) EstimateString(s string) uint64 {
+	return cms.Estimate([]byte(s))
+}
+
+// TotalCount return the sum of all added counts
+func (cms *CountMinSketch) TotalCount() uint64 {
+	return cms.total
+}
+
+// Width return the number of counters in each row
+func (cms *CountMinSketch) Width() uint64 {
+	return cms.width
+}
+
+// Depth return the number of rows
+func (cms *CountMinSketch) Depth() uint64 {
+	return cms.depth
+}
+
+// Merge other sketch into the sketch, the two sketches should have the same width and depth.
+func (cms *CountMinSketch) Merge(other *CountMinSketch) error {
+	if cms.width != other.width || cms.depth != other.depth {
+		return errors.New("count-min sketches with different width or depth can not be merged")
+	}
+
+	for i := range cms.counts {
+		for j := range cms.counts[i] {
+			cms.counts[i][j] += other.counts[i][j]
+		}
+	}
+	cms.total += other.total
+
+	return nil
+}
+
+// Clear reset all counters of the sketch
+func (cms *CountMinSketch) Clear() {
+	for i := range cms.counts {
+		for j := range cms.counts[i] {
+			cms.counts[i][j] = 0
+		}
+	}
+	cms.total = 0
+}
+
+// MarshalBinary implements the encoding.BinaryMarshaler interface.
+func (cms *CountMinSketch) MarshalBinary() ([]byte, error) {
+	var buf bytes.Buffer
+	buf.WriteByte(countMinSketchMagic)
+
+	if err := binary.Write(&buf, binary.BigEndian, []uint64{cms.width, cms.depth, cms.total}); err != nil {
+		return nil, err
+	}
+	for _, row := range cms.counts {
+		if err := binary.Write(&buf, binary.BigEndian, row); err != nil {
+			return nil, err
+		}
+	}
+
+	return buf.Bytes(), nil
+}
+
+// UnmarshalBinary implements the encoding.BinaryUnmarshaler interface.
+func (cms *CountMinSketch) UnmarshalBinary(data []byte) error {
+	invalidErr := errors.New("invalid count-min sketch data")
+
+	r := bytes.NewReader(data)
+	if magic, err := r.ReadByte(); err != nil || magic != countMinSketchMagic {
+		return invalidErr
+	}
+
+	header := make([]uint64, 3)
+	if err := binary.Read(r, binary.BigEndian, header); err != nil {
+		return invalidErr
+	}
+	// compare with the number of remaining counters instead of width*depth*8, which may overflow
+	width, depth := header[0], header[1]
+	cells := uint64(r.Len()) / 8
+	if width == 0 || depth == 0 || r.Len()%8 != 0 || cells%width != 0 || cells/width != depth {
+		return invalidErr
+	}
+
+	counts := make([][]uint64, depth)
+	for i := range counts {
+		counts[i] = make([]uint64, width)
+		if err := binary.Read(r, binary.BigEndian, counts[i]); err != nil {
+			return invalidErr
+		}
+	}
+
+	cms.width, cms.depth, cms.total = width, depth, header[2]
+	cms.counts = counts
+
+	return nil
+}
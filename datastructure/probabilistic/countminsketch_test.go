@@ -0,0 +1,98 @@
+package datastructure
+
+import (
+	"fmt"
+	"math"
+	"testing"
+
+	"github.com/duke-git/lancet/v2/internal"
+)
+
+func TestCountMinSketch(t *testing.T) {
+	t.Parallel()
+
+	assert := internal.NewAssert(t, "TestCountMinSketch")
+
+	cms := NewCountMinSketch(0.001, 0.01)
+	assert.Equal(uint64(2719), cms.Width())
+	assert.Equal(uint64(5), cms.Depth())
+
+	for i := 0; i < 1000; i++ {
+		cms.AddString(fmt.Sprintf("item-%d", i), 1)
+	}
+	cms.AddString("hot", 500)
+	cms.Add([]byte("hot"), 500)
+
+	assert.Equal(uint64(2000), cms.TotalCount())
+
+	hot := cms.EstimateString("hot")
+	assert.GreaterOrEqual(hot, uint64(1000))
+	assert.LessOrEqual(hot, uint64(1002))
+
+	assert.GreaterOrEqual(cms.Estimate([]byte("item-1")), uint64(1))
+	assert.LessOrEqual(cms.EstimateString("missing"), uint64(2))
+
+	cms.Clear()
+	assert.Equal(uint64(0), cms.EstimateString("hot"))
+	assert.Equal(uint64(0), cms.TotalCount())
+}
+
+func TestCountMinSketch_MergeAndBinary(t *testing.T) {
+	t.Parallel()
+
+	assert := internal.NewAssert(t, "TestCountMinSketch_MergeAndBinary")
+
+	cms1 := NewCountMinSketchWithSize(100, 4)
+	cms2 := NewCountMinSketchWithSize(100, 4)
+	cms1.AddString("a", 3)
+	cms2.AddString("a", 4)
+	cms2.AddString("b", 1)
+
+	assert.IsNil(cms1.Merge(cms2))
+	assert.Equal(uint64(7), cms1.EstimateString("a"))
+	assert.Equal(uint64(8), cms1.TotalCount())
+	assert.IsNotNil(cms1.Merge(NewCountMinSketchWithSize(10, 4)))
+
+	data, err := cms1.MarshalBinary()
+	assert.IsNil(err)
+
+	decoded := &CountMinSketch{}
+	assert.IsNil(decoded.UnmarshalBinary(data))
+	assert.Equal(cms1, decoded)
+
+	assert.IsNotNil(decoded.UnmarshalBinary(data[:20]))
+}
+
+func TestCountMinSketch_UnmarshalCorrupted(t *testing.T) {
+	t.Parallel()
+
+	assert := internal.NewAssert(t, "TestCountMinSketch_UnmarshalCorrupted")
+
+	cases := [][]byte{
+		// width*depth*8 overflows to 0
+		encodeHeader(countMinSketchMagic, 1<<61, 8, 0),
+		encodeHeader(countMinSketchMagic, math.MaxUint64, math.MaxUint64, 0),
+		encodeHeader(countMinSketchMagic, 0, 1, 0),
+		append(encodeHeader(countMinSketchMagic, 2, 1, 0), 1, 2, 3),
+	}
+	for _, data := range cases {
+		cms := &CountMinSketch{}
+		assert.IsNotNil(cms.UnmarshalBinary(data))
+	}
+}
+
+func FuzzCountMinSketch_UnmarshalBinary(f *testing.F) {
+	cms := NewCountMinSketchWithSize(4, 2)
+	cms.AddString("a", 1)
+	data, _ := cms.MarshalBinary()
+	f.Add(data)
+	f.Add(encodeHeader(countMinSketchMagic, 1<<61, 8, 0))
+
+	f.Fuzz(func(t *testing.T, data []byte) {
+		decoded := &CountMinSketch{}
+		if decoded.UnmarshalBinary(data) == nil {
+			decoded.AddString("b", 1)
+			decoded.EstimateString("a")
+		}
+	})
+}
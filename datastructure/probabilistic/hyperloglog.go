@@ -0,0 +1,150 @@
+// Copyright 2023 dudaodong@gmail.com. All rights reserved.
+// Use of this source code is governed by MIT license
+
+// Package datastructure contains some data structure.
+// Probabilistic structure contains BloomFilter, ScalableBloomFilter, CountMinSketch and HyperLogLog.
+package datastructure
+
+import (
+	"errors"
+	"math"
+	"math/bits"
+)
+
+// HyperLogLog is a probabilistic data structure to estimate the number of distinct items in a stream.
+// The standard error is about 1.04 / sqrt(2^precision).
+type HyperLogLog struct {
+	precision uint8
+	registers []uint8
+}
+
+// NewHyperLogLog return a HyperLogLog pointer with 2^precision registers.
+// precision should be in range [4, 18], the out of range value is clamped.
+func NewHyperLogLog(precision uint8) *HyperLogLog {
+	if precision < 4 {
+		precision = 4
+	} else if precision > 18 {
+		precision = 18
+	}
+
+	return &HyperLogLog{
+		precision: precision,
+		registers: make([]uint8, 1<<precision),
+	}
+}
+
+// Add data into the hyperloglog
+func (hll *HyperLogLog) Add(data []byte) {
+	hash, _ := baseHashes(data)
+
+	index := hash >> (64 - hll.precision)
+	// set a sentinel bit so the rank never exceeds 64 - precision + 1
+	rest := hash<<hll.precision | 1<<(hll.precision-1)
+	rank := uint8(bits.LeadingZeros64(rest)) + 1
+
+	if rank > hll.registers[index] {
+		hll.registers[index] = rank
+	}
+}
+
+// AddString add string into the hyperloglog
+func (hll *HyperLogLog) AddString(s string) {
+	hll.Add([]byte(s))
+}
+
+// Count return the estimated number of distinct items
+func (hll *HyperLogLog) Count() uint64 {
+	m := float64(len(hll.registers))
+
+	sum := 0.0
+	zeros := 0
+	for _, r := range hll.registers {
+		sum += 1 / float64(uint64(1)<<r)
+		if r == 0 {
+			zeros++
+		}
+	}
+
+	estimate := hllAlpha(m) * m * m / sum
+
+	// small range correction with linear counting
+	if estimate <= 2.5*m && zeros > 0 {
+		estimate = m * math.Log(m/float64(zeros))
+	}
+
+	return uint64(math.Round(estimate))
+}
+
+// Precision return the precision of the hyperloglog
+func (hll *HyperLogLog) Precision() uint8 {
+	return hll.precision
+}
+
+// Merge other hyperloglog into the hyperloglog, the two hyperloglogs should have the same precision.
+func (hll *HyperLogLog) Merge(other *HyperLogLog) error {
+	if hll.precision != other.precision {
+		return errors.New("hyperloglogs with different precision can not be merged")
+	}
+
+	for i, r := range other.registers {
+		if r > hll.registers[i] {
+			hll.registers[i] = r
+		}
+	}
+
+	return nil
+}
+
+// Clear reset all registers of the hyperloglog
+func (hll *HyperLogLog) Clear() {
+	for i := range hll.registers {
+		hll.registers[i] = 0
+	}
+}
+
+// MarshalBinary implements the encoding.BinaryMarshaler interface.
+func (hll *HyperLogLog) MarshalBinary() ([]byte, error) {
+	data := make([]byte, 0, len(hll.registers)+2)
+	data = append(data, hyperLogLogMagic, hll.precision)
+	data = append(data, hll.registers...)
+
+	return data, nil
+}
+
+// UnmarshalBinary implements the encoding.BinaryUnmarshaler interface.
+func (hll *HyperLogLog) UnmarshalBinary(data []byte) error {
+	if len(data) < 2 || data[0] != hyperLogLogMagic {
+		return errors.New("invalid hyperloglog data")
+	}
+
+	precision := data[1]
+	if precision < 4 || precision > 18 || len(data)-2 != 1<<precision {
+		return errors.New("invalid hyperloglog data")
+	}
+
+	maxRank := 64 - precision + 1
+	for _, r := range data[2:] {
+		if r > maxRank {
+			return errors.New("invalid hyperloglog data")
+		}
+	}
+
+	hll.precision = precision
+	hll.registers = make([]uint8, 1<<precision)
+	copy(hll.registers, data[2:])
+
+	return nil
+}
+
+func hllAlpha(m float64) float64 {
+	switch m {
+	case 16:
+		return 0.673
+	case 32:
+		return 0.697
+	case 64:
+		return 0.709
+	default:
+		return 0.7213 / (1 + 1.079/m)
+	}
+}
@@ -0,0 +1,88 @@
+package datastructure
+
+import (
+	"fmt"
+	"math"
+	"testing"
+
+	"github.com/duke-git/lancet/v2/internal"
+)
+
+func TestHyperLogLog(t *testing.T) {
+	t.Parallel()
+
+	assert := internal.NewAssert(t, "TestHyperLogLog")
+
+	hll := NewHyperLogLog(14)
+	assert.Equal(uint64(0), hll.Count())
+
+	for _, n := range []int{10, 1000, 100000} {
+		hll.Clear()
+		for i := 0; i < n; i++ {
+			hll.AddString(fmt.Sprintf("item-%d", i))
+			// duplicated items are not counted
+			hll.Add([]byte(fmt.Sprintf("item-%d", i)))
+		}
+
+		errorRate := math.Abs(float64(hll.Count())-float64(n)) / float64(n)
+		assert.Less(errorRate, 0.03)
+	}
+
+	assert.Equal(uint8(4), NewHyperLogLog(1).Precision())
+	assert.Equal(uint8(18), NewHyperLogLog(20).Precision())
+}
+
+func TestHyperLogLog_MergeAndBinary(t *testing.T) {
+	t.Parallel()
+
+	assert := internal.NewAssert(t, "TestHyperLogLog_MergeAndBinary")
+
+	hll1 := NewHyperLogLog(12)
+	hll2 := NewHyperLogLog(12)
+	for i := 0; i < 5000; i++ {
+		hll1.AddString(fmt.Sprintf("item-%d", i))
+		hll2.AddString(fmt.Sprintf("item-%d", i+2500))
+	}
+
+	assert.IsNil(hll1.Merge(hll2))
+	errorRate := math.Abs(float64(hll1.Count())-7500) / 7500
+	assert.Less(errorRate, 0.05)
+	assert.IsNotNil(hll1.Merge(NewHyperLogLog(10)))
+
+	data, err := hll1.MarshalBinary()
+	assert.IsNil(err)
+
+	decoded := &HyperLogLog{}
+	assert.IsNil(decoded.UnmarshalBinary(data))
+	assert.Equal(hll1, decoded)
+	assert.Equal(hll1.Count(), decoded.Count())
+
+	assert.IsNotNil(decoded.UnmarshalBinary(data[:100]))
+}
+
+func TestHyperLogLog_UnmarshalCorrupted(t *testing.T) {
+	t.Parallel()
+
+	assert := internal.NewAssert(t, "TestHyperLogLog_UnmarshalCorrupted")
+
+	valid := append([]byte{hyperLogLogMagic, 4}, make([]byte, 16)...)
+	valid[2] = 64 - 4 + 1
+
+	// register exceeds 64 - precision + 1
+	outOfRange := append([]byte{}, valid...)
+	outOfRange[3] = 64 - 4 + 2
+
+	cases := [][]byte{
+		outOfRange,
+		{hyperLogLogMagic, 3},
+		{hyperLogLogMagic, 19},
+		valid[:len(valid)-1],
+	}
+	for _, data := range cases {
+		hll := &HyperLogLog{}
+		assert.IsNotNil(hll.UnmarshalBinary(data))
+	}
+
+	hll := &HyperLogLog{}
+	assert.IsNil(hll.UnmarshalBinary(valid))
+}
@@ -0,0 +1,77 @@
+package datastructure
+
+import (
+	"bytes"
+	"encoding/binary"
+	"errors"
+	"hash/fnv"
+)
+
+// magic bytes of the binary format of each structure
+const (
+	bloomFilterMagic byte = 0xB1 + iota
+	scalableBloomFilterMagic
+	countMinSketchMagic
+	hyperLogLogMagic
+)
+
+// bloomFilterMinSize is the minimal size of an encoded bloom filter: magic byte, 4 header words and 1 bit word
+const bloomFilterMinSize = 1 + 4*8 + 8
+
+// baseHashes return two independent 64 bit hashes of data
+func baseHashes(data []byte) (uint64, uint64) {
+	h := fnv.New128a()
+	h.Write(data)
+	sum := h.Sum(nil)
+
+	return mix64(binary.BigEndian.Uint64(sum[:8])), mix64(binary.BigEndian.Uint64(sum[8:]))
+}
+
+// bitLocation return the i-th location with double hashing: h1 + i*h2
+func bitLocation(h1, h2, i, m uint64) uint64 {
+	return (h1 + i*h2) % m
+}
+
+// mix64 is the finalizer of murmur3, it spreads the bits of fnv hash
+func mix64(h uint64) uint64 {
+	h ^= h >> 33
+	h *= 0xff51afd7ed558ccd
+	h ^= h >> 33
+	h *= 0xc4ceb9fe1a85ec53
+	h ^= h >> 33
+	return h
+}
+
+// readFrom decode a bloom filter from reader
+func (bf *BloomFilter) readFrom(r *bytes.Reader) error {
+	invalidErr := errors.New("invalid bloom filter data")
+
+	if magic, err := r.ReadByte(); err != nil || magic != bloomFilterMagic {
+		return invalidErr
+	}
+
+	header := make([]uint64, 4)
+	if err := binary.Read(r, binary.BigEndian, header); err != nil {
+		return invalidErr
+	}
+
+	// check m against the remaining bytes before any arithmetic, so a crafted header can not overflow
+	m, k := header[0], header[1]
+	if m == 0 || k == 0 || k > m || m > uint64(r.Len())*8 {
+		return invalidErr
+	}
+	words := (m + 63) / 64
+	if words*8 > uint64(r.Len()) {
+		return invalidErr
+	}
+
+	bits := make([]uint64, words)
+	if err := binary.Read(r, binary.BigEndian, bits); err != nil {
+		return invalidErr
+	}
+
+	bf.m, bf.k, bf.count, bf.itemLimit = m, k, header[2], header[3]
+	bf.bits = bits
+
+	return nil
+}
@@ -0,0 +1,208 @@
+// Copyright 2023 dudaodong@gmail.com. All rights reserved.
+// Use of this source code is governed by MIT license
+
+// Package datastructure contains some data structure.
+// Probabilistic structure contains BloomFilter, ScalableBloomFilter, CountMinSketch and HyperLogLog.
+package datastructure
+
+import (
+	"bytes"
+	"encoding/binary"
+	"errors"
+	"math"
+)
+
+// ScalableBloomFilter is a bloom filter which grows when more items are added,
+// it keeps the false positive rate bounded no matter how many items are added.
+// see: "Scalable Bloom Filters" by Almeida, Baquero, Preguiça and Hutchison.
+type ScalableBloomFilter struct {
+	filters         []*BloomFilter
+	initialCapacity uint64
+	initialRate     float64
+	growth          uint64
+	tightening      float64
+}
+
+// NewScalableBloomFilter return a ScalableBloomFilter pointer.
+// param `initialCapacity` is the number of items of the first filter, param `falsePositiveRate` is the upper bound of false positive rate.
+// Each new filter is twice as large as the previous one, and its false positive rate is tightened by 0.9.
+func NewScalableBloomFilter(initialCapacity uint64, falsePositiveRate float64) *ScalableBloomFilter {
+	if initialCapacity == 0 {
+		initialCapacity = 1
+	}
+	if falsePositiveRate <= 0 || falsePositiveRate >= 1 {
+		falsePositiveRate = 0.01
+	}
+
+	return &ScalableBloomFilter{
+		filters:         []*BloomFilter{},
+		initialCapacity: initialCapacity,
+		initialRate:     falsePositiveRate,
+		growth:          2,
+		tightening:      0.9,
+	}
+}
+
+// Add data into the filter, data which may be in the filter is ignored.
+func (sbf *ScalableBloomFilter) Add(data []byte) {
+	if sbf.Contain(data) {
+		return
+	}
+
+	last := sbf.current()
+	if last.count >= last.itemLimit {
+		last = sbf.grow()
+	}
+	last.Add(data)
+}
+
+// AddString add string into the filter
+func (sbf *ScalableBloomFilter) AddString(s string) {
+	sbf.Add([]byte(s))
+}
+
+// Contain checks if data may be in the filter, false means data is definitely not in the filter.
+func (sbf *ScalableBloomFilter) Contain(data []byte) bool {
+	for _, f := range sbf.filters {
+		if f.Contain(data) {
+			return true
+		}
+	}
+	return false
+}
+
+// ContainString checks if string may be in the filter
+func (sbf *ScalableBloomFilter) ContainString(s string) bool {
+	return sbf.Contain([]byte(s))
+}
+
+// Count return the number of added items
+func (sbf *ScalableBloomFilter) Count() uint64 {
+	var count uint64
+	for _, f := range sbf.filters {
+		count += f.count
+	}
+	return count
+}
+
+// FilterCount return the number of underlying bloom filters
+func (sbf *ScalableBloomFilter) FilterCount() int {
+	return len(sbf.filters)
+}
+
+// FalsePositiveRate return the estimated false positive rate with current number of items.
+func (sbf *ScalableBloomFilter) FalsePositiveRate() float64 {
+	notFalsePositive := 1.0
+	for _, f := range sbf.filters {
+		notFalsePositive *= 1 - f.FalsePositiveRate()
+	}
+	return 1 - notFalsePositive
+}
+
+// Merge other filter into the filter, the two filters should be created with the same parameters.
+// The underlying bloom filters of other are copied into the filter.
+func (sbf *ScalableBloomFilter) Merge(other *ScalableBloomFilter) error {
+	if sbf.initialCapacity != other.initialCapacity || sbf.initialRate != other.initialRate ||
+		sbf.growth != other.growth || sbf.tightening != other.tightening {
+		return errors.New("scalable bloom filters with different parameters can not be merged")
+	}
+
+	for _, f := range other.filters {
+		clone := NewBloomFilterWithSize(f.m, f.k)
+		if err := clone.Merge(f); err != nil {
+			return err
+		}
+		clone.itemLimit = f.itemLimit
+		sbf.filters = append(sbf.filters, clone)
+	}
+
+	return nil
+}
+
+// Clear remove all items of the filter
+func (sbf *ScalableBloomFilter) Clear() {
+	sbf.filters = []*BloomFilter{}
+}
+
+// MarshalBinary implements the encoding.BinaryMarshaler interface.
+func (sbf *ScalableBloomFilter) MarshalBinary() ([]byte, error) {
+	var buf bytes.Buffer
+	buf.WriteByte(scalableBloomFilterMagic)
+
+	header := []uint64{
+		sbf.initialCapacity,
+		math.Float64bits(sbf.initialRate),
+		sbf.growth,
+		math.Float64bits(sbf.tightening),
+		uint64(len(sbf.filters)),
+	}
+	if err := binary.Write(&buf, binary.BigEndian, header); err != nil {
+		return nil, err
+	}
+
+	for _, f := range sbf.filters {
+		data, err := f.MarshalBinary()
+		if err != nil {
+			return nil, err
+		}
+		buf.Write(data)
+	}
+
+	return buf.Bytes(), nil
+}
+
+// UnmarshalBinary implements the encoding.BinaryUnmarshaler interface.
+func (sbf *ScalableBloomFilter) UnmarshalBinary(data []byte) error {
+	r := bytes.NewReader(data)
+	if magic, err := r.ReadByte(); err != nil || magic != scalableBloomFilterMagic {
+		return errors.New("invalid scalable bloom filter data")
+	}
+
+	header := make([]uint64, 5)
+	if err := binary.Read(r, binary.BigEndian, header); err != nil {
+		return errors.New("invalid scalable bloom filter data")
+	}
+
+	// each filter takes at least the magic byte, the header and one word
+	if header[4] > uint64(r.Len())/bloomFilterMinSize {
+		return errors.New("invalid scalable bloom filter data")
+	}
+
+	filters := make([]*BloomFilter, 0, header[4])
+	for i := uint64(0); i < header[4]; i++ {
+		f := &BloomFilter{}
+		if err := f.readFrom(r); err != nil {
+			return err
+		}
+		filters = append(filters, f)
+	}
+
+	sbf.initialCapacity = header[0]
+	sbf.initialRate = math.Float64frombits(header[1])
+	sbf.growth = header[2]
+	sbf.tightening = math.Float64frombits(header[3])
+	sbf.filters = filters
+
+	return nil
+}
+
+// current return the filter which new items are added into
+func (sbf *ScalableBloomFilter) current() *BloomFilter {
+	if len(sbf.filters) == 0 {
+		return sbf.grow()
+	}
+	return sbf.filters[len(sbf.filters)-1]
+}
+
+// grow append a new filter with larger capacity and lower false positive rate
+func (sbf *ScalableBloomFilter) grow() *BloomFilter {
+	n := len(sbf.filters)
+	capacity := sbf.initialCapacity * uint64(math.Pow(float64(sbf.growth), float64(n)))
+	// the first filter takes (1 - r) of the error budget, so the sum of the series stays below initialRate
+	rate := sbf.initialRate * (1 - sbf.tightening) * math.Pow(sbf.tightening, float64(n))
+
+	f := NewBloomFilter(capacity, rate)
+	sbf.filters = append(sbf.filters, f)
+
+	return f
+}
@@ -0,0 +1,229 @@
+// Copyright 2023 dudaodong@gmail.com. All rights reserved.
+// Use of this source code is governed by MIT license
+
+// Package datastructure contains some data structure.
+// Queue structure contains ArrayQueue, LinkedQueue, CircularQueue, PriorityQueue and BlockingQueue.
+package datastructure
+
+import (
+	"context"
+	"errors"
+	"sync"
+	"time"
+
+	"github.com/duke-git/lancet/v2/constraints"
+	heap "github.com/duke-git/lancet/v2/datastructure/heap"
+)
+
+// ErrQueueClosed is returned when put item into a closed queue, or take item from a closed and empty queue.
+var ErrQueueClosed = errors.New("queue is closed")
+
+// BlockingQueue is a thread-safe queue, Put blocks when the queue is full and Take blocks when the queue is empty.
+// The order of items depends on the underlying storage: array and linked storage are fifo, priority storage is ordered by comparator.
+// After Close, Put fails immediately, while the remaining items still could be taken.
+type BlockingQueue[T any] struct {
+	mu       sync.Mutex
+	store    blockingStore[T]
+	capacity int
+	closed   bool
+	changed  chan struct{}
+}
+
+// NewArrayBlockingQueue return a bounded fifo BlockingQueue pointer backed by a ring buffer.
+func NewArrayBlockingQueue[T any](capacity int) *BlockingQueue[T] {
+	if capacity <= 0 {
+		capacity = 1
+	}
+	return newBlockingQueue[T](newRingStore[T](capacity), capacity)
+}
+
+// NewLinkedBlockingQueue return a fifo BlockingQueue pointer backed by a link list.
+// if capacity <= 0, the queue is unbounded and Put never blocks.
+func NewLinkedBlockingQueue[T any](capacity int) *BlockingQueue[T] {
+	return newBlockingQueue[T](&linkStore[T]{queue: NewLinkedQueue[T]()}, capacity)
+}
+
+// NewPriorityBlockingQueue return a BlockingQueue pointer ordered by comparator, Take returns the smallest item first.
+// if capacity <= 0, the queue is unbounded and Put never blocks.
+// type T should implements Compare function in constraints.Comparator interface.
+func NewPriorityBlockingQueue[T any](capacity int, comparator constraints.Comparator) *BlockingQueue[T] {
+	return newBlockingQueue[T](&heapStore[T]{heap: heap.NewDaryHeap[T](2, comparator)}, capacity)
+}
+
+func newBlockingQueue[T any](store blockingStore[T], capacity int) *BlockingQueue[T] {
+	return &BlockingQueue[T]{
+		store:    store,
+		capacity: capacity,
+		changed:  make(chan struct{}),
+	}
+}
+
+// Put insert item into the queue, wait until the queue is not full.
+// return ctx.Err() if ctx is done before the item is inserted, or ErrQueueClosed if the queue is closed.
+func (q *BlockingQueue[T]) Put(ctx context.Context, item T) error {
+	q.mu.Lock()
+	for {
+		if q.closed {
+			q.mu.Unlock()
+			return ErrQueueClosed
+		}
+		if !q.isFull() {
+			break
+		}
+		if err := q.wait(ctx); err != nil {
+			return err
+		}
+	}
+
+	q.store.push(item)
+	q.notify()
+	q.mu.Unlock()
+
+	return nil
+}
+
+// Offer insert item into the queue, wait at most timeout if the queue is full.
+// return false if the item is not inserted. if timeout <= 0, it does not wait.
+func (q *BlockingQueue[T]) Offer(item T, timeout time.Duration) bool {
+	ctx, cancel := timeoutContext(timeout)
+	defer cancel()
+
+	return q.Put(ctx, item) == nil
+}
+
+// Take remove and return the head item of the queue, wait until the queue is not empty.
+// return ctx.Err() if ctx is done before an item is available, or ErrQueueClosed if the queue is closed and empty.
+func (q *BlockingQueue[T]) Take(ctx context.Context) (T, error) {
+	var zero T
+
+	q.mu.Lock()
+	for q.store.size() == 0 {
+		if q.closed {
+			q.mu.Unlock()
+			return zero, ErrQueueClosed
+		}
+		if err := q.wait(ctx); err != nil {
+			return zero, err
+		}
+	}
+
+	item := q.store.pop()
+	q.notify()
+	q.mu.Unlock()
+
+	return item, nil
+}
+
+// Poll remove and return the head item of the queue, wait at most timeout if the queue is empty.
+// return zero value and false if no item is available. if timeout <= 0, it does not wait.
+func (q *BlockingQueue[T]) Poll(timeout time.Duration) (T, bool) {
+	ctx, cancel := timeoutContext(timeout)
+	defer cancel()
+
+	item, err := q.Take(ctx)
+	return item, err == nil
+}
+
+// Peek return the head item of the queue without removing it.
+// if the queue is empty, return zero value and false.
+func (q *BlockingQueue[T]) Peek() (T, bool) {
+	q.mu.Lock()
+	defer q.mu.Unlock()
+
+	if q.store.size() == 0 {
+		var zero T
+		return zero, false
+	}
+	return q.store.peek(), true
+}
+
+// DrainTo remove at most maxItems items from the queue and append them to dst, return the appended slice.
+// if maxItems <= 0, all items are removed.
+func (q *BlockingQueue[T]) DrainTo(dst []T, maxItems int) []T {
+	q.mu.Lock()
+	defer q.mu.Unlock()
+
+	n := q.store.size()
+	if maxItems > 0 && maxItems < n {
+		n = maxItems
+	}
+	for i := 0; i < n; i++ {
+		dst = append(dst, q.store.pop())
+	}
+	if n > 0 {
+		q.notify()
+	}
+
+	return dst
+}
+
+// Size return number of items in the queue
+func (q *BlockingQueue[T]) Size() int {
+	q.mu.Lock()
+	defer q.mu.Unlock()
+
+	return q.store.size()
+}
+
+// IsEmpty checks if the queue is empty or not
+func (q *BlockingQueue[T]) IsEmpty() bool {
+	return q.Size() == 0
+}
+
+// RemainingCapacity return the number of items could be put into the queue without blocking.
+// return -1 if the queue is unbounded.
+func (q *BlockingQueue[T]) RemainingCapacity() int {
+	if q.capacity <= 0 {
+		return -1
+	}
+
+	q.mu.Lock()
+	defer q.mu.Unlock()
+
+	return q.capacity - q.store.size()
+}
+
+// Close the queue, all blocked Put return ErrQueueClosed, and blocked Take return ErrQueueClosed once the queue is empty.
+// Close an already closed queue does nothing.
+func (q *BlockingQueue[T]) Close() {
+	q.mu.Lock()
+	defer q.mu.Unlock()
+
+	if !q.closed {
+		q.closed = true
+		q.notify()
+	}
+}
+
+// IsClosed checks if the queue is closed or not
+func (q *BlockingQueue[T]) IsClosed() bool {
+	q.mu.Lock()
+	defer q.mu.Unlock()
+
+	return q.closed
+}
+
+func (q *BlockingQueue[T]) isFull() bool {
+	return q.capacity > 0 && q.store.size() >= q.capacity
+}
+
+// notify wake up all waiting goroutines, the caller should hold the lock.
+func (q *BlockingQueue[T]) notify() {
+	close(q.changed)
+	q.changed = make(chan struct{})
+}
+
+// wait release the lock and wait until the queue changed or ctx is done.
+// if ctx is done, it returns ctx.Err() without holding the lock, or else it returns nil with the lock held.
+func (q *BlockingQueue[T]) wait(ctx context.Context) error {
+	changed := q.changed
+	q.mu.Unlock()
+
+	select {
+	case <-changed:
+		q.mu.Lock()
+		return nil
+	case <-ctx.Done():
+		return ctx.Err()
+	}
+}
@@ -0,0 +1,105 @@
+package datastructure
+
+import (
+	"context"
+	"time"
+
+	heap "github.com/duke-git/lancet/v2/datastructure/heap"
+)
+
+// blockingStore is the underlying storage of BlockingQueue, it is not thread-safe.
+type blockingStore[T any] interface {
+	push(item T)
+	pop() T
+	peek() T
+	size() int
+}
+
+// ringStore is a fixed capacity ring buffer
+type ringStore[T any] struct {
+	data  []T
+	head  int
+	count int
+}
+
+func newRingStore[T any](capacity int) *ringStore[T] {
+	return &ringStore[T]{data: make([]T, capacity)}
+}
+
+func (s *ringStore[T]) push(item T) {
+	s.data[(s.head+s.count)%len(s.data)] = item
+	s.count++
+}
+
+func (s *ringStore[T]) pop() T {
+	var zero T
+	item := s.data[s.head]
+	s.data[s.head] = zero
+	s.head = (s.head + 1) % len(s.data)
+	s.count--
+	return item
+}
+
+func (s *ringStore[T]) peek() T {
+	return s.data[s.head]
+}
+
+func (s *ringStore[T]) size() int {
+	return s.count
+}
+
+// linkStore is an unbounded storage backed by LinkedQueue
+type linkStore[T any] struct {
+	queue *LinkedQueue[T]
+}
+
+func (s *linkStore[T]) push(item T) {
+	s.queue.Enqueue(item)
+}
+
+func (s *linkStore[T]) pop() T {
+	item, _ := s.queue.Dequeue()
+	return *item
+}
+
+func (s *linkStore[T]) peek() T {
+	item, _ := s.queue.Front()
+	return *item
+}
+
+func (s *linkStore[T]) size() int {
+	return s.queue.Size()
+}
+
+// heapStore is an unbounded storage backed by DaryHeap
+type heapStore[T any] struct {
+	heap *heap.DaryHeap[T]
+}
+
+func (s *heapStore[T]) push(item T) {
+	s.heap.Push(item)
+}
+
+func (s *heapStore[T]) pop() T {
+	item, _ := s.heap.Pop()
+	return item
+}
+
+func (s *heapStore[T]) peek() T {
+	item, _ := s.heap.Peek()
+	return item
+}
+
+func (s *heapStore[T]) size() int {
+	return s.heap.Size()
+}
+
+// timeoutContext return a context which is done after timeout, if timeout <= 0, the context is already done.
+func timeoutContext(timeout time.Duration) (context.Context, context.CancelFunc) {
+	if timeout <= 0 {
+		ctx, cancel := context.WithCancel(context.Background())
+		cancel()
+		return ctx, cancel
+	}
+	return context.WithTimeout(context.Background(), timeout)
+}
@@ -0,0 +1,187 @@
+package datastructure
+
+import (
+	"context"
+	"sync"
+	"testing"
+	"time"
+
+	"github.com/duke-git/lancet/v2/internal"
+)
+
+func TestBlockingQueue_PutAndTake(t *testing.T) {
+	t.Parallel()
+
+	assert := internal.NewAssert(t, "TestBlockingQueue_PutAndTake")
+
+	queues := []*BlockingQueue[int]{
+		NewArrayBlockingQueue[int](3),
+		NewLinkedBlockingQueue[int](3),
+		NewLinkedBlockingQueue[int](0),
+	}
+
+	for _, q := range queues {
+		ctx := context.Background()
+		assert.IsNil(q.Put(ctx, 1))
+		assert.IsNil(q.Put(ctx, 2))
+		assert.IsNil(q.Put(ctx, 3))
+		assert.Equal(3, q.Size())
+
+		head, ok := q.Peek()
+		assert.Equal(1, head)
+		assert.Equal(true, ok)
+
+		for _, expected := range []int{1, 2, 3} {
+			val, err := q.Take(ctx)
+			assert.IsNil(err)
+			assert.Equal(expected, val)
+		}
+		assert.Equal(true, q.IsEmpty())
+
+		_, ok = q.Peek()
+		assert.Equal(false, ok)
+	}
+}
+
+func TestBlockingQueue_Priority(t *testing.T) {
+	t.Parallel()
+
+	assert := internal.NewAssert(t, "TestBlockingQueue_Priority")
+
+	q := NewPriorityBlockingQueue[int](0, &intComparator{})
+	for _, v := range []int{5, 1, 4, 2, 3} {
+		assert.Equal(true, q.Offer(v, 0))
+	}
+	assert.Equal(-1, q.RemainingCapacity())
+
+	assert.Equal([]int{1, 2, 3, 4, 5}, q.DrainTo(nil, 0))
+}
+
+func TestBlockingQueue_OfferAndPoll(t *testing.T) {
+	t.Parallel()
+
+	assert := internal.NewAssert(t, "TestBlockingQueue_OfferAndPoll")
+
+	q := NewArrayBlockingQueue[int](2)
+	assert.Equal(true, q.Offer(1, 0))
+	assert.Equal(true, q.Offer(2, 0))
+	assert.Equal(false, q.Offer(3, 0))
+	assert.Equal(false, q.Offer(3, 10*time.Millisecond))
+	assert.Equal(0, q.RemainingCapacity())
+
+	val, ok := q.Poll(0)
+	assert.Equal(1, val)
+	assert.Equal(true, ok)
+
+	val, ok = q.Poll(10 * time.Millisecond)
+	assert.Equal(2, val)
+	assert.Equal(true, ok)
+
+	_, ok = q.Poll(10 * time.Millisecond)
+	assert.Equal(false, ok)
+
+	go func() {
+		time.Sleep(20 * time.Millisecond)
+		q.Offer(9, 0)
+	}()
+	val, ok = q.Poll(time.Second)
+	assert.Equal(9, val)
+	assert.Equal(true, ok)
+}
+
+func TestBlockingQueue_Blocking(t *testing.T) {
+	t.Parallel()
+
+	assert := internal.NewAssert(t, "TestBlockingQueue_Blocking")
+
+	q := NewArrayBlockingQueue[int](1)
+	ctx := context.Background()
+
+	var wg sync.WaitGroup
+	wg.Add(1)
+	go func() {
+		defer wg.Done()
+		for i := 0; i < 100; i++ {
+			assert.IsNil(q.Put(ctx, i))
+		}
+	}()
+
+	sum := 0
+	for i := 0; i < 100; i++ {
+		val, err := q.Take(ctx)
+		assert.IsNil(err)
+		assert.Equal(i, val)
+		sum += val
+	}
+	wg.Wait()
+
+	assert.Equal(4950, sum)
+}
+
+func TestBlockingQueue_Context(t *testing.T) {
+	t.Parallel()
+
+	assert := internal.NewAssert(t, "TestBlockingQueue_Context")
+
+	q := NewArrayBlockingQueue[int](1)
+
+	ctx, cancel := context.WithTimeout(context.Background(), 10*time.Millisecond)
+	defer cancel()
+	_, err := q.Take(ctx)
+	assert.Equal(context.DeadlineExceeded, err)
+
+	assert.IsNil(q.Put(context.Background(), 1))
+
+	ctx, cancel = context.WithCancel(context.Background())
+	go func() {
+		time.Sleep(10 * time.Millisecond)
+		cancel()
+	}()
+	err = q.Put(ctx, 2)
+	assert.Equal(context.Canceled, err)
+}
+
+func TestBlockingQueue_DrainTo(t *testing.T) {
+	t.Parallel()
+
+	assert := internal.NewAssert(t, "TestBlockingQueue_DrainTo")
+
+	q := NewLinkedBlockingQueue[int](0)
+	for i := 1; i <= 5; i++ {
+		q.Offer(i, 0)
+	}
+
+	assert.Equal([]int{0, 1, 2}, q.DrainTo([]int{0}, 2))
+	assert.Equal([]int{3, 4, 5}, q.DrainTo(nil, 0))
+	assert.Equal([]int{}, q.DrainTo([]int{}, 0))
+}
+
+func TestBlockingQueue_Close(t *testing.T) {
+	t.Parallel()
+
+	assert := internal.NewAssert(t, "TestBlockingQueue_Close")
+
+	q := NewLinkedBlockingQueue[int](1)
+	ctx := context.Background()
+	assert.IsNil(q.Put(ctx, 1))
+
+	errCh := make(chan error)
+	go func() {
+		errCh <- q.Put(ctx, 2)
+	}()
+
+	time.Sleep(10 * time.Millisecond)
+	q.Close()
+	q.Close()
+
+	assert.Equal(ErrQueueClosed, <-errCh)
+	assert.Equal(true, q.IsClosed())
+	assert.Equal(ErrQueueClosed, q.Put(ctx, 3))
+
+	val, err := q.Take(ctx)
+	assert.IsNil(err)
+	assert.Equal(1, val)
+
+	_, err = q.Take(ctx)
+	assert.Equal(ErrQueueClosed, err)
+}
@@ -0,0 +1,140 @@
+// Copyright 2023 dudaodong@gmail.com. All rights reserved.
+// Use of this source code is governed by MIT license
+
+// Package datastructure contains some data structure.
+// Clock is the time source of DelayQueue and TimingWheel, ManualClock could be injected to make tests deterministic.
+package datastructure
+
+import (
+	"sync"
+	"time"
+)
+
+// Clock provides current time and timers.
+type Clock interface {
+	// Now return current time
+	Now() time.Time
+	// TimerAt creates a timer which sends current time on its channel once the clock reaches deadline.
+	TimerAt(deadline time.Time) Timer
+}
+
+// Timer is a single event timer created by Clock.
+type Timer interface {
+	// C return the channel on which the time is delivered
+	C() <-chan time.Time
+	// Stop prevents the timer from firing, return false if the timer has already fired or been stopped.
+	Stop() bool
+}
+
+// SystemClock return a Clock backed by the time package.
+func SystemClock() Clock {
+	return systemClock{}
+}
+
+type systemClock struct{}
+
+func (systemClock) Now() time.Time {
+	return time.Now()
+}
+
+func (systemClock) TimerAt(deadline time.Time) Timer {
+	return &systemTimer{time.NewTimer(time.Until(deadline))}
+}
+
+type systemTimer struct {
+	timer *time.Timer
+}
+
+func (t *systemTimer) C() <-chan time.Time {
+	return t.timer.C
+}
+
+func (t *systemTimer) Stop() bool {
+	return t.timer.Stop()
+}
+
+// ManualClock is a Clock which only moves forward when Advance or Set is called, it is useful for tests.
+type ManualClock struct {
+	mu     sync.Mutex
+	now    time.Time
+	timers []*manualTimer
+}
+
+// NewManualClock return a ManualClock pointer starts at the given time.
+func NewManualClock(start time.Time) *ManualClock {
+	return &ManualClock{now: start}
+}
+
+// Now return current time of the clock
+func (c *ManualClock) Now() time.Time {
+	c.mu.Lock()
+	defer c.mu.Unlock()
+
+	return c.now
+}
+
+// TimerAt creates a timer which fires once the clock reaches deadline.
+// if deadline is not after current time, the timer fires immediately.
+func (c *ManualClock) TimerAt(deadline time.Time) Timer {
+	c.mu.Lock()
+	defer c.mu.Unlock()
+
+	t := &manualTimer{clock: c, deadline: deadline, ch: make(chan time.Time, 1)}
+	if !deadline.After(c.now) {
+		t.ch <- c.now
+		return t
+	}
+
+	c.timers = append(c.timers, t)
+	return t
+}
+
+// Advance move the clock forward by d, and fire all timers whose deadline is reached.
+func (c *ManualClock) Advance(d time.Duration) {
+	c.Set(c.Now().Add(d))
+}
+
+// Set move the clock to t, and fire all timers whose deadline is reached.
+// The clock never goes backward, setting an earlier time does nothing.
+func (c *ManualClock) Set(t time.Time) {
+	c.mu.Lock()
+	defer c.mu.Unlock()
+
+	if t.Before(c.now) {
+		return
+	}
+	c.now = t
+
+	pending := c.timers[:0]
+	for _, timer := range c.timers {
+		if timer.deadline.After(t) {
+			pending = append(pending, timer)
+			continue
+		}
+		timer.ch <- t
+	}
+	c.timers = pending
+}
+
+type manualTimer struct {
+	clock    *ManualClock
+	deadline time.Time
+	ch       chan time.Time
+}
+
+func (t *manualTimer) C() <-chan time.Time {
+	return t.ch
+}
+
+func (t *manualTimer) Stop() bool {
+	t.clock.mu.Lock()
+	defer t.clock.mu.Unlock()
+
+	for i, timer := range t.clock.timers {
+		if timer == t {
+			t.clock.timers = append(t.clock.timers[:i], t.clock.timers[i+1:]...)
+			return true
+		}
+	}
+	return false
+}
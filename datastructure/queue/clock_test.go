@@ -0,0 +1,59 @@
+package datastructure
+
+import (
+	"testing"
+	"time"
+
+	"github.com/duke-git/lancet/v2/internal"
+)
+
+func TestManualClock(t *testing.T) {
+	t.Parallel()
+
+	assert := internal.NewAssert(t, "TestManualClock")
+
+	start := time.Date(2023, 1, 1, 0, 0, 0, 0, time.UTC)
+	clock := NewManualClock(start)
+	assert.Equal(start, clock.Now())
+
+	t1 := clock.TimerAt(start.Add(time.Second))
+	t2 := clock.TimerAt(start.Add(2 * time.Second))
+	t3 := clock.TimerAt(start)
+
+	select {
+	case now := <-t3.C():
+		assert.Equal(start, now)
+	default:
+		t.Fatal("timer with past deadline should fire immediately")
+	}
+
+	clock.Advance(time.Second)
+	select {
+	case now := <-t1.C():
+		assert.Equal(start.Add(time.Second), now)
+	default:
+		t.Fatal("timer should fire after advance")
+	}
+
+	assert.Equal(true, t2.Stop())
+	assert.Equal(false, t2.Stop())
+
+	clock.Set(start)
+	assert.Equal(start.Add(time.Second), clock.Now())
+}
+
+func TestSystemClock(t *testing.T) {
+	t.Parallel()
+
+	assert := internal.NewAssert(t, "TestSystemClock")
+
+	clock := SystemClock()
+	timer := clock.TimerAt(clock.Now().Add(10 * time.Millisecond))
+
+	select {
+	case <-timer.C():
+	case <-time.After(time.Second):
+		t.Fatal("timer should fire")
+	}
+	assert.Equal(false, timer.Stop())
+}
@@ -0,0 +1,223 @@
+// Copyright 2023 dudaodong@gmail.com. All rights reserved.
+// Use of this source code is governed by MIT license
+
+// Package datastructure contains some data structure.
+// Queue structure contains ArrayQueue, LinkedQueue, CircularQueue, PriorityQueue, BlockingQueue and DelayQueue.
+package datastructure
+
+import (
+	"context"
+	"sync"
+	"time"
+
+	heap "github.com/duke-git/lancet/v2/datastructure/heap"
+)
+
+// DelayQueue is a thread-safe unbounded queue, an item could only be taken after its deadline.
+// Items are ordered by deadline, the item with the earliest deadline is the head of queue.
+type DelayQueue[T any] struct {
+	mu      sync.Mutex
+	items   *heap.DaryHeap[*delayedItem[T]]
+	clock   Clock
+	closed  bool
+	changed chan struct{}
+}
+
+type delayedItem[T any] struct {
+	value    T
+	deadline time.Time
+}
+
+type deadlineComparator[T any] struct{}
+
+func (c *deadlineComparator[T]) Compare(v1, v2 any) int {
+	d1, d2 := v1.(*delayedItem[T]).deadline, v2.(*delayedItem[T]).deadline
+	if d1.Before(d2) {
+		return -1
+	} else if d1.After(d2) {
+		return 1
+	}
+	return 0
+}
+
+// NewDelayQueue return a empty DelayQueue pointer which uses system clock.
+func NewDelayQueue[T any]() *DelayQueue[T] {
+	return NewDelayQueueWithClock[T](SystemClock())
+}
+
+// NewDelayQueueWithClock return a empty DelayQueue pointer which uses the given clock.
+func NewDelayQueueWithClock[T any](clock Clock) *DelayQueue[T] {
+	return &DelayQueue[T]{
+		items:   heap.NewDaryHeap[*delayedItem[T]](4, &deadlineComparator[T]{}),
+		clock:   clock,
+		changed: make(chan struct{}),
+	}
+}
+
+// Put insert item into the queue, the item will be available after delay.
+// return ErrQueueClosed if the queue is closed.
+func (q *DelayQueue[T]) Put(item T, delay time.Duration) error {
+	return q.PutAt(item, q.clock.Now().Add(delay))
+}
+
+// PutAt insert item into the queue, the item will be available at deadline.
+// return ErrQueueClosed if the queue is closed.
+func (q *DelayQueue[T]) PutAt(item T, deadline time.Time) error {
+	q.mu.Lock()
+	defer q.mu.Unlock()
+
+	if q.closed {
+		return ErrQueueClosed
+	}
+
+	q.items.Push(&delayedItem[T]{value: item, deadline: deadline})
+	q.notify()
+
+	return nil
+}
+
+// Take remove and return the head item of the queue, wait until its deadline is reached.
+// return ctx.Err() if ctx is done before an item is available.
+// Once the queue is closed, return ErrQueueClosed if there is no expired item.
+func (q *DelayQueue[T]) Take(ctx context.Context) (T, error) {
+	var zero T
+
+	q.mu.Lock()
+	for {
+		head, ok := q.items.Peek()
+		if ok && !q.clock.Now().Before(head.deadline) {
+			break
+		}
+		if q.closed {
+			q.mu.Unlock()
+			return zero, ErrQueueClosed
+		}
+
+		var timer Timer
+		if ok {
+			timer = q.clock.TimerAt(head.deadline)
+		}
+		err := q.wait(ctx, timer)
+		if timer != nil {
+			timer.Stop()
+		}
+		if err != nil {
+			return zero, err
+		}
+	}
+
+	head, _ := q.items.Pop()
+	q.notify()
+	q.mu.Unlock()
+
+	return head.value, nil
+}
+
+// Poll remove and return the head item of the queue, wait at most timeout for an expired item.
+// return zero value and false if no item is available. if timeout <= 0, it does not wait.
+func (q *DelayQueue[T]) Poll(timeout time.Duration) (T, bool) {
+	ctx, cancel := timeoutContext(timeout)
+	defer cancel()
+
+	item, err := q.Take(ctx)
+	return item, err == nil
+}
+
+// Peek return the head item of the queue and its deadline without removing it, no matter it is expired or not.
+// if the queue is empty, return zero value and false.
+func (q *DelayQueue[T]) Peek() (T, time.Time, bool) {
+	q.mu.Lock()
+	defer q.mu.Unlock()
+
+	head, ok := q.items.Peek()
+	if !ok {
+		var zero T
+		return zero, time.Time{}, false
+	}
+	return head.value, head.deadline, true
+}
+
+// DrainTo remove at most maxItems expired items from the queue and append them to dst, return the appended slice.
+// if maxItems <= 0, all expired items are removed.
+func (q *DelayQueue[T]) DrainTo(dst []T, maxItems int) []T {
+	q.mu.Lock()
+	defer q.mu.Unlock()
+
+	now := q.clock.Now()
+	n := 0
+	for maxItems <= 0 || n < maxItems {
+		head, ok := q.items.Peek()
+		if !ok || now.Before(head.deadline) {
+			break
+		}
+		q.items.Pop()
+		dst = append(dst, head.value)
+		n++
+	}
+	if n > 0 {
+		q.notify()
+	}
+
+	return dst
+}
+
+// Size return number of items in the queue, including the unexpired ones.
+func (q *DelayQueue[T]) Size() int {
+	q.mu.Lock()
+	defer q.mu.Unlock()
+
+	return q.items.Size()
+}
+
+// IsEmpty checks if the queue is empty or not
+func (q *DelayQueue[T]) IsEmpty() bool {
+	return q.Size() == 0
+}
+
+// Close the queue, Put fails after closed, blocked Take returns ErrQueueClosed if there is no expired item.
+// Close an already closed queue does nothing.
+func (q *DelayQueue[T]) Close() {
+	q.mu.Lock()
+	defer q.mu.Unlock()
+
+	if !q.closed {
+		q.closed = true
+		q.notify()
+	}
+}
+
+// IsClosed checks if the queue is closed or not
+func (q *DelayQueue[T]) IsClosed() bool {
+	q.mu.Lock()
+	defer q.mu.Unlock()
+
+	return q.closed
+}
+
+// notify wake up all waiting goroutines, the caller should hold the lock.
+func (q *DelayQueue[T]) notify() {
+	close(q.changed)
+	q.changed = make(chan struct{})
+}
+
+// wait release the lock and wait until the queue changed, timer fired or ctx is done.
+// if ctx is done, it returns ctx.Err() without holding the lock, or else it returns nil with the lock held.
+func (q *DelayQueue[T]) wait(ctx context.Context, timer Timer) error {
+	changed := q.changed
+	q.mu.Unlock()
+
+	var fired <-chan time.Time
+	if timer != nil {
+		fired = timer.C()
+	}
+
+	select {
+	case <-changed:
+	case <-fired:
+	case <-ctx.Done():
+		return ctx.Err()
+	}
+
+	q.mu.Lock()
+	return nil
+}
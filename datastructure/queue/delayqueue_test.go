@@ -0,0 +1,123 @@
+package datastructure
+
+import (
+	"context"
+	"testing"
+	"time"
+
+	"github.com/duke-git/lancet/v2/internal"
+)
+
+func TestDelayQueue_PutAndTake(t *testing.T) {
+	t.Parallel()
+
+	assert := internal.NewAssert(t, "TestDelayQueue_PutAndTake")
+
+	start := time.Date(2023, 1, 1, 0, 0, 0, 0, time.UTC)
+	clock := NewManualClock(start)
+	q := NewDelayQueueWithClock[string](clock)
+
+	assert.IsNil(q.Put("c", 3*time.Second))
+	assert.IsNil(q.Put("a", time.Second))
+	assert.IsNil(q.PutAt("b", start.Add(2*time.Second)))
+	assert.Equal(3, q.Size())
+
+	head, deadline, ok := q.Peek()
+	assert.Equal("a", head)
+	assert.Equal(start.Add(time.Second), deadline)
+	assert.Equal(true, ok)
+
+	_, ok = q.Poll(0)
+	assert.Equal(false, ok)
+
+	result := make(chan string)
+	go func() {
+		for i := 0; i < 3; i++ {
+			item, err := q.Take(context.Background())
+			assert.IsNil(err)
+			result <- item
+		}
+	}()
+
+	for _, expected := range []string{"a", "b", "c"} {
+		clock.Advance(time.Second)
+		select {
+		case item := <-result:
+			assert.Equal(expected, item)
+		case <-time.After(time.Second):
+			t.Fatalf("item %s should be taken", expected)
+		}
+	}
+
+	assert.Equal(true, q.IsEmpty())
+}
+
+func TestDelayQueue_PutEarlierItem(t *testing.T) {
+	t.Parallel()
+
+	assert := internal.NewAssert(t, "TestDelayQueue_PutEarlierItem")
+
+	q := NewDelayQueue[int]()
+	assert.IsNil(q.Put(1, time.Hour))
+
+	go func() {
+		time.Sleep(10 * time.Millisecond)
+		q.Put(2, 10*time.Millisecond)
+	}()
+
+	ctx, cancel := context.WithTimeout(context.Background(), time.Second)
+	defer cancel()
+
+	item, err := q.Take(ctx)
+	assert.IsNil(err)
+	assert.Equal(2, item)
+}
+
+func TestDelayQueue_DrainTo(t *testing.T) {
+	t.Parallel()
+
+	assert := internal.NewAssert(t, "TestDelayQueue_DrainTo")
+
+	clock := NewManualClock(time.Now())
+	q := NewDelayQueueWithClock[int](clock)
+	for i := 1; i <= 5; i++ {
+		q.Put(i, time.Duration(i)*time.Second)
+	}
+
+	clock.Advance(3 * time.Second)
+	assert.Equal([]int{1, 2}, q.DrainTo(nil, 2))
+	assert.Equal([]int{3}, q.DrainTo(nil, 0))
+	assert.Equal(2, q.Size())
+}
+
+func TestDelayQueue_Close(t *testing.T) {
+	t.Parallel()
+
+	assert := internal.NewAssert(t, "TestDelayQueue_Close")
+
+	clock := NewManualClock(time.Now())
+	q := NewDelayQueueWithClock[int](clock)
+	q.Put(1, 0)
+	q.Put(2, time.Minute)
+
+	errCh := make(chan error)
+	go func() {
+		_, err := q.Take(context.Background())
+		assert.IsNil(err)
+		_, err = q.Take(context.Background())
+		errCh <- err
+	}()
+
+	time.Sleep(10 * time.Millisecond)
+	q.Close()
+
+	assert.Equal(ErrQueueClosed, <-errCh)
+	assert.Equal(true, q.IsClosed())
+	assert.Equal(ErrQueueClosed, q.Put(3, 0))
+	assert.Equal(1, q.Size())
+
+	ctx, cancel := context.WithCancel(context.Background())
+	cancel()
+	_, err := NewDelayQueue[int]().Take(ctx)
+	assert.Equal(context.Canceled, err)
+}
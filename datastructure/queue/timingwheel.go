@@ -0,0 +1,260 @@
+// Copyright 2023 dudaodong@gmail.com. All rights reserved.
+// Use of this source code is governed by MIT license
+
+// Package datastructure contains some data structure.
+// TimingWheel is a hierarchical timing wheel, it schedules a huge number of timer tasks with few timers.
+package datastructure
+
+import (
+	"container/list"
+	"context"
+	"sync"
+	"time"
+)
+
+// TimingWheel is a hierarchical timing wheel.
+// Timer tasks are put into buckets by expiration, and only the buckets are scheduled by a DelayQueue,
+// a task whose delay exceeds the interval of the wheel is put into an overflow wheel with larger tick.
+type TimingWheel struct {
+	mu      sync.Mutex
+	root    *timingWheelLevel
+	queue   *DelayQueue[*timerBucket]
+	clock   Clock
+	cancel  context.CancelFunc
+	done    chan struct{}
+	running bool
+}
+
+// TimerTask is a handle of the function scheduled by TimingWheel.
+type TimerTask struct {
+	expiration int64
+	fn         func()
+	bucket     *timerBucket
+	element    *list.Element
+	wheel      *TimingWheel
+}
+
+// NewTimingWheel return a TimingWheel pointer which uses system clock.
+// param `tick` is the time span of a bucket, param `wheelSize` is the number of buckets of each level.
+func NewTimingWheel(tick time.Duration, wheelSize int) *TimingWheel {
+	return NewTimingWheelWithClock(tick, wheelSize, SystemClock())
+}
+
+// NewTimingWheelWithClock return a TimingWheel pointer which uses the given clock.
+func NewTimingWheelWithClock(tick time.Duration, wheelSize int, clock Clock) *TimingWheel {
+	if tick <= 0 {
+		tick = time.Millisecond
+	}
+	if wheelSize <= 0 {
+		wheelSize = 1
+	}
+
+	queue := NewDelayQueueWithClock[*timerBucket](clock)
+	startTime := clock.Now().UnixNano()
+
+	return &TimingWheel{
+		root:  newTimingWheelLevel(int64(tick), int64(wheelSize), startTime, queue),
+		queue: queue,
+		clock: clock,
+	}
+}
+
+// Start run the timing wheel in a new goroutine, the tasks in the wheel do not run before Start.
+// Start an already started timing wheel does nothing.
+func (tw *TimingWheel) Start() {
+	tw.mu.Lock()
+	defer tw.mu.Unlock()
+
+	if tw.running {
+		return
+	}
+
+	ctx, cancel := context.WithCancel(context.Background())
+	tw.cancel = cancel
+	tw.done = make(chan struct{})
+	tw.running = true
+
+	go tw.run(ctx, tw.done)
+}
+
+// Stop the timing wheel and wait until its goroutine exits, the pending tasks are kept
+// and will be executed if the timing wheel is started again.
+func (tw *TimingWheel) Stop() {
+	tw.mu.Lock()
+	if !tw.running {
+		tw.mu.Unlock()
+		return
+	}
+	tw.running = false
+	cancel, done := tw.cancel, tw.done
+	tw.mu.Unlock()
+
+	cancel()
+	<-done
+}
+
+// Add schedules fn to run in its own goroutine after delay, return a handle which could cancel it.
+// if delay is less than the tick, fn runs as soon as the timing wheel is running.
+func (tw *TimingWheel) Add(delay time.Duration, fn func()) *TimerTask {
+	task := &TimerTask{
+		expiration: tw.clock.Now().Add(delay).UnixNano(),
+		fn:         fn,
+		wheel:      tw,
+	}
+
+	tw.mu.Lock()
+	if !tw.root.add(task) {
+		tw.root.addExpired(task)
+	}
+	tw.mu.Unlock()
+
+	return task
+}
+
+// Cancel prevents the task from running, return false if the task has already run or been cancelled.
+func (t *TimerTask) Cancel() bool {
+	t.wheel.mu.Lock()
+	defer t.wheel.mu.Unlock()
+
+	if t.bucket == nil {
+		return false
+	}
+	t.bucket.remove(t)
+
+	return true
+}
+
+// Expiration return the time when the task is scheduled to run.
+func (t *TimerTask) Expiration() time.Time {
+	return time.Unix(0, t.expiration)
+}
+
+func (tw *TimingWheel) run(ctx context.Context, done chan struct{}) {
+	defer close(done)
+
+	for {
+		bucket, err := tw.queue.Take(ctx)
+		if err != nil {
+			return
+		}
+
+		tw.mu.Lock()
+		tw.root.advanceClock(bucket.expiration)
+		expired := bucket.flush(tw.root.add)
+		tw.mu.Unlock()
+
+		for _, task := range expired {
+			go task.fn()
+		}
+	}
+}
+
+// timingWheelLevel is one level of the hierarchical timing wheel, all fields are guarded by TimingWheel.mu.
+type timingWheelLevel struct {
+	tick        int64
+	wheelSize   int64
+	interval    int64
+	currentTime int64
+	buckets     []*timerBucket
+	queue       *DelayQueue[*timerBucket]
+	overflow    *timingWheelLevel
+}
+
+func newTimingWheelLevel(tick, wheelSize, startTime int64, queue *DelayQueue[*timerBucket]) *timingWheelLevel {
+	buckets := make([]*timerBucket, wheelSize)
+	for i := range buckets {
+		buckets[i] = &timerBucket{expiration: -1, tasks: list.New()}
+	}
+
+	return &timingWheelLevel{
+		tick:        tick,
+		wheelSize:   wheelSize,
+		interval:    tick * wheelSize,
+		currentTime: startTime - startTime%tick,
+		buckets:     buckets,
+		queue:       queue,
+	}
+}
+
+// add put the task into a bucket, return false if the task is already expired.
+func (w *timingWheelLevel) add(task *TimerTask) bool {
+	switch {
+	case task.expiration < w.currentTime+w.tick:
+		return false
+	case task.expiration < w.currentTime+w.interval:
+		virtualID := task.expiration / w.tick
+		bucket := w.buckets[virtualID%w.wheelSize]
+		bucket.add(task)
+
+		expiration := virtualID * w.tick
+		if bucket.expiration != expiration {
+			bucket.expiration = expiration
+			w.queue.PutAt(bucket, time.Unix(0, expiration))
+		}
+		return true
+	default:
+		if w.overflow == nil {
+			w.overflow = newTimingWheelLevel(w.interval, w.wheelSize, w.currentTime, w.queue)
+		}
+		return w.overflow.add(task)
+	}
+}
+
+// addExpired put the expired task into the bucket of current time, so it runs in the next round of the wheel.
+func (w *timingWheelLevel) addExpired(task *TimerTask) {
+	bucket := w.buckets[(w.currentTime/w.tick)%w.wheelSize]
+	bucket.add(task)
+
+	if bucket.expiration != w.currentTime {
+		bucket.expiration = w.currentTime
+		w.queue.PutAt(bucket, time.Unix(0, w.currentTime))
+	}
+}
+
+// advanceClock move current time of the wheel and its overflow wheel forward
+func (w *timingWheelLevel) advanceClock(expiration int64) {
+	if expiration >= w.currentTime+w.tick {
+		w.currentTime = expiration - expiration%w.tick
+		if w.overflow != nil {
+			w.overflow.advanceClock(w.currentTime)
+		}
+	}
+}
+
+// timerBucket is a list of tasks which expire in the same tick.
+type timerBucket struct {
+	expiration int64
+	tasks      *list.List
+}
+
+func (b *timerBucket) add(task *TimerTask) {
+	task.element = b.tasks.PushBack(task)
+	task.bucket = b
+}
+
+func (b *timerBucket) remove(task *TimerTask) {
+	b.tasks.Remove(task.element)
+	task.element = nil
+	task.bucket = nil
+}
+
+// flush remove all tasks from the bucket and re-add them with reinsert,
+// return the tasks which can not be re-added because they are expired.
+func (b *timerBucket) flush(reinsert func(task *TimerTask) bool) []*TimerTask {
+	tasks := make([]*TimerTask, 0, b.tasks.Len())
+	for e := b.tasks.Front(); e != nil; e = e.Next() {
+		tasks = append(tasks, e.Value.(*TimerTask))
+	}
+	b.tasks.Init()
+	b.expiration = -1
+
+	expired := []*TimerTask{}
+	for _, task := range tasks {
+		task.bucket, task.element = nil, nil
+		if !reinsert(task) {
+			expired = append(expired, task)
+		}
+	}
+
+	return expired
+}
@@ -0,0 +1,153 @@
+package datastructure
+
+import (
+	"testing"
+	"time"
+
+	"github.com/duke-git/lancet/v2/internal"
+)
+
+func waitFired(t *testing.T, fired <-chan int, expected int) {
+	select {
+	case v := <-fired:
+		if v != expected {
+			t.Fatalf("expected task %d to fire, got %d", expected, v)
+		}
+	case <-time.After(time.Second):
+		t.Fatalf("task %d should fire", expected)
+	}
+}
+
+func assertNotFired(t *testing.T, fired <-chan int) {
+	select {
+	case v := <-fired:
+		t.Fatalf("task %d should not fire", v)
+	case <-time.After(20 * time.Millisecond):
+	}
+}
+
+func TestTimingWheel_Add(t *testing.T) {
+	t.Parallel()
+
+	start := time.Date(2023, 1, 1, 0, 0, 0, 0, time.UTC)
+	clock := NewManualClock(start)
+
+	tw := NewTimingWheelWithClock(10*time.Millisecond, 8, clock)
+	tw.Start()
+	defer tw.Stop()
+
+	fired := make(chan int, 10)
+	tw.Add(50*time.Millisecond, func() { fired <- 1 })
+	// exceeds interval of the first level, goes into overflow wheels
+	tw.Add(time.Second, func() { fired <- 2 })
+	tw.Add(10*time.Second, func() { fired <- 3 })
+
+	clock.Advance(40 * time.Millisecond)
+	assertNotFired(t, fired)
+
+	clock.Advance(10 * time.Millisecond)
+	waitFired(t, fired, 1)
+
+	clock.Advance(900 * time.Millisecond)
+	assertNotFired(t, fired)
+
+	clock.Advance(50 * time.Millisecond)
+	waitFired(t, fired, 2)
+
+	clock.Advance(9 * time.Second)
+	waitFired(t, fired, 3)
+}
+
+func TestTimingWheel_Cancel(t *testing.T) {
+	t.Parallel()
+
+	assert := internal.NewAssert(t, "TestTimingWheel_Cancel")
+
+	start := time.Date(2023, 1, 1, 0, 0, 0, 0, time.UTC)
+	clock := NewManualClock(start)
+
+	tw := NewTimingWheelWithClock(time.Millisecond, 20, clock)
+	tw.Start()
+	defer tw.Stop()
+
+	fired := make(chan int, 10)
+	task1 := tw.Add(5*time.Millisecond, func() { fired <- 1 })
+	task2 := tw.Add(100*time.Millisecond, func() { fired <- 2 })
+
+	assert.Equal(true, start.Add(100*time.Millisecond).Equal(task2.Expiration()))
+	assert.Equal(true, task2.Cancel())
+	assert.Equal(false, task2.Cancel())
+
+	clock.Advance(200 * time.Millisecond)
+	waitFired(t, fired, 1)
+	assertNotFired(t, fired)
+
+	assert.Equal(false, task1.Cancel())
+}
+
+func TestTimingWheel_ExpiredTask(t *testing.T) {
+	t.Parallel()
+
+	assert := internal.NewAssert(t, "TestTimingWheel_ExpiredTask")
+
+	clock := NewManualClock(time.Now())
+	tw := NewTimingWheelWithClock(time.Second, 10, clock)
+
+	fired := make(chan int, 2)
+	tw.Add(0, func() { fired <- 1 })
+	task := tw.Add(-time.Second, func() { fired <- 2 })
+
+	// the expired tasks do not run before Start
+	assertNotFired(t, fired)
+	assert.Equal(true, task.Cancel())
+
+	tw.Start()
+	waitFired(t, fired, 1)
+	assertNotFired(t, fired)
+
+	// and do not run after Stop
+	tw.Stop()
+	tw.Add(0, func() { fired <- 3 })
+	assertNotFired(t, fired)
+
+	tw.Start()
+	defer tw.Stop()
+	waitFired(t, fired, 3)
+}
+
+func TestTimingWheel_StopAndStart(t *testing.T) {
+	t.Parallel()
+
+	clock := NewManualClock(time.Now())
+	tw := NewTimingWheelWithClock(time.Millisecond, 10, clock)
+	tw.Start()
+	tw.Start()
+
+	fired := make(chan int, 1)
+	tw.Add(5*time.Millisecond, func() { fired <- 1 })
+
+	tw.Stop()
+	tw.Stop()
+
+	clock.Advance(10 * time.Millisecond)
+	assertNotFired(t, fired)
+
+	tw.Start()
+	defer tw.Stop()
+	waitFired(t, fired, 1)
+}
+
+func TestTimingWheel_SystemClock(t *testing.T) {
+	t.Parallel()
+
+	tw := NewTimingWheel(time.Millisecond, 20)
+	tw.Start()
+	defer tw.Stop()
+
+	fired := make(chan int, 2)
+	tw.Add(10*time.Millisecond, func() { fired <- 1 })
+	tw.Add(50*time.Millisecond, func() { fired <- 2 })
+
+	waitFired(t, fired, 1)
+	waitFired(t, fired, 2)
+}
@@ -0,0 +1,1019 @@
+// Copyright 2023 dudaodong@gmail.com. All rights reserved.
+// Use of this source code is governed by MIT license.
+
+package fileutil
+
+import (
+	"archive/tar"
+	"archive/zip"
+	"bufio"
+	"bytes"
+	"compress/gzip"
+	"errors"
+	"fmt"
+	"io"
+	"io/fs"
+	"math"
+	"os"
+	"path"
+	"path/filepath"
+	"strings"
+	"time"
+	"unicode/utf8"
+
+	"golang.org/x/text/encoding/simplifiedchinese"
+	"golang.org/x/text/transform"
+)
+
+// ArchiveFormat is the format of archive.
+type ArchiveFormat string
+
+const (
+	ArchiveZip   ArchiveFormat = "zip"
+	ArchiveTar   ArchiveFormat = "tar"
+	ArchiveTarGz ArchiveFormat = "tar.gz"
+)
+
+// SymlinkPolicy decides how symbolic links are handled when creating and extracting archive.
+type SymlinkPolicy int
+
+const (
+	// SymlinkSkip ignores symbolic links, it is the default policy.
+	SymlinkSkip SymlinkPolicy = iota
+	// SymlinkPreserve stores symbolic links as links. When extracting, the links are restored
+	// only if they point inside the destination directory.
+	SymlinkPreserve
+	// SymlinkFollow stores the files and directories which symbolic links point to.
+	// When extracting, it is the same as SymlinkPreserve.
+	SymlinkFollow
+	// SymlinkReject fails with ErrArchiveSymlink when a symbolic link is met.
+	SymlinkReject
+)
+
+var (
+	// ErrUnsupportedArchive is returned when the archive format is unknown.
+	ErrUnsupportedArchive = errors.New("unsupported archive format")
+	// ErrArchiveTooLarge is returned when the total size of files exceeds Archive.MaxTotalSize.
+	ErrArchiveTooLarge = errors.New("archive exceeds max total size")
+	// ErrArchiveTooManyFiles is returned when the number of entries exceeds Archive.MaxFiles.
+	ErrArchiveTooManyFiles = errors.New("archive exceeds max file count")
+	// ErrUnsafeArchivePath is returned when an entry or link points outside the destination directory.
+	ErrUnsafeArchivePath = errors.New("unsafe path in archive")
+	// ErrArchiveSymlink is returned when a symbolic link is met with SymlinkReject policy.
+	ErrArchiveSymlink = errors.New("symbolic link is not allowed")
+)
+
+// ArchiveProgress is the progress of creating or extracting archive.
+type ArchiveProgress struct {
+	// Name is the name of the entry just processed.
+	Name string
+	// Files is the number of entries processed.
+	Files int
+	// Bytes is the total size of file contents processed.
+	Bytes int64
+}
+
+// Archive creates and extracts zip, tar and tar.gz archives. The zero value is ready to use, eg.
+//
+//	archive := &fileutil.Archive{Exclude: []string{"*.log"}, MaxTotalSize: 1 << 30}
+//	err := archive.Extract("./data.tar.gz", "./data")
+//
+// The modes and modification times of entries are preserved. The setuid, setgid and sticky bits are dropped.
+type Archive struct {
+	// Format is the format of archive. If it is empty, the format is detected by file extension
+	// when creating, and by content when extracting.
+	Format ArchiveFormat
+
+	// Include and Exclude are glob patterns of path.Match filtering the entries. A pattern matches the
+	// slash separated name of entry in archive, or its base name if the pattern has no slash. A pattern
+	// matching a directory matches all entries in it. If Include is empty, all entries are included.
+	Include []string
+	Exclude []string
+
+	// Symlinks is the policy of symbolic links.
+	Symlinks SymlinkPolicy
+
+	// MaxTotalSize limits the total size of file contents, 0 means no limit.
+	MaxTotalSize int64
+	// MaxFiles limits the number of entries, 0 means no limit.
+	MaxFiles int
+	// MaxArchiveSize limits the size of zip read by ExtractFrom, which is buffered to a temporary file.
+	// 0 means it is derived from MaxTotalSize with room for the headers, and no limit if MaxTotalSize is 0 too.
+	MaxArchiveSize int64
+
+	// OnProgress is called after each entry is processed.
+	OnProgress func(progress ArchiveProgress)
+}
+
+// ArchiveFormatOf returns the archive format by the extension of path, or empty string if it is unknown.
+func ArchiveFormatOf(path string) ArchiveFormat {
+	name := strings.ToLower(path)
+
+	switch {
+	case strings.HasSuffix(name, ".zip"):
+		return ArchiveZip
+	case strings.HasSuffix(name, ".tar"):
+		return ArchiveTar
+	case strings.HasSuffix(name, ".tar.gz"), strings.HasSuffix(name, ".tgz"):
+		return ArchiveTarGz
+	}
+
+	return ""
+}
+
+// Create creates archive destPath of srcPath, srcPath could be a single file or a directory.
+// The entries are named relative to the parent directory of srcPath like Zip.
+func (a *Archive) Create(srcPath string, destPath string) error {
+	format := a.Format
+	if format == "" {
+		format = ArchiveFormatOf(destPath)
+	}
+
+	file, err := os.Create(destPath)
+	if err != nil {
+		return err
+	}
+
+	err = a.write(file, format, srcPath, destPath)
+	if closeErr := file.Close(); err == nil {
+		err = closeErr
+	}
+	if err != nil {
+		os.Remove(destPath)
+	}
+
+	return err
+}
+
+// Write writes the archive of srcPath to w, the Format should be set.
+func (a *Archive) Write(w io.Writer, srcPath string) error {
+	return a.write(w, a.Format, srcPath, "")
+}
+
+// Extract extracts archive file archivePath to destPath.
+// If an error is returned, the entries already extracted are kept.
+func (a *Archive) Extract(archivePath string, destPath string) error {
+	file, err := os.Open(archivePath)
+	if err != nil {
+		return err
+	}
+	defer file.Close()
+
+	format := a.Format
+	if format == "" {
+		if format, err = sniffArchiveFormat(bufio.NewReader(file)); err != nil {
+			return err
+		}
+		if _, err = file.Seek(0, io.SeekStart); err != nil {
+			return err
+		}
+	}
+
+	if format == ArchiveZip {
+		info, err := file.Stat()
+		if err != nil {
+			return err
+		}
+		r, err := zip.NewReader(file, info.Size())
+		if err != nil {
+			return err
+		}
+		return a.extract(zipEntries(r), destPath)
+	}
+
+	return a.extractStream(file, format, destPath)
+}
+
+// ExtractFrom extracts archive read from r to destPath. Tar and tar.gz are extracted while reading,
+// zip is buffered to a temporary file first since its directory is at the end, the buffered size is limited by
+// MaxArchiveSize. If an error is returned, the entries already extracted are kept.
+func (a *Archive) ExtractFrom(r io.Reader, destPath string) error {
+	format := a.Format
+	if format == "" {
+		br := bufio.NewReader(r)
+		var err error
+		if format, err = sniffArchiveFormat(br); err != nil {
+			return err
+		}
+		r = br
+	}
+
+	if format != ArchiveZip {
+		return a.extractStream(r, format, destPath)
+	}
+
+	tempFile, err := os.CreateTemp("", "archive-*.zip")
+	if err != nil {
+		return err
+	}
+	defer os.Remove(tempFile.Name())
+	defer tempFile.Close()
+
+	limit := a.zipBufferLimit()
+	if limit > 0 {
+		r = io.LimitReader(r, limit+1)
+	}
+
+	size, err := io.Copy(tempFile, r)
+	if err != nil {
+		return err
+	}
+	if limit > 0 && size > limit {
+		return ErrArchiveTooLarge
+	}
+
+	zr, err := zip.NewReader(tempFile, size)
+	if err != nil {
+		return err
+	}
+
+	return a.extract(zipEntries(zr), destPath)
+}
+
+// zipBufferLimit returns the max size of zip buffered by ExtractFrom, 0 means no limit.
+func (a *Archive) zipBufferLimit() int64 {
+	if a.MaxArchiveSize > 0 {
+		return a.MaxArchiveSize
+	}
+	if a.MaxTotalSize <= 0 || a.MaxTotalSize > math.MaxInt64/4 {
+		return 0
+	}
+	// a stored entry is a little larger than its content, and the headers and directory take extra room
+	return 2*a.MaxTotalSize + 1<<20
+}
+
+func (a *Archive) extractStream(r io.Reader, format ArchiveFormat, destPath string) error {
+	switch format {
+	case ArchiveTar:
+		return a.extract(tarEntries(tar.NewReader(r)), destPath)
+	case ArchiveTarGz:
+		gr, err := gzip.NewReader(r)
+		if err != nil {
+			return err
+		}
+		defer gr.Close()
+		return a.extract(tarEntries(tar.NewReader(gr)), destPath)
+	}
+
+	return fmt.Errorf("%w: %q", ErrUnsupportedArchive, format)
+}
+
+// match reports whether the entry name passes the Include and Exclude patterns.
+func (a *Archive) match(name string) bool {
+	name = strings.Trim(name, "/")
+
+	if matchArchivePatterns(a.Exclude, name) {
+		return false
+	}
+
+	return len(a.Include) == 0 || matchArchivePatterns(a.Include, name)
+}
+
+func matchArchivePatterns(patterns []string, name string) bool {
+	for p := name; p != "." && p != "/" && p != ""; p = path.Dir(p) {
+		for _, pattern := range patterns {
+			if ok, _ := path.Match(pattern, p); ok {
+				return true
+			}
+			if !strings.Contains(pattern, "/") {
+				if ok, _ := path.Match(pattern, path.Base(p)); ok {
+					return true
+				}
+			}
+		}
+	}
+
+	return false
+}
+
+// archiveCounter enforces the limits and reports the progress.
+type archiveCounter struct {
+	archive *Archive
+	files   int
+	bytes   int64
+}
+
+func (c *archiveCounter) addFile() error {
+	c.files++
+	if c.archive.MaxFiles > 0 && c.files > c.archive.MaxFiles {
+		return ErrArchiveTooManyFiles
+	}
+	return nil
+}
+
+// checkSize fails early if the declared size of entry exceeds the limit.
+func (c *archiveCounter) checkSize(size int64) error {
+	if c.archive.MaxTotalSize > 0 && size > c.archive.MaxTotalSize-c.bytes {
+		return ErrArchiveTooLarge
+	}
+	return nil
+}
+
+// reader counts the bytes read from r, the actual size is counted since the declared size may be forged.
+func (c *archiveCounter) reader(r io.Reader) io.Reader {
+	return &archiveCountingReader{counter: c, reader: r}
+}
+
+type archiveCountingReader struct {
+	counter *archiveCounter
+	reader  io.Reader
+}
+
+func (r *archiveCountingReader) Read(p []byte) (int, error) {
+	max := r.counter.archive.MaxTotalSize
+	// read at most one byte more than the limit to detect the excess
+	if max > 0 && int64(len(p)) > max-r.counter.bytes+1 {
+		p = p[:max-r.counter.bytes+1]
+	}
+
+	n, err := r.reader.Read(p)
+	r.counter.bytes += int64(n)
+	if max > 0 && r.counter.bytes > max {
+		return n, ErrArchiveTooLarge
+	}
+
+	return n, err
+}
+
+func (c *archiveCounter) progress(name string) {
+	if c.archive.OnProgress != nil {
+		c.archive.OnProgress(ArchiveProgress{Name: name, Files: c.files, Bytes: c.bytes})
+	}
+}
+
+// archiveEntry is an entry of zip or tar archive.
+type archiveEntry struct {
+	name     string
+	mode     fs.FileMode
+	modTime  time.Time
+	size     int64
+	linkname string
+	hardlink bool
+	open     func() (io.ReadCloser, error)
+}
+
+func zipEntries(r *zip.Reader) func() (*archiveEntry, error) {
+	i := 0
+
+	return func() (*archiveEntry, error) {
+		if i >= len(r.File) {
+			return nil, io.EOF
+		}
+		f := r.File[i]
+		i++
+
+		entry := &archiveEntry{
+			name:    zipEntryName(f),
+			mode:    f.Mode(),
+			modTime: f.Modified,
+			size:    int64(f.UncompressedSize64),
+			open:    f.Open,
+		}
+
+		// the target of symbolic link is the content of entry
+		if entry.mode&fs.ModeSymlink != 0 {
+			rc, err := f.Open()
+			if err != nil {
+				return nil, err
+			}
+			target, err := io.ReadAll(io.LimitReader(rc, 4096))
+			rc.Close()
+			if err != nil {
+				return nil, err
+			}
+			entry.linkname = string(target)
+		}
+
+		return entry, nil
+	}
+}
+
+// zipEntryName decodes the name of zip entry, the name without utf-8 flag which is not valid utf-8
+// is taken as GB18030 encoded.
+func zipEntryName(f *zip.File) string {
+	if f.Flags&0x800 != 0 || utf8.ValidString(f.Name) {
+		return f.Name
+	}
+
+	decoder := transform.NewReader(strings.NewReader(f.Name), simplifiedchinese.GB18030.NewDecoder())
+	content, err := io.ReadAll(decoder)
+	if err != nil {
+		return f.Name
+	}
+
+	return string(content)
+}
+
+func tarEntries(r *tar.Reader) func() (*archiveEntry, error) {
+	return func() (*archiveEntry, error) {
+		header, err := r.Next()
+		if err != nil {
+			return nil, err
+		}
+
+		return &archiveEntry{
+			name:     header.Name,
+			mode:     header.FileInfo().Mode(),
+			modTime:  header.ModTime,
+			size:     header.Size,
+			linkname: header.Linkname,
+			hardlink: header.Typeflag == tar.TypeLink,
+			open: func() (io.ReadCloser, error) {
+				return io.NopCloser(r), nil
+			},
+		}, nil
+	}
+}
+
+// sniffArchiveFormat detects the archive format by the magic number.
+func sniffArchiveFormat(r *bufio.Reader) (ArchiveFormat, error) {
+	buf, _ := r.Peek(512)
+
+	switch {
+	case bytes.HasPrefix(buf, []byte("PK\x03\x04")), bytes.HasPrefix(buf, []byte("PK\x05\x06")):
+		return ArchiveZip, nil
+	case bytes.HasPrefix(buf, []byte{0x1f, 0x8b}):
+		return ArchiveTarGz, nil
+	case len(buf) >= 262 && string(buf[257:262]) == "ustar":
+		return ArchiveTar, nil
+	}
+
+	return "", ErrUnsupportedArchive
+}
+
+// archiveExtractor extracts the entries into root.
+type archiveExtractor struct {
+	archiveCounter
+	root  string
+	dirs  []extractedDir
+	links []string
+}
+
+// extractedDir is the directory whose mode and modification time are restored after extracting.
+type extractedDir struct {
+	path    string
+	mode    fs.FileMode
+	modTime time.Time
+}
+
+func (a *Archive) extract(next func() (*archiveEntry, error), destPath string) error {
+	if err := os.MkdirAll(destPath, os.ModePerm); err != nil {
+		return err
+	}
+
+	root, err := filepath.Abs(destPath)
+	if err != nil {
+		return err
+	}
+	if root, err = filepath.EvalSymlinks(root); err != nil {
+		return err
+	}
+
+	x := &archiveExtractor{archiveCounter: archiveCounter{archive: a}, root: root}
+
+	for {
+		entry, err := next()
+		if err == io.EOF {
+			break
+		}
+		if err != nil {
+			return err
+		}
+
+		if err := x.extractEntry(entry); err != nil {
+			return err
+		}
+	}
+
+	// the later entries may change where the links resolve to, eg. a link through a directory replaced by link,
+	// so check them again and remove the unsafe ones
+	for _, link := range x.links {
+		linkname, err := os.Readlink(link)
+		if err != nil {
+			// replaced by a later entry
+			continue
+		}
+		if err := x.checkLink(link, linkname); err != nil {
+			os.Remove(link)
+			return err
+		}
+	}
+
+	// the modification time of directory is changed by creating its entries, so restore them at last
+	for i := len(x.dirs) - 1; i >= 0; i-- {
+		dir := x.dirs[i]
+		if err := os.Chmod(dir.path, archivePerm(dir.mode)); err != nil {
+			return err
+		}
+		if !dir.modTime.IsZero() {
+			if err := os.Chtimes(dir.path, dir.modTime, dir.modTime); err != nil {
+				return err
+			}
+		}
+	}
+
+	return nil
+}
+
+func (x *archiveExtractor) extractEntry(entry *archiveEntry) error {
+	if !x.archive.match(entry.name) {
+		return nil
+	}
+
+	target, err := x.entryPath(entry.name)
+	if err != nil {
+		return err
+	}
+
+	switch {
+	case entry.mode&fs.ModeSymlink != 0:
+		switch x.archive.Symlinks {
+		case SymlinkSkip:
+			return nil
+		case SymlinkReject:
+			return fmt.Errorf("%w: %s", ErrArchiveSymlink, entry.name)
+		}
+
+		linkname := filepath.FromSlash(entry.linkname)
+		if linkname == "" || filepath.IsAbs(linkname) {
+			return fmt.Errorf("%w: %s -> %s", ErrUnsafeArchivePath, entry.name, entry.linkname)
+		}
+		if err := x.addFile(); err != nil {
+			return err
+		}
+		if err := x.prepare(target); err != nil {
+			return err
+		}
+		if err := x.checkLink(target, linkname); err != nil {
+			return err
+		}
+		if err := os.Symlink(linkname, target); err != nil {
+			return err
+		}
+		x.links = append(x.links, target)
+
+	case entry.hardlink:
+		source, err := x.entryPath(entry.linkname)
+		if err != nil {
+			return err
+		}
+		if err := x.checkResolved(source); err != nil {
+			return err
+		}
+		if err := x.addFile(); err != nil {
+			return err
+		}
+		if err := x.prepare(target); err != nil {
+			return err
+		}
+		if err := os.Link(source, target); err != nil {
+			return err
+		}
+
+	case entry.mode.IsDir():
+		if err := x.addFile(); err != nil {
+			return err
+		}
+		if err := x.checkResolved(target); err != nil {
+			return err
+		}
+		// keep the directory writable until all entries are extracted
+		if err := os.MkdirAll(target, os.ModePerm); err != nil {
+			return err
+		}
+		x.dirs = append(x.dirs, extractedDir{path: target, mode: entry.mode, modTime: entry.modTime})
+
+	case entry.mode.IsRegular():
+		if err := x.addFile(); err != nil {
+			return err
+		}
+		if err := x.checkSize(entry.size); err != nil {
+			return err
+		}
+		if err := x.prepare(target); err != nil {
+			return err
+		}
+		if err := x.writeFile(target, entry); err != nil {
+			return err
+		}
+
+	default:
+		// devices, fifos and sockets are not extracted
+		return nil
+	}
+
+	x.progress(entry.name)
+
+	return nil
+}
+
+func (x *archiveExtractor) writeFile(target string, entry *archiveEntry) error {
+	r, err := entry.open()
+	if err != nil {
+		return err
+	}
+	defer r.Close()
+
+	perm := archivePerm(entry.mode)
+	file, err := os.OpenFile(target, os.O_WRONLY|os.O_CREATE|os.O_TRUNC, perm)
+	if err != nil {
+		return err
+	}
+
+	_, err = io.Copy(file, x.reader(r))
+	if closeErr := file.Close(); err == nil {
+		err = closeErr
+	}
+	if err != nil {
+		return err
+	}
+
+	// the perm of OpenFile is masked by umask and not applied to existing file
+	if err := os.Chmod(target, perm); err != nil {
+		return err
+	}
+	if !entry.modTime.IsZero() {
+		return os.Chtimes(target, entry.modTime, entry.modTime)
+	}
+
+	return nil
+}
+
+// entryPath returns the path of entry in root, the absolute name and the name out of root are rejected.
+func (x *archiveExtractor) entryPath(name string) (string, error) {
+	p := filepath.FromSlash(name)
+	if p == "" || filepath.IsAbs(p) || filepath.VolumeName(p) != "" || strings.HasPrefix(p, string(filepath.Separator)) {
+		return "", fmt.Errorf("%w: %s", ErrUnsafeArchivePath, name)
+	}
+
+	p = filepath.Join(x.root, p)
+	if !isInsideDir(x.root, p) {
+		return "", fmt.Errorf("%w: %s", ErrUnsafeArchivePath, name)
+	}
+
+	return p, nil
+}
+
+// checkResolved checks the existing part of path does not lead out of root through symbolic links.
+func (x *archiveExtractor) checkResolved(p string) error {
+	existing := p
+	for {
+		if _, err := os.Lstat(existing); err == nil {
+			break
+		}
+		parent := filepath.Dir(existing)
+		if parent == existing {
+			return nil
+		}
+		existing = parent
+	}
+
+	resolved, err := filepath.EvalSymlinks(existing)
+	if err != nil {
+		return err
+	}
+	if !isInsideDir(x.root, resolved) {
+		return fmt.Errorf("%w: %s", ErrUnsafeArchivePath, p)
+	}
+
+	return nil
+}
+
+// checkLink checks the symbolic link at target with linkname resolves inside root.
+func (x *archiveExtractor) checkLink(target, linkname string) error {
+	resolved, err := resolveLink(filepath.Dir(target), linkname)
+	if err != nil || !isInsideDir(x.root, resolved) {
+		return fmt.Errorf("%w: %s -> %s", ErrUnsafeArchivePath, target, linkname)
+	}
+	return nil
+}
+
+// resolveLink resolves linkname relative to dir component by component like the os does, the existing
+// symbolic links are followed before "..", eg. with link e -> ".", "e/e/.." is the parent of dir rather than dir
+// as filepath.Join cleans it lexically. The part which does not exist yet is joined lexically.
+func resolveLink(dir, linkname string) (string, error) {
+	resolved, err := filepath.EvalSymlinks(dir)
+	if err != nil {
+		return "", err
+	}
+
+	parts := strings.Split(filepath.ToSlash(linkname), "/")
+	for i, part := range parts {
+		switch part {
+		case "", ".":
+		case "..":
+			resolved = filepath.Dir(resolved)
+		default:
+			next := filepath.Join(resolved, part)
+			if _, err := os.Lstat(next); err != nil {
+				return filepath.Join(append([]string{resolved}, parts[i:]...)...), nil
+			}
+			if resolved, err = filepath.EvalSymlinks(next); err != nil {
+				return "", err
+			}
+		}
+	}
+
+	return resolved, nil
+}
+
+// prepare creates the parent directory of target, and removes the existing link at target,
+// so the new entry is not written through it.
+func (x *archiveExtractor) prepare(target string) error {
+	dir := filepath.Dir(target)
+	if err := x.checkResolved(dir); err != nil {
+		return err
+	}
+	if err := os.MkdirAll(dir, os.ModePerm); err != nil {
+		return err
+	}
+
+	if info, err := os.Lstat(target); err == nil && info.Mode()&fs.ModeSymlink != 0 {
+		return os.Remove(target)
+	}
+
+	return nil
+}
+
+func isInsideDir(root, p string) bool {
+	rel, err := filepath.Rel(root, p)
+	return err == nil && rel != ".." && !strings.HasPrefix(rel, ".."+string(filepath.Separator))
+}
+
+// archivePerm returns the permission bits of mode, or the default one if it is not set.
+func archivePerm(mode fs.FileMode) fs.FileMode {
+	perm := mode.Perm()
+	if perm != 0 {
+		return perm
+	}
+	if mode.IsDir() {
+		return 0755
+	}
+	return 0644
+}
+
+// archiveWriter writes entries of zip or tar archive.
+type archiveWriter interface {
+	writeEntry(entry *archiveEntry, r io.Reader) error
+	Close() error
+}
+
+func newArchiveWriter(w io.Writer, format ArchiveFormat) (archiveWriter, error) {
+	switch format {
+	case ArchiveZip:
+		return &zipArchiveWriter{zip.NewWriter(w)}, nil
+	case ArchiveTar:
+		return &tarArchiveWriter{writer: tar.NewWriter(w)}, nil
+	case ArchiveTarGz:
+		gw := gzip.NewWriter(w)
+		return &tarArchiveWriter{writer: tar.NewWriter(gw), gzip: gw}, nil
+	}
+
+	return nil, fmt.Errorf("%w: %q", ErrUnsupportedArchive, format)
+}
+
+type zipArchiveWriter struct {
+	writer *zip.Writer
+}
+
+func (w *zipArchiveWriter) writeEntry(entry *archiveEntry, r io.Reader) error {
+	header := &zip.FileHeader{
+		Name:     entry.name,
+		Method:   zip.Deflate,
+		Modified: entry.modTime,
+	}
+	header.SetMode(entry.mode)
+
+	if entry.mode.IsDir() {
+		header.Name += "/"
+		header.Method = zip.Store
+	}
+	if entry.mode&fs.ModeSymlink != 0 {
+		r = strings.NewReader(entry.linkname)
+	}
+
+	fw, err := w.writer.CreateHeader(header)
+	if err != nil {
+		return err
+	}
+	if r != nil {
+		_, err = io.Copy(fw, r)
+	}
+
+	return err
+}
+
+func (w *zipArchiveWriter) Close() error {
+	return w.writer.Close()
+}
+
+type tarArchiveWriter struct {
+	writer *tar.Writer
+	gzip   *gzip.Writer
+}
+
+func (w *tarArchiveWriter) writeEntry(entry *archiveEntry, r io.Reader) error {
+	header := &tar.Header{
+		Typeflag: tar.TypeReg,
+		Name:     entry.name,
+		Mode:     int64(entry.mode.Perm()),
+		ModTime:  entry.modTime,
+		Size:     entry.size,
+	}
+
+	switch {
+	case entry.mode.IsDir():
+		header.Typeflag = tar.TypeDir
+		header.Name += "/"
+		header.Size = 0
+	case entry.mode&fs.ModeSymlink != 0:
+		header.Typeflag = tar.TypeSymlink
+		header.Linkname = entry.linkname
+		header.Size = 0
+	}
+
+	if err := w.writer.WriteHeader(header); err != nil {
+		return err
+	}
+	if r != nil && header.Typeflag == tar.TypeReg {
+		if _, err := io.Copy(w.writer, r); err != nil {
+			return err
+		}
+	}
+
+	return nil
+}
+
+func (w *tarArchiveWriter) Close() error {
+	err := w.writer.Close()
+	if w.gzip != nil {
+		if gzipErr := w.gzip.Close(); err == nil {
+			err = gzipErr
+		}
+	}
+	return err
+}
+
+// archiveCreator walks the files and writes them to archive.
+type archiveCreator struct {
+	archiveCounter
+	writer archiveWriter
+	// dest is the archive file being written, it is skipped if it is in srcPath
+	dest fs.FileInfo
+	// visiting is the resolved directories being walked with SymlinkFollow, to break cycles
+	visiting map[string]bool
+}
+
+func (a *Archive) write(w io.Writer, format ArchiveFormat, srcPath string, destPath string) error {
+	aw, err := newArchiveWriter(w, format)
+	if err != nil {
+		return err
+	}
+
+	c := &archiveCreator{
+		archiveCounter: archiveCounter{archive: a},
+		writer:         aw,
+		visiting:       map[string]bool{},
+	}
+	if destPath != "" {
+		c.dest, _ = os.Stat(destPath)
+	}
+
+	absPath, err := filepath.Abs(srcPath)
+	if err != nil {
+		return err
+	}
+	name := filepath.Base(absPath)
+	if name == string(filepath.Separator) {
+		name = ""
+	}
+
+	info, err := os.Lstat(absPath)
+	if err == nil {
+		err = c.add(absPath, name, info)
+	}
+	if closeErr := aw.Close(); err == nil {
+		err = closeErr
+	}
+
+	return err
+}
+
+func (c *archiveCreator) add(filePath, name string, info fs.FileInfo) error {
+	// the excluded directory is not walked
+	if matchArchivePatterns(c.archive.Exclude, name) {
+		return nil
+	}
+	if c.dest != nil && os.SameFile(info, c.dest) {
+		return nil
+	}
+
+	entry := &archiveEntry{name: name, mode: info.Mode(), modTime: info.ModTime()}
+
+	if info.Mode()&fs.ModeSymlink != 0 {
+		switch c.archive.Symlinks {
+		case SymlinkSkip:
+			return nil
+		case SymlinkReject:
+			return fmt.Errorf("%w: %s", ErrArchiveSymlink, filePath)
+		case SymlinkPreserve:
+			target, err := os.Readlink(filePath)
+			if err != nil {
+				return err
+			}
+			entry.linkname = filepath.ToSlash(target)
+			return c.writeEntry(entry, nil)
+		}
+
+		followed, err := os.Stat(filePath)
+		if err != nil {
+			return err
+		}
+		info = followed
+		entry.mode = info.Mode()
+		entry.modTime = info.ModTime()
+	}
+
+	switch {
+	case info.IsDir():
+		return c.addDir(filePath, entry)
+	case info.Mode().IsRegular():
+		entry.size = info.Size()
+		file, err := os.Open(filePath)
+		if err != nil {
+			return err
+		}
+		defer file.Close()
+		return c.writeEntry(entry, file)
+	}
+
+	// devices, fifos and sockets are not archived
+	return nil
+}
+
+func (c *archiveCreator) addDir(dirPath string, entry *archiveEntry) error {
+	if c.archive.Symlinks == SymlinkFollow {
+		resolved, err := filepath.EvalSymlinks(dirPath)
+		if err != nil {
+			return err
+		}
+		if c.visiting[resolved] {
+			return nil
+		}
+		c.visiting[resolved] = true
+		defer delete(c.visiting, resolved)
+	}
+
+	if entry.name != "" {
+		if err := c.writeEntry(entry, nil); err != nil {
+			return err
+		}
+	}
+
+	files, err := os.ReadDir(dirPath)
+	if err != nil {
+		return err
+	}
+
+	for _, file := range files {
+		info, err := file.Info()
+		if err != nil {
+			return err
+		}
+		name := file.Name()
+		if entry.name != "" {
+			name = entry.name + "/" + name
+		}
+		if err := c.add(filepath.Join(dirPath, file.Name()), name, info); err != nil {
+			return err
+		}
+	}
+
+	return nil
+}
+
+// writeEntry writes the entry passing Include patterns to archive.
+func (c *archiveCreator) writeEntry(entry *archiveEntry, r io.Reader) error {
+	if !c.archive.match(entry.name) {
+		return nil
+	}
+
+	if err := c.addFile(); err != nil {
+		return err
+	}
+	if err := c.checkSize(entry.size); err != nil {
+		return err
+	}
+
+	if r != nil {
+		r = c.reader(r)
+	}
+
+	if err := c.writer.writeEntry(entry, r); err != nil {
+		return err
+	}
+
+	c.progress(entry.name)
+
+	return nil
+}
@@ -0,0 +1,440 @@
+package fileutil
+
+import (
+	"archive/tar"
+	"archive/zip"
+	"bytes"
+	"errors"
+	"io"
+	"io/fs"
+	"os"
+	"path/filepath"
+	"runtime"
+	"strings"
+	"testing"
+	"time"
+
+	"github.com/duke-git/lancet/v2/internal"
+	"golang.org/x/text/encoding/simplifiedchinese"
+)
+
+// createArchiveSource creates the directory tree to be archived in dir.
+func createArchiveSource(t *testing.T, dir string) (string, time.Time) {
+	src := filepath.Join(dir, "src")
+	modTime := time.Date(2020, 1, 2, 3, 4, 5, 0, time.UTC)
+
+	files := map[string]string{
+		"a.txt":         "hello",
+		"run.sh":        "#!/bin/sh",
+		"sub/b.txt":     "world",
+		"sub/c.log":     "log",
+		"sub/deep/d.go": "package deep",
+	}
+	for name, content := range files {
+		p := filepath.Join(src, filepath.FromSlash(name))
+		if err := os.MkdirAll(filepath.Dir(p), 0755); err != nil {
+			t.Fatal(err)
+		}
+		if err := os.WriteFile(p, []byte(content), 0644); err != nil {
+			t.Fatal(err)
+		}
+		if err := os.Chtimes(p, modTime, modTime); err != nil {
+			t.Fatal(err)
+		}
+	}
+	if err := os.Chmod(filepath.Join(src, "run.sh"), 0750); err != nil {
+		t.Fatal(err)
+	}
+
+	return src, modTime
+}
+
+func writeTestTar(t *testing.T, headers []*tar.Header, contents []string) *bytes.Buffer {
+	buf := &bytes.Buffer{}
+	w := tar.NewWriter(buf)
+	for i, header := range headers {
+		header.Size = int64(len(contents[i]))
+		if err := w.WriteHeader(header); err != nil {
+			t.Fatal(err)
+		}
+		if _, err := w.Write([]byte(contents[i])); err != nil {
+			t.Fatal(err)
+		}
+	}
+	if err := w.Close(); err != nil {
+		t.Fatal(err)
+	}
+	return buf
+}
+
+func TestArchiveCreateAndExtract(t *testing.T) {
+	t.Parallel()
+	assert := internal.NewAssert(t, "TestArchiveCreateAndExtract")
+
+	dir := t.TempDir()
+	src, modTime := createArchiveSource(t, dir)
+
+	for _, name := range []string{"test.zip", "test.tar", "test.tar.gz", "test.tgz"} {
+		archivePath := filepath.Join(dir, name)
+		dest := filepath.Join(dir, "out-"+name)
+
+		archive := &Archive{}
+		assert.IsNil(archive.Create(src, archivePath))
+		assert.IsNil(archive.Extract(archivePath, dest))
+
+		content, err := os.ReadFile(filepath.Join(dest, "src", "sub", "deep", "d.go"))
+		assert.IsNil(err)
+		assert.Equal("package deep", string(content))
+
+		info, err := os.Stat(filepath.Join(dest, "src", "a.txt"))
+		assert.IsNil(err)
+		assert.Equal(true, info.ModTime().Equal(modTime))
+
+		if runtime.GOOS != "windows" {
+			info, err = os.Stat(filepath.Join(dest, "src", "run.sh"))
+			assert.IsNil(err)
+			assert.Equal(fs.FileMode(0750), info.Mode().Perm())
+		}
+	}
+
+	// single file
+	archivePath := filepath.Join(dir, "single.tar")
+	archive := &Archive{}
+	assert.IsNil(archive.Create(filepath.Join(src, "a.txt"), archivePath))
+	assert.IsNil(archive.Extract(archivePath, filepath.Join(dir, "single")))
+	assert.Equal(true, IsExist(filepath.Join(dir, "single", "a.txt")))
+
+	// unknown format
+	err := archive.Create(src, filepath.Join(dir, "test.rar"))
+	assert.Equal(true, errors.Is(err, ErrUnsupportedArchive))
+	assert.Equal(false, IsExist(filepath.Join(dir, "test.rar")))
+	err = archive.Extract(filepath.Join(src, "a.txt"), filepath.Join(dir, "unknown"))
+	assert.Equal(true, errors.Is(err, ErrUnsupportedArchive))
+}
+
+func TestArchiveCreateInSource(t *testing.T) {
+	t.Parallel()
+	assert := internal.NewAssert(t, "TestArchiveCreateInSource")
+
+	dir := t.TempDir()
+	src, _ := createArchiveSource(t, dir)
+
+	archivePath := filepath.Join(src, "self.zip")
+	archive := &Archive{}
+	assert.IsNil(archive.Create(src, archivePath))
+
+	dest := filepath.Join(dir, "out")
+	assert.IsNil(archive.Extract(archivePath, dest))
+	assert.Equal(true, IsExist(filepath.Join(dest, "src", "a.txt")))
+	assert.Equal(false, IsExist(filepath.Join(dest, "src", "self.zip")))
+}
+
+func TestArchiveIncludeExclude(t *testing.T) {
+	t.Parallel()
+	assert := internal.NewAssert(t, "TestArchiveIncludeExclude")
+
+	dir := t.TempDir()
+	src, _ := createArchiveSource(t, dir)
+
+	archivePath := filepath.Join(dir, "test.tar.gz")
+	archive := &Archive{Exclude: []string{"*.log", "src/sub/deep"}}
+	assert.IsNil(archive.Create(src, archivePath))
+
+	dest := filepath.Join(dir, "out")
+	assert.IsNil((&Archive{}).Extract(archivePath, dest))
+	assert.Equal(true, IsExist(filepath.Join(dest, "src", "sub", "b.txt")))
+	assert.Equal(false, IsExist(filepath.Join(dest, "src", "sub", "c.log")))
+	assert.Equal(false, IsExist(filepath.Join(dest, "src", "sub", "deep")))
+
+	dest = filepath.Join(dir, "out-include")
+	archive = &Archive{Include: []string{"*.txt"}, Exclude: []string{"a.txt"}}
+	assert.IsNil(archive.Extract(archivePath, dest))
+	assert.Equal(true, IsExist(filepath.Join(dest, "src", "sub", "b.txt")))
+	assert.Equal(false, IsExist(filepath.Join(dest, "src", "a.txt")))
+	assert.Equal(false, IsExist(filepath.Join(dest, "src", "run.sh")))
+
+	dest = filepath.Join(dir, "out-dir")
+	archive = &Archive{Include: []string{"src/sub"}}
+	assert.IsNil(archive.Extract(archivePath, dest))
+	assert.Equal(true, IsExist(filepath.Join(dest, "src", "sub", "b.txt")))
+	assert.Equal(false, IsExist(filepath.Join(dest, "src", "a.txt")))
+}
+
+func TestArchiveSymlinks(t *testing.T) {
+	if runtime.GOOS == "windows" {
+		t.Skip("symbolic link requires privilege on windows")
+	}
+	t.Parallel()
+	assert := internal.NewAssert(t, "TestArchiveSymlinks")
+
+	dir := t.TempDir()
+	src, _ := createArchiveSource(t, dir)
+	assert.IsNil(os.Symlink("a.txt", filepath.Join(src, "link.txt")))
+	assert.IsNil(os.Symlink("sub", filepath.Join(src, "linkdir")))
+	assert.IsNil(os.Symlink("..", filepath.Join(src, "sub", "cycle")))
+
+	for _, name := range []string{"test.zip", "test.tar"} {
+		archivePath := filepath.Join(dir, name)
+
+		// skip
+		dest := filepath.Join(dir, "skip-"+name)
+		assert.IsNil((&Archive{}).Create(src, archivePath))
+		assert.IsNil((&Archive{Symlinks: SymlinkPreserve}).Extract(archivePath, dest))
+		assert.Equal(false, IsExist(filepath.Join(dest, "src", "link.txt")))
+
+		// preserve
+		dest = filepath.Join(dir, "preserve-"+name)
+		archive := &Archive{Symlinks: SymlinkPreserve}
+		assert.IsNil(archive.Create(src, archivePath))
+		assert.IsNil(archive.Extract(archivePath, dest))
+		assert.Equal(true, IsLink(filepath.Join(dest, "src", "link.txt")))
+		content, err := os.ReadFile(filepath.Join(dest, "src", "linkdir", "b.txt"))
+		assert.IsNil(err)
+		assert.Equal("world", string(content))
+
+		err = (&Archive{Symlinks: SymlinkReject}).Extract(archivePath, filepath.Join(dir, "reject-"+name))
+		assert.Equal(true, errors.Is(err, ErrArchiveSymlink))
+
+		// follow
+		dest = filepath.Join(dir, "follow-"+name)
+		archive = &Archive{Symlinks: SymlinkFollow}
+		assert.IsNil(archive.Create(src, archivePath))
+		assert.IsNil(archive.Extract(archivePath, dest))
+		assert.Equal(false, IsLink(filepath.Join(dest, "src", "link.txt")))
+		content, err = os.ReadFile(filepath.Join(dest, "src", "link.txt"))
+		assert.IsNil(err)
+		assert.Equal("hello", string(content))
+		assert.Equal(true, IsExist(filepath.Join(dest, "src", "linkdir", "deep", "d.go")))
+	}
+
+	err := (&Archive{Symlinks: SymlinkReject}).Create(src, filepath.Join(dir, "reject.zip"))
+	assert.Equal(true, errors.Is(err, ErrArchiveSymlink))
+
+	// the chained links are inside lexically, but d resolves to the parent of destination
+	secret := filepath.Join(dir, "secret")
+	assert.IsNil(os.WriteFile(secret, []byte("secret"), 0644))
+
+	chained := []*tar.Header{
+		{Name: "e", Typeflag: tar.TypeSymlink, Linkname: "."},
+		{Name: "d", Typeflag: tar.TypeSymlink, Linkname: "e/e/.."},
+	}
+	dest := filepath.Join(dir, "out")
+	err = (&Archive{Format: ArchiveTar, Symlinks: SymlinkPreserve}).ExtractFrom(writeTestTar(t, chained, []string{"", ""}), dest)
+	assert.Equal(true, errors.Is(err, ErrUnsafeArchivePath))
+	_, err = os.ReadFile(filepath.Join(dest, "d", "secret"))
+	assert.IsNotNil(err)
+
+	// x does not exist when d is created, the later link x makes d resolve out of destination
+	later := []*tar.Header{
+		{Name: "d", Typeflag: tar.TypeSymlink, Linkname: "x/.."},
+		{Name: "x", Typeflag: tar.TypeSymlink, Linkname: "."},
+	}
+	dest = filepath.Join(dir, "out-later")
+	err = (&Archive{Format: ArchiveTar, Symlinks: SymlinkPreserve}).ExtractFrom(writeTestTar(t, later, []string{"", ""}), dest)
+	assert.Equal(true, errors.Is(err, ErrUnsafeArchivePath))
+	assert.Equal(false, IsLink(filepath.Join(dest, "d")))
+}
+
+func TestArchiveUnsafePath(t *testing.T) {
+	if runtime.GOOS == "windows" {
+		t.Skip("symbolic link requires privilege on windows")
+	}
+	t.Parallel()
+	assert := internal.NewAssert(t, "TestArchiveUnsafePath")
+
+	dir := t.TempDir()
+	archive := &Archive{Format: ArchiveTar, Symlinks: SymlinkPreserve}
+
+	cases := []struct {
+		headers  []*tar.Header
+		contents []string
+	}{
+		{
+			headers:  []*tar.Header{{Name: "../evil.txt", Typeflag: tar.TypeReg, Mode: 0644}},
+			contents: []string{"evil"},
+		},
+		{
+			headers:  []*tar.Header{{Name: "/evil.txt", Typeflag: tar.TypeReg, Mode: 0644}},
+			contents: []string{"evil"},
+		},
+		{
+			headers:  []*tar.Header{{Name: "link", Typeflag: tar.TypeSymlink, Linkname: "../"}},
+			contents: []string{""},
+		},
+		{
+			headers:  []*tar.Header{{Name: "link", Typeflag: tar.TypeSymlink, Linkname: "/etc/passwd"}},
+			contents: []string{""},
+		},
+		{
+			headers:  []*tar.Header{{Name: "link", Typeflag: tar.TypeLink, Linkname: "../evil.txt"}},
+			contents: []string{""},
+		},
+		{
+			// the links are inside lexically, but b resolves to the parent of destination
+			headers: []*tar.Header{
+				{Name: "a", Typeflag: tar.TypeSymlink, Linkname: "."},
+				{Name: "b", Typeflag: tar.TypeSymlink, Linkname: "a/.."},
+				{Name: "b/evil.txt", Typeflag: tar.TypeReg, Mode: 0644},
+			},
+			contents: []string{"", "", "evil"},
+		},
+	}
+
+	for i, c := range cases {
+		dest := filepath.Join(dir, "out", strings.Repeat("x", i+1))
+		err := archive.ExtractFrom(writeTestTar(t, c.headers, c.contents), dest)
+		assert.Equal(true, errors.Is(err, ErrUnsafeArchivePath))
+	}
+
+	assert.Equal(false, IsExist(filepath.Join(dir, "out", "evil.txt")))
+	assert.Equal(false, IsExist(filepath.Join(dir, "evil.txt")))
+}
+
+func TestArchiveLimits(t *testing.T) {
+	t.Parallel()
+	assert := internal.NewAssert(t, "TestArchiveLimits")
+
+	dir := t.TempDir()
+
+	// a small zip of large file
+	buf := &bytes.Buffer{}
+	w := zip.NewWriter(buf)
+	f, err := w.Create("zeros")
+	assert.IsNil(err)
+	_, err = f.Write(make([]byte, 1<<20))
+	assert.IsNil(err)
+	assert.IsNil(w.Close())
+	zipPath := filepath.Join(dir, "bomb.zip")
+	assert.IsNil(os.WriteFile(zipPath, buf.Bytes(), 0644))
+
+	err = (&Archive{MaxTotalSize: 1 << 10}).Extract(zipPath, filepath.Join(dir, "out1"))
+	assert.Equal(true, errors.Is(err, ErrArchiveTooLarge))
+	assert.IsNil((&Archive{MaxTotalSize: 1 << 20}).Extract(zipPath, filepath.Join(dir, "out2")))
+
+	// the declared size is checked first, the actual size is counted while copying
+	counter := &archiveCounter{archive: &Archive{MaxTotalSize: 10}}
+	assert.IsNotNil(counter.checkSize(11))
+	_, err = bytes.NewBuffer(nil).ReadFrom(counter.reader(strings.NewReader(strings.Repeat("x", 11))))
+	assert.Equal(true, errors.Is(err, ErrArchiveTooLarge))
+	assert.Equal(int64(11), counter.bytes)
+
+	src, _ := createArchiveSource(t, dir)
+	tarPath := filepath.Join(dir, "test.tar")
+	assert.IsNil((&Archive{}).Create(src, tarPath))
+
+	err = (&Archive{MaxFiles: 3}).Extract(tarPath, filepath.Join(dir, "out3"))
+	assert.Equal(true, errors.Is(err, ErrArchiveTooManyFiles))
+	err = (&Archive{MaxFiles: 3}).Create(src, filepath.Join(dir, "limited.tar"))
+	assert.Equal(true, errors.Is(err, ErrArchiveTooManyFiles))
+	assert.IsNil((&Archive{MaxFiles: 8}).Extract(tarPath, filepath.Join(dir, "out4")))
+}
+
+// zeroReader is an endless reader of zero bytes
+type zeroReader struct{}
+
+func (zeroReader) Read(p []byte) (int, error) {
+	for i := range p {
+		p[i] = 0
+	}
+	return len(p), nil
+}
+
+func TestArchiveExtractFromZipLimit(t *testing.T) {
+	t.Parallel()
+	assert := internal.NewAssert(t, "TestArchiveExtractFromZipLimit")
+
+	dir := t.TempDir()
+	endless := func() io.Reader {
+		return io.MultiReader(strings.NewReader("PK\x03\x04"), zeroReader{})
+	}
+
+	err := (&Archive{MaxArchiveSize: 1 << 20}).ExtractFrom(endless(), filepath.Join(dir, "out1"))
+	assert.Equal(true, errors.Is(err, ErrArchiveTooLarge))
+
+	// derived from MaxTotalSize
+	err = (&Archive{MaxTotalSize: 1 << 10}).ExtractFrom(endless(), filepath.Join(dir, "out2"))
+	assert.Equal(true, errors.Is(err, ErrArchiveTooLarge))
+	assert.Equal(false, IsExist(filepath.Join(dir, "out2")))
+
+	src, _ := createArchiveSource(t, dir)
+	buf := &bytes.Buffer{}
+	assert.IsNil((&Archive{Format: ArchiveZip}).Write(buf, src))
+	size := int64(buf.Len())
+
+	err = (&Archive{MaxArchiveSize: size - 1}).ExtractFrom(bytes.NewReader(buf.Bytes()), filepath.Join(dir, "out3"))
+	assert.Equal(true, errors.Is(err, ErrArchiveTooLarge))
+	assert.IsNil((&Archive{MaxArchiveSize: size}).ExtractFrom(bytes.NewReader(buf.Bytes()), filepath.Join(dir, "out4")))
+	assert.IsNil((&Archive{MaxTotalSize: 100}).ExtractFrom(bytes.NewReader(buf.Bytes()), filepath.Join(dir, "out5")))
+}
+
+func TestArchiveExtractFrom(t *testing.T) {
+	t.Parallel()
+	assert := internal.NewAssert(t, "TestArchiveExtractFrom")
+
+	dir := t.TempDir()
+	src, _ := createArchiveSource(t, dir)
+
+	for _, format := range []ArchiveFormat{ArchiveZip, ArchiveTar, ArchiveTarGz} {
+		buf := &bytes.Buffer{}
+		assert.IsNil((&Archive{Format: format}).Write(buf, src))
+
+		var progress []ArchiveProgress
+		archive := &Archive{
+			OnProgress: func(p ArchiveProgress) {
+				progress = append(progress, p)
+			},
+		}
+
+		dest := filepath.Join(dir, "out-"+string(format))
+		assert.IsNil(archive.ExtractFrom(buf, dest))
+		assert.Equal(true, IsExist(filepath.Join(dest, "src", "sub", "b.txt")))
+
+		// 3 directories and 5 files
+		assert.Equal(8, len(progress))
+		assert.Equal(8, progress[7].Files)
+		assert.Equal(int64(len("hello#!/bin/shworldlogpackage deep")), progress[7].Bytes)
+	}
+
+	err := (&Archive{}).Write(&bytes.Buffer{}, src)
+	assert.Equal(true, errors.Is(err, ErrUnsupportedArchive))
+	err = (&Archive{}).ExtractFrom(strings.NewReader("not an archive"), dir)
+	assert.Equal(true, errors.Is(err, ErrUnsupportedArchive))
+}
+
+func TestUnZipEntryName(t *testing.T) {
+	t.Parallel()
+	assert := internal.NewAssert(t, "TestUnZipEntryName")
+
+	dir := t.TempDir()
+
+	gbName, err := simplifiedchinese.GB18030.NewEncoder().String("中文.txt")
+	assert.IsNil(err)
+
+	buf := &bytes.Buffer{}
+	w := zip.NewWriter(buf)
+	// utf-8 name without utf-8 flag is kept
+	_, err = w.CreateHeader(&zip.FileHeader{Name: "英文.txt", NonUTF8: true})
+	assert.IsNil(err)
+	_, err = w.CreateHeader(&zip.FileHeader{Name: gbName, NonUTF8: true})
+	assert.IsNil(err)
+	assert.IsNil(w.Close())
+
+	zipPath := filepath.Join(dir, "names.zip")
+	assert.IsNil(os.WriteFile(zipPath, buf.Bytes(), 0644))
+
+	dest := filepath.Join(dir, "out")
+	assert.IsNil(UnZip(zipPath, dest))
+	assert.Equal(true, IsExist(filepath.Join(dest, "中文.txt")))
+	assert.Equal(true, IsExist(filepath.Join(dest, "英文.txt")))
+}
+
+func TestArchiveFormatOf(t *testing.T) {
+	t.Parallel()
+	assert := internal.NewAssert(t, "TestArchiveFormatOf")
+
+	assert.Equal(ArchiveZip, ArchiveFormatOf("a.ZIP"))
+	assert.Equal(ArchiveTar, ArchiveFormatOf("a.tar"))
+	assert.Equal(ArchiveTarGz, ArchiveFormatOf("a.tar.gz"))
+	assert.Equal(ArchiveTarGz, ArchiveFormatOf("a.tgz"))
+	assert.Equal(ArchiveFormat(""), ArchiveFormatOf("a.gz"))
+}
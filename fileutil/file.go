@@ -8,9 +8,7 @@ import (
 	"archive/zip"
 	"bufio"
 	"bytes"
-	"crypto/sha1"
-	"crypto/sha256"
-	"crypto/sha512"
+	"crypto"
 	"encoding/csv"
 	"errors"
 	"fmt"
@@ -24,9 +22,8 @@ import (
 	"strings"
 	"sync"
 
+	"github.com/duke-git/lancet/v2/cryptor"
 	"github.com/duke-git/lancet/v2/validator"
-	"golang.org/x/text/encoding/simplifiedchinese"
-	"golang.org/x/text/transform"
 )
 
 // FileReader is a reader supporting offset seeking and reading one
@@ -459,59 +456,12 @@ func addFileToArchive2(w *zip.Writer, basePath, baseInZip string) error {
 }
 
 // UnZip unzip the file and save it to destPath.
+// The entry names without utf-8 flag which are not valid utf-8 are decoded as GB18030.
+// Use Archive to filter the entries and limit the extracted size.
 // Play: https://go.dev/play/p/g0w34kS7B8m
 func UnZip(zipFile string, destPath string) error {
-	zipReader, err := zip.OpenReader(zipFile)
-	if err != nil {
-		return err
-	}
-	defer zipReader.Close()
-
-	for _, f := range zipReader.File {
-		decodeName := f.Name
-		if f.Flags == 0 {
-			i := bytes.NewReader([]byte(f.Name))
-			decoder := transform.NewReader(i, simplifiedchinese.GB18030.NewDecoder())
-			content, _ := io.ReadAll(decoder)
-			decodeName = string(content)
-		}
-		// issue#62: fix ZipSlip bug
-		path, err := safeFilepathJoin(destPath, decodeName)
-		if err != nil {
-			return err
-		}
-
-		if f.FileInfo().IsDir() {
-			err = os.MkdirAll(path, os.ModePerm)
-			if err != nil {
-				return err
-			}
-		} else {
-			err = os.MkdirAll(filepath.Dir(path), os.ModePerm)
-			if err != nil {
-				return err
-			}
-
-			inFile, err := f.Open()
-			if err != nil {
-				return err
-			}
-			defer inFile.Close()
-
-			outFile, err := os.OpenFile(path, os.O_WRONLY|os.O_CREATE|os.O_TRUNC, f.Mode())
-			if err != nil {
-				return err
-			}
-			defer outFile.Close()
-
-			_, err = io.Copy(outFile, inFile)
-			if err != nil {
-				return err
-			}
-		}
-	}
-
-	return nil
+	archive := &Archive{Format: ArchiveZip}
+	return archive.Extract(zipFile, destPath)
 }
 
 // ZipAppendEntry append a single file or directory by fpath to an existing zip file.
@@ -572,17 +522,6 @@ func ZipAppendEntry(fpath string, destPath string) error {
 	return CopyFile(tempFile.Name(), destPath)
 }
 
-func safeFilepathJoin(path1, path2 string) (string, error) {
-	relPath, err := filepath.Rel(".", path2)
-	if err != nil || strings.HasPrefix(relPath, "..") {
-		return "", fmt.Errorf("(zipslip) filepath is unsafe %q: %v", path2, err)
-	}
-	if path1 == "" {
-		path1 = "."
-	}
-	return filepath.Join(path1, filepath.Join("/", relPath)), nil
-}
-
 // IsLink checks if a file is symbol link or not.
 // Play: https://go.dev/play/p/TL-b-Kzvf44
 func IsLink(path string) bool {
@@ -700,29 +639,20 @@ func Sha(filepath string, shaType ...int) (string, error) {
 	}
 	defer file.Close()
 
-	h := sha1.New()
+	hash := crypto.SHA1
 	if len(shaType) > 0 {
 		if shaType[0] == 1 {
-			h = sha1.New()
+			hash = crypto.SHA1
 		} else if shaType[0] == 256 {
-			h = sha256.New()
+			hash = crypto.SHA256
 		} else if shaType[0] == 512 {
-			h = sha512.New()
+			hash = crypto.SHA512
 		} else {
 			return "", errors.New("param `shaType` should be 1, 256 or 512")
 		}
 	}
 
-	_, err = io.Copy(h, file)
-
-	if err != nil {
-		return "", err
-	}
-
-	sha := fmt.Sprintf("%x", h.Sum(nil))
-
-	return sha, nil
-
+	return cryptor.HashReader(file, hash)
 }
 
 // ReadCsvFile read file content into slice.
@@ -234,6 +234,36 @@ func ExampleZip() {
 	// true
 }
 
+func ExampleArchive() {
+	srcFile := "./test_archive.txt"
+	WriteStringToFile(srcFile, "hello", false)
+
+	archiveFile := "./test_archive.tar.gz"
+	archive := &Archive{MaxTotalSize: 1 << 20, MaxFiles: 100}
+
+	err := archive.Create(srcFile, archiveFile)
+	if err != nil {
+		return
+	}
+
+	destPath := "./test_archive"
+	err = archive.Extract(archiveFile, destPath)
+	if err != nil {
+		return
+	}
+
+	content, _ := ReadFileToString(destPath + "/test_archive.txt")
+
+	os.Remove(srcFile)
+	os.Remove(archiveFile)
+	os.RemoveAll(destPath)
+
+	fmt.Println(content)
+
+	// Output:
+	// hello
+}
+
 func ExampleUnZip() {
 	zipFile := "./testdata/file.go.zip"
 
@@ -3,6 +3,9 @@ module github.com/duke-git/lancet/v2
 go 1.18
 
 require (
+	golang.org/x/crypto v0.9.0
 	golang.org/x/exp v0.0.0-20221208152030-732eee02a75a
 	golang.org/x/text v0.9.0
 )
+
+require golang.org/x/sys v0.8.0 // indirect
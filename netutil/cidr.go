@@ -0,0 +1,541 @@
+// Copyright 2023 dudaodong@gmail.com. All rights reserved.
+// Use of this source code is governed by MIT license.
+
+package netutil
+
+import (
+	"errors"
+	"fmt"
+	"math/big"
+	"math/bits"
+	"net"
+	"sort"
+	"strings"
+	"sync"
+)
+
+// CIDR is an ipv4 or ipv6 network, eg. 192.168.0.0/16 or 2001:db8::/32.
+type CIDR struct {
+	network uint128
+	prefix  int
+	bits    int
+}
+
+// ParseCIDR parse the CIDR string, the host bits are cleared, eg. 192.168.1.1/24 is parsed to 192.168.1.0/24.
+// A single ip is parsed to /32 for ipv4 and /128 for ipv6.
+func ParseCIDR(s string) (*CIDR, error) {
+	s = strings.TrimSpace(s)
+	if !strings.Contains(s, "/") {
+		ip := net.ParseIP(s)
+		if ip == nil {
+			return nil, fmt.Errorf("invalid cidr: %s", s)
+		}
+		u, bitLen := ipToUint128(ip)
+		return &CIDR{network: u, prefix: bitLen, bits: bitLen}, nil
+	}
+
+	_, ipNet, err := net.ParseCIDR(s)
+	if err != nil {
+		return nil, fmt.Errorf("invalid cidr: %s", s)
+	}
+
+	return NewCIDRFromIPNet(ipNet), nil
+}
+
+// NewCIDRFromIPNet create CIDR from net.IPNet.
+func NewCIDRFromIPNet(ipNet *net.IPNet) *CIDR {
+	prefix, _ := ipNet.Mask.Size()
+	u, bitLen := ipToUint128(ipNet.IP)
+	if len(ipNet.Mask) == net.IPv6len && bitLen == 32 {
+		// ipv4 in ipv6 form with 128 bits mask
+		prefix -= 96
+	}
+
+	return newCIDR(u, prefix, bitLen)
+}
+
+func newCIDR(u uint128, prefix, bitLen int) *CIDR {
+	return &CIDR{network: u.and(networkMask(prefix, bitLen)), prefix: prefix, bits: bitLen}
+}
+
+// String return the CIDR notation.
+func (c *CIDR) String() string {
+	return fmt.Sprintf("%s/%d", c.IP(), c.prefix)
+}
+
+// IP return the network address.
+func (c *CIDR) IP() net.IP {
+	return uint128ToIP(c.network, c.bits)
+}
+
+// Prefix return the prefix length.
+func (c *CIDR) Prefix() int {
+	return c.prefix
+}
+
+// Bits return 32 for ipv4 network and 128 for ipv6 network.
+func (c *CIDR) Bits() int {
+	return c.bits
+}
+
+// IsIPv4 checks if the CIDR is an ipv4 network.
+func (c *CIDR) IsIPv4() bool {
+	return c.bits == 32
+}
+
+// IPNet return the net.IPNet of the CIDR.
+func (c *CIDR) IPNet() *net.IPNet {
+	return &net.IPNet{IP: c.IP(), Mask: net.CIDRMask(c.prefix, c.bits)}
+}
+
+// First return the first ip of the CIDR, it is the network address.
+func (c *CIDR) First() net.IP {
+	return c.IP()
+}
+
+// Last return the last ip of the CIDR, it is the broadcast address of ipv4 network.
+func (c *CIDR) Last() net.IP {
+	return uint128ToIP(c.last(), c.bits)
+}
+
+// Size return the number of ips of the CIDR.
+func (c *CIDR) Size() *big.Int {
+	return new(big.Int).Lsh(big.NewInt(1), uint(c.bits-c.prefix))
+}
+
+// Range return the ip range of the CIDR.
+func (c *CIDR) Range() IPRange {
+	return IPRange{Start: c.First(), End: c.Last()}
+}
+
+// Contains checks if the ip is in the CIDR.
+func (c *CIDR) Contains(ip net.IP) bool {
+	u, bitLen := ipToUint128(ip)
+	if bitLen != c.bits {
+		return false
+	}
+	return u.and(networkMask(c.prefix, c.bits)) == c.network
+}
+
+// ContainsCIDR checks if the other CIDR is a subnet of the CIDR.
+func (c *CIDR) ContainsCIDR(other *CIDR) bool {
+	return c.bits == other.bits && c.prefix <= other.prefix &&
+		other.network.and(networkMask(c.prefix, c.bits)) == c.network
+}
+
+// Overlaps checks if the two CIDRs have common ips.
+func (c *CIDR) Overlaps(other *CIDR) bool {
+	return c.ContainsCIDR(other) || other.ContainsCIDR(c)
+}
+
+// Each calls fn for each ip of the CIDR in order, stop when fn return false.
+func (c *CIDR) Each(fn func(ip net.IP) bool) {
+	last := c.last()
+	for u := c.network; ; u = u.add1() {
+		if !fn(uint128ToIP(u, c.bits)) || u == last {
+			return
+		}
+	}
+}
+
+// Split split the CIDR into subnets with the new prefix length, eg. 10.0.0.0/24 split by 26 return 4 subnets.
+// It returns error if the new prefix is invalid or the number of subnets exceeds 2^24.
+func (c *CIDR) Split(newPrefix int) ([]*CIDR, error) {
+	if newPrefix < c.prefix || newPrefix > c.bits {
+		return nil, fmt.Errorf("invalid prefix %d for %s", newPrefix, c)
+	}
+	if newPrefix-c.prefix > 24 {
+		return nil, errors.New("too many subnets")
+	}
+
+	count := 1 << (newPrefix - c.prefix)
+	step := uint128{lo: 1}.lsh(uint(c.bits - newPrefix))
+
+	result := make([]*CIDR, 0, count)
+	u := c.network
+	for i := 0; i < count; i++ {
+		result = append(result, &CIDR{network: u, prefix: newPrefix, bits: c.bits})
+		u = u.add(step)
+	}
+
+	return result, nil
+}
+
+// Supernet return the network which contains the CIDR with the new shorter prefix length.
+func (c *CIDR) Supernet(newPrefix int) (*CIDR, error) {
+	if newPrefix < 0 || newPrefix > c.prefix {
+		return nil, fmt.Errorf("invalid prefix %d for %s", newPrefix, c)
+	}
+	return newCIDR(c.network, newPrefix, c.bits), nil
+}
+
+func (c *CIDR) last() uint128 {
+	return c.network.or(networkMask(c.prefix, c.bits).not().and(bitMask(c.bits)))
+}
+
+// MergeCIDRs merge the overlapping and adjacent CIDRs into the minimal list of supernets, eg.
+// [10.0.0.0/25, 10.0.0.128/25, 10.0.1.0/24] is merged to [10.0.0.0/23]. ipv4 networks are listed before ipv6 networks.
+func MergeCIDRs(cidrs []*CIDR) []*CIDR {
+	ranges := make([]ipRange128, 0, len(cidrs))
+	for _, c := range cidrs {
+		ranges = append(ranges, ipRange128{start: c.network, end: c.last(), bits: c.bits})
+	}
+
+	result := []*CIDR{}
+	for _, r := range mergeRanges(ranges) {
+		result = append(result, r.toCIDRs()...)
+	}
+
+	return result
+}
+
+// IPRange is a range of continuous ips from Start to End, both are included.
+type IPRange struct {
+	Start net.IP
+	End   net.IP
+}
+
+// ParseIPRange parse ip range string like `192.168.1.10-192.168.1.20`.
+func ParseIPRange(s string) (IPRange, error) {
+	start, end, ok := strings.Cut(s, "-")
+	if !ok {
+		return IPRange{}, fmt.Errorf("invalid ip range: %s", s)
+	}
+
+	r := IPRange{Start: net.ParseIP(strings.TrimSpace(start)), End: net.ParseIP(strings.TrimSpace(end))}
+	if _, err := r.toRange128(); err != nil {
+		return IPRange{}, err
+	}
+
+	return r, nil
+}
+
+// String return the range notation `start-end`.
+func (r IPRange) String() string {
+	return r.Start.String() + "-" + r.End.String()
+}
+
+// Contains checks if the ip is in the range.
+func (r IPRange) Contains(ip net.IP) bool {
+	rng, err := r.toRange128()
+	if err != nil {
+		return false
+	}
+	return rng.contains(ip)
+}
+
+// ToCIDRs convert the range to the minimal list of CIDRs.
+func (r IPRange) ToCIDRs() ([]*CIDR, error) {
+	rng, err := r.toRange128()
+	if err != nil {
+		return nil, err
+	}
+	return rng.toCIDRs(), nil
+}
+
+func (r IPRange) toRange128() (ipRange128, error) {
+	if r.Start == nil || r.End == nil {
+		return ipRange128{}, errors.New("invalid ip range")
+	}
+
+	start, startBits := ipToUint128(r.Start)
+	end, endBits := ipToUint128(r.End)
+	if startBits != endBits {
+		return ipRange128{}, errors.New("ip range start and end should be the same ip version")
+	}
+	if start.cmp(end) > 0 {
+		return ipRange128{}, errors.New("ip range start should not be greater than end")
+	}
+
+	return ipRange128{start: start, end: end, bits: startBits}, nil
+}
+
+// IPRangeToCIDRs convert the ip range from start to end to the minimal list of CIDRs.
+func IPRangeToCIDRs(start, end net.IP) ([]*CIDR, error) {
+	return IPRange{Start: start, End: end}.ToCIDRs()
+}
+
+// IPSet is a set of ips built from ips, CIDRs and ip ranges, it is safe for concurrent use.
+// The entries are merged into sorted ranges, so Contains is a binary search.
+type IPSet struct {
+	mu     sync.RWMutex
+	ranges []ipRange128
+}
+
+// NewIPSet return an IPSet pointer with entries, see IPSet.Add for entry format.
+func NewIPSet(entries ...string) (*IPSet, error) {
+	set := &IPSet{}
+	if err := set.Add(entries...); err != nil {
+		return nil, err
+	}
+	return set, nil
+}
+
+// Add entries into the set, entry could be ip `10.0.0.1`, CIDR `10.0.0.0/8` or ip range `10.0.0.1-10.0.0.9`.
+// No entry is added if any entry is invalid.
+func (s *IPSet) Add(entries ...string) error {
+	ranges := make([]ipRange128, 0, len(entries))
+	for _, entry := range entries {
+		r, err := parseIPSetEntry(entry)
+		if err != nil {
+			return err
+		}
+		ranges = append(ranges, r)
+	}
+
+	s.mu.Lock()
+	defer s.mu.Unlock()
+	s.ranges = mergeRanges(append(ranges, s.ranges...))
+
+	return nil
+}
+
+// AddCIDR add the CIDR into the set.
+func (s *IPSet) AddCIDR(cidr *CIDR) {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+	s.ranges = mergeRanges(append(s.ranges, ipRange128{start: cidr.network, end: cidr.last(), bits: cidr.bits}))
+}
+
+// Contains checks if the ip is in the set.
+func (s *IPSet) Contains(ip net.IP) bool {
+	if ip == nil {
+		return false
+	}
+
+	u, bitLen := ipToUint128(ip)
+
+	s.mu.RLock()
+	defer s.mu.RUnlock()
+
+	// ranges are sorted by bits then start, find the first range which ends at or after ip
+	i := sort.Search(len(s.ranges), func(i int) bool {
+		r := s.ranges[i]
+		if r.bits != bitLen {
+			return r.bits > bitLen
+		}
+		return r.end.cmp(u) >= 0
+	})
+
+	return i < len(s.ranges) && s.ranges[i].bits == bitLen && s.ranges[i].start.cmp(u) <= 0
+}
+
+// ContainsString checks if the ip string is in the set.
+func (s *IPSet) ContainsString(ip string) bool {
+	return s.Contains(net.ParseIP(strings.TrimSpace(ip)))
+}
+
+// CIDRs return the minimal list of CIDRs of the set.
+func (s *IPSet) CIDRs() []*CIDR {
+	s.mu.RLock()
+	defer s.mu.RUnlock()
+
+	result := []*CIDR{}
+	for _, r := range s.ranges {
+		result = append(result, r.toCIDRs()...)
+	}
+	return result
+}
+
+// Ranges return the merged ip ranges of the set.
+func (s *IPSet) Ranges() []IPRange {
+	s.mu.RLock()
+	defer s.mu.RUnlock()
+
+	result := make([]IPRange, 0, len(s.ranges))
+	for _, r := range s.ranges {
+		result = append(result, IPRange{Start: uint128ToIP(r.start, r.bits), End: uint128ToIP(r.end, r.bits)})
+	}
+	return result
+}
+
+func parseIPSetEntry(entry string) (ipRange128, error) {
+	entry = strings.TrimSpace(entry)
+	if strings.Contains(entry, "-") {
+		r, err := ParseIPRange(entry)
+		if err != nil {
+			return ipRange128{}, err
+		}
+		return r.toRange128()
+	}
+
+	c, err := ParseCIDR(entry)
+	if err != nil {
+		return ipRange128{}, err
+	}
+	return ipRange128{start: c.network, end: c.last(), bits: c.bits}, nil
+}
+
+// ipRange128 is ip range represented by uint128
+type ipRange128 struct {
+	start uint128
+	end   uint128
+	bits  int
+}
+
+func (r ipRange128) contains(ip net.IP) bool {
+	u, bitLen := ipToUint128(ip)
+	return bitLen == r.bits && r.start.cmp(u) <= 0 && u.cmp(r.end) <= 0
+}
+
+// toCIDRs split the range into the minimal list of aligned blocks
+func (r ipRange128) toCIDRs() []*CIDR {
+	result := []*CIDR{}
+	start := r.start
+	for {
+		size := start.trailingZeros()
+		if size > r.bits {
+			size = r.bits
+		}
+		for size > 0 && start.or(bitMask(size)).cmp(r.end) > 0 {
+			size--
+		}
+
+		result = append(result, &CIDR{network: start, prefix: r.bits - size, bits: r.bits})
+
+		blockEnd := start.or(bitMask(size))
+		if blockEnd.cmp(r.end) >= 0 {
+			return result
+		}
+		start = blockEnd.add1()
+	}
+}
+
+// mergeRanges sort ranges and merge the overlapping and adjacent ones
+func mergeRanges(ranges []ipRange128) []ipRange128 {
+	sort.Slice(ranges, func(i, j int) bool {
+		if ranges[i].bits != ranges[j].bits {
+			return ranges[i].bits < ranges[j].bits
+		}
+		return ranges[i].start.cmp(ranges[j].start) < 0
+	})
+
+	result := make([]ipRange128, 0, len(ranges))
+	for _, r := range ranges {
+		if n := len(result); n > 0 {
+			last := &result[n-1]
+			if last.bits == r.bits && (last.end == bitMask(last.bits) || last.end.add1().cmp(r.start) >= 0) {
+				if r.end.cmp(last.end) > 0 {
+					last.end = r.end
+				}
+				continue
+			}
+		}
+		result = append(result, r)
+	}
+
+	return result
+}
+
+// uint128 is a 128 bits unsigned integer, ipv4 address uses the low 32 bits.
+type uint128 struct {
+	hi uint64
+	lo uint64
+}
+
+// ipToUint128 convert ip to uint128, return the bit length of ip: 32 or 128.
+func ipToUint128(ip net.IP) (uint128, int) {
+	if ip4 := ip.To4(); ip4 != nil {
+		return uint128{lo: uint64(ip4[0])<<24 | uint64(ip4[1])<<16 | uint64(ip4[2])<<8 | uint64(ip4[3])}, 32
+	}
+
+	ip16 := ip.To16()
+	if ip16 == nil {
+		return uint128{}, 0
+	}
+
+	var u uint128
+	for i := 0; i < 8; i++ {
+		u.hi = u.hi<<8 | uint64(ip16[i])
+		u.lo = u.lo<<8 | uint64(ip16[i+8])
+	}
+	return u, 128
+}
+
+func uint128ToIP(u uint128, bitLen int) net.IP {
+	if bitLen == 32 {
+		return net.IPv4(byte(u.lo>>24), byte(u.lo>>16), byte(u.lo>>8), byte(u.lo)).To4()
+	}
+
+	ip := make(net.IP, net.IPv6len)
+	for i := 0; i < 8; i++ {
+		ip[7-i] = byte(u.hi >> (8 * i))
+		ip[15-i] = byte(u.lo >> (8 * i))
+	}
+	return ip
+}
+
+// bitMask return the uint128 with the low n bits set
+func bitMask(n int) uint128 {
+	switch {
+	case n <= 0:
+		return uint128{}
+	case n < 64:
+		return uint128{lo: 1<<uint(n) - 1}
+	case n < 128:
+		return uint128{hi: 1<<uint(n-64) - 1, lo: ^uint64(0)}
+	default:
+		return uint128{hi: ^uint64(0), lo: ^uint64(0)}
+	}
+}
+
+// networkMask return the mask of prefix for ip with bitLen bits
+func networkMask(prefix, bitLen int) uint128 {
+	return bitMask(bitLen).and(bitMask(bitLen - prefix).not())
+}
+
+func (u uint128) and(v uint128) uint128 {
+	return uint128{hi: u.hi & v.hi, lo: u.lo & v.lo}
+}
+
+func (u uint128) or(v uint128) uint128 {
+	return uint128{hi: u.hi | v.hi, lo: u.lo | v.lo}
+}
+
+func (u uint128) not() uint128 {
+	return uint128{hi: ^u.hi, lo: ^u.lo}
+}
+
+func (u uint128) add(v uint128) uint128 {
+	lo, carry := bits.Add64(u.lo, v.lo, 0)
+	hi, _ := bits.Add64(u.hi, v.hi, carry)
+	return uint128{hi: hi, lo: lo}
+}
+
+func (u uint128) add1() uint128 {
+	return u.add(uint128{lo: 1})
+}
+
+func (u uint128) lsh(n uint) uint128 {
+	switch {
+	case n >= 128:
+		return uint128{}
+	case n >= 64:
+		return uint128{hi: u.lo << (n - 64)}
+	case n == 0:
+		return u
+	default:
+		return uint128{hi: u.hi<<n | u.lo>>(64-n), lo: u.lo << n}
+	}
+}
+
+func (u uint128) cmp(v uint128) int {
+	switch {
+	case u.hi < v.hi:
+		return -1
+	case u.hi > v.hi:
+		return 1
+	case u.lo < v.lo:
+		return -1
+	case u.lo > v.lo:
+		return 1
+	}
+	return 0
+}
+
+func (u uint128) trailingZeros() int {
+	if u.lo != 0 {
+		return bits.TrailingZeros64(u.lo)
+	}
+	return 64 + bits.TrailingZeros64(u.hi)
+}
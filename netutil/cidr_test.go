@@ -0,0 +1,230 @@
+package netutil
+
+import (
+	"math/big"
+	"net"
+	"testing"
+
+	"github.com/duke-git/lancet/v2/internal"
+)
+
+func cidrStrings(cidrs []*CIDR) []string {
+	result := make([]string, len(cidrs))
+	for i, c := range cidrs {
+		result[i] = c.String()
+	}
+	return result
+}
+
+func TestParseCIDR(t *testing.T) {
+	t.Parallel()
+
+	assert := internal.NewAssert(t, "TestParseCIDR")
+
+	c, err := ParseCIDR("192.168.1.77/24")
+	assert.IsNil(err)
+	assert.Equal("192.168.1.0/24", c.String())
+	assert.Equal(24, c.Prefix())
+	assert.Equal(32, c.Bits())
+	assert.Equal(true, c.IsIPv4())
+	assert.Equal("192.168.1.0", c.First().String())
+	assert.Equal("192.168.1.255", c.Last().String())
+	assert.Equal(big.NewInt(256), c.Size())
+	assert.Equal("192.168.1.0/24", c.IPNet().String())
+	assert.Equal("192.168.1.0-192.168.1.255", c.Range().String())
+
+	c, err = ParseCIDR("2001:db8::1/32")
+	assert.IsNil(err)
+	assert.Equal("2001:db8::/32", c.String())
+	assert.Equal("2001:db8:ffff:ffff:ffff:ffff:ffff:ffff", c.Last().String())
+	assert.Equal(new(big.Int).Lsh(big.NewInt(1), 96), c.Size())
+	assert.Equal(false, c.IsIPv4())
+
+	c, err = ParseCIDR("10.0.0.1")
+	assert.IsNil(err)
+	assert.Equal("10.0.0.1/32", c.String())
+
+	c, err = ParseCIDR("::1")
+	assert.IsNil(err)
+	assert.Equal("::1/128", c.String())
+
+	c, err = ParseCIDR("0.0.0.0/0")
+	assert.IsNil(err)
+	assert.Equal("255.255.255.255", c.Last().String())
+
+	_, err = ParseCIDR("10.0.0.0/33")
+	assert.IsNotNil(err)
+	_, err = ParseCIDR("invalid")
+	assert.IsNotNil(err)
+}
+
+func TestCIDR_Contains(t *testing.T) {
+	t.Parallel()
+
+	assert := internal.NewAssert(t, "TestCIDR_Contains")
+
+	c, _ := ParseCIDR("10.0.0.0/8")
+	assert.Equal(true, c.Contains(net.ParseIP("10.255.0.1")))
+	assert.Equal(false, c.Contains(net.ParseIP("11.0.0.1")))
+	assert.Equal(false, c.Contains(net.ParseIP("::ffff:b00:1")))
+	assert.Equal(true, c.Contains(net.ParseIP("::ffff:a00:1")))
+	assert.Equal(false, c.Contains(net.ParseIP("2001:db8::1")))
+
+	sub, _ := ParseCIDR("10.1.0.0/16")
+	other, _ := ParseCIDR("11.0.0.0/16")
+	assert.Equal(true, c.ContainsCIDR(sub))
+	assert.Equal(false, sub.ContainsCIDR(c))
+	assert.Equal(true, sub.Overlaps(c))
+	assert.Equal(false, other.Overlaps(c))
+
+	v6, _ := ParseCIDR("2001:db8::/32")
+	assert.Equal(true, v6.Contains(net.ParseIP("2001:db8:1::1")))
+	assert.Equal(false, v6.Contains(net.ParseIP("2001:db9::1")))
+}
+
+func TestCIDR_EachAndSplit(t *testing.T) {
+	t.Parallel()
+
+	assert := internal.NewAssert(t, "TestCIDR_EachAndSplit")
+
+	c, _ := ParseCIDR("192.168.1.0/30")
+	ips := []string{}
+	c.Each(func(ip net.IP) bool {
+		ips = append(ips, ip.String())
+		return true
+	})
+	assert.Equal([]string{"192.168.1.0", "192.168.1.1", "192.168.1.2", "192.168.1.3"}, ips)
+
+	ips = []string{}
+	c.Each(func(ip net.IP) bool {
+		ips = append(ips, ip.String())
+		return len(ips) < 2
+	})
+	assert.Equal(2, len(ips))
+
+	all, _ := ParseCIDR("255.255.255.254/31")
+	count := 0
+	all.Each(func(ip net.IP) bool {
+		count++
+		return true
+	})
+	assert.Equal(2, count)
+
+	c, _ = ParseCIDR("10.0.0.0/24")
+	subnets, err := c.Split(26)
+	assert.IsNil(err)
+	assert.Equal([]string{"10.0.0.0/26", "10.0.0.64/26", "10.0.0.128/26", "10.0.0.192/26"}, cidrStrings(subnets))
+
+	_, err = c.Split(23)
+	assert.IsNotNil(err)
+	_, err = c.Split(33)
+	assert.IsNotNil(err)
+
+	v6, _ := ParseCIDR("2001:db8::/32")
+	subnets, err = v6.Split(34)
+	assert.IsNil(err)
+	assert.Equal([]string{"2001:db8::/34", "2001:db8:4000::/34", "2001:db8:8000::/34", "2001:db8:c000::/34"}, cidrStrings(subnets))
+
+	_, err = v6.Split(64)
+	assert.IsNotNil(err)
+
+	supernet, err := c.Supernet(16)
+	assert.IsNil(err)
+	assert.Equal("10.0.0.0/16", supernet.String())
+	_, err = c.Supernet(25)
+	assert.IsNotNil(err)
+}
+
+func TestMergeCIDRs(t *testing.T) {
+	t.Parallel()
+
+	assert := internal.NewAssert(t, "TestMergeCIDRs")
+
+	parse := func(items ...string) []*CIDR {
+		result := []*CIDR{}
+		for _, item := range items {
+			c, _ := ParseCIDR(item)
+			result = append(result, c)
+		}
+		return result
+	}
+
+	merged := MergeCIDRs(parse("10.0.1.0/24", "10.0.0.128/25", "10.0.0.0/25", "10.0.0.5/32", "2001:db8::/33", "2001:db8:8000::/33"))
+	assert.Equal([]string{"10.0.0.0/23", "2001:db8::/32"}, cidrStrings(merged))
+
+	merged = MergeCIDRs(parse("10.0.1.0/24", "10.0.2.0/24"))
+	assert.Equal([]string{"10.0.1.0/24", "10.0.2.0/24"}, cidrStrings(merged))
+
+	merged = MergeCIDRs(parse("0.0.0.0/1", "128.0.0.0/1", "1.0.0.0/8"))
+	assert.Equal([]string{"0.0.0.0/0"}, cidrStrings(merged))
+}
+
+func TestIPRangeToCIDRs(t *testing.T) {
+	t.Parallel()
+
+	assert := internal.NewAssert(t, "TestIPRangeToCIDRs")
+
+	cidrs, err := IPRangeToCIDRs(net.ParseIP("192.168.1.10"), net.ParseIP("192.168.1.20"))
+	assert.IsNil(err)
+	assert.Equal([]string{"192.168.1.10/31", "192.168.1.12/30", "192.168.1.16/30", "192.168.1.20/32"}, cidrStrings(cidrs))
+
+	cidrs, err = IPRangeToCIDRs(net.ParseIP("0.0.0.0"), net.ParseIP("255.255.255.255"))
+	assert.IsNil(err)
+	assert.Equal([]string{"0.0.0.0/0"}, cidrStrings(cidrs))
+
+	cidrs, err = IPRangeToCIDRs(net.ParseIP("::"), net.ParseIP("ffff:ffff:ffff:ffff:ffff:ffff:ffff:ffff"))
+	assert.IsNil(err)
+	assert.Equal([]string{"::/0"}, cidrStrings(cidrs))
+
+	cidrs, err = IPRangeToCIDRs(net.ParseIP("2001:db8::1"), net.ParseIP("2001:db8::4"))
+	assert.IsNil(err)
+	assert.Equal([]string{"2001:db8::1/128", "2001:db8::2/127", "2001:db8::4/128"}, cidrStrings(cidrs))
+
+	_, err = IPRangeToCIDRs(net.ParseIP("10.0.0.2"), net.ParseIP("10.0.0.1"))
+	assert.IsNotNil(err)
+	_, err = IPRangeToCIDRs(net.ParseIP("10.0.0.1"), net.ParseIP("::1"))
+	assert.IsNotNil(err)
+
+	r, err := ParseIPRange("10.0.0.1 - 10.0.0.9")
+	assert.IsNil(err)
+	assert.Equal(true, r.Contains(net.ParseIP("10.0.0.5")))
+	assert.Equal(false, r.Contains(net.ParseIP("10.0.0.10")))
+
+	_, err = ParseIPRange("10.0.0.1")
+	assert.IsNotNil(err)
+}
+
+func TestIPSet(t *testing.T) {
+	t.Parallel()
+
+	assert := internal.NewAssert(t, "TestIPSet")
+
+	set, err := NewIPSet("10.0.0.0/8", "192.168.1.10-192.168.1.20", "8.8.8.8", "2001:db8::/32")
+	assert.IsNil(err)
+
+	assert.Equal(true, set.ContainsString("10.1.2.3"))
+	assert.Equal(true, set.ContainsString("192.168.1.15"))
+	assert.Equal(false, set.ContainsString("192.168.1.21"))
+	assert.Equal(true, set.ContainsString("8.8.8.8"))
+	assert.Equal(false, set.ContainsString("8.8.4.4"))
+	assert.Equal(true, set.ContainsString("2001:db8::1"))
+	assert.Equal(false, set.ContainsString("2001:db9::1"))
+	assert.Equal(false, set.ContainsString("invalid"))
+	assert.Equal(false, set.Contains(nil))
+
+	err = set.Add("8.8.8.9", "invalid")
+	assert.IsNotNil(err)
+	assert.Equal(false, set.ContainsString("8.8.8.9"))
+
+	err = set.Add("8.8.8.9", "8.8.8.10/31")
+	assert.IsNil(err)
+	c, _ := ParseCIDR("172.16.0.0/12")
+	set.AddCIDR(c)
+
+	assert.Equal([]string{"8.8.8.8/30", "10.0.0.0/8", "172.16.0.0/12", "192.168.1.10/31", "192.168.1.12/30",
+		"192.168.1.16/30", "192.168.1.20/32", "2001:db8::/32"}, cidrStrings(set.CIDRs()))
+	assert.Equal("8.8.8.8-8.8.8.11", set.Ranges()[0].String())
+
+	_, err = NewIPSet("10.0.0.0/40")
+	assert.IsNotNil(err)
+}
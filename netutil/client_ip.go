@@ -0,0 +1,190 @@
+// Copyright 2023 dudaodong@gmail.com. All rights reserved.
+// Use of this source code is governed by MIT license.
+
+package netutil
+
+import (
+	"fmt"
+	"net"
+	"net/http"
+	"strings"
+)
+
+// ClientIPResolver resolves the client ip of request behind reverse proxies.
+// Only one forwarding header is trusted, X-Forwarded-For by default, and only when the request comes from a trusted proxy,
+// so the client can not spoof its ip. The header should be the one the proxies set, other forwarding headers are ignored
+// since the client may send them as well.
+type ClientIPResolver struct {
+	trustedProxies  []*net.IPNet
+	trustInternalIP bool
+	trustedHeader   string
+}
+
+// NewClientIPResolver return a ClientIPResolver pointer, param `trustedProxies` are the CIDRs or ips of trusted proxies.
+func NewClientIPResolver(trustedProxies ...string) (*ClientIPResolver, error) {
+	resolver := &ClientIPResolver{trustedHeader: "X-Forwarded-For"}
+
+	for _, proxy := range trustedProxies {
+		proxy = strings.TrimSpace(proxy)
+		if !strings.Contains(proxy, "/") {
+			ip := net.ParseIP(proxy)
+			if ip == nil {
+				return nil, fmt.Errorf("invalid trusted proxy: %s", proxy)
+			}
+			if ip4 := ip.To4(); ip4 != nil {
+				proxy += "/32"
+			} else {
+				proxy += "/128"
+			}
+		}
+
+		_, ipNet, err := net.ParseCIDR(proxy)
+		if err != nil {
+			return nil, fmt.Errorf("invalid trusted proxy: %s", proxy)
+		}
+		resolver.trustedProxies = append(resolver.trustedProxies, ipNet)
+	}
+
+	return resolver, nil
+}
+
+// TrustInternalProxies makes the resolver trust all internal ips (checked by IsInternalIP) as proxies.
+func (r *ClientIPResolver) TrustInternalProxies() *ClientIPResolver {
+	r.trustInternalIP = true
+	return r
+}
+
+// TrustHeader sets the forwarding header set by the trusted proxies, it replaces the default X-Forwarded-For.
+// The header could be `Forwarded` (RFC 7239), `X-Forwarded-For`, or a header of single ip like `X-Real-IP`.
+func (r *ClientIPResolver) TrustHeader(header string) *ClientIPResolver {
+	r.trustedHeader = http.CanonicalHeaderKey(header)
+	return r
+}
+
+// IsTrustedProxy checks if the ip is a trusted proxy.
+func (r *ClientIPResolver) IsTrustedProxy(ip net.IP) bool {
+	if ip == nil {
+		return false
+	}
+	if r.trustInternalIP && IsInternalIP(ip) {
+		return true
+	}
+	for _, ipNet := range r.trustedProxies {
+		if ipNet.Contains(ip) {
+			return true
+		}
+	}
+	return false
+}
+
+// Resolve return the client ip of request, nil if RemoteAddr of request is invalid.
+// If the request comes from a trusted proxy, the trusted header (see TrustHeader) is checked. The forwarding chain
+// is walked from right to left, the first untrusted ip is the client ip. If the header has no valid ip,
+// the remote ip is returned.
+func (r *ClientIPResolver) Resolve(req *http.Request) net.IP {
+	remote := parseIPWithPort(req.RemoteAddr)
+	if remote == nil || !r.IsTrustedProxy(remote) {
+		return remote
+	}
+
+	values := req.Header.Values(r.trustedHeader)
+	if len(values) == 0 {
+		return remote
+	}
+
+	var chain []string
+	if r.trustedHeader == "Forwarded" {
+		chain = parseForwardedFor(values)
+	} else {
+		for _, value := range values {
+			chain = append(chain, strings.Split(value, ",")...)
+		}
+	}
+
+	if ip := r.resolveChain(chain); ip != nil {
+		return ip
+	}
+
+	return remote
+}
+
+// ResolveString return the client ip string of request, empty string if it could not be resolved.
+func (r *ClientIPResolver) ResolveString(req *http.Request) string {
+	ip := r.Resolve(req)
+	if ip == nil {
+		return ""
+	}
+	return ip.String()
+}
+
+// IsPublicClient checks if the resolved client ip of request is a public ip (checked by IsPublicIP).
+func (r *ClientIPResolver) IsPublicClient(req *http.Request) bool {
+	ip := r.Resolve(req)
+	return ip != nil && IsPublicIP(ip)
+}
+
+// resolveChain walk the forwarding chain from right to left, return the first untrusted ip.
+// If an invalid node is met, the last valid ip is returned, because the nodes before it can not be trusted.
+func (r *ClientIPResolver) resolveChain(chain []string) net.IP {
+	var last net.IP
+	for i := len(chain) - 1; i >= 0; i-- {
+		ip := parseIPWithPort(chain[i])
+		if ip == nil {
+			return last
+		}
+		if !r.IsTrustedProxy(ip) {
+			return ip
+		}
+		last = ip
+	}
+	return last
+}
+
+// parseForwardedFor return the `for` nodes of `Forwarded` header values, eg.
+// Forwarded: for=192.0.2.60;proto=http;by=203.0.113.43, for="[2001:db8:cafe::17]:4711"
+func parseForwardedFor(values []string) []string {
+	result := []string{}
+	for _, value := range values {
+		for _, element := range strings.Split(value, ",") {
+			node := ""
+			for _, pair := range strings.Split(element, ";") {
+				key, val, ok := strings.Cut(strings.TrimSpace(pair), "=")
+				if ok && strings.EqualFold(strings.TrimSpace(key), "for") {
+					node = strings.Trim(strings.TrimSpace(val), `"`)
+				}
+			}
+			result = append(result, node)
+		}
+	}
+	return result
+}
+
+// parseIPWithPort parse ip from `ip`, `ip:port`, `[ipv6]` and `[ipv6]:port`, return nil if it is invalid.
+func parseIPWithPort(s string) net.IP {
+	s = strings.TrimSpace(s)
+	if s == "" {
+		return nil
+	}
+
+	if ip := parseIPWithoutZone(s); ip != nil {
+		return ip
+	}
+
+	if host, _, err := net.SplitHostPort(s); err == nil {
+		return parseIPWithoutZone(host)
+	}
+
+	if strings.HasPrefix(s, "[") && strings.HasSuffix(s, "]") {
+		return parseIPWithoutZone(s[1 : len(s)-1])
+	}
+
+	return nil
+}
+
+// parseIPWithoutZone parse ip and drop the ipv6 zone, eg. fe80::1%eth0
+func parseIPWithoutZone(s string) net.IP {
+	if i := strings.LastIndex(s, "%"); i > 0 {
+		s = s[:i]
+	}
+	return net.ParseIP(s)
+}
@@ -0,0 +1,127 @@
+package netutil
+
+import (
+	"net"
+	"net/http"
+	"net/http/httptest"
+	"testing"
+
+	"github.com/duke-git/lancet/v2/internal"
+)
+
+func TestNewClientIPResolver(t *testing.T) {
+	t.Parallel()
+
+	assert := internal.NewAssert(t, "TestNewClientIPResolver")
+
+	resolver, err := NewClientIPResolver("10.0.0.0/8", "192.168.1.1", "2001:db8::/32", "::1")
+	assert.IsNil(err)
+
+	assert.Equal(true, resolver.IsTrustedProxy(net.ParseIP("10.1.2.3")))
+	assert.Equal(true, resolver.IsTrustedProxy(net.ParseIP("192.168.1.1")))
+	assert.Equal(false, resolver.IsTrustedProxy(net.ParseIP("192.168.1.2")))
+	assert.Equal(true, resolver.IsTrustedProxy(net.ParseIP("2001:db8::1")))
+	assert.Equal(true, resolver.IsTrustedProxy(net.ParseIP("::1")))
+	assert.Equal(false, resolver.IsTrustedProxy(net.ParseIP("8.8.8.8")))
+	assert.Equal(false, resolver.IsTrustedProxy(nil))
+
+	_, err = NewClientIPResolver("10.0.0.0/33")
+	assert.IsNotNil(err)
+	_, err = NewClientIPResolver("invalid")
+	assert.IsNotNil(err)
+
+	resolver, _ = NewClientIPResolver()
+	assert.Equal(false, resolver.IsTrustedProxy(net.ParseIP("172.16.0.1")))
+	assert.Equal(true, resolver.TrustInternalProxies().IsTrustedProxy(net.ParseIP("172.16.0.1")))
+}
+
+func TestClientIPResolver_Resolve(t *testing.T) {
+	t.Parallel()
+
+	assert := internal.NewAssert(t, "TestClientIPResolver_Resolve")
+
+	resolver, _ := NewClientIPResolver("10.0.0.0/8", "2001:db8::/32")
+
+	tests := []struct {
+		remoteAddr string
+		header     http.Header
+		expected   string
+	}{
+		// untrusted remote, headers are ignored
+		{"1.2.3.4:1234", http.Header{"X-Forwarded-For": {"5.6.7.8"}}, "1.2.3.4"},
+		{"1.2.3.4:1234", http.Header{"X-Real-Ip": {"5.6.7.8"}}, "1.2.3.4"},
+		// trusted remote without headers
+		{"10.0.0.1:1234", http.Header{}, "10.0.0.1"},
+		// spoofed leftmost entries are skipped
+		{"10.0.0.1:1234", http.Header{"X-Forwarded-For": {"6.6.6.6, 5.6.7.8, 10.0.0.2"}}, "5.6.7.8"},
+		{"10.0.0.1:1234", http.Header{"X-Forwarded-For": {"6.6.6.6", "5.6.7.8, 10.0.0.2"}}, "5.6.7.8"},
+		// all entries are trusted
+		{"10.0.0.1:1234", http.Header{"X-Forwarded-For": {"10.0.0.3, 10.0.0.2"}}, "10.0.0.3"},
+		// invalid entry stops the walk
+		{"10.0.0.1:1234", http.Header{"X-Forwarded-For": {"5.6.7.8, garbage, 10.0.0.2"}}, "10.0.0.2"},
+		{"10.0.0.1:1234", http.Header{"X-Forwarded-For": {"garbage"}}, "10.0.0.1"},
+		// other forwarding headers are ignored, the client may send them
+		{"10.0.0.1:1234", http.Header{
+			"Forwarded":       {"for=6.6.6.6"},
+			"X-Forwarded-For": {"5.6.7.8"},
+		}, "5.6.7.8"},
+		{"10.0.0.1:1234", http.Header{"Forwarded": {"for=6.6.6.6"}, "X-Real-Ip": {"6.6.6.6"}}, "10.0.0.1"},
+		{"invalid", http.Header{}, ""},
+	}
+
+	for _, tt := range tests {
+		req := httptest.NewRequest(http.MethodGet, "/", nil)
+		req.RemoteAddr = tt.remoteAddr
+		req.Header = tt.header
+
+		assert.Equal(tt.expected, resolver.ResolveString(req))
+	}
+
+	req := httptest.NewRequest(http.MethodGet, "/", nil)
+	req.RemoteAddr = "10.0.0.1:1234"
+	req.Header.Set("X-Forwarded-For", "8.8.8.8")
+	assert.Equal(true, resolver.IsPublicClient(req))
+
+	req.Header.Set("X-Forwarded-For", "192.168.1.1")
+	assert.Equal(false, resolver.IsPublicClient(req))
+}
+
+func TestClientIPResolver_TrustHeader(t *testing.T) {
+	t.Parallel()
+
+	assert := internal.NewAssert(t, "TestClientIPResolver_TrustHeader")
+
+	forwarded, _ := NewClientIPResolver("10.0.0.0/8", "2001:db8::/32")
+	forwarded.TrustHeader("forwarded")
+
+	realIP, _ := NewClientIPResolver("10.0.0.0/8", "2001:db8::/32")
+	realIP.TrustHeader("X-Real-IP")
+
+	tests := []struct {
+		resolver   *ClientIPResolver
+		remoteAddr string
+		header     http.Header
+		expected   string
+	}{
+		{forwarded, "10.0.0.1:1234", http.Header{
+			"Forwarded":       {`for=6.6.6.6, for="[2001:db8:cafe::17]:4711";proto=https, for=9.9.9.9:80;by=10.0.0.1`},
+			"X-Forwarded-For": {"5.6.7.8"},
+		}, "9.9.9.9"},
+		{forwarded, "10.0.0.1:1234", http.Header{"Forwarded": {`for="[2001:db9::1]:4711", for=10.0.0.5`}}, "2001:db9::1"},
+		// no fallback to other headers
+		{forwarded, "10.0.0.1:1234", http.Header{"Forwarded": {`for=unknown`}, "X-Real-Ip": {"5.6.7.8"}}, "10.0.0.1"},
+		{forwarded, "10.0.0.1:1234", http.Header{"X-Forwarded-For": {"5.6.7.8"}}, "10.0.0.1"},
+		{forwarded, "1.2.3.4:1234", http.Header{"Forwarded": {"for=5.6.7.8"}}, "1.2.3.4"},
+		{realIP, "[2001:db8::1]:443", http.Header{"X-Real-Ip": {"5.6.7.8"}}, "5.6.7.8"},
+		{realIP, "10.0.0.1:1234", http.Header{"X-Forwarded-For": {"5.6.7.8"}}, "10.0.0.1"},
+		{realIP, "10.0.0.1:1234", http.Header{"X-Real-Ip": {"garbage"}}, "10.0.0.1"},
+	}
+
+	for _, tt := range tests {
+		req := httptest.NewRequest(http.MethodGet, "/", nil)
+		req.RemoteAddr = tt.remoteAddr
+		req.Header = tt.header
+
+		assert.Equal(tt.expected, tt.resolver.ResolveString(req))
+	}
+}
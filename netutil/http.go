@@ -26,6 +26,7 @@ import (
 	"os"
 	"sort"
 	"strings"
+	"sync"
 	"time"
 
 	"github.com/duke-git/lancet/v2/slice"
@@ -110,6 +111,12 @@ type HttpClientConfig struct {
 	ResponseTimeout  time.Duration
 	Verbose          bool
 	Proxy            *url.URL
+	// RetryPolicy retries the failed request, nil means no retry.
+	RetryPolicy *RetryPolicy
+	// CacheStore caches the GET responses, nil means no cache.
+	CacheStore HttpCacheStore
+	// CacheMaxEntrySize is the max body size of cached response, 0 means DefaultCacheMaxEntrySize.
+	CacheMaxEntrySize int64
 }
 
 // defaultHttpClientConfig defalut client config.
@@ -127,6 +134,9 @@ type HttpClient struct {
 	Request *http.Request
 	Config  HttpClientConfig
 	Context context.Context
+
+	middlewares []Middleware
+	tlsOnce     sync.Once
 }
 
 // NewHttpClient make a HttpClient instance.
@@ -213,7 +223,7 @@ func (client *HttpClient) SendRequest(request *HttpRequest) (*http.Response, err
 
 	client.Request = req
 
-	resp, err := client.Client.Do(req)
+	resp, err := client.do(req)
 	if err != nil {
 		return nil, err
 	}
@@ -256,6 +266,16 @@ func (client *HttpClient) setTLS(rawUrl string) {
 	}
 }
 
+// initTLS set http client transport TLSClientConfig once, the concurrent requests
+// of RequestBuilder must not write the shared transport while it is dialing.
+func (client *HttpClient) initTLS() {
+	client.tlsOnce.Do(func() {
+		if transport, ok := client.Client.Transport.(*http.Transport); ok && client.TLS != nil {
+			transport.TLSClientConfig = client.TLS
+		}
+	})
+}
+
 // setHeader set http request header
 func (client *HttpClient) setHeader(req *http.Request, headers http.Header) {
 	if headers == nil {
@@ -384,6 +404,8 @@ func validateRequest(req *HttpRequest) error {
 
 // StructToUrlValues convert struct to url valuse,
 // only convert the field which is exported and has `json` tag.
+// The values are encoded by json then formatted by fmt, see StructToQueryValues for `query` tag,
+// repeated params of slice and the reverse binding UrlValuesToStruct.
 // Play: https://go.dev/play/p/pFqMkM40w9z
 func StructToUrlValues(targetStruct any) (url.Values, error) {
 	result := url.Values{}
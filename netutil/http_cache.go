@@ -0,0 +1,414 @@
+// Copyright 2023 dudaodong@gmail.com. All rights reserved.
+// Use of this source code is governed by MIT license.
+
+package netutil
+
+import (
+	"bytes"
+	"crypto/sha256"
+	"encoding/hex"
+	"encoding/json"
+	"errors"
+	"fmt"
+	"io"
+	"net/http"
+	"os"
+	"path/filepath"
+	"strconv"
+	"strings"
+	"sync"
+	"time"
+
+	"github.com/duke-git/lancet/v2/algorithm"
+)
+
+// CacheStatusHeader is the response header which tells how the response is served by the http cache.
+const CacheStatusHeader = "X-Cache-Status"
+
+// DefaultCacheMaxEntrySize is the default max body size of response cached by CacheMiddleware.
+const DefaultCacheMaxEntrySize int64 = 10 << 20
+
+// CacheStatus is the value of CacheStatusHeader.
+type CacheStatus string
+
+const (
+	// CacheHit means the response is served from cache without sending request.
+	CacheHit CacheStatus = "HIT"
+	// CacheMiss means the response is fetched from server.
+	CacheMiss CacheStatus = "MISS"
+	// CacheRevalidated means the cached response is validated by server with a 304 response.
+	CacheRevalidated CacheStatus = "REVALIDATED"
+)
+
+// GetCacheStatus return the cache status of the response, empty string if the response is not served by cache.
+func GetCacheStatus(resp *http.Response) CacheStatus {
+	if resp == nil {
+		return ""
+	}
+	return CacheStatus(resp.Header.Get(CacheStatusHeader))
+}
+
+// CachedResponse is a response stored in HttpCacheStore.
+type CachedResponse struct {
+	StatusCode int         `json:"statusCode"`
+	Header     http.Header `json:"header"`
+	Body       []byte      `json:"body"`
+	// VaryHeader is the request header values selected by the `Vary` response header.
+	VaryHeader http.Header `json:"varyHeader"`
+	// StoredAt is the time when the response is received or revalidated.
+	StoredAt time.Time `json:"storedAt"`
+}
+
+// HttpCacheStore stores the cached responses, the implementation should be safe for concurrent use.
+type HttpCacheStore interface {
+	Get(key string) (*CachedResponse, bool)
+	Set(key string, resp *CachedResponse) error
+	Delete(key string) error
+}
+
+// MemoryCacheStore is a HttpCacheStore which keeps the responses in memory, the least recently used one is evicted when it is full.
+type MemoryCacheStore struct {
+	mu    sync.Mutex
+	cache *algorithm.LRUCache[string, *CachedResponse]
+}
+
+// NewMemoryCacheStore return a MemoryCacheStore pointer which holds at most capacity responses.
+func NewMemoryCacheStore(capacity int) *MemoryCacheStore {
+	return &MemoryCacheStore{cache: algorithm.NewLRUCache[string, *CachedResponse](capacity)}
+}
+
+// Get the cached response of key.
+func (s *MemoryCacheStore) Get(key string) (*CachedResponse, bool) {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+	return s.cache.Get(key)
+}
+
+// Set the cached response of key.
+func (s *MemoryCacheStore) Set(key string, resp *CachedResponse) error {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+	s.cache.Put(key, resp)
+	return nil
+}
+
+// Delete the cached response of key.
+func (s *MemoryCacheStore) Delete(key string) error {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+	s.cache.Delete(key)
+	return nil
+}
+
+// DiskCacheStore is a HttpCacheStore which keeps each response in a json file of the directory.
+type DiskCacheStore struct {
+	mu  sync.RWMutex
+	dir string
+}
+
+// NewDiskCacheStore return a DiskCacheStore pointer, the directory is created if it does not exist.
+func NewDiskCacheStore(dir string) (*DiskCacheStore, error) {
+	if err := os.MkdirAll(dir, 0755); err != nil {
+		return nil, err
+	}
+	return &DiskCacheStore{dir: dir}, nil
+}
+
+// Get the cached response of key.
+func (s *DiskCacheStore) Get(key string) (*CachedResponse, bool) {
+	s.mu.RLock()
+	defer s.mu.RUnlock()
+
+	data, err := os.ReadFile(s.path(key))
+	if err != nil {
+		return nil, false
+	}
+
+	resp := &CachedResponse{}
+	if err = json.Unmarshal(data, resp); err != nil {
+		return nil, false
+	}
+
+	return resp, true
+}
+
+// Set the cached response of key.
+func (s *DiskCacheStore) Set(key string, resp *CachedResponse) error {
+	data, err := json.Marshal(resp)
+	if err != nil {
+		return err
+	}
+
+	s.mu.Lock()
+	defer s.mu.Unlock()
+
+	return os.WriteFile(s.path(key), data, 0644)
+}
+
+// Delete the cached response of key.
+func (s *DiskCacheStore) Delete(key string) error {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+
+	err := os.Remove(s.path(key))
+	if err != nil && !errors.Is(err, os.ErrNotExist) {
+		return err
+	}
+	return nil
+}
+
+// path return the file path of key
+func (s *DiskCacheStore) path(key string) string {
+	sum := sha256.Sum256([]byte(key))
+	return filepath.Join(s.dir, hex.EncodeToString(sum[:])+".json")
+}
+
+// CacheMiddleware return a middleware which caches GET responses in store. It honours `Cache-Control` and `Expires`,
+// and revalidates stale responses with `If-None-Match` and `If-Modified-Since`.
+// The CacheStatusHeader of response is set to HIT, MISS or REVALIDATED.
+// The response whose body is larger than maxEntrySize is passed through without caching,
+// maxEntrySize <= 0 means DefaultCacheMaxEntrySize.
+// HttpClient uses it automatically when HttpClientConfig.CacheStore is set.
+func CacheMiddleware(store HttpCacheStore, maxEntrySize int64) Middleware {
+	if maxEntrySize <= 0 {
+		maxEntrySize = DefaultCacheMaxEntrySize
+	}
+
+	return func(next http.RoundTripper) http.RoundTripper {
+		return RoundTripFunc(func(req *http.Request) (*http.Response, error) {
+			reqDirectives := parseCacheControl(req.Header)
+			if !isCacheableRequest(req) || reqDirectives.has("no-store") {
+				return next.RoundTrip(req)
+			}
+
+			key := req.Method + " " + req.URL.String()
+
+			cached, ok := store.Get(key)
+			if ok && !cached.matchVary(req) {
+				cached, ok = nil, false
+			}
+
+			if ok && !reqDirectives.has("no-cache") && cached.isFresh(time.Now()) {
+				return cached.toResponse(req, CacheHit), nil
+			}
+
+			sendReq := req
+			if ok && cached.hasValidator() {
+				sendReq = cloneRequest(req)
+				if etag := cached.Header.Get("ETag"); etag != "" {
+					sendReq.Header.Set("If-None-Match", etag)
+				}
+				if lastModified := cached.Header.Get("Last-Modified"); lastModified != "" {
+					sendReq.Header.Set("If-Modified-Since", lastModified)
+				}
+			}
+
+			resp, err := next.RoundTrip(sendReq)
+			if err != nil {
+				return nil, err
+			}
+
+			if ok && resp.StatusCode == http.StatusNotModified {
+				io.Copy(io.Discard, resp.Body)
+				resp.Body.Close()
+
+				// copy the entry, it may be shared with other goroutines by the store
+				updated := *cached
+				updated.Header = cached.Header.Clone()
+				for k, v := range resp.Header {
+					updated.Header[k] = v
+				}
+				updated.StoredAt = time.Now()
+				cached = &updated
+				if err := store.Set(key, cached); err != nil {
+					return nil, err
+				}
+
+				return cached.toResponse(req, CacheRevalidated), nil
+			}
+
+			if !isCacheableResponse(resp) || resp.ContentLength > maxEntrySize {
+				if ok {
+					store.Delete(key)
+				}
+				resp.Header.Set(CacheStatusHeader, string(CacheMiss))
+				return resp, nil
+			}
+
+			body, err := io.ReadAll(io.LimitReader(resp.Body, maxEntrySize+1))
+			if err != nil {
+				resp.Body.Close()
+				return nil, err
+			}
+			if int64(len(body)) > maxEntrySize {
+				// the size is unknown until reading, stream the read part and the rest without caching
+				if ok {
+					store.Delete(key)
+				}
+				resp.Body = &multiReadCloser{Reader: io.MultiReader(bytes.NewReader(body), resp.Body), Closer: resp.Body}
+				resp.Header.Set(CacheStatusHeader, string(CacheMiss))
+				return resp, nil
+			}
+			resp.Body.Close()
+
+			entry := &CachedResponse{
+				StatusCode: resp.StatusCode,
+				Header:     resp.Header.Clone(),
+				Body:       body,
+				VaryHeader: varyHeader(req, resp.Header),
+				StoredAt:   time.Now(),
+			}
+			if err = store.Set(key, entry); err != nil {
+				return nil, err
+			}
+
+			resp.Body = io.NopCloser(bytes.NewReader(body))
+			resp.Header.Set(CacheStatusHeader, string(CacheMiss))
+
+			return resp, nil
+		})
+	}
+}
+
+// multiReadCloser reads from Reader and closes Closer
+type multiReadCloser struct {
+	io.Reader
+	io.Closer
+}
+
+// isFresh checks if the cached response could be used without revalidation
+func (c *CachedResponse) isFresh(now time.Time) bool {
+	directives := parseCacheControl(c.Header)
+	if directives.has("no-cache") {
+		return false
+	}
+
+	var lifetime time.Duration
+	if maxAge, ok := directives.seconds("max-age"); ok {
+		lifetime = maxAge
+	} else if expires, err := http.ParseTime(c.Header.Get("Expires")); err == nil {
+		date, err := http.ParseTime(c.Header.Get("Date"))
+		if err != nil {
+			date = c.StoredAt
+		}
+		lifetime = expires.Sub(date)
+	}
+
+	age := now.Sub(c.StoredAt)
+	if initialAge, err := strconv.Atoi(c.Header.Get("Age")); err == nil && initialAge > 0 {
+		age += time.Duration(initialAge) * time.Second
+	}
+
+	return age < lifetime
+}
+
+// hasValidator checks if the cached response has ETag or Last-Modified header
+func (c *CachedResponse) hasValidator() bool {
+	return c.Header.Get("ETag") != "" || c.Header.Get("Last-Modified") != ""
+}
+
+// matchVary checks if the request has the same header values selected by `Vary`
+func (c *CachedResponse) matchVary(req *http.Request) bool {
+	for key, values := range c.VaryHeader {
+		if key == "*" {
+			return false
+		}
+		if strings.Join(req.Header.Values(key), ",") != strings.Join(values, ",") {
+			return false
+		}
+	}
+	return true
+}
+
+// toResponse create a new http response from the cached response
+func (c *CachedResponse) toResponse(req *http.Request, status CacheStatus) *http.Response {
+	header := c.Header.Clone()
+	header.Set(CacheStatusHeader, string(status))
+
+	return &http.Response{
+		Status:        fmt.Sprintf("%d %s", c.StatusCode, http.StatusText(c.StatusCode)),
+		StatusCode:    c.StatusCode,
+		Proto:         "HTTP/1.1",
+		ProtoMajor:    1,
+		ProtoMinor:    1,
+		Header:        header,
+		Body:          io.NopCloser(bytes.NewReader(c.Body)),
+		ContentLength: int64(len(c.Body)),
+		Request:       req,
+	}
+}
+
+// isCacheableRequest checks if the response of request could be cached.
+// The conditional requests of caller are not cached, so the caller gets the server response.
+func isCacheableRequest(req *http.Request) bool {
+	return req.Method == http.MethodGet &&
+		req.Header.Get("Range") == "" &&
+		req.Header.Get("If-None-Match") == "" &&
+		req.Header.Get("If-Modified-Since") == ""
+}
+
+// isCacheableResponse checks if the response could be stored
+func isCacheableResponse(resp *http.Response) bool {
+	if resp.StatusCode != http.StatusOK {
+		return false
+	}
+
+	directives := parseCacheControl(resp.Header)
+	if directives.has("no-store") || resp.Header.Get("Vary") == "*" {
+		return false
+	}
+
+	if _, ok := directives.seconds("max-age"); ok {
+		return true
+	}
+	return resp.Header.Get("Expires") != "" || resp.Header.Get("ETag") != "" || resp.Header.Get("Last-Modified") != ""
+}
+
+// varyHeader return the request header values selected by `Vary` response header
+func varyHeader(req *http.Request, respHeader http.Header) http.Header {
+	result := http.Header{}
+	for _, vary := range respHeader.Values("Vary") {
+		for _, key := range strings.Split(vary, ",") {
+			key = http.CanonicalHeaderKey(strings.TrimSpace(key))
+			if key != "" {
+				result[key] = req.Header.Values(key)
+			}
+		}
+	}
+	return result
+}
+
+// cacheControl is the parsed `Cache-Control` header
+type cacheControl map[string]string
+
+func parseCacheControl(header http.Header) cacheControl {
+	result := cacheControl{}
+	for _, value := range header.Values("Cache-Control") {
+		for _, directive := range strings.Split(value, ",") {
+			directive = strings.TrimSpace(directive)
+			if directive == "" {
+				continue
+			}
+			name, arg, _ := strings.Cut(directive, "=")
+			result[strings.ToLower(strings.TrimSpace(name))] = strings.Trim(strings.TrimSpace(arg), `"`)
+		}
+	}
+	return result
+}
+
+func (cc cacheControl) has(name string) bool {
+	_, ok := cc[name]
+	return ok
+}
+
+func (cc cacheControl) seconds(name string) (time.Duration, bool) {
+	value, ok := cc[name]
+	if !ok {
+		return 0, false
+	}
+	n, err := strconv.Atoi(value)
+	if err != nil || n < 0 {
+		return 0, false
+	}
+	return time.Duration(n) * time.Second, true
+}
@@ -0,0 +1,215 @@
+package netutil
+
+import (
+	"context"
+	"fmt"
+	"io"
+	"net/http"
+	"net/http/httptest"
+	"sync/atomic"
+	"testing"
+
+	"github.com/duke-git/lancet/v2/internal"
+)
+
+func newCacheTestServer(count *int32) *httptest.Server {
+	return httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		n := atomic.AddInt32(count, 1)
+
+		switch r.URL.Path {
+		case "/fresh":
+			w.Header().Set("Cache-Control", "max-age=60")
+		case "/etag":
+			w.Header().Set("Cache-Control", "no-cache")
+			w.Header().Set("ETag", `"v1"`)
+			if r.Header.Get("If-None-Match") == `"v1"` {
+				w.WriteHeader(http.StatusNotModified)
+				return
+			}
+		case "/modified":
+			w.Header().Set("Cache-Control", "max-age=0")
+			w.Header().Set("Last-Modified", "Mon, 02 Jan 2006 15:04:05 GMT")
+			if r.Header.Get("If-Modified-Since") != "" {
+				w.WriteHeader(http.StatusNotModified)
+				return
+			}
+		case "/nostore":
+			w.Header().Set("Cache-Control", "no-store")
+		case "/vary":
+			w.Header().Set("Cache-Control", "max-age=60")
+			w.Header().Set("Vary", "Accept-Language")
+		}
+
+		fmt.Fprintf(w, "response %d", n)
+	}))
+}
+
+func getWithCache(client *HttpClient, url string, header ...string) (string, CacheStatus) {
+	req := client.R().Get(url)
+	if len(header) == 2 {
+		req.Header(header[0], header[1])
+	}
+	resp, err := req.Do(context.Background())
+	if err != nil {
+		return err.Error(), ""
+	}
+	defer resp.Body.Close()
+
+	body, _ := io.ReadAll(resp.Body)
+	return string(body), GetCacheStatus(resp)
+}
+
+func TestCacheMiddleware(t *testing.T) {
+	t.Parallel()
+
+	assert := internal.NewAssert(t, "TestCacheMiddleware")
+
+	var count int32
+	server := newCacheTestServer(&count)
+	defer server.Close()
+
+	client := NewHttpClientWithConfig(&HttpClientConfig{CacheStore: NewMemoryCacheStore(10)})
+
+	body, status := getWithCache(client, server.URL+"/fresh")
+	assert.Equal("response 1", body)
+	assert.Equal(CacheMiss, status)
+
+	body, status = getWithCache(client, server.URL+"/fresh")
+	assert.Equal("response 1", body)
+	assert.Equal(CacheHit, status)
+
+	body, status = getWithCache(client, server.URL+"/fresh", "Cache-Control", "no-cache")
+	assert.Equal("response 2", body)
+	assert.Equal(CacheMiss, status)
+
+	body, status = getWithCache(client, server.URL+"/etag")
+	assert.Equal("response 3", body)
+	assert.Equal(CacheMiss, status)
+
+	body, status = getWithCache(client, server.URL+"/etag")
+	assert.Equal("response 3", body)
+	assert.Equal(CacheRevalidated, status)
+	assert.Equal(int32(4), atomic.LoadInt32(&count))
+
+	getWithCache(client, server.URL+"/modified")
+	body, status = getWithCache(client, server.URL+"/modified")
+	assert.Equal("response 5", body)
+	assert.Equal(CacheRevalidated, status)
+
+	getWithCache(client, server.URL+"/nostore")
+	body, status = getWithCache(client, server.URL+"/nostore")
+	assert.Equal("response 8", body)
+	assert.Equal(CacheMiss, status)
+
+	body, _ = getWithCache(client, server.URL+"/vary", "Accept-Language", "en")
+	assert.Equal("response 9", body)
+	body, status = getWithCache(client, server.URL+"/vary", "Accept-Language", "zh")
+	assert.Equal("response 10", body)
+	assert.Equal(CacheMiss, status)
+	body, status = getWithCache(client, server.URL+"/vary", "Accept-Language", "zh")
+	assert.Equal("response 10", body)
+	assert.Equal(CacheHit, status)
+
+	resp, err := client.R().Post(server.URL + "/fresh").Do(context.Background())
+	assert.IsNil(err)
+	resp.Body.Close()
+	assert.Equal(CacheStatus(""), GetCacheStatus(resp))
+}
+
+func TestCacheMiddleware_MaxEntrySize(t *testing.T) {
+	t.Parallel()
+
+	assert := internal.NewAssert(t, "TestCacheMiddleware_MaxEntrySize")
+
+	var count int32
+	server := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		n := atomic.AddInt32(&count, 1)
+		w.Header().Set("Cache-Control", "max-age=60")
+
+		switch r.URL.Path {
+		case "/small":
+			fmt.Fprintf(w, "ok %d", n)
+		case "/large":
+			w.Header().Set("Content-Length", "16")
+			fmt.Fprintf(w, "large response %d", n)
+		case "/chunked":
+			// flush before writing the body, so the response has no Content-Length
+			w.(http.Flusher).Flush()
+			fmt.Fprintf(w, "chunked response %d", n)
+		}
+	}))
+	defer server.Close()
+
+	store := NewMemoryCacheStore(10)
+	client := NewHttpClientWithConfig(&HttpClientConfig{CacheStore: store, CacheMaxEntrySize: 8})
+
+	getWithCache(client, server.URL+"/small")
+	body, status := getWithCache(client, server.URL+"/small")
+	assert.Equal("ok 1", body)
+	assert.Equal(CacheHit, status)
+
+	for _, path := range []string{"/large", "/chunked"} {
+		body, status = getWithCache(client, server.URL+path)
+		assert.Equal(CacheMiss, status)
+		expected := fmt.Sprintf("%s response %d", path[1:], atomic.LoadInt32(&count))
+		assert.Equal(expected, body)
+
+		_, ok := store.Get(http.MethodGet + " " + server.URL + path)
+		assert.Equal(false, ok)
+	}
+}
+
+func TestDiskCacheStore(t *testing.T) {
+	t.Parallel()
+
+	assert := internal.NewAssert(t, "TestDiskCacheStore")
+
+	var count int32
+	server := newCacheTestServer(&count)
+	defer server.Close()
+
+	dir := t.TempDir()
+	store, err := NewDiskCacheStore(dir)
+	assert.IsNil(err)
+
+	client := NewHttpClientWithConfig(&HttpClientConfig{CacheStore: store})
+	body, status := getWithCache(client, server.URL+"/fresh")
+	assert.Equal("response 1", body)
+	assert.Equal(CacheMiss, status)
+
+	// a new store of the same directory shares the cached responses
+	store, err = NewDiskCacheStore(dir)
+	assert.IsNil(err)
+
+	client = NewHttpClientWithConfig(&HttpClientConfig{CacheStore: store})
+	body, status = getWithCache(client, server.URL+"/fresh")
+	assert.Equal("response 1", body)
+	assert.Equal(CacheHit, status)
+
+	key := http.MethodGet + " " + server.URL + "/fresh"
+	assert.IsNil(store.Delete(key))
+	_, ok := store.Get(key)
+	assert.Equal(false, ok)
+	assert.IsNil(store.Delete(key))
+}
+
+func TestMemoryCacheStore(t *testing.T) {
+	t.Parallel()
+
+	assert := internal.NewAssert(t, "TestMemoryCacheStore")
+
+	store := NewMemoryCacheStore(2)
+	store.Set("a", &CachedResponse{StatusCode: 200})
+	store.Set("b", &CachedResponse{StatusCode: 200})
+	store.Get("a")
+	store.Set("c", &CachedResponse{StatusCode: 200})
+
+	_, ok := store.Get("b")
+	assert.Equal(false, ok)
+	_, ok = store.Get("a")
+	assert.Equal(true, ok)
+
+	store.Delete("a")
+	_, ok = store.Get("a")
+	assert.Equal(false, ok)
+}
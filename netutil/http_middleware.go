@@ -0,0 +1,178 @@
+// Copyright 2023 dudaodong@gmail.com. All rights reserved.
+// Use of this source code is governed by MIT license.
+
+package netutil
+
+import (
+	"net/http"
+	"net/url"
+	"time"
+
+	"github.com/duke-git/lancet/v2/random"
+)
+
+// RoundTripFunc is an adapter to allow the use of ordinary function as http.RoundTripper.
+type RoundTripFunc func(req *http.Request) (*http.Response, error)
+
+// RoundTrip calls f(req).
+func (f RoundTripFunc) RoundTrip(req *http.Request) (*http.Response, error) {
+	return f(req)
+}
+
+// Middleware wraps a http.RoundTripper, it could modify the request before calling next,
+// and inspect or modify the response after next returns.
+type Middleware func(next http.RoundTripper) http.RoundTripper
+
+// Use register middlewares to the client. The middlewares are called in the order they are registered,
+// the first registered one is the outermost one, which sees the request first and the response last.
+func (client *HttpClient) Use(middlewares ...Middleware) *HttpClient {
+	client.middlewares = append(client.middlewares, middlewares...)
+	return client
+}
+
+// do send the request through the middleware chain, the cache and retry middlewares are the innermost ones.
+func (client *HttpClient) do(req *http.Request) (*http.Response, error) {
+	var handler http.RoundTripper = RoundTripFunc(client.Client.Do)
+	if client.Config.RetryPolicy != nil {
+		handler = RetryMiddleware(client.Config.RetryPolicy)(handler)
+	}
+	if client.Config.CacheStore != nil {
+		handler = CacheMiddleware(client.Config.CacheStore, client.Config.CacheMaxEntrySize)(handler)
+	}
+	for i := len(client.middlewares) - 1; i >= 0; i-- {
+		handler = client.middlewares[i](handler)
+	}
+
+	return handler.RoundTrip(req)
+}
+
+// BearerAuthMiddleware return a middleware which sets `Authorization: Bearer <token>` header.
+func BearerAuthMiddleware(token string) Middleware {
+	return func(next http.RoundTripper) http.RoundTripper {
+		return RoundTripFunc(func(req *http.Request) (*http.Response, error) {
+			req = cloneRequest(req)
+			req.Header.Set("Authorization", "Bearer "+token)
+			return next.RoundTrip(req)
+		})
+	}
+}
+
+// BasicAuthMiddleware return a middleware which sets basic authentication header.
+func BasicAuthMiddleware(username, password string) Middleware {
+	return func(next http.RoundTripper) http.RoundTripper {
+		return RoundTripFunc(func(req *http.Request) (*http.Response, error) {
+			req = cloneRequest(req)
+			req.SetBasicAuth(username, password)
+			return next.RoundTrip(req)
+		})
+	}
+}
+
+// RequestIDMiddleware return a middleware which sets request id header if the request does not have one.
+// param `header` is the header name, default is `X-Request-ID`; param `generator` generates request id, default is uuid v4.
+func RequestIDMiddleware(header string, generator func() string) Middleware {
+	if header == "" {
+		header = "X-Request-ID"
+	}
+	if generator == nil {
+		generator = func() string {
+			id, _ := random.UUIdV4()
+			return id
+		}
+	}
+
+	return func(next http.RoundTripper) http.RoundTripper {
+		return RoundTripFunc(func(req *http.Request) (*http.Response, error) {
+			if req.Header.Get(header) == "" {
+				req = cloneRequest(req)
+				req.Header.Set(header, generator())
+			}
+			return next.RoundTrip(req)
+		})
+	}
+}
+
+// HttpLogEntry is the structured log of a http request and its response.
+// Sensitive headers are redacted.
+type HttpLogEntry struct {
+	Method         string
+	URL            string
+	StatusCode     int
+	Latency        time.Duration
+	RequestHeader  http.Header
+	ResponseHeader http.Header
+	Err            error
+}
+
+// defaultRedactedHeaders are always redacted in HttpLogEntry
+var defaultRedactedHeaders = []string{"Authorization", "Proxy-Authorization", "Cookie", "Set-Cookie"}
+
+// LoggingMiddleware return a middleware which calls logFunc with the log entry after each request is done.
+// Authorization, Proxy-Authorization, Cookie and Set-Cookie headers are always redacted,
+// param `redactHeaders` specifies other headers to redact.
+func LoggingMiddleware(logFunc func(entry HttpLogEntry), redactHeaders ...string) Middleware {
+	redactHeaders = append(redactHeaders, defaultRedactedHeaders...)
+
+	return func(next http.RoundTripper) http.RoundTripper {
+		return RoundTripFunc(func(req *http.Request) (*http.Response, error) {
+			start := time.Now()
+			resp, err := next.RoundTrip(req)
+
+			entry := HttpLogEntry{
+				Method:        req.Method,
+				URL:           redactURL(req.URL),
+				Latency:       time.Since(start),
+				RequestHeader: RedactHeader(req.Header, redactHeaders...),
+				Err:           err,
+			}
+			if resp != nil {
+				entry.StatusCode = resp.StatusCode
+				entry.ResponseHeader = RedactHeader(resp.Header, redactHeaders...)
+			}
+			logFunc(entry)
+
+			return resp, err
+		})
+	}
+}
+
+// RedactHeader return a copy of header, the values of given keys are replaced with `[REDACTED]`.
+func RedactHeader(header http.Header, keys ...string) http.Header {
+	result := header.Clone()
+	if result == nil {
+		return http.Header{}
+	}
+
+	for _, key := range keys {
+		key = http.CanonicalHeaderKey(key)
+		if values, ok := result[key]; ok {
+			redacted := make([]string, len(values))
+			for i := range redacted {
+				redacted[i] = "[REDACTED]"
+			}
+			result[key] = redacted
+		}
+	}
+
+	return result
+}
+
+// redactURL return the url string with password of userinfo redacted.
+func redactURL(u *url.URL) string {
+	if u == nil {
+		return ""
+	}
+	return u.Redacted()
+}
+
+// cloneRequest return a shallow copy of req with deep copied header,
+// so that middleware does not modify the request of caller.
+func cloneRequest(req *http.Request) *http.Request {
+	r := new(http.Request)
+	*r = *req
+	r.Header = req.Header.Clone()
+	if r.Header == nil {
+		r.Header = make(http.Header)
+	}
+	return r
+}
@@ -0,0 +1,163 @@
+package netutil
+
+import (
+	"net/http"
+	"net/http/httptest"
+	"testing"
+
+	"github.com/duke-git/lancet/v2/internal"
+)
+
+func TestHttpClient_Use(t *testing.T) {
+	t.Parallel()
+
+	assert := internal.NewAssert(t, "TestHttpClient_Use")
+
+	server := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		w.Header().Set("X-Trace", r.Header.Get("X-Trace"))
+		w.WriteHeader(http.StatusTeapot)
+	}))
+	defer server.Close()
+
+	order := []string{}
+	tracer := func(name string) Middleware {
+		return func(next http.RoundTripper) http.RoundTripper {
+			return RoundTripFunc(func(req *http.Request) (*http.Response, error) {
+				order = append(order, "before "+name)
+				req.Header.Add("X-Trace", name)
+				resp, err := next.RoundTrip(req)
+				order = append(order, "after "+name)
+				resp.Header.Set("X-Modified-By", name)
+				return resp, err
+			})
+		}
+	}
+
+	client := NewHttpClient().Use(tracer("a"), tracer("b"))
+	resp, err := client.SendRequest(&HttpRequest{RawURL: server.URL, Method: "GET"})
+	assert.IsNil(err)
+	defer resp.Body.Close()
+
+	assert.Equal(http.StatusTeapot, resp.StatusCode)
+	assert.Equal("a", resp.Header.Get("X-Trace"))
+	assert.Equal("a", resp.Header.Get("X-Modified-By"))
+	assert.Equal([]string{"before a", "before b", "after b", "after a"}, order)
+}
+
+func TestAuthMiddleware(t *testing.T) {
+	t.Parallel()
+
+	assert := internal.NewAssert(t, "TestAuthMiddleware")
+
+	var authHeader string
+	server := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		authHeader = r.Header.Get("Authorization")
+	}))
+	defer server.Close()
+
+	header := http.Header{}
+	request := &HttpRequest{RawURL: server.URL, Method: "GET", Headers: header}
+
+	resp, err := NewHttpClient().Use(BearerAuthMiddleware("token")).SendRequest(request)
+	assert.IsNil(err)
+	resp.Body.Close()
+	assert.Equal("Bearer token", authHeader)
+	assert.Equal("", header.Get("Authorization"))
+
+	resp, err = NewHttpClient().Use(BasicAuthMiddleware("user", "pass")).SendRequest(request)
+	assert.IsNil(err)
+	resp.Body.Close()
+	assert.Equal("Basic dXNlcjpwYXNz", authHeader)
+}
+
+func TestRequestIDMiddleware(t *testing.T) {
+	t.Parallel()
+
+	assert := internal.NewAssert(t, "TestRequestIDMiddleware")
+
+	var requestID string
+	server := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		requestID = r.Header.Get("X-Request-ID")
+	}))
+	defer server.Close()
+
+	client := NewHttpClient().Use(RequestIDMiddleware("", nil))
+	resp, err := client.SendRequest(&HttpRequest{RawURL: server.URL, Method: "GET"})
+	assert.IsNil(err)
+	resp.Body.Close()
+	assert.Equal(36, len(requestID))
+
+	header := http.Header{}
+	header.Set("X-Request-ID", "existed")
+	resp, err = client.SendRequest(&HttpRequest{RawURL: server.URL, Method: "GET", Headers: header})
+	assert.IsNil(err)
+	resp.Body.Close()
+	assert.Equal("existed", requestID)
+
+	client = NewHttpClient().Use(RequestIDMiddleware("X-Correlation-ID", func() string { return "id-1" }))
+	server.Config.Handler = http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		requestID = r.Header.Get("X-Correlation-ID")
+	})
+	resp, err = client.SendRequest(&HttpRequest{RawURL: server.URL, Method: "GET"})
+	assert.IsNil(err)
+	resp.Body.Close()
+	assert.Equal("id-1", requestID)
+}
+
+func TestLoggingMiddleware(t *testing.T) {
+	t.Parallel()
+
+	assert := internal.NewAssert(t, "TestLoggingMiddleware")
+
+	server := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		w.Header().Set("Set-Cookie", "session=secret")
+		w.WriteHeader(http.StatusCreated)
+	}))
+	defer server.Close()
+
+	var entry HttpLogEntry
+	client := NewHttpClient().Use(
+		LoggingMiddleware(func(e HttpLogEntry) { entry = e }, "X-Api-Key"),
+		BearerAuthMiddleware("token"),
+	)
+
+	header := http.Header{}
+	header.Set("X-Api-Key", "key")
+	header.Set("X-Other", "other")
+	resp, err := client.SendRequest(&HttpRequest{RawURL: server.URL + "/path", Method: "POST", Headers: header})
+	assert.IsNil(err)
+	resp.Body.Close()
+
+	assert.Equal("POST", entry.Method)
+	assert.Equal(server.URL+"/path", entry.URL)
+	assert.Equal(http.StatusCreated, entry.StatusCode)
+	assert.Equal(true, entry.Latency > 0)
+	assert.IsNil(entry.Err)
+	assert.Equal("[REDACTED]", entry.RequestHeader.Get("X-Api-Key"))
+	assert.Equal("other", entry.RequestHeader.Get("X-Other"))
+	assert.Equal("[REDACTED]", entry.ResponseHeader.Get("Set-Cookie"))
+	assert.Equal("session=secret", resp.Header.Get("Set-Cookie"))
+
+	client = NewHttpClient().Use(LoggingMiddleware(func(e HttpLogEntry) { entry = e }))
+	_, err = client.SendRequest(&HttpRequest{RawURL: "http://127.0.0.1:1", Method: "GET"})
+	assert.IsNotNil(err)
+	assert.IsNotNil(entry.Err)
+	assert.Equal(0, entry.StatusCode)
+}
+
+func TestRedactHeader(t *testing.T) {
+	t.Parallel()
+
+	assert := internal.NewAssert(t, "TestRedactHeader")
+
+	header := http.Header{}
+	header.Add("Authorization", "Bearer token")
+	header.Add("Accept", "*/*")
+
+	redacted := RedactHeader(header, "authorization")
+	assert.Equal("[REDACTED]", redacted.Get("Authorization"))
+	assert.Equal("*/*", redacted.Get("Accept"))
+	assert.Equal("Bearer token", header.Get("Authorization"))
+
+	assert.Equal(http.Header{}, RedactHeader(nil, "Authorization"))
+}
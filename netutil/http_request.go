@@ -0,0 +1,278 @@
+// Copyright 2023 dudaodong@gmail.com. All rights reserved.
+// Use of this source code is governed by MIT license.
+
+package netutil
+
+import (
+	"bytes"
+	"context"
+	"encoding/json"
+	"errors"
+	"fmt"
+	"io"
+	"net/http"
+	"net/url"
+	"strings"
+	"time"
+)
+
+// httpErrorBodyLimit is the max length of response body kept in HTTPError
+const httpErrorBodyLimit = 4096
+
+// HTTPError is returned by DoJSON when the response status code is not 2xx.
+type HTTPError struct {
+	StatusCode int
+	Status     string
+	Header     http.Header
+	// Body is the leading part of response body, at most 4096 bytes.
+	Body []byte
+}
+
+// Error implements the error interface.
+func (e *HTTPError) Error() string {
+	if len(e.Body) == 0 {
+		return fmt.Sprintf("http error: %s", e.Status)
+	}
+	return fmt.Sprintf("http error: %s: %s", e.Status, strings.TrimSpace(string(e.Body)))
+}
+
+// newHTTPError create HTTPError from response, the response body is consumed and closed.
+func newHTTPError(resp *http.Response) *HTTPError {
+	defer resp.Body.Close()
+
+	body, _ := io.ReadAll(io.LimitReader(resp.Body, httpErrorBodyLimit))
+	io.Copy(io.Discard, resp.Body)
+
+	return &HTTPError{
+		StatusCode: resp.StatusCode,
+		Status:     resp.Status,
+		Header:     resp.Header,
+		Body:       body,
+	}
+}
+
+// RequestBuilder is a fluent http request builder, create it with HttpClient.R().
+// eg. client.R().Get(url).Query("page", "1").Header("X-Token", token).Do(ctx)
+type RequestBuilder struct {
+	client  *HttpClient
+	method  string
+	rawURL  string
+	header  http.Header
+	query   url.Values
+	body    []byte
+	timeout time.Duration
+	err     error
+}
+
+// R return a new request builder which is sent by the client.
+func (client *HttpClient) R() *RequestBuilder {
+	return &RequestBuilder{
+		client: client,
+		method: http.MethodGet,
+		header: make(http.Header),
+		query:  make(url.Values),
+	}
+}
+
+// Get set request method to GET and the url.
+func (r *RequestBuilder) Get(rawURL string) *RequestBuilder {
+	return r.Method(http.MethodGet, rawURL)
+}
+
+// Post set request method to POST and the url.
+func (r *RequestBuilder) Post(rawURL string) *RequestBuilder {
+	return r.Method(http.MethodPost, rawURL)
+}
+
+// Put set request method to PUT and the url.
+func (r *RequestBuilder) Put(rawURL string) *RequestBuilder {
+	return r.Method(http.MethodPut, rawURL)
+}
+
+// Patch set request method to PATCH and the url.
+func (r *RequestBuilder) Patch(rawURL string) *RequestBuilder {
+	return r.Method(http.MethodPatch, rawURL)
+}
+
+// Delete set request method to DELETE and the url.
+func (r *RequestBuilder) Delete(rawURL string) *RequestBuilder {
+	return r.Method(http.MethodDelete, rawURL)
+}
+
+// Head set request method to HEAD and the url.
+func (r *RequestBuilder) Head(rawURL string) *RequestBuilder {
+	return r.Method(http.MethodHead, rawURL)
+}
+
+// Method set request method and the url.
+func (r *RequestBuilder) Method(method, rawURL string) *RequestBuilder {
+	r.method = strings.ToUpper(method)
+	r.rawURL = rawURL
+	return r
+}
+
+// Query add query string param to the request.
+func (r *RequestBuilder) Query(key string, values ...string) *RequestBuilder {
+	for _, v := range values {
+		r.query.Add(key, v)
+	}
+	return r
+}
+
+// QueryValues add all query string params to the request.
+func (r *RequestBuilder) QueryValues(values url.Values) *RequestBuilder {
+	for key, vals := range values {
+		r.Query(key, vals...)
+	}
+	return r
+}
+
+// Header set request header, the existing values of the key are replaced.
+func (r *RequestBuilder) Header(key, value string) *RequestBuilder {
+	r.header.Set(key, value)
+	return r
+}
+
+// Headers set all headers to the request.
+func (r *RequestBuilder) Headers(header http.Header) *RequestBuilder {
+	for key, vals := range header {
+		r.header.Del(key)
+		for _, v := range vals {
+			r.header.Add(key, v)
+		}
+	}
+	return r
+}
+
+// Body set the raw request body.
+func (r *RequestBuilder) Body(body []byte) *RequestBuilder {
+	r.body = body
+	return r
+}
+
+// JSON encode body as json and set `Content-Type: application/json` header.
+func (r *RequestBuilder) JSON(body any) *RequestBuilder {
+	data, err := json.Marshal(body)
+	if err != nil {
+		r.err = err
+		return r
+	}
+	r.body = data
+	r.header.Set("Content-Type", "application/json")
+	return r
+}
+
+// Form encode values as request body and set `Content-Type: application/x-www-form-urlencoded` header.
+func (r *RequestBuilder) Form(values url.Values) *RequestBuilder {
+	r.body = []byte(values.Encode())
+	r.header.Set("Content-Type", "application/x-www-form-urlencoded")
+	return r
+}
+
+// Timeout set the timeout of the request, it includes reading the response body.
+func (r *RequestBuilder) Timeout(timeout time.Duration) *RequestBuilder {
+	r.timeout = timeout
+	return r
+}
+
+// Do send the request with ctx, if ctx is nil, the Context of client is used.
+// The caller should close the response body.
+func (r *RequestBuilder) Do(ctx context.Context) (*http.Response, error) {
+	var body io.Reader
+	if r.body != nil {
+		body = bytes.NewReader(r.body)
+	}
+	return r.doWithBody(ctx, body)
+}
+
+// doWithBody send the request with the given body reader
+func (r *RequestBuilder) doWithBody(ctx context.Context, body io.Reader) (*http.Response, error) {
+	if r.err != nil {
+		return nil, r.err
+	}
+
+	err := validateRequest(&HttpRequest{RawURL: r.rawURL, Method: r.method})
+	if err != nil {
+		return nil, err
+	}
+
+	if ctx == nil {
+		ctx = r.client.Context
+	}
+	if ctx == nil {
+		ctx = context.Background()
+	}
+
+	cancel := context.CancelFunc(func() {})
+	if r.timeout > 0 {
+		ctx, cancel = context.WithTimeout(ctx, r.timeout)
+	}
+
+	req, err := http.NewRequestWithContext(ctx, r.method, r.rawURL, body)
+	if err != nil {
+		cancel()
+		return nil, err
+	}
+
+	if len(r.query) > 0 {
+		query := req.URL.Query()
+		for key, vals := range r.query {
+			for _, v := range vals {
+				query.Add(key, v)
+			}
+		}
+		req.URL.RawQuery = query.Encode()
+	}
+
+	r.client.initTLS()
+	r.client.setHeader(req, r.header.Clone())
+
+	resp, err := r.client.do(req)
+	if err != nil {
+		cancel()
+		return nil, err
+	}
+
+	resp.Body = &cancelReadCloser{ReadCloser: resp.Body, cancel: cancel}
+
+	return resp, nil
+}
+
+// DoJSON send the request and decode the json response body into value of type T.
+// Non 2xx response is returned as *HTTPError. Empty response body decodes to zero value of T.
+func DoJSON[T any](ctx context.Context, r *RequestBuilder) (T, error) {
+	var result T
+
+	if r.header.Get("Accept") == "" {
+		r.Header("Accept", "application/json")
+	}
+
+	resp, err := r.Do(ctx)
+	if err != nil {
+		return result, err
+	}
+
+	if resp.StatusCode < 200 || resp.StatusCode > 299 {
+		return result, newHTTPError(resp)
+	}
+	defer resp.Body.Close()
+
+	err = json.NewDecoder(resp.Body).Decode(&result)
+	if err != nil && !errors.Is(err, io.EOF) {
+		return result, err
+	}
+
+	return result, nil
+}
+
+// cancelReadCloser cancels the request context when the response body is closed
+type cancelReadCloser struct {
+	io.ReadCloser
+	cancel context.CancelFunc
+}
+
+func (c *cancelReadCloser) Close() error {
+	err := c.ReadCloser.Close()
+	c.cancel()
+	return err
+}
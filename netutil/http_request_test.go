@@ -0,0 +1,173 @@
+package netutil
+
+import (
+	"context"
+	"encoding/json"
+	"errors"
+	"io"
+	"net/http"
+	"net/http/httptest"
+	"sync"
+	"testing"
+	"time"
+
+	"github.com/duke-git/lancet/v2/internal"
+)
+
+type echoResult struct {
+	Method string              `json:"method"`
+	Query  map[string][]string `json:"query"`
+	Token  string              `json:"token"`
+	Body   map[string]any      `json:"body"`
+}
+
+func newEchoServer() *httptest.Server {
+	return httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		switch r.URL.Path {
+		case "/slow":
+			time.Sleep(200 * time.Millisecond)
+		case "/notfound":
+			w.Header().Set("X-Reason", "missing")
+			w.WriteHeader(http.StatusNotFound)
+			w.Write([]byte(`{"error":"not found"}`))
+			return
+		case "/empty":
+			w.WriteHeader(http.StatusNoContent)
+			return
+		}
+
+		result := echoResult{
+			Method: r.Method,
+			Query:  r.URL.Query(),
+			Token:  r.Header.Get("X-Token"),
+		}
+		if r.Header.Get("Content-Type") == "application/json" {
+			json.NewDecoder(r.Body).Decode(&result.Body)
+		}
+		json.NewEncoder(w).Encode(result)
+	}))
+}
+
+func TestRequestBuilder_Do(t *testing.T) {
+	t.Parallel()
+
+	assert := internal.NewAssert(t, "TestRequestBuilder_Do")
+
+	server := newEchoServer()
+	defer server.Close()
+
+	client := NewHttpClient()
+	resp, err := client.R().Post(server.URL+"?a=1").
+		Query("b", "2", "3").
+		Header("X-Token", "secret").
+		JSON(map[string]any{"name": "lancet"}).
+		Do(context.Background())
+	assert.IsNil(err)
+
+	var result echoResult
+	err = client.DecodeResponse(resp, &result)
+	assert.IsNil(err)
+
+	assert.Equal(http.MethodPost, result.Method)
+	assert.Equal([]string{"1"}, result.Query["a"])
+	assert.Equal([]string{"2", "3"}, result.Query["b"])
+	assert.Equal("secret", result.Token)
+	assert.Equal("lancet", result.Body["name"])
+
+	_, err = client.R().Method("INVALID", server.URL).Do(context.Background())
+	assert.IsNotNil(err)
+
+	_, err = client.R().Post(server.URL).JSON(make(chan int)).Do(context.Background())
+	assert.IsNotNil(err)
+}
+
+func TestDoJSON(t *testing.T) {
+	t.Parallel()
+
+	assert := internal.NewAssert(t, "TestDoJSON")
+
+	server := newEchoServer()
+	defer server.Close()
+
+	client := NewHttpClient()
+
+	result, err := DoJSON[echoResult](context.Background(), client.R().Put(server.URL).Header("X-Token", "t"))
+	assert.IsNil(err)
+	assert.Equal(http.MethodPut, result.Method)
+	assert.Equal("t", result.Token)
+
+	empty, err := DoJSON[*echoResult](context.Background(), client.R().Get(server.URL+"/empty"))
+	assert.IsNil(err)
+	assert.IsNil(empty)
+
+	_, err = DoJSON[echoResult](context.Background(), client.R().Get(server.URL+"/notfound"))
+	assert.IsNotNil(err)
+
+	var httpErr *HTTPError
+	assert.Equal(true, errors.As(err, &httpErr))
+	assert.Equal(http.StatusNotFound, httpErr.StatusCode)
+	assert.Equal("missing", httpErr.Header.Get("X-Reason"))
+	assert.Equal(`{"error":"not found"}`, string(httpErr.Body))
+	assert.Equal(`http error: 404 Not Found: {"error":"not found"}`, httpErr.Error())
+}
+
+func TestRequestBuilder_Timeout(t *testing.T) {
+	t.Parallel()
+
+	assert := internal.NewAssert(t, "TestRequestBuilder_Timeout")
+
+	server := newEchoServer()
+	defer server.Close()
+
+	client := NewHttpClient()
+
+	_, err := client.R().Get(server.URL + "/slow").Timeout(20 * time.Millisecond).Do(context.Background())
+	assert.IsNotNil(err)
+	assert.Equal(true, errors.Is(err, context.DeadlineExceeded))
+
+	ctx, cancel := context.WithCancel(context.Background())
+	cancel()
+	_, err = client.R().Get(server.URL).Do(ctx)
+	assert.Equal(true, errors.Is(err, context.Canceled))
+
+	resp, err := client.R().Get(server.URL).Timeout(time.Second).Do(context.Background())
+	assert.IsNil(err)
+	_, err = io.ReadAll(resp.Body)
+	assert.IsNil(err)
+	assert.IsNil(resp.Body.Close())
+}
+
+func TestRequestBuilder_ConcurrentTLS(t *testing.T) {
+	t.Parallel()
+
+	assert := internal.NewAssert(t, "TestRequestBuilder_ConcurrentTLS")
+
+	server := httptest.NewTLSServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		w.Write([]byte("ok"))
+	}))
+	defer server.Close()
+
+	client := NewHttpClient()
+	client.TLS = server.Client().Transport.(*http.Transport).TLSClientConfig
+
+	var wg sync.WaitGroup
+	errs := make(chan error, 8)
+	for i := 0; i < 8; i++ {
+		wg.Add(1)
+		go func() {
+			defer wg.Done()
+			resp, err := client.R().Get(server.URL).Do(context.Background())
+			if err == nil {
+				_, err = io.ReadAll(resp.Body)
+				resp.Body.Close()
+			}
+			errs <- err
+		}()
+	}
+	wg.Wait()
+	close(errs)
+
+	for err := range errs {
+		assert.IsNil(err)
+	}
+}
@@ -0,0 +1,192 @@
+// Copyright 2023 dudaodong@gmail.com. All rights reserved.
+// Use of this source code is governed by MIT license.
+
+package netutil
+
+import (
+	"bytes"
+	"io"
+	"net/http"
+	"strconv"
+	"strings"
+	"time"
+
+	"github.com/duke-git/lancet/v2/retry"
+	"github.com/duke-git/lancet/v2/slice"
+)
+
+// RetryPolicy decides when and how a failed http request is retried.
+type RetryPolicy struct {
+	// MaxRetries is the max number of retries, the first attempt is not counted.
+	MaxRetries uint
+	// Backoff creates the backoff strategy for each request, default is linear backoff with 1 second interval.
+	Backoff func() retry.BackoffStrategy
+	// StatusCodes are the response status codes to retry.
+	StatusCodes []int
+	// RetryOnNetworkError retries the request when it fails without response, eg. connection refused.
+	RetryOnNetworkError bool
+	// RetryNonIdempotent retries the non-idempotent requests, eg. POST and PATCH.
+	// Request with `Idempotency-Key` header is always regarded as idempotent.
+	RetryNonIdempotent bool
+	// MaxRetryAfter caps the wait time specified by `Retry-After` response header, zero means no cap.
+	MaxRetryAfter time.Duration
+}
+
+// DefaultRetryPolicy return a RetryPolicy which retries at most 3 times on network error and 429, 502, 503, 504 status,
+// with exponential backoff starting from 100 milliseconds.
+func DefaultRetryPolicy() *RetryPolicy {
+	return &RetryPolicy{
+		MaxRetries: 3,
+		Backoff: func() retry.BackoffStrategy {
+			return retry.NewExponentialWithJitterBackoff(100*time.Millisecond, 2, 50*time.Millisecond)
+		},
+		StatusCodes: []int{
+			http.StatusTooManyRequests,
+			http.StatusBadGateway,
+			http.StatusServiceUnavailable,
+			http.StatusGatewayTimeout,
+		},
+		RetryOnNetworkError: true,
+		MaxRetryAfter:       time.Minute,
+	}
+}
+
+// RetryMiddleware return a middleware which retries the request according to the policy.
+// The request body is buffered, so it could be sent again in each attempt.
+// HttpClient uses it automatically when HttpClientConfig.RetryPolicy is set.
+func RetryMiddleware(policy *RetryPolicy) Middleware {
+	return func(next http.RoundTripper) http.RoundTripper {
+		return RoundTripFunc(func(req *http.Request) (*http.Response, error) {
+			if policy == nil || policy.MaxRetries == 0 || !policy.canRetry(req) {
+				return next.RoundTrip(req)
+			}
+
+			if err := bufferRequestBody(req); err != nil {
+				return nil, err
+			}
+
+			var backoff retry.BackoffStrategy
+			if policy.Backoff != nil {
+				backoff = policy.Backoff()
+			} else {
+				backoff = retry.NewLinearBackoff(time.Second)
+			}
+
+			for attempt := uint(0); ; attempt++ {
+				attemptReq, err := rewindRequest(req)
+				if err != nil {
+					return nil, err
+				}
+
+				resp, err := next.RoundTrip(attemptReq)
+				if attempt >= policy.MaxRetries || !policy.shouldRetry(req, resp, err) {
+					return resp, err
+				}
+
+				wait := backoff.CalculateInterval()
+				if retryAfter, ok := parseRetryAfter(resp); ok {
+					if policy.MaxRetryAfter > 0 && retryAfter > policy.MaxRetryAfter {
+						retryAfter = policy.MaxRetryAfter
+					}
+					wait = retryAfter
+				}
+
+				if resp != nil {
+					io.Copy(io.Discard, resp.Body)
+					resp.Body.Close()
+				}
+
+				timer := time.NewTimer(wait)
+				select {
+				case <-timer.C:
+				case <-req.Context().Done():
+					timer.Stop()
+					return nil, req.Context().Err()
+				}
+			}
+		})
+	}
+}
+
+// canRetry checks if the request is allowed to retry by its method
+func (policy *RetryPolicy) canRetry(req *http.Request) bool {
+	if policy.RetryNonIdempotent || req.Header.Get("Idempotency-Key") != "" {
+		return true
+	}
+
+	idempotentMethods := []string{http.MethodGet, http.MethodHead, http.MethodOptions,
+		http.MethodTrace, http.MethodPut, http.MethodDelete}
+
+	return slice.Contain(idempotentMethods, strings.ToUpper(req.Method))
+}
+
+// shouldRetry checks if the result of an attempt should be retried
+func (policy *RetryPolicy) shouldRetry(req *http.Request, resp *http.Response, err error) bool {
+	if req.Context().Err() != nil {
+		return false
+	}
+	if err != nil {
+		return policy.RetryOnNetworkError
+	}
+	return slice.Contain(policy.StatusCodes, resp.StatusCode)
+}
+
+// bufferRequestBody read the request body into memory and set GetBody, so it could be rewound.
+func bufferRequestBody(req *http.Request) error {
+	if req.Body == nil || req.Body == http.NoBody || req.GetBody != nil {
+		return nil
+	}
+
+	data, err := io.ReadAll(req.Body)
+	req.Body.Close()
+	if err != nil {
+		return err
+	}
+
+	req.GetBody = func() (io.ReadCloser, error) {
+		return io.NopCloser(bytes.NewReader(data)), nil
+	}
+	req.Body, _ = req.GetBody()
+	req.ContentLength = int64(len(data))
+
+	return nil
+}
+
+// rewindRequest return a copy of request with a fresh body
+func rewindRequest(req *http.Request) (*http.Request, error) {
+	r := cloneRequest(req)
+	if req.GetBody != nil {
+		body, err := req.GetBody()
+		if err != nil {
+			return nil, err
+		}
+		r.Body = body
+	}
+	return r, nil
+}
+
+// parseRetryAfter parse `Retry-After` header, which is either seconds or http date.
+func parseRetryAfter(resp *http.Response) (time.Duration, bool) {
+	if resp == nil {
+		return 0, false
+	}
+
+	value := strings.TrimSpace(resp.Header.Get("Retry-After"))
+	if value == "" {
+		return 0, false
+	}
+
+	if seconds, err := strconv.Atoi(value); err == nil && seconds >= 0 {
+		return time.Duration(seconds) * time.Second, true
+	}
+
+	if date, err := http.ParseTime(value); err == nil {
+		wait := time.Until(date)
+		if wait < 0 {
+			wait = 0
+		}
+		return wait, true
+	}
+
+	return 0, false
+}
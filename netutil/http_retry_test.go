@@ -0,0 +1,205 @@
+package netutil
+
+import (
+	"context"
+	"io"
+	"net/http"
+	"net/http/httptest"
+	"strings"
+	"sync/atomic"
+	"testing"
+	"time"
+
+	"github.com/duke-git/lancet/v2/internal"
+	"github.com/duke-git/lancet/v2/retry"
+)
+
+func fastRetryPolicy() *RetryPolicy {
+	policy := DefaultRetryPolicy()
+	policy.Backoff = func() retry.BackoffStrategy {
+		return retry.NewLinearBackoff(time.Millisecond)
+	}
+	return policy
+}
+
+func TestRetryPolicy_StatusCode(t *testing.T) {
+	t.Parallel()
+
+	assert := internal.NewAssert(t, "TestRetryPolicy_StatusCode")
+
+	var count int32
+	server := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		if atomic.AddInt32(&count, 1) < 3 {
+			w.WriteHeader(http.StatusServiceUnavailable)
+			return
+		}
+		w.WriteHeader(http.StatusOK)
+	}))
+	defer server.Close()
+
+	client := NewHttpClientWithConfig(&HttpClientConfig{RetryPolicy: fastRetryPolicy()})
+	resp, err := client.SendRequest(&HttpRequest{RawURL: server.URL, Method: "GET"})
+	assert.IsNil(err)
+	defer resp.Body.Close()
+
+	assert.Equal(http.StatusOK, resp.StatusCode)
+	assert.Equal(int32(3), atomic.LoadInt32(&count))
+
+	policy := fastRetryPolicy()
+	policy.MaxRetries = 1
+	atomic.StoreInt32(&count, 0)
+
+	client = NewHttpClientWithConfig(&HttpClientConfig{RetryPolicy: policy})
+	resp, err = client.SendRequest(&HttpRequest{RawURL: server.URL, Method: "GET"})
+	assert.IsNil(err)
+	defer resp.Body.Close()
+
+	assert.Equal(http.StatusServiceUnavailable, resp.StatusCode)
+	assert.Equal(int32(2), atomic.LoadInt32(&count))
+}
+
+func TestRetryPolicy_RetryAfter(t *testing.T) {
+	t.Parallel()
+
+	assert := internal.NewAssert(t, "TestRetryPolicy_RetryAfter")
+
+	var count int32
+	server := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		if atomic.AddInt32(&count, 1) == 1 {
+			w.Header().Set("Retry-After", "100")
+			w.WriteHeader(http.StatusTooManyRequests)
+			return
+		}
+		w.WriteHeader(http.StatusOK)
+	}))
+	defer server.Close()
+
+	policy := fastRetryPolicy()
+	policy.MaxRetryAfter = 50 * time.Millisecond
+
+	client := NewHttpClientWithConfig(&HttpClientConfig{RetryPolicy: policy})
+
+	start := time.Now()
+	resp, err := client.SendRequest(&HttpRequest{RawURL: server.URL, Method: "GET"})
+	elapsed := time.Since(start)
+	assert.IsNil(err)
+	defer resp.Body.Close()
+
+	assert.Equal(http.StatusOK, resp.StatusCode)
+	assert.GreaterOrEqual(elapsed, 50*time.Millisecond)
+	assert.Less(elapsed, 10*time.Second)
+}
+
+func TestRetryPolicy_NonIdempotent(t *testing.T) {
+	t.Parallel()
+
+	assert := internal.NewAssert(t, "TestRetryPolicy_NonIdempotent")
+
+	var count int32
+	bodies := make(chan string, 10)
+	server := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		data, _ := io.ReadAll(r.Body)
+		bodies <- string(data)
+		if atomic.AddInt32(&count, 1) <= 2 {
+			w.WriteHeader(http.StatusBadGateway)
+			return
+		}
+		w.WriteHeader(http.StatusCreated)
+	}))
+	defer server.Close()
+
+	client := NewHttpClientWithConfig(&HttpClientConfig{RetryPolicy: fastRetryPolicy()})
+
+	resp, err := client.SendRequest(&HttpRequest{RawURL: server.URL, Method: "POST", Body: []byte("payload")})
+	assert.IsNil(err)
+	resp.Body.Close()
+	assert.Equal(http.StatusBadGateway, resp.StatusCode)
+	assert.Equal(int32(1), atomic.LoadInt32(&count))
+	assert.Equal("payload", <-bodies)
+
+	header := http.Header{}
+	header.Set("Idempotency-Key", "abc")
+	resp, err = client.SendRequest(&HttpRequest{RawURL: server.URL, Method: "POST", Headers: header, Body: []byte("payload")})
+	assert.IsNil(err)
+	resp.Body.Close()
+	assert.Equal(http.StatusCreated, resp.StatusCode)
+	assert.Equal(int32(3), atomic.LoadInt32(&count))
+	assert.Equal("payload", <-bodies)
+	assert.Equal("payload", <-bodies)
+}
+
+func TestRetryPolicy_NetworkError(t *testing.T) {
+	t.Parallel()
+
+	assert := internal.NewAssert(t, "TestRetryPolicy_NetworkError")
+
+	var count int32
+	failing := RoundTripFunc(func(req *http.Request) (*http.Response, error) {
+		if atomic.AddInt32(&count, 1) < 3 {
+			return nil, io.ErrUnexpectedEOF
+		}
+		return &http.Response{StatusCode: http.StatusOK, Body: io.NopCloser(strings.NewReader(""))}, nil
+	})
+
+	req, _ := http.NewRequest(http.MethodGet, "http://example.com", nil)
+
+	resp, err := RetryMiddleware(fastRetryPolicy())(failing).RoundTrip(req)
+	assert.IsNil(err)
+	assert.Equal(http.StatusOK, resp.StatusCode)
+	assert.Equal(int32(3), atomic.LoadInt32(&count))
+
+	policy := fastRetryPolicy()
+	policy.RetryOnNetworkError = false
+	atomic.StoreInt32(&count, 0)
+
+	_, err = RetryMiddleware(policy)(failing).RoundTrip(req)
+	assert.Equal(io.ErrUnexpectedEOF, err)
+	assert.Equal(int32(1), atomic.LoadInt32(&count))
+}
+
+func TestRetryPolicy_ContextCanceled(t *testing.T) {
+	t.Parallel()
+
+	assert := internal.NewAssert(t, "TestRetryPolicy_ContextCanceled")
+
+	unavailable := RoundTripFunc(func(req *http.Request) (*http.Response, error) {
+		return &http.Response{StatusCode: http.StatusServiceUnavailable, Body: io.NopCloser(strings.NewReader(""))}, nil
+	})
+
+	policy := fastRetryPolicy()
+	policy.Backoff = func() retry.BackoffStrategy {
+		return retry.NewLinearBackoff(time.Hour)
+	}
+
+	ctx, cancel := context.WithTimeout(context.Background(), 20*time.Millisecond)
+	defer cancel()
+
+	req, _ := http.NewRequestWithContext(ctx, http.MethodGet, "http://example.com", nil)
+
+	_, err := RetryMiddleware(policy)(unavailable).RoundTrip(req)
+	assert.Equal(context.DeadlineExceeded, err)
+}
+
+func TestParseRetryAfter(t *testing.T) {
+	t.Parallel()
+
+	assert := internal.NewAssert(t, "TestParseRetryAfter")
+
+	resp := &http.Response{Header: http.Header{}}
+	_, ok := parseRetryAfter(resp)
+	assert.ShouldBeFalse(ok)
+
+	resp.Header.Set("Retry-After", "3")
+	wait, ok := parseRetryAfter(resp)
+	assert.ShouldBeTrue(ok)
+	assert.Equal(3*time.Second, wait)
+
+	resp.Header.Set("Retry-After", time.Now().Add(time.Hour).UTC().Format(http.TimeFormat))
+	wait, ok = parseRetryAfter(resp)
+	assert.ShouldBeTrue(ok)
+	assert.Greater(wait, 59*time.Minute)
+
+	resp.Header.Set("Retry-After", "invalid")
+	_, ok = parseRetryAfter(resp)
+	assert.ShouldBeFalse(ok)
+}
@@ -0,0 +1,367 @@
+// Copyright 2023 dudaodong@gmail.com. All rights reserved.
+// Use of this source code is governed by MIT license.
+
+package netutil
+
+import (
+	"context"
+	"encoding/json"
+	"errors"
+	"fmt"
+	"io"
+	"net"
+	"net/http"
+	"runtime/debug"
+	"strconv"
+	"strings"
+	"time"
+
+	"github.com/duke-git/lancet/v2/slice"
+	"github.com/duke-git/lancet/v2/validator"
+)
+
+// maxJSONBodySize is the max size of request body decoded by JSONHandler
+const maxJSONBodySize = 10 << 20
+
+// defaultShutdownTimeout is the max time to wait for active connections when shutting down server
+const defaultShutdownTimeout = 10 * time.Second
+
+// APIError is the structured error response of server, it is written as json:
+// {"code": "...", "message": "...", "details": ...}
+type APIError struct {
+	StatusCode int    `json:"-"`
+	Code       string `json:"code"`
+	Message    string `json:"message"`
+	Details    any    `json:"details,omitempty"`
+}
+
+// NewAPIError return an APIError pointer.
+func NewAPIError(statusCode int, code, message string) *APIError {
+	return &APIError{StatusCode: statusCode, Code: code, Message: message}
+}
+
+// Error implements the error interface.
+func (e *APIError) Error() string {
+	return fmt.Sprintf("%s: %s", e.Code, e.Message)
+}
+
+// WithDetails return a copy of the error with details.
+func (e *APIError) WithDetails(details any) *APIError {
+	err := *e
+	err.Details = details
+	return &err
+}
+
+// WriteJSON write v as json response with status code.
+func WriteJSON(w http.ResponseWriter, statusCode int, v any) error {
+	w.Header().Set("Content-Type", "application/json; charset=utf-8")
+	w.WriteHeader(statusCode)
+	return json.NewEncoder(w).Encode(v)
+}
+
+// WriteError write err as json error response. If err is not an APIError, a 500 response is written
+// without exposing the error message.
+func WriteError(w http.ResponseWriter, err error) error {
+	var apiErr *APIError
+	if !errors.As(err, &apiErr) {
+		apiErr = NewAPIError(http.StatusInternalServerError, "internal_error", http.StatusText(http.StatusInternalServerError))
+	}
+
+	statusCode := apiErr.StatusCode
+	if statusCode == 0 {
+		statusCode = http.StatusInternalServerError
+	}
+
+	return WriteJSON(w, statusCode, apiErr)
+}
+
+// JSONHandler return a http.Handler which decodes the json request body into Req, validates it,
+// calls fn and writes the result as json response.
+// Req is validated by its `validate` struct tags, and its Validate() error method if it has one.
+// For GET, HEAD and DELETE request without body, Req is left as zero value.
+// The error returned by fn is written by WriteError.
+func JSONHandler[Req, Resp any](fn func(ctx context.Context, req Req) (Resp, error)) http.Handler {
+	return http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		var req Req
+
+		if r.Body != nil && r.Body != http.NoBody && r.ContentLength != 0 {
+			// count the bytes read by MaxBytesReader, it reads one more byte than the limit when the body is too large
+			counter := &progressReader{reader: r.Body}
+			body := http.MaxBytesReader(w, io.NopCloser(counter), maxJSONBodySize)
+			err := json.NewDecoder(body).Decode(&req)
+			if err != nil && counter.transferred > maxJSONBodySize {
+				WriteError(w, NewAPIError(http.StatusRequestEntityTooLarge, "request_too_large", "request body too large"))
+				return
+			}
+			if err != nil && !(errors.Is(err, io.EOF) && r.ContentLength < 0) {
+				WriteError(w, NewAPIError(http.StatusBadRequest, "invalid_request", "invalid json body: "+err.Error()))
+				return
+			}
+		}
+
+		if fieldErrors := validator.ValidateStruct(req); len(fieldErrors) > 0 {
+			WriteError(w, NewAPIError(http.StatusUnprocessableEntity, "validation_failed", "request validation failed").
+				WithDetails(fieldErrors))
+			return
+		}
+
+		if v, ok := any(req).(interface{ Validate() error }); ok {
+			if err := v.Validate(); err != nil {
+				var apiErr *APIError
+				if !errors.As(err, &apiErr) {
+					apiErr = NewAPIError(http.StatusUnprocessableEntity, "validation_failed", err.Error())
+				}
+				WriteError(w, apiErr)
+				return
+			}
+		}
+
+		resp, err := fn(r.Context(), req)
+		if err != nil {
+			WriteError(w, err)
+			return
+		}
+
+		WriteJSON(w, http.StatusOK, resp)
+	})
+}
+
+// HandlerMiddleware wraps a http.Handler on server side.
+type HandlerMiddleware func(next http.Handler) http.Handler
+
+// ChainHandler wraps handler with middlewares, the first middleware is the outermost one.
+func ChainHandler(handler http.Handler, middlewares ...HandlerMiddleware) http.Handler {
+	for i := len(middlewares) - 1; i >= 0; i-- {
+		handler = middlewares[i](handler)
+	}
+	return handler
+}
+
+// RecoveryMiddleware return a middleware which recovers the panic of handler and writes a 500 error response.
+// If the handler has already written the response header, the connection is aborted instead.
+// param `onPanic` is called with the recovered value and stack, it could be nil.
+func RecoveryMiddleware(onPanic func(r *http.Request, err any, stack []byte)) HandlerMiddleware {
+	return func(next http.Handler) http.Handler {
+		return http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+			recorder := &statusRecorder{ResponseWriter: w}
+
+			defer func() {
+				if err := recover(); err != nil {
+					if err == http.ErrAbortHandler {
+						panic(err)
+					}
+					if onPanic != nil {
+						onPanic(r, err, debug.Stack())
+					}
+					if recorder.statusCode != 0 {
+						panic(http.ErrAbortHandler)
+					}
+					WriteError(w, fmt.Errorf("%v", err))
+				}
+			}()
+
+			next.ServeHTTP(recorder, r)
+		})
+	}
+}
+
+// AccessLogEntry is the log of a request handled by server.
+type AccessLogEntry struct {
+	Method     string
+	Path       string
+	RemoteAddr string
+	StatusCode int
+	Bytes      int64
+	Latency    time.Duration
+}
+
+// AccessLogMiddleware return a middleware which calls logFunc with the log entry after each request is handled.
+func AccessLogMiddleware(logFunc func(entry AccessLogEntry)) HandlerMiddleware {
+	return func(next http.Handler) http.Handler {
+		return http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+			start := time.Now()
+			recorder := &statusRecorder{ResponseWriter: w}
+
+			defer func() {
+				statusCode := recorder.statusCode
+				if statusCode == 0 {
+					statusCode = http.StatusOK
+				}
+				logFunc(AccessLogEntry{
+					Method:     r.Method,
+					Path:       r.URL.Path,
+					RemoteAddr: r.RemoteAddr,
+					StatusCode: statusCode,
+					Bytes:      recorder.bytes,
+					Latency:    time.Since(start),
+				})
+			}()
+
+			next.ServeHTTP(recorder, r)
+		})
+	}
+}
+
+// statusRecorder records the status code and size of response
+type statusRecorder struct {
+	http.ResponseWriter
+	statusCode int
+	bytes      int64
+}
+
+func (r *statusRecorder) WriteHeader(statusCode int) {
+	if r.statusCode == 0 {
+		r.statusCode = statusCode
+	}
+	r.ResponseWriter.WriteHeader(statusCode)
+}
+
+func (r *statusRecorder) Write(data []byte) (int, error) {
+	if r.statusCode == 0 {
+		r.statusCode = http.StatusOK
+	}
+	n, err := r.ResponseWriter.Write(data)
+	r.bytes += int64(n)
+	return n, err
+}
+
+// Flush implements http.Flusher if the underlying ResponseWriter does.
+func (r *statusRecorder) Flush() {
+	if f, ok := r.ResponseWriter.(http.Flusher); ok {
+		f.Flush()
+	}
+}
+
+// CORSOptions are the options of CORSMiddleware.
+type CORSOptions struct {
+	// AllowedOrigins is the list of allowed origins, `*` allows all origins.
+	AllowedOrigins []string
+	// AllowedMethods default is GET, HEAD, POST, PUT, PATCH and DELETE.
+	AllowedMethods []string
+	// AllowedHeaders is the list of allowed request headers, empty means the requested headers are allowed.
+	AllowedHeaders []string
+	// ExposedHeaders is the list of response headers which could be read by browser.
+	ExposedHeaders []string
+	// AllowCredentials allows cookies and authorization headers.
+	AllowCredentials bool
+	// MaxAge is how long the preflight response could be cached.
+	MaxAge time.Duration
+}
+
+// CORSMiddleware return a middleware which handles cross origin requests, the preflight requests are answered
+// with 204 response and are not passed to the handler.
+func CORSMiddleware(options CORSOptions) HandlerMiddleware {
+	if len(options.AllowedMethods) == 0 {
+		options.AllowedMethods = []string{http.MethodGet, http.MethodHead, http.MethodPost,
+			http.MethodPut, http.MethodPatch, http.MethodDelete}
+	}
+	allowAll := slice.Contain(options.AllowedOrigins, "*")
+
+	return func(next http.Handler) http.Handler {
+		return http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+			origin := r.Header.Get("Origin")
+			preflight := r.Method == http.MethodOptions && r.Header.Get("Access-Control-Request-Method") != ""
+
+			header := w.Header()
+			header.Add("Vary", "Origin")
+
+			if origin == "" || !(allowAll || slice.Contain(options.AllowedOrigins, origin)) {
+				if preflight {
+					w.WriteHeader(http.StatusNoContent)
+					return
+				}
+				next.ServeHTTP(w, r)
+				return
+			}
+
+			if allowAll && !options.AllowCredentials {
+				header.Set("Access-Control-Allow-Origin", "*")
+			} else {
+				header.Set("Access-Control-Allow-Origin", origin)
+			}
+			if options.AllowCredentials {
+				header.Set("Access-Control-Allow-Credentials", "true")
+			}
+
+			if !preflight {
+				if len(options.ExposedHeaders) > 0 {
+					header.Set("Access-Control-Expose-Headers", strings.Join(options.ExposedHeaders, ", "))
+				}
+				next.ServeHTTP(w, r)
+				return
+			}
+
+			header.Add("Vary", "Access-Control-Request-Method")
+			header.Add("Vary", "Access-Control-Request-Headers")
+
+			method := r.Header.Get("Access-Control-Request-Method")
+			if !slice.Contain(options.AllowedMethods, method) {
+				w.WriteHeader(http.StatusNoContent)
+				return
+			}
+			header.Set("Access-Control-Allow-Methods", strings.Join(options.AllowedMethods, ", "))
+
+			if len(options.AllowedHeaders) > 0 {
+				header.Set("Access-Control-Allow-Headers", strings.Join(options.AllowedHeaders, ", "))
+			} else if requested := r.Header.Get("Access-Control-Request-Headers"); requested != "" {
+				header.Set("Access-Control-Allow-Headers", requested)
+			}
+			if options.MaxAge > 0 {
+				header.Set("Access-Control-Max-Age", strconv.Itoa(int(options.MaxAge.Seconds())))
+			}
+
+			w.WriteHeader(http.StatusNoContent)
+		})
+	}
+}
+
+// Serve start the server and shut it down gracefully when ctx is done, it waits at most 10 seconds
+// for active connections. It returns nil if the server is shut down by ctx.
+func Serve(ctx context.Context, srv *http.Server) error {
+	addr := srv.Addr
+	if addr == "" {
+		addr = ":http"
+		if srv.TLSConfig != nil {
+			addr = ":https"
+		}
+	}
+
+	listener, err := net.Listen("tcp", addr)
+	if err != nil {
+		return err
+	}
+
+	return ServeListener(ctx, srv, listener)
+}
+
+// ServeListener serve on the listener and shut down the server gracefully when ctx is done.
+// If srv.TLSConfig has certificates, the server serves https.
+func ServeListener(ctx context.Context, srv *http.Server, listener net.Listener) error {
+	errChan := make(chan error, 1)
+	go func() {
+		if srv.TLSConfig != nil && (len(srv.TLSConfig.Certificates) > 0 || srv.TLSConfig.GetCertificate != nil) {
+			errChan <- srv.ServeTLS(listener, "", "")
+		} else {
+			errChan <- srv.Serve(listener)
+		}
+	}()
+
+	select {
+	case err := <-errChan:
+		return err
+	case <-ctx.Done():
+	}
+
+	shutdownCtx, cancel := context.WithTimeout(context.Background(), defaultShutdownTimeout)
+	defer cancel()
+
+	if err := srv.Shutdown(shutdownCtx); err != nil {
+		return err
+	}
+
+	if err := <-errChan; err != nil && !errors.Is(err, http.ErrServerClosed) {
+		return err
+	}
+
+	return nil
+}
@@ -0,0 +1,220 @@
+package netutil
+
+import (
+	"context"
+	"errors"
+	"net"
+	"net/http"
+	"net/http/httptest"
+	"strings"
+	"testing"
+	"time"
+
+	"github.com/duke-git/lancet/v2/internal"
+)
+
+type createUserRequest struct {
+	Name  string `json:"name" validate:"required"`
+	Email string `json:"email" validate:"required,email"`
+}
+
+func (r createUserRequest) Validate() error {
+	if r.Name == "admin" {
+		return NewAPIError(http.StatusConflict, "name_taken", "name is taken")
+	}
+	return nil
+}
+
+type createUserResponse struct {
+	ID   int    `json:"id"`
+	Name string `json:"name"`
+}
+
+func TestJSONHandler(t *testing.T) {
+	t.Parallel()
+
+	assert := internal.NewAssert(t, "TestJSONHandler")
+
+	handler := JSONHandler(func(ctx context.Context, req createUserRequest) (createUserResponse, error) {
+		if req.Name == "error" {
+			return createUserResponse{}, errors.New("database is down")
+		}
+		if req.Name == "missing" {
+			return createUserResponse{}, NewAPIError(http.StatusNotFound, "not_found", "user not found")
+		}
+		return createUserResponse{ID: 1, Name: req.Name}, nil
+	})
+
+	tests := []struct {
+		body       string
+		statusCode int
+		response   string
+	}{
+		{`{"name":"lancet","email":"lancet@example.com"}`, http.StatusOK, `{"id":1,"name":"lancet"}`},
+		{`{"name":`, http.StatusBadRequest, `"code":"invalid_request"`},
+		{`{"name":"lancet","email":"invalid"}`, http.StatusUnprocessableEntity,
+			`{"code":"validation_failed","message":"request validation failed","details":{"email":"should be a valid email"}}`},
+		{`{"name":"admin","email":"admin@example.com"}`, http.StatusConflict, `{"code":"name_taken","message":"name is taken"}`},
+		{`{"name":"missing","email":"a@example.com"}`, http.StatusNotFound, `{"code":"not_found","message":"user not found"}`},
+		{`{"name":"error","email":"a@example.com"}`, http.StatusInternalServerError,
+			`{"code":"internal_error","message":"Internal Server Error"}`},
+	}
+
+	for _, tt := range tests {
+		req := httptest.NewRequest(http.MethodPost, "/users", strings.NewReader(tt.body))
+		w := httptest.NewRecorder()
+		handler.ServeHTTP(w, req)
+
+		assert.Equal(tt.statusCode, w.Code)
+		assert.Equal("application/json; charset=utf-8", w.Header().Get("Content-Type"))
+		assert.Equal(true, strings.Contains(w.Body.String(), tt.response))
+	}
+
+	large := `{"name":"` + strings.Repeat("a", maxJSONBodySize) + `","email":"a@example.com"}`
+	req := httptest.NewRequest(http.MethodPost, "/users", strings.NewReader(large))
+	w := httptest.NewRecorder()
+	handler.ServeHTTP(w, req)
+	assert.Equal(http.StatusRequestEntityTooLarge, w.Code)
+	assert.Equal(true, strings.Contains(w.Body.String(), `"code":"request_too_large"`))
+}
+
+func TestRecoveryAndAccessLogMiddleware(t *testing.T) {
+	t.Parallel()
+
+	assert := internal.NewAssert(t, "TestRecoveryAndAccessLogMiddleware")
+
+	var recovered any
+	var entry AccessLogEntry
+
+	handler := ChainHandler(
+		http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+			if r.URL.Path == "/panic" {
+				panic("boom")
+			}
+			w.WriteHeader(http.StatusAccepted)
+			w.Write([]byte("ok"))
+		}),
+		AccessLogMiddleware(func(e AccessLogEntry) { entry = e }),
+		RecoveryMiddleware(func(r *http.Request, err any, stack []byte) { recovered = err }),
+	)
+
+	w := httptest.NewRecorder()
+	handler.ServeHTTP(w, httptest.NewRequest(http.MethodGet, "/ok", nil))
+	assert.Equal(http.StatusAccepted, w.Code)
+	assert.Equal(http.MethodGet, entry.Method)
+	assert.Equal("/ok", entry.Path)
+	assert.Equal(http.StatusAccepted, entry.StatusCode)
+	assert.Equal(int64(2), entry.Bytes)
+
+	w = httptest.NewRecorder()
+	handler.ServeHTTP(w, httptest.NewRequest(http.MethodGet, "/panic", nil))
+	assert.Equal(http.StatusInternalServerError, w.Code)
+	assert.Equal("boom", recovered)
+	assert.Equal("/panic", entry.Path)
+	assert.Equal(http.StatusInternalServerError, entry.StatusCode)
+}
+
+func TestRecoveryMiddleware_HeaderWritten(t *testing.T) {
+	t.Parallel()
+
+	assert := internal.NewAssert(t, "TestRecoveryMiddleware_HeaderWritten")
+
+	var recovered any
+	handler := RecoveryMiddleware(func(r *http.Request, err any, stack []byte) { recovered = err })(
+		http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+			w.Write([]byte("partial"))
+			panic("boom")
+		}))
+
+	w := httptest.NewRecorder()
+	func() {
+		defer func() {
+			assert.Equal(http.ErrAbortHandler, recover())
+		}()
+		handler.ServeHTTP(w, httptest.NewRequest(http.MethodGet, "/", nil))
+	}()
+
+	assert.Equal("boom", recovered)
+	assert.Equal(http.StatusOK, w.Code)
+	assert.Equal("partial", w.Body.String())
+}
+
+func TestCORSMiddleware(t *testing.T) {
+	t.Parallel()
+
+	assert := internal.NewAssert(t, "TestCORSMiddleware")
+
+	handler := CORSMiddleware(CORSOptions{
+		AllowedOrigins:   []string{"https://example.com"},
+		ExposedHeaders:   []string{"X-Total"},
+		AllowCredentials: true,
+		MaxAge:           time.Hour,
+	})(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		w.Write([]byte("ok"))
+	}))
+
+	req := httptest.NewRequest(http.MethodOptions, "/", nil)
+	req.Header.Set("Origin", "https://example.com")
+	req.Header.Set("Access-Control-Request-Method", http.MethodPut)
+	req.Header.Set("Access-Control-Request-Headers", "X-Token")
+	w := httptest.NewRecorder()
+	handler.ServeHTTP(w, req)
+
+	assert.Equal(http.StatusNoContent, w.Code)
+	assert.Equal("https://example.com", w.Header().Get("Access-Control-Allow-Origin"))
+	assert.Equal("true", w.Header().Get("Access-Control-Allow-Credentials"))
+	assert.Equal("X-Token", w.Header().Get("Access-Control-Allow-Headers"))
+	assert.Equal("3600", w.Header().Get("Access-Control-Max-Age"))
+	assert.Equal(true, strings.Contains(w.Header().Get("Access-Control-Allow-Methods"), http.MethodPut))
+
+	req = httptest.NewRequest(http.MethodGet, "/", nil)
+	req.Header.Set("Origin", "https://example.com")
+	w = httptest.NewRecorder()
+	handler.ServeHTTP(w, req)
+
+	assert.Equal("ok", w.Body.String())
+	assert.Equal("X-Total", w.Header().Get("Access-Control-Expose-Headers"))
+
+	req = httptest.NewRequest(http.MethodGet, "/", nil)
+	req.Header.Set("Origin", "https://evil.com")
+	w = httptest.NewRecorder()
+	handler.ServeHTTP(w, req)
+
+	assert.Equal("ok", w.Body.String())
+	assert.Equal("", w.Header().Get("Access-Control-Allow-Origin"))
+}
+
+func TestServeListener(t *testing.T) {
+	t.Parallel()
+
+	assert := internal.NewAssert(t, "TestServeListener")
+
+	listener, err := net.Listen("tcp", "127.0.0.1:0")
+	assert.IsNil(err)
+
+	srv := &http.Server{Handler: http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		w.Write([]byte("ok"))
+	})}
+
+	ctx, cancel := context.WithCancel(context.Background())
+	done := make(chan error, 1)
+	go func() {
+		done <- ServeListener(ctx, srv, listener)
+	}()
+
+	resp, err := http.Get("http://" + listener.Addr().String())
+	assert.IsNil(err)
+	resp.Body.Close()
+	assert.Equal(http.StatusOK, resp.StatusCode)
+
+	cancel()
+	select {
+	case err = <-done:
+		assert.IsNil(err)
+	case <-time.After(5 * time.Second):
+		t.Fatal("server is not shut down")
+	}
+
+	_, err = http.Get("http://" + listener.Addr().String())
+	assert.IsNotNil(err)
+}
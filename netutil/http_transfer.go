@@ -0,0 +1,447 @@
+// Copyright 2023 dudaodong@gmail.com. All rights reserved.
+// Use of this source code is governed by MIT license.
+
+package netutil
+
+import (
+	"context"
+	"encoding/json"
+	"errors"
+	"fmt"
+	"io"
+	"mime/multipart"
+	"net/http"
+	"os"
+	"path/filepath"
+	"strings"
+	"sync"
+
+	"github.com/duke-git/lancet/v2/fileutil"
+)
+
+// ErrChecksumMismatch is returned by Download when the checksum of downloaded file is not the expected one.
+var ErrChecksumMismatch = errors.New("checksum mismatch")
+
+// ProgressFunc is called while transferring data, param `total` is -1 if the size is unknown.
+type ProgressFunc func(transferred, total int64)
+
+// MultipartFile is a file part of multipart upload.
+// If Reader is nil, the file is opened from Path. FileName defaults to the base name of Path.
+type MultipartFile struct {
+	FieldName string
+	FileName  string
+	Path      string
+	Reader    io.Reader
+}
+
+// Upload send a multipart/form-data POST request with fields and files.
+// The body is streamed, the files are not buffered in memory. param `progress` could be nil.
+func (client *HttpClient) Upload(ctx context.Context, rawURL string, fields map[string]string,
+	files []MultipartFile, progress ProgressFunc) (*http.Response, error) {
+	total, err := multipartFilesSize(files)
+	if err != nil {
+		return nil, err
+	}
+
+	pr, pw := io.Pipe()
+	writer := multipart.NewWriter(pw)
+
+	go func() {
+		pw.CloseWithError(writeMultipart(writer, fields, files, total, progress))
+	}()
+
+	req := client.R().Post(rawURL).Header("Content-Type", writer.FormDataContentType())
+
+	resp, err := req.doWithBody(ctx, pr)
+	if err != nil {
+		// the body may never be read, unblock the writing goroutine
+		pr.CloseWithError(err)
+		return nil, err
+	}
+
+	return resp, nil
+}
+
+// DownloadOptions are the options of HttpClient.Download.
+type DownloadOptions struct {
+	// Concurrency is the number of parallel range requests, default is 1.
+	Concurrency int
+	// Checksum is the expected hex encoded sha checksum of the file, empty means no verification.
+	Checksum string
+	// ShaType is the sha algorithm of Checksum: 1, 256 or 512, default is 256.
+	ShaType int
+	// Progress is called while downloading.
+	Progress ProgressFunc
+}
+
+// Download save the file of url to local path. If the server supports range requests, the file is downloaded
+// in parallel segments, and an interrupted download is resumed from where it stopped when called again.
+// The data is written into `path.part` first, and the download state is kept in `path.download`.
+func (client *HttpClient) Download(ctx context.Context, rawURL, path string, options *DownloadOptions) error {
+	if options == nil {
+		options = &DownloadOptions{}
+	}
+	if options.Concurrency <= 0 {
+		options.Concurrency = 1
+	}
+	if options.ShaType == 0 {
+		options.ShaType = 256
+	}
+
+	resp, err := client.R().Head(rawURL).Do(ctx)
+	if err != nil {
+		return err
+	}
+	resp.Body.Close()
+	if resp.StatusCode < 200 || resp.StatusCode > 299 {
+		return newHTTPError(resp)
+	}
+
+	partPath := path + ".part"
+	if resp.ContentLength > 0 && resp.Header.Get("Accept-Ranges") == "bytes" {
+		state := newDownloadState(rawURL, resp, options.Concurrency)
+		err = client.downloadRanges(ctx, partPath, path+".download", state, options.Progress)
+	} else {
+		err = client.downloadStream(ctx, rawURL, partPath, options.Progress)
+	}
+	if err != nil {
+		return err
+	}
+
+	if options.Checksum != "" {
+		sum, err := fileutil.Sha(partPath, options.ShaType)
+		if err != nil {
+			return err
+		}
+		if !strings.EqualFold(sum, options.Checksum) {
+			os.Remove(partPath)
+			return fmt.Errorf("%w: expected %s, got %s", ErrChecksumMismatch, options.Checksum, sum)
+		}
+	}
+
+	return os.Rename(partPath, path)
+}
+
+// downloadSegment is a byte range [Start, End] of the file, Done is the number of downloaded bytes.
+type downloadSegment struct {
+	Start int64 `json:"start"`
+	End   int64 `json:"end"`
+	Done  int64 `json:"done"`
+}
+
+// downloadState is saved to disk to resume the download
+type downloadState struct {
+	URL          string             `json:"url"`
+	Size         int64              `json:"size"`
+	ETag         string             `json:"etag"`
+	LastModified string             `json:"lastModified"`
+	Segments     []*downloadSegment `json:"segments"`
+}
+
+func newDownloadState(rawURL string, resp *http.Response, concurrency int) *downloadState {
+	size := resp.ContentLength
+	state := &downloadState{
+		URL:          rawURL,
+		Size:         size,
+		ETag:         resp.Header.Get("ETag"),
+		LastModified: resp.Header.Get("Last-Modified"),
+	}
+
+	segmentSize := (size + int64(concurrency) - 1) / int64(concurrency)
+	for start := int64(0); start < size; start += segmentSize {
+		end := start + segmentSize - 1
+		if end >= size {
+			end = size - 1
+		}
+		state.Segments = append(state.Segments, &downloadSegment{Start: start, End: end})
+	}
+
+	return state
+}
+
+// sameResource checks if the saved state is for the same remote file
+func (state *downloadState) sameResource(other *downloadState) bool {
+	return state.URL == other.URL && state.Size == other.Size &&
+		state.ETag == other.ETag && state.LastModified == other.LastModified
+}
+
+// downloaded return the total number of downloaded bytes
+func (state *downloadState) downloaded() int64 {
+	var n int64
+	for _, seg := range state.Segments {
+		n += seg.Done
+	}
+	return n
+}
+
+// downloadRanges download the segments of state in parallel, and resume from the saved state if possible.
+func (client *HttpClient) downloadRanges(ctx context.Context, partPath, statePath string,
+	state *downloadState, progress ProgressFunc) error {
+	if saved, err := loadDownloadState(statePath); err == nil && saved.sameResource(state) && fileutil.IsExist(partPath) {
+		state = saved
+	}
+
+	file, err := os.OpenFile(partPath, os.O_CREATE|os.O_WRONLY, 0644)
+	if err != nil {
+		return err
+	}
+	defer file.Close()
+	if err = file.Truncate(state.Size); err != nil {
+		return err
+	}
+
+	var mu sync.Mutex
+	downloaded := state.downloaded()
+	save := func() error {
+		mu.Lock()
+		defer mu.Unlock()
+		return saveDownloadState(statePath, state)
+	}
+	if err = save(); err != nil {
+		return err
+	}
+
+	ctx, cancel := context.WithCancel(ctx)
+	defer cancel()
+
+	var wg sync.WaitGroup
+	errs := make([]error, len(state.Segments))
+	for i, seg := range state.Segments {
+		if seg.Start+seg.Done > seg.End {
+			continue
+		}
+
+		wg.Add(1)
+		go func(i int, seg *downloadSegment) {
+			defer wg.Done()
+
+			err := client.downloadSegment(ctx, state, seg, file, func(n int64) {
+				mu.Lock()
+				seg.Done += n
+				downloaded += n
+				current := downloaded
+				mu.Unlock()
+
+				if progress != nil {
+					progress(current, state.Size)
+				}
+			})
+			if err != nil {
+				errs[i] = err
+				cancel()
+				return
+			}
+			errs[i] = save()
+		}(i, seg)
+	}
+	wg.Wait()
+
+	if err := save(); err != nil {
+		return err
+	}
+	if err = firstDownloadError(errs); err != nil {
+		return err
+	}
+
+	if err = file.Close(); err != nil {
+		return err
+	}
+
+	return os.Remove(statePath)
+}
+
+// downloadSegment download the remaining part of seg and write it into file
+func (client *HttpClient) downloadSegment(ctx context.Context, state *downloadState, seg *downloadSegment,
+	file *os.File, onWrite func(n int64)) error {
+	offset := seg.Start + seg.Done
+
+	req := client.R().Get(state.URL).Header("Range", fmt.Sprintf("bytes=%d-%d", offset, seg.End))
+	if state.ETag != "" {
+		req.Header("If-Range", state.ETag)
+	} else if state.LastModified != "" {
+		req.Header("If-Range", state.LastModified)
+	}
+
+	resp, err := req.Do(ctx)
+	if err != nil {
+		return err
+	}
+	if resp.StatusCode != http.StatusPartialContent {
+		if resp.StatusCode < 200 || resp.StatusCode > 299 {
+			return newHTTPError(resp)
+		}
+		resp.Body.Close()
+		return errors.New("server does not return partial content, the remote file may be changed")
+	}
+	defer resp.Body.Close()
+
+	buf := make([]byte, 32*1024)
+	for offset <= seg.End {
+		n, err := resp.Body.Read(buf)
+		if int64(n) > seg.End-offset+1 {
+			n = int(seg.End - offset + 1)
+		}
+		if n > 0 {
+			if _, werr := file.WriteAt(buf[:n], offset); werr != nil {
+				return werr
+			}
+			offset += int64(n)
+			onWrite(int64(n))
+		}
+		if err == io.EOF {
+			break
+		}
+		if err != nil {
+			return err
+		}
+	}
+
+	if offset <= seg.End {
+		return io.ErrUnexpectedEOF
+	}
+
+	return nil
+}
+
+// downloadStream download the whole file with a single request
+func (client *HttpClient) downloadStream(ctx context.Context, rawURL, partPath string, progress ProgressFunc) error {
+	resp, err := client.R().Get(rawURL).Do(ctx)
+	if err != nil {
+		return err
+	}
+	if resp.StatusCode < 200 || resp.StatusCode > 299 {
+		return newHTTPError(resp)
+	}
+	defer resp.Body.Close()
+
+	file, err := os.Create(partPath)
+	if err != nil {
+		return err
+	}
+	defer file.Close()
+
+	var body io.Reader = resp.Body
+	if progress != nil {
+		body = &progressReader{reader: resp.Body, total: resp.ContentLength, progress: progress}
+	}
+
+	if _, err = io.Copy(file, body); err != nil {
+		return err
+	}
+
+	return file.Close()
+}
+
+// firstDownloadError return the first error which is not caused by cancelling the other segments
+func firstDownloadError(errs []error) error {
+	var canceled error
+	for _, err := range errs {
+		if err == nil {
+			continue
+		}
+		if !errors.Is(err, context.Canceled) {
+			return err
+		}
+		if canceled == nil {
+			canceled = err
+		}
+	}
+	return canceled
+}
+
+func loadDownloadState(path string) (*downloadState, error) {
+	data, err := os.ReadFile(path)
+	if err != nil {
+		return nil, err
+	}
+
+	state := &downloadState{}
+	if err = json.Unmarshal(data, state); err != nil {
+		return nil, err
+	}
+
+	return state, nil
+}
+
+func saveDownloadState(path string, state *downloadState) error {
+	data, err := json.Marshal(state)
+	if err != nil {
+		return err
+	}
+	return os.WriteFile(path, data, 0644)
+}
+
+// multipartFilesSize return the total size of files, -1 if any file size is unknown.
+func multipartFilesSize(files []MultipartFile) (int64, error) {
+	var total int64
+	for _, f := range files {
+		if f.Reader != nil {
+			return -1, nil
+		}
+		info, err := os.Stat(f.Path)
+		if err != nil {
+			return 0, err
+		}
+		total += info.Size()
+	}
+	return total, nil
+}
+
+// writeMultipart write fields and files into the multipart writer
+func writeMultipart(writer *multipart.Writer, fields map[string]string, files []MultipartFile,
+	total int64, progress ProgressFunc) error {
+	for key, value := range fields {
+		if err := writer.WriteField(key, value); err != nil {
+			return err
+		}
+	}
+
+	var transferred int64
+	for _, f := range files {
+		reader := f.Reader
+		fileName := f.FileName
+		if reader == nil {
+			file, err := os.Open(f.Path)
+			if err != nil {
+				return err
+			}
+			defer file.Close()
+			reader = file
+		}
+		if fileName == "" {
+			fileName = filepath.Base(f.Path)
+		}
+
+		part, err := writer.CreateFormFile(f.FieldName, fileName)
+		if err != nil {
+			return err
+		}
+
+		pr := &progressReader{reader: reader, transferred: transferred, total: total, progress: progress}
+		if _, err = io.Copy(part, pr); err != nil {
+			return err
+		}
+		transferred = pr.transferred
+	}
+
+	return writer.Close()
+}
+
+// progressReader reports the number of bytes read
+type progressReader struct {
+	reader      io.Reader
+	transferred int64
+	total       int64
+	progress    ProgressFunc
+}
+
+func (r *progressReader) Read(p []byte) (int, error) {
+	n, err := r.reader.Read(p)
+	if n > 0 {
+		r.transferred += int64(n)
+		if r.progress != nil {
+			r.progress(r.transferred, r.total)
+		}
+	}
+	return n, err
+}
@@ -0,0 +1,257 @@
+package netutil
+
+import (
+	"bytes"
+	"context"
+	"crypto/sha256"
+	"errors"
+	"fmt"
+	"io"
+	"net/http"
+	"net/http/httptest"
+	"os"
+	"path/filepath"
+	"runtime"
+	"strings"
+	"sync"
+	"testing"
+	"time"
+
+	"github.com/duke-git/lancet/v2/internal"
+)
+
+func newFileServer(content []byte, ranges *[]string, mu *sync.Mutex) *httptest.Server {
+	modTime := time.Date(2023, 1, 1, 0, 0, 0, 0, time.UTC)
+	return httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		if ranges != nil && r.Method == http.MethodGet {
+			mu.Lock()
+			*ranges = append(*ranges, r.Header.Get("Range"))
+			mu.Unlock()
+		}
+		w.Header().Set("ETag", `"v1"`)
+		http.ServeContent(w, r, "data.bin", modTime, bytes.NewReader(content))
+	}))
+}
+
+func TestHttpClient_Upload(t *testing.T) {
+	t.Parallel()
+
+	assert := internal.NewAssert(t, "TestHttpClient_Upload")
+
+	dir := t.TempDir()
+	path := filepath.Join(dir, "a.txt")
+	os.WriteFile(path, []byte("hello lancet"), 0644)
+
+	server := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		if err := r.ParseMultipartForm(1024); err != nil {
+			w.WriteHeader(http.StatusBadRequest)
+			return
+		}
+		fmt.Fprintf(w, "%s|", r.FormValue("name"))
+		for _, field := range []string{"file1", "file2"} {
+			file, header, err := r.FormFile(field)
+			if err != nil {
+				w.WriteHeader(http.StatusBadRequest)
+				return
+			}
+			data, _ := io.ReadAll(file)
+			file.Close()
+			fmt.Fprintf(w, "%s:%s|", header.Filename, data)
+		}
+	}))
+	defer server.Close()
+
+	var transferred, total int64
+	files := []MultipartFile{
+		{FieldName: "file1", Path: path},
+		{FieldName: "file2", FileName: "b.txt", Reader: strings.NewReader("from reader")},
+	}
+
+	client := NewHttpClient()
+	resp, err := client.Upload(context.Background(), server.URL, map[string]string{"name": "lancet"}, files,
+		func(n, t int64) {
+			transferred, total = n, t
+		})
+	assert.IsNil(err)
+	defer resp.Body.Close()
+
+	body, _ := io.ReadAll(resp.Body)
+	assert.Equal(http.StatusOK, resp.StatusCode)
+	assert.Equal("lancet|a.txt:hello lancet|b.txt:from reader|", string(body))
+	assert.Equal(int64(23), transferred)
+	assert.Equal(int64(-1), total)
+
+	_, err = client.Upload(context.Background(), server.URL, nil, []MultipartFile{{FieldName: "f", Path: filepath.Join(dir, "none")}}, nil)
+	assert.IsNotNil(err)
+}
+
+// waitGoroutines wait until the number of goroutines is not greater than n, return false on timeout
+func waitGoroutines(n int) bool {
+	deadline := time.Now().Add(time.Second)
+	for runtime.NumGoroutine() > n {
+		if time.Now().After(deadline) {
+			return false
+		}
+		time.Sleep(10 * time.Millisecond)
+	}
+	return true
+}
+
+// the tests counting goroutines do not run in parallel
+func TestHttpClient_UploadInvalidURL(t *testing.T) {
+	assert := internal.NewAssert(t, "TestHttpClient_UploadInvalidURL")
+
+	path := filepath.Join(t.TempDir(), "a.txt")
+	os.WriteFile(path, []byte("hello lancet"), 0644)
+	files := []MultipartFile{{FieldName: "file", Path: path}}
+
+	client := NewHttpClient()
+	client.Use(func(next http.RoundTripper) http.RoundTripper {
+		return RoundTripFunc(func(req *http.Request) (*http.Response, error) {
+			return nil, errors.New("rejected")
+		})
+	})
+
+	before := runtime.NumGoroutine()
+
+	_, err := NewHttpClient().Upload(context.Background(), "://invalid", nil, files, nil)
+	assert.IsNotNil(err)
+	_, err = client.Upload(context.Background(), "http://127.0.0.1", nil, files, nil)
+	assert.IsNotNil(err)
+	_, err = UploadFile(path, "://invalid")
+	assert.IsNotNil(err)
+
+	assert.Equal(true, waitGoroutines(before))
+}
+
+func TestUploadFile(t *testing.T) {
+	t.Parallel()
+
+	assert := internal.NewAssert(t, "TestUploadFile")
+
+	path := filepath.Join(t.TempDir(), "upload.txt")
+	os.WriteFile(path, []byte("content"), 0644)
+
+	received := make(chan string, 1)
+	server := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		file, _, err := r.FormFile("uploadfile")
+		if err != nil {
+			received <- err.Error()
+			return
+		}
+		defer file.Close()
+		data, _ := io.ReadAll(file)
+		received <- string(data)
+	}))
+	defer server.Close()
+
+	ok, err := UploadFile(path, server.URL)
+	assert.IsNil(err)
+	assert.Equal(true, ok)
+	assert.Equal("content", <-received)
+}
+
+func TestHttpClient_Download(t *testing.T) {
+	t.Parallel()
+
+	assert := internal.NewAssert(t, "TestHttpClient_Download")
+
+	content := bytes.Repeat([]byte("0123456789abcdef"), 10000)
+	checksum := fmt.Sprintf("%x", sha256.Sum256(content))
+
+	var mu sync.Mutex
+	ranges := []string{}
+	server := newFileServer(content, &ranges, &mu)
+	defer server.Close()
+
+	path := filepath.Join(t.TempDir(), "data.bin")
+
+	var lastProgress int64
+	client := NewHttpClient()
+	err := client.Download(context.Background(), server.URL, path, &DownloadOptions{
+		Concurrency: 4,
+		Checksum:    checksum,
+		Progress: func(n, total int64) {
+			mu.Lock()
+			if n > lastProgress {
+				lastProgress = n
+			}
+			mu.Unlock()
+			assert.Equal(int64(len(content)), total)
+		},
+	})
+	assert.IsNil(err)
+
+	data, _ := os.ReadFile(path)
+	assert.Equal(content, data)
+	assert.Equal(int64(len(content)), lastProgress)
+	assert.Equal(4, len(ranges))
+
+	_, err = os.Stat(path + ".part")
+	assert.Equal(true, os.IsNotExist(err))
+	_, err = os.Stat(path + ".download")
+	assert.Equal(true, os.IsNotExist(err))
+
+	err = client.Download(context.Background(), server.URL, path, &DownloadOptions{Checksum: "bad"})
+	assert.Equal(true, errors.Is(err, ErrChecksumMismatch))
+}
+
+func TestHttpClient_DownloadResume(t *testing.T) {
+	t.Parallel()
+
+	assert := internal.NewAssert(t, "TestHttpClient_DownloadResume")
+
+	content := bytes.Repeat([]byte("lancet"), 1000)
+
+	var mu sync.Mutex
+	ranges := []string{}
+	server := newFileServer(content, &ranges, &mu)
+	defer server.Close()
+
+	path := filepath.Join(t.TempDir(), "data.bin")
+
+	// simulate an interrupted download, the first 1000 bytes are saved
+	resp, err := NewHttpClient().R().Head(server.URL).Do(context.Background())
+	assert.IsNil(err)
+	resp.Body.Close()
+
+	state := newDownloadState(server.URL, resp, 1)
+	state.Segments[0].Done = 1000
+	saveDownloadState(path+".download", state)
+	os.WriteFile(path+".part", content[:1000], 0644)
+
+	err = NewHttpClient().Download(context.Background(), server.URL, path, nil)
+	assert.IsNil(err)
+
+	data, _ := os.ReadFile(path)
+	assert.Equal(content, data)
+	assert.Equal([]string{fmt.Sprintf("bytes=1000-%d", len(content)-1)}, ranges)
+}
+
+func TestHttpClient_DownloadStream(t *testing.T) {
+	t.Parallel()
+
+	assert := internal.NewAssert(t, "TestHttpClient_DownloadStream")
+
+	server := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		if r.URL.Path == "/missing" {
+			w.WriteHeader(http.StatusNotFound)
+			return
+		}
+		w.Write([]byte("no range support"))
+	}))
+	defer server.Close()
+
+	path := filepath.Join(t.TempDir(), "data.txt")
+
+	err := NewHttpClient().Download(context.Background(), server.URL, path, nil)
+	assert.IsNil(err)
+
+	data, _ := os.ReadFile(path)
+	assert.Equal("no range support", string(data))
+
+	err = NewHttpClient().Download(context.Background(), server.URL+"/missing", path, nil)
+	var httpErr *HTTPError
+	assert.Equal(true, errors.As(err, &httpErr))
+	assert.Equal(http.StatusNotFound, httpErr.StatusCode)
+}
@@ -1,7 +1,6 @@
 package netutil
 
 import (
-	"bytes"
 	"encoding/json"
 	"errors"
 	"fmt"
@@ -66,6 +65,7 @@ func GetInternalIp() string {
 // }
 
 // GetRequestPublicIp return the requested public ip.
+// Note: it trusts X-Forwarded-For and X-Real-Ip headers of any client, use ClientIPResolver if the ip matters for security.
 // Play: https://go.dev/play/p/kxU-YDc_eBo
 func GetRequestPublicIp(req *http.Request) string {
 	var ip string
@@ -217,43 +217,26 @@ func EncodeUrl(urlStr string) (string, error) {
 	return URL.String(), nil
 }
 
-// DownloadFile will upload the file to a server.
+// UploadFile will upload the file to a server.
 func UploadFile(filepath string, server string) (bool, error) {
 	if !fileutil.IsExist(filepath) {
 		return false, errors.New("file not exist")
 	}
 
-	fileInfo, err := os.Stat(filepath)
-	if err != nil {
-		return false, err
-	}
-
-	bodyBuffer := &bytes.Buffer{}
-	writer := multipart.NewWriter(bodyBuffer)
-
-	formFile, err := writer.CreateFormFile("uploadfile", fileInfo.Name())
-	if err != nil {
-		return false, err
-	}
-
-	srcFile, err := os.Open(filepath)
-	if err != nil {
-		return false, err
-	}
-	defer srcFile.Close()
-
-	_, err = io.Copy(formFile, srcFile)
-	if err != nil {
-		return false, err
-	}
+	pr, pw := io.Pipe()
+	writer := multipart.NewWriter(pw)
+	files := []MultipartFile{{FieldName: "uploadfile", Path: filepath}}
 
-	contentType := writer.FormDataContentType()
-	writer.Close()
+	go func() {
+		pw.CloseWithError(writeMultipart(writer, nil, files, -1, nil))
+	}()
 
-	_, err = http.Post(server, contentType, bodyBuffer)
+	resp, err := http.Post(server, writer.FormDataContentType(), pr)
 	if err != nil {
+		pr.CloseWithError(err)
 		return false, err
 	}
+	resp.Body.Close()
 
 	return true, nil
 }
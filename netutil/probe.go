@@ -0,0 +1,268 @@
+// Copyright 2023 dudaodong@gmail.com. All rights reserved.
+// Use of this source code is governed by MIT license.
+
+package netutil
+
+import (
+	"context"
+	"encoding/binary"
+	"errors"
+	"fmt"
+	"io"
+	"net"
+	"net/http"
+	"net/http/httptrace"
+	"os"
+	"sync"
+	"time"
+)
+
+// ProbeKind is the protocol used by probe.
+type ProbeKind string
+
+const (
+	ProbeKindTCP  ProbeKind = "tcp"
+	ProbeKindHTTP ProbeKind = "http"
+	// ProbeKindICMP sends icmp echo with raw socket, it usually requires root or CAP_NET_RAW.
+	ProbeKindICMP ProbeKind = "icmp"
+)
+
+// ProbeResult is the result of probing a target.
+type ProbeResult struct {
+	Kind   ProbeKind
+	Target string
+	// Addr is the resolved remote address which is connected.
+	Addr    string
+	Latency time.Duration
+	// StatusCode is the response status code of http probe.
+	StatusCode int
+	Err        error
+}
+
+// OK checks if the probe is successful.
+func (r ProbeResult) OK() bool {
+	return r.Err == nil
+}
+
+// ProbeTarget is a target of ProbeMany.
+type ProbeTarget struct {
+	Kind ProbeKind
+	// Address is `host:port` for tcp probe, url for http probe and host for icmp probe.
+	Address string
+	// ExpectStatus is the expected status code of http probe, zero means any 2xx status.
+	ExpectStatus int
+	// Timeout of the probe, zero means no timeout other than ctx.
+	Timeout time.Duration
+}
+
+// ProbeTCP checks if a tcp connection could be established to addr.
+func ProbeTCP(ctx context.Context, addr string) ProbeResult {
+	result := ProbeResult{Kind: ProbeKindTCP, Target: addr}
+
+	var dialer net.Dialer
+	start := time.Now()
+	conn, err := dialer.DialContext(ctx, "tcp", addr)
+	result.Latency = time.Since(start)
+	if err != nil {
+		result.Err = err
+		return result
+	}
+	defer conn.Close()
+
+	result.Addr = conn.RemoteAddr().String()
+
+	return result
+}
+
+// ProbeHTTP send GET request to url and checks the response status code,
+// param `expectStatus` is the expected status code, zero means any 2xx status.
+// The latency is the time until the response header is received.
+func ProbeHTTP(ctx context.Context, url string, expectStatus int) ProbeResult {
+	result := ProbeResult{Kind: ProbeKindHTTP, Target: url}
+
+	trace := &httptrace.ClientTrace{
+		GotConn: func(info httptrace.GotConnInfo) {
+			result.Addr = info.Conn.RemoteAddr().String()
+		},
+	}
+
+	req, err := http.NewRequestWithContext(httptrace.WithClientTrace(ctx, trace), http.MethodGet, url, nil)
+	if err != nil {
+		result.Err = err
+		return result
+	}
+
+	transport := http.DefaultTransport.(*http.Transport).Clone()
+	transport.DisableKeepAlives = true
+	client := &http.Client{
+		Transport: transport,
+		CheckRedirect: func(req *http.Request, via []*http.Request) error {
+			return http.ErrUseLastResponse
+		},
+	}
+
+	start := time.Now()
+	resp, err := client.Do(req)
+	result.Latency = time.Since(start)
+	if err != nil {
+		result.Err = err
+		return result
+	}
+	io.Copy(io.Discard, io.LimitReader(resp.Body, 64<<10))
+	resp.Body.Close()
+
+	result.StatusCode = resp.StatusCode
+	if expectStatus == 0 && (resp.StatusCode < 200 || resp.StatusCode > 299) ||
+		expectStatus != 0 && resp.StatusCode != expectStatus {
+		result.Err = fmt.Errorf("unexpected status code: %d", resp.StatusCode)
+	}
+
+	return result
+}
+
+// ProbeICMP send an icmp echo request to the ipv4 host and waits for the reply.
+// It uses raw socket, which usually requires root or CAP_NET_RAW.
+func ProbeICMP(ctx context.Context, host string) ProbeResult {
+	result := ProbeResult{Kind: ProbeKindICMP, Target: host}
+
+	ips, err := net.DefaultResolver.LookupIP(ctx, "ip4", host)
+	if err != nil {
+		result.Err = err
+		return result
+	}
+	dst := &net.IPAddr{IP: ips[0]}
+	result.Addr = dst.String()
+
+	conn, err := net.ListenPacket("ip4:icmp", "0.0.0.0")
+	if err != nil {
+		result.Err = err
+		return result
+	}
+	defer conn.Close()
+
+	if deadline, ok := ctx.Deadline(); ok {
+		conn.SetDeadline(deadline)
+	}
+	done := make(chan struct{})
+	defer close(done)
+	go func() {
+		select {
+		case <-ctx.Done():
+			conn.SetDeadline(time.Now())
+		case <-done:
+		}
+	}()
+
+	id := uint16(os.Getpid() & 0xffff)
+	seq := uint16(time.Now().UnixNano() & 0xffff)
+
+	start := time.Now()
+	if _, err = conn.WriteTo(icmpEchoRequest(id, seq), dst); err != nil {
+		result.Err = err
+		return result
+	}
+
+	buf := make([]byte, 1500)
+	for {
+		n, peer, err := conn.ReadFrom(buf)
+		if err != nil {
+			if ctx.Err() != nil {
+				err = ctx.Err()
+			}
+			result.Err = err
+			return result
+		}
+
+		if isICMPEchoReply(buf[:n], id, seq) && peer.String() == dst.String() {
+			result.Latency = time.Since(start)
+			return result
+		}
+	}
+}
+
+// ProbeMany probe the targets concurrently with at most concurrency goroutines,
+// the results are in the same order of targets. concurrency <= 0 means no limit.
+func ProbeMany(ctx context.Context, targets []ProbeTarget, concurrency int) []ProbeResult {
+	if concurrency <= 0 || concurrency > len(targets) {
+		concurrency = len(targets)
+	}
+
+	results := make([]ProbeResult, len(targets))
+	sem := make(chan struct{}, concurrency)
+
+	var wg sync.WaitGroup
+	for i, target := range targets {
+		wg.Add(1)
+		go func(i int, target ProbeTarget) {
+			defer wg.Done()
+
+			sem <- struct{}{}
+			defer func() { <-sem }()
+
+			results[i] = Probe(ctx, target)
+		}(i, target)
+	}
+	wg.Wait()
+
+	return results
+}
+
+// Probe probe the target by its kind.
+func Probe(ctx context.Context, target ProbeTarget) ProbeResult {
+	if target.Timeout > 0 {
+		var cancel context.CancelFunc
+		ctx, cancel = context.WithTimeout(ctx, target.Timeout)
+		defer cancel()
+	}
+
+	switch target.Kind {
+	case ProbeKindTCP:
+		return ProbeTCP(ctx, target.Address)
+	case ProbeKindHTTP:
+		return ProbeHTTP(ctx, target.Address, target.ExpectStatus)
+	case ProbeKindICMP:
+		return ProbeICMP(ctx, target.Address)
+	default:
+		return ProbeResult{Kind: target.Kind, Target: target.Address, Err: errors.New("unsupported probe kind")}
+	}
+}
+
+// icmpEchoRequest build the icmp echo request message
+func icmpEchoRequest(id, seq uint16) []byte {
+	msg := make([]byte, 16)
+	msg[0] = 8 // echo request
+	binary.BigEndian.PutUint16(msg[4:], id)
+	binary.BigEndian.PutUint16(msg[6:], seq)
+	copy(msg[8:], "lancet!!")
+	binary.BigEndian.PutUint16(msg[2:], icmpChecksum(msg))
+	return msg
+}
+
+// isICMPEchoReply checks if the message is the echo reply of id and seq.
+// The message read from raw ip4 socket may have ipv4 header.
+func isICMPEchoReply(msg []byte, id, seq uint16) bool {
+	if len(msg) >= 20 && msg[0]>>4 == 4 {
+		headerLen := int(msg[0]&0x0f) * 4
+		if len(msg) < headerLen {
+			return false
+		}
+		msg = msg[headerLen:]
+	}
+
+	return len(msg) >= 8 && msg[0] == 0 &&
+		binary.BigEndian.Uint16(msg[4:]) == id && binary.BigEndian.Uint16(msg[6:]) == seq
+}
+
+func icmpChecksum(msg []byte) uint16 {
+	var sum uint32
+	for i := 0; i+1 < len(msg); i += 2 {
+		sum += uint32(msg[i])<<8 | uint32(msg[i+1])
+	}
+	if len(msg)%2 == 1 {
+		sum += uint32(msg[len(msg)-1]) << 8
+	}
+	for sum>>16 != 0 {
+		sum = sum&0xffff + sum>>16
+	}
+	return ^uint16(sum)
+}
@@ -0,0 +1,144 @@
+package netutil
+
+import (
+	"context"
+	"errors"
+	"net"
+	"net/http"
+	"net/http/httptest"
+	"os"
+	"strings"
+	"testing"
+	"time"
+
+	"github.com/duke-git/lancet/v2/internal"
+)
+
+func TestProbeTCP(t *testing.T) {
+	t.Parallel()
+
+	assert := internal.NewAssert(t, "TestProbeTCP")
+
+	listener, err := net.Listen("tcp", "127.0.0.1:0")
+	assert.IsNil(err)
+	addr := listener.Addr().String()
+
+	result := ProbeTCP(context.Background(), addr)
+	assert.Equal(true, result.OK())
+	assert.Equal(ProbeKindTCP, result.Kind)
+	assert.Equal(addr, result.Target)
+	assert.Equal(addr, result.Addr)
+	assert.Greater(result.Latency, time.Duration(0))
+
+	listener.Close()
+	result = ProbeTCP(context.Background(), addr)
+	assert.Equal(false, result.OK())
+
+	ctx, cancel := context.WithCancel(context.Background())
+	cancel()
+	result = ProbeTCP(ctx, addr)
+	assert.IsNotNil(result.Err)
+}
+
+func TestProbeHTTP(t *testing.T) {
+	t.Parallel()
+
+	assert := internal.NewAssert(t, "TestProbeHTTP")
+
+	server := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		switch r.URL.Path {
+		case "/health":
+			w.Write([]byte("ok"))
+		case "/redirect":
+			http.Redirect(w, r, "/health", http.StatusFound)
+		case "/slow":
+			time.Sleep(200 * time.Millisecond)
+		default:
+			w.WriteHeader(http.StatusServiceUnavailable)
+		}
+	}))
+	defer server.Close()
+
+	result := ProbeHTTP(context.Background(), server.URL+"/health", 0)
+	assert.Equal(true, result.OK())
+	assert.Equal(http.StatusOK, result.StatusCode)
+	assert.Equal(strings.TrimPrefix(server.URL, "http://"), result.Addr)
+
+	result = ProbeHTTP(context.Background(), server.URL+"/down", 0)
+	assert.Equal(false, result.OK())
+	assert.Equal(http.StatusServiceUnavailable, result.StatusCode)
+
+	result = ProbeHTTP(context.Background(), server.URL+"/down", http.StatusServiceUnavailable)
+	assert.Equal(true, result.OK())
+
+	result = ProbeHTTP(context.Background(), server.URL+"/redirect", http.StatusFound)
+	assert.Equal(true, result.OK())
+
+	ctx, cancel := context.WithTimeout(context.Background(), 20*time.Millisecond)
+	defer cancel()
+	result = ProbeHTTP(ctx, server.URL+"/slow", 0)
+	assert.Equal(false, result.OK())
+
+	result = ProbeHTTP(context.Background(), "://invalid", 0)
+	assert.Equal(false, result.OK())
+}
+
+func TestProbeMany(t *testing.T) {
+	t.Parallel()
+
+	assert := internal.NewAssert(t, "TestProbeMany")
+
+	server := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		if r.URL.Path == "/slow" {
+			time.Sleep(200 * time.Millisecond)
+		}
+	}))
+	defer server.Close()
+
+	targets := []ProbeTarget{
+		{Kind: ProbeKindHTTP, Address: server.URL},
+		{Kind: ProbeKindTCP, Address: strings.TrimPrefix(server.URL, "http://")},
+		{Kind: ProbeKindHTTP, Address: server.URL + "/slow", Timeout: 20 * time.Millisecond},
+		{Kind: "udp", Address: "127.0.0.1:53"},
+	}
+
+	results := ProbeMany(context.Background(), targets, 2)
+	assert.Equal(4, len(results))
+	assert.Equal(true, results[0].OK())
+	assert.Equal(ProbeKindHTTP, results[0].Kind)
+	assert.Equal(true, results[1].OK())
+	assert.Equal(ProbeKindTCP, results[1].Kind)
+	assert.Equal(false, results[2].OK())
+	assert.Equal(false, results[3].OK())
+	assert.Equal("127.0.0.1:53", results[3].Target)
+}
+
+func TestProbeICMP(t *testing.T) {
+	t.Parallel()
+
+	assert := internal.NewAssert(t, "TestProbeICMP")
+
+	ctx, cancel := context.WithTimeout(context.Background(), 2*time.Second)
+	defer cancel()
+
+	result := ProbeICMP(ctx, "127.0.0.1")
+	if errors.Is(result.Err, os.ErrPermission) {
+		t.Skip("raw socket is not permitted")
+	}
+	assert.IsNil(result.Err)
+	assert.Equal("127.0.0.1", result.Addr)
+}
+
+func TestICMPMessage(t *testing.T) {
+	t.Parallel()
+
+	assert := internal.NewAssert(t, "TestICMPMessage")
+
+	msg := icmpEchoRequest(1, 2)
+	assert.Equal(uint16(0), icmpChecksum(msg))
+	assert.Equal(false, isICMPEchoReply(msg, 1, 2))
+
+	msg[0] = 0
+	assert.Equal(true, isICMPEchoReply(msg, 1, 2))
+	assert.Equal(false, isICMPEchoReply(msg, 1, 3))
+}
@@ -0,0 +1,325 @@
+// Copyright 2023 dudaodong@gmail.com. All rights reserved.
+// Use of this source code is governed by MIT license.
+
+package netutil
+
+import (
+	"encoding"
+	"errors"
+	"fmt"
+	"net/url"
+	"reflect"
+	"strconv"
+	"strings"
+	"time"
+)
+
+var (
+	timeType          = reflect.TypeOf(time.Time{})
+	durationType      = reflect.TypeOf(time.Duration(0))
+	textUnmarshalType = reflect.TypeOf((*encoding.TextUnmarshaler)(nil)).Elem()
+	textMarshalType   = reflect.TypeOf((*encoding.TextMarshaler)(nil)).Elem()
+)
+
+// queryField is a struct field bound to query param
+type queryField struct {
+	name      string
+	omitEmpty bool
+	layout    string
+	index     []int
+}
+
+// StructToQueryValues convert struct to url values by `query` tag, it is the reverse of UrlValuesToStruct.
+// The tag is like `query:"name,omitempty"`, if there is no `query` tag, the name of `json` tag or field name is used.
+// Slice is converted to repeated params, time.Time is formatted by `layout` tag, default is time.RFC3339.
+// Embedded struct fields are flattened.
+//
+// It differs from StructToUrlValues, which converts the struct through json encoding: StructToUrlValues only uses
+// `json` tags and formats every value with fmt, so a slice becomes one param like "[a b]" and a nested struct
+// becomes "map[...]". Use StructToQueryValues to build query params which UrlValuesToStruct can bind back.
+func StructToQueryValues(v any) (url.Values, error) {
+	rv := reflect.ValueOf(v)
+	for rv.Kind() == reflect.Ptr {
+		if rv.IsNil() {
+			return nil, errors.New("nil struct pointer")
+		}
+		rv = rv.Elem()
+	}
+	if rv.Kind() != reflect.Struct {
+		return nil, fmt.Errorf("unsupported type %s, it should be struct", rv.Type())
+	}
+
+	result := url.Values{}
+	for _, field := range queryFields(rv.Type()) {
+		fv, ok := fieldByIndex(rv, field.index)
+		if !ok || (field.omitEmpty && fv.IsZero()) {
+			continue
+		}
+
+		for fv.Kind() == reflect.Ptr {
+			if fv.IsNil() {
+				break
+			}
+			fv = fv.Elem()
+		}
+		if fv.Kind() == reflect.Ptr {
+			continue
+		}
+
+		if (fv.Kind() == reflect.Slice || fv.Kind() == reflect.Array) && !fv.Type().Implements(textMarshalType) &&
+			fv.Type().Elem().Kind() != reflect.Uint8 {
+			for i := 0; i < fv.Len(); i++ {
+				s, err := formatQueryValue(fv.Index(i), field.layout)
+				if err != nil {
+					return nil, fmt.Errorf("field %s: %w", field.name, err)
+				}
+				result.Add(field.name, s)
+			}
+			continue
+		}
+
+		s, err := formatQueryValue(fv, field.layout)
+		if err != nil {
+			return nil, fmt.Errorf("field %s: %w", field.name, err)
+		}
+		result.Set(field.name, s)
+	}
+
+	return result, nil
+}
+
+// UrlValuesToStruct bind url values into the struct which target points to, it is the reverse of StructToQueryValues.
+// Fields are matched by `query` tag, `json` tag or field name in order. Supported field types are string, bool,
+// ints, uints, floats, time.Time (parsed by `layout` tag, default is time.RFC3339), time.Duration,
+// encoding.TextUnmarshaler, and their pointers and slices. The params not in values are left unchanged.
+func UrlValuesToStruct(values url.Values, target any) error {
+	rv := reflect.ValueOf(target)
+	if rv.Kind() != reflect.Ptr || rv.IsNil() || rv.Elem().Kind() != reflect.Struct {
+		return errors.New("target should be a non-nil struct pointer")
+	}
+	rv = rv.Elem()
+
+	for _, field := range queryFields(rv.Type()) {
+		params, ok := values[field.name]
+		if !ok || len(params) == 0 {
+			continue
+		}
+
+		fv, ok := allocFieldByIndex(rv, field.index)
+		if !ok {
+			continue
+		}
+		if err := setQueryField(fv, params, field.layout); err != nil {
+			return fmt.Errorf("field %s: %w", field.name, err)
+		}
+	}
+
+	return nil
+}
+
+// queryFields return the fields of struct type, embedded struct fields are flattened.
+func queryFields(rt reflect.Type) []queryField {
+	result := []queryField{}
+
+	for i := 0; i < rt.NumField(); i++ {
+		sf := rt.Field(i)
+
+		tag, hasTag := sf.Tag.Lookup("query")
+		if !hasTag {
+			tag, hasTag = sf.Tag.Lookup("json")
+		}
+		if tag == "-" {
+			continue
+		}
+
+		parts := strings.Split(tag, ",")
+		name := parts[0]
+
+		ft := sf.Type
+		if ft.Kind() == reflect.Ptr {
+			ft = ft.Elem()
+		}
+		if sf.Anonymous && name == "" && ft.Kind() == reflect.Struct {
+			for _, f := range queryFields(ft) {
+				f.index = append([]int{i}, f.index...)
+				result = append(result, f)
+			}
+			continue
+		}
+
+		if !sf.IsExported() {
+			continue
+		}
+		if name == "" {
+			name = sf.Name
+		}
+
+		field := queryField{name: name, layout: sf.Tag.Get("layout"), index: []int{i}}
+		for _, opt := range parts[1:] {
+			if opt == "omitempty" {
+				field.omitEmpty = true
+			}
+		}
+		result = append(result, field)
+	}
+
+	return result
+}
+
+// fieldByIndex return the field, false if it is in a nil embedded struct pointer
+func fieldByIndex(rv reflect.Value, index []int) (reflect.Value, bool) {
+	for i, idx := range index {
+		if i > 0 {
+			if rv.Kind() == reflect.Ptr {
+				if rv.IsNil() {
+					return reflect.Value{}, false
+				}
+				rv = rv.Elem()
+			}
+		}
+		rv = rv.Field(idx)
+	}
+	return rv, true
+}
+
+// allocFieldByIndex return the field, the nil embedded struct pointers are allocated.
+// It returns false if the field is not settable, eg. in a nil pointer of unexported embedded struct.
+func allocFieldByIndex(rv reflect.Value, index []int) (reflect.Value, bool) {
+	for i, idx := range index {
+		if i > 0 && rv.Kind() == reflect.Ptr {
+			if rv.IsNil() {
+				if !rv.CanSet() {
+					return reflect.Value{}, false
+				}
+				rv.Set(reflect.New(rv.Type().Elem()))
+			}
+			rv = rv.Elem()
+		}
+		rv = rv.Field(idx)
+	}
+	return rv, rv.CanSet()
+}
+
+func setQueryField(fv reflect.Value, params []string, layout string) error {
+	if fv.Kind() == reflect.Ptr {
+		if fv.IsNil() {
+			fv.Set(reflect.New(fv.Type().Elem()))
+		}
+		return setQueryField(fv.Elem(), params, layout)
+	}
+
+	if fv.Kind() == reflect.Slice && !reflect.PtrTo(fv.Type()).Implements(textUnmarshalType) &&
+		fv.Type().Elem().Kind() != reflect.Uint8 {
+		slice := reflect.MakeSlice(fv.Type(), len(params), len(params))
+		for i, param := range params {
+			if err := setQueryField(slice.Index(i), []string{param}, layout); err != nil {
+				return err
+			}
+		}
+		fv.Set(slice)
+		return nil
+	}
+
+	return parseQueryValue(fv, params[0], layout)
+}
+
+func parseQueryValue(fv reflect.Value, s, layout string) error {
+	if fv.CanAddr() && fv.Addr().Type().Implements(textUnmarshalType) && fv.Type() != timeType {
+		return fv.Addr().Interface().(encoding.TextUnmarshaler).UnmarshalText([]byte(s))
+	}
+
+	switch fv.Type() {
+	case timeType:
+		if layout == "" {
+			layout = time.RFC3339
+		}
+		t, err := time.Parse(layout, s)
+		if err != nil {
+			return err
+		}
+		fv.Set(reflect.ValueOf(t))
+		return nil
+	case durationType:
+		d, err := time.ParseDuration(s)
+		if err != nil {
+			return err
+		}
+		fv.SetInt(int64(d))
+		return nil
+	}
+
+	switch fv.Kind() {
+	case reflect.String:
+		fv.SetString(s)
+	case reflect.Bool:
+		if s == "" {
+			fv.SetBool(false)
+			return nil
+		}
+		b, err := strconv.ParseBool(s)
+		if err != nil {
+			return err
+		}
+		fv.SetBool(b)
+	case reflect.Int, reflect.Int8, reflect.Int16, reflect.Int32, reflect.Int64:
+		n, err := strconv.ParseInt(s, 10, fv.Type().Bits())
+		if err != nil {
+			return err
+		}
+		fv.SetInt(n)
+	case reflect.Uint, reflect.Uint8, reflect.Uint16, reflect.Uint32, reflect.Uint64:
+		n, err := strconv.ParseUint(s, 10, fv.Type().Bits())
+		if err != nil {
+			return err
+		}
+		fv.SetUint(n)
+	case reflect.Float32, reflect.Float64:
+		n, err := strconv.ParseFloat(s, fv.Type().Bits())
+		if err != nil {
+			return err
+		}
+		fv.SetFloat(n)
+	case reflect.Slice:
+		fv.SetBytes([]byte(s))
+	default:
+		return fmt.Errorf("unsupported type %s", fv.Type())
+	}
+
+	return nil
+}
+
+func formatQueryValue(fv reflect.Value, layout string) (string, error) {
+	switch fv.Type() {
+	case timeType:
+		if layout == "" {
+			layout = time.RFC3339
+		}
+		return fv.Interface().(time.Time).Format(layout), nil
+	case durationType:
+		return time.Duration(fv.Int()).String(), nil
+	}
+
+	if fv.Type().Implements(textMarshalType) {
+		data, err := fv.Interface().(encoding.TextMarshaler).MarshalText()
+		return string(data), err
+	}
+
+	switch fv.Kind() {
+	case reflect.String:
+		return fv.String(), nil
+	case reflect.Bool:
+		return strconv.FormatBool(fv.Bool()), nil
+	case reflect.Int, reflect.Int8, reflect.Int16, reflect.Int32, reflect.Int64:
+		return strconv.FormatInt(fv.Int(), 10), nil
+	case reflect.Uint, reflect.Uint8, reflect.Uint16, reflect.Uint32, reflect.Uint64:
+		return strconv.FormatUint(fv.Uint(), 10), nil
+	case reflect.Float32, reflect.Float64:
+		return strconv.FormatFloat(fv.Float(), 'f', -1, fv.Type().Bits()), nil
+	case reflect.Slice:
+		if fv.Type().Elem().Kind() == reflect.Uint8 {
+			return string(fv.Bytes()), nil
+		}
+	}
+
+	return "", fmt.Errorf("unsupported type %s", fv.Type())
+}
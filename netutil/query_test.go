@@ -0,0 +1,130 @@
+package netutil
+
+import (
+	"net"
+	"net/url"
+	"testing"
+	"time"
+
+	"github.com/duke-git/lancet/v2/internal"
+)
+
+type queryPage struct {
+	Page int `query:"page"`
+	Size int `query:"size,omitempty"`
+}
+
+type querySearch struct {
+	queryPage
+	Keyword  string        `query:"q"`
+	Tags     []string      `query:"tag"`
+	IDs      []int64       `query:"id"`
+	Active   *bool         `query:"active"`
+	Score    float64       `json:"score"`
+	Since    time.Time     `query:"since"`
+	Day      time.Time     `query:"day" layout:"2006-01-02"`
+	Timeout  time.Duration `query:"timeout"`
+	IP       net.IP        `query:"ip"`
+	Name     string
+	Ignored  string `query:"-"`
+	internal string
+}
+
+func TestUrlValuesToStruct(t *testing.T) {
+	t.Parallel()
+
+	assert := internal.NewAssert(t, "TestUrlValuesToStruct")
+
+	values, _ := url.ParseQuery("page=2&q=go&tag=a&tag=b&id=1&id=2&active=true&score=9.5" +
+		"&since=2023-01-02T03:04:05Z&day=2023-05-06&timeout=1m30s&ip=10.0.0.1&Name=lancet&Ignored=x")
+
+	var s querySearch
+	err := UrlValuesToStruct(values, &s)
+	assert.IsNil(err)
+
+	assert.Equal(2, s.Page)
+	assert.Equal(0, s.Size)
+	assert.Equal("go", s.Keyword)
+	assert.Equal([]string{"a", "b"}, s.Tags)
+	assert.Equal([]int64{1, 2}, s.IDs)
+	assert.Equal(true, *s.Active)
+	assert.Equal(9.5, s.Score)
+	assert.Equal(time.Date(2023, 1, 2, 3, 4, 5, 0, time.UTC), s.Since)
+	assert.Equal(time.Date(2023, 5, 6, 0, 0, 0, 0, time.UTC), s.Day)
+	assert.Equal(90*time.Second, s.Timeout)
+	assert.Equal("10.0.0.1", s.IP.String())
+	assert.Equal("lancet", s.Name)
+	assert.Equal("", s.Ignored)
+
+	err = UrlValuesToStruct(url.Values{"page": {"abc"}}, &s)
+	assert.IsNotNil(err)
+
+	err = UrlValuesToStruct(values, s)
+	assert.IsNotNil(err)
+}
+
+type queryPaging struct {
+	Page int `query:"page"`
+}
+
+func TestUrlValuesToStructUnexportedEmbedded(t *testing.T) {
+	t.Parallel()
+
+	assert := internal.NewAssert(t, "TestUrlValuesToStructUnexportedEmbedded")
+
+	type search struct {
+		*queryPaging
+		Keyword string `query:"q"`
+	}
+
+	values := url.Values{"page": {"2"}, "q": {"go"}}
+
+	// the nil pointer of unexported embedded struct can not be allocated, its fields are skipped
+	var s1 search
+	assert.IsNil(UrlValuesToStruct(values, &s1))
+	assert.Equal("go", s1.Keyword)
+	assert.IsNil(s1.queryPaging)
+
+	s2 := search{queryPaging: &queryPaging{}}
+	assert.IsNil(UrlValuesToStruct(values, &s2))
+	assert.Equal(2, s2.Page)
+}
+
+func TestStructToQueryValues(t *testing.T) {
+	t.Parallel()
+
+	assert := internal.NewAssert(t, "TestStructToQueryValues")
+
+	active := false
+	s := querySearch{
+		queryPage: queryPage{Page: 3},
+		Keyword:   "go",
+		Tags:      []string{"a", "b"},
+		Active:    &active,
+		Day:       time.Date(2023, 5, 6, 0, 0, 0, 0, time.UTC),
+		IP:        net.ParseIP("10.0.0.1"),
+		Ignored:   "x",
+	}
+
+	values, err := StructToQueryValues(s)
+	assert.IsNil(err)
+	assert.Equal("3", values.Get("page"))
+	assert.Equal(false, values.Has("size"))
+	assert.Equal([]string{"a", "b"}, values["tag"])
+	assert.Equal(false, values.Has("id"))
+	assert.Equal("false", values.Get("active"))
+	assert.Equal("2023-05-06", values.Get("day"))
+	assert.Equal("10.0.0.1", values.Get("ip"))
+	assert.Equal(false, values.Has("Ignored"))
+
+	var actual querySearch
+	err = UrlValuesToStruct(values, &actual)
+	assert.IsNil(err)
+	assert.Equal(s.Page, actual.Page)
+	assert.Equal(s.Tags, actual.Tags)
+	assert.Equal(false, *actual.Active)
+	assert.Equal(s.Day, actual.Day)
+
+	_, err = StructToQueryValues("abc")
+	assert.IsNotNil(err)
+}
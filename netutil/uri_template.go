@@ -0,0 +1,283 @@
+// Copyright 2023 dudaodong@gmail.com. All rights reserved.
+// Use of this source code is governed by MIT license.
+
+package netutil
+
+import (
+	"fmt"
+	"reflect"
+	"sort"
+	"strconv"
+	"strings"
+	"unicode/utf8"
+)
+
+// uriTemplateOperator is the expansion behavior of expression operator, see RFC 6570 Appendix A.
+type uriTemplateOperator struct {
+	first         string
+	sep           string
+	named         bool
+	ifEmpty       string
+	allowReserved bool
+}
+
+var uriTemplateOperators = map[byte]uriTemplateOperator{
+	'+': {first: "", sep: ",", allowReserved: true},
+	'#': {first: "#", sep: ",", allowReserved: true},
+	'.': {first: ".", sep: "."},
+	'/': {first: "/", sep: "/"},
+	';': {first: ";", sep: ";", named: true},
+	'?': {first: "?", sep: "&", named: true, ifEmpty: "="},
+	'&': {first: "&", sep: "&", named: true, ifEmpty: "="},
+}
+
+// ExpandURITemplate expand the RFC 6570 uri template (up to level 4) with variables, eg.
+// ExpandURITemplate("/users/{id}{?fields*}", map[string]any{"id": 1, "fields": []string{"name", "age"}})
+// return "/users/1?fields=name&fields=age".
+// The value of variable could be string, number, bool, slice or map, nil, empty slice and empty map are undefined.
+func ExpandURITemplate(template string, vars map[string]any) (string, error) {
+	var builder strings.Builder
+
+	for i := 0; i < len(template); {
+		start := strings.IndexByte(template[i:], '{')
+		if start < 0 {
+			builder.WriteString(encodeURITemplateLiteral(template[i:]))
+			break
+		}
+		builder.WriteString(encodeURITemplateLiteral(template[i : i+start]))
+
+		end := strings.IndexByte(template[i+start:], '}')
+		if end < 0 {
+			return "", fmt.Errorf("unclosed expression in uri template at %d", i+start)
+		}
+
+		expression := template[i+start+1 : i+start+end]
+		expanded, err := expandURITemplateExpression(expression, vars)
+		if err != nil {
+			return "", err
+		}
+		builder.WriteString(expanded)
+
+		i += start + end + 1
+	}
+
+	return builder.String(), nil
+}
+
+func expandURITemplateExpression(expression string, vars map[string]any) (string, error) {
+	if expression == "" {
+		return "", fmt.Errorf("empty expression in uri template")
+	}
+
+	op := uriTemplateOperator{sep: ","}
+	if o, ok := uriTemplateOperators[expression[0]]; ok {
+		op = o
+		expression = expression[1:]
+	} else if strings.ContainsRune("=,!@|", rune(expression[0])) {
+		return "", fmt.Errorf("reserved operator %q in uri template", expression[0])
+	}
+
+	parts := []string{}
+	for _, varSpec := range strings.Split(expression, ",") {
+		name, explode, prefix, err := parseURITemplateVarSpec(varSpec)
+		if err != nil {
+			return "", err
+		}
+
+		part, defined, err := expandURITemplateVar(op, name, vars[name], explode, prefix)
+		if err != nil {
+			return "", err
+		}
+		if defined {
+			parts = append(parts, part)
+		}
+	}
+
+	if len(parts) == 0 {
+		return "", nil
+	}
+
+	return op.first + strings.Join(parts, op.sep), nil
+}
+
+// parseURITemplateVarSpec parse `name`, `name*` and `name:prefix`
+func parseURITemplateVarSpec(varSpec string) (string, bool, int, error) {
+	if strings.HasSuffix(varSpec, "*") {
+		name := strings.TrimSuffix(varSpec, "*")
+		return name, true, 0, validateURITemplateVarName(name)
+	}
+
+	if name, prefix, ok := strings.Cut(varSpec, ":"); ok {
+		n, err := strconv.Atoi(prefix)
+		if err != nil || n <= 0 || n >= 10000 {
+			return "", false, 0, fmt.Errorf("invalid prefix modifier %q in uri template", varSpec)
+		}
+		return name, false, n, validateURITemplateVarName(name)
+	}
+
+	return varSpec, false, 0, validateURITemplateVarName(varSpec)
+}
+
+func validateURITemplateVarName(name string) error {
+	if name == "" {
+		return fmt.Errorf("empty variable name in uri template")
+	}
+	for i := 0; i < len(name); i++ {
+		c := name[i]
+		if !(isAlphaNum(c) || c == '_' || c == '.' || c == '%') {
+			return fmt.Errorf("invalid variable name %q in uri template", name)
+		}
+	}
+	return nil
+}
+
+// expandURITemplateVar expand a variable, return false if the variable is undefined.
+func expandURITemplateVar(op uriTemplateOperator, name string, value any, explode bool, prefix int) (string, bool, error) {
+	if value == nil {
+		return "", false, nil
+	}
+
+	rv := reflect.ValueOf(value)
+	for rv.Kind() == reflect.Ptr || rv.Kind() == reflect.Interface {
+		if rv.IsNil() {
+			return "", false, nil
+		}
+		rv = rv.Elem()
+	}
+
+	encode := func(s string) string {
+		return encodeURITemplateValue(s, op.allowReserved)
+	}
+
+	switch rv.Kind() {
+	case reflect.Slice, reflect.Array:
+		if rv.Kind() == reflect.Slice && rv.Type().Elem().Kind() == reflect.Uint8 {
+			break
+		}
+		if prefix > 0 {
+			return "", false, fmt.Errorf("prefix modifier is not applicable to list variable %q", name)
+		}
+		if rv.Len() == 0 {
+			return "", false, nil
+		}
+
+		items := make([]string, rv.Len())
+		for i := range items {
+			item := encode(fmt.Sprintf("%v", rv.Index(i).Interface()))
+			if explode && op.named {
+				item = namedURITemplateValue(op, name, item)
+			}
+			items[i] = item
+		}
+
+		if explode {
+			return strings.Join(items, op.sep), true, nil
+		}
+		joined := strings.Join(items, ",")
+		if op.named {
+			return namedURITemplateValue(op, name, joined), true, nil
+		}
+		return joined, true, nil
+
+	case reflect.Map:
+		if prefix > 0 {
+			return "", false, fmt.Errorf("prefix modifier is not applicable to map variable %q", name)
+		}
+		if rv.Len() == 0 {
+			return "", false, nil
+		}
+
+		keys := make([]string, 0, rv.Len())
+		values := map[string]string{}
+		for _, k := range rv.MapKeys() {
+			key := fmt.Sprintf("%v", k.Interface())
+			keys = append(keys, key)
+			values[key] = fmt.Sprintf("%v", rv.MapIndex(k).Interface())
+		}
+		sort.Strings(keys)
+
+		items := make([]string, 0, len(keys)*2)
+		for _, key := range keys {
+			if explode {
+				if op.named {
+					items = append(items, namedURITemplateValue(op, encode(key), encode(values[key])))
+				} else {
+					items = append(items, encode(key)+"="+encode(values[key]))
+				}
+			} else {
+				items = append(items, encode(key), encode(values[key]))
+			}
+		}
+
+		if explode {
+			return strings.Join(items, op.sep), true, nil
+		}
+		joined := strings.Join(items, ",")
+		if op.named {
+			return namedURITemplateValue(op, name, joined), true, nil
+		}
+		return joined, true, nil
+	}
+
+	s := fmt.Sprintf("%v", rv.Interface())
+	if rv.Kind() == reflect.Slice {
+		s = string(rv.Bytes())
+	}
+	if prefix > 0 && utf8.RuneCountInString(s) > prefix {
+		s = string([]rune(s)[:prefix])
+	}
+
+	if op.named {
+		return namedURITemplateValue(op, name, encode(s)), true, nil
+	}
+	return encode(s), true, nil
+}
+
+func namedURITemplateValue(op uriTemplateOperator, name, value string) string {
+	if value == "" {
+		return name + op.ifEmpty
+	}
+	return name + "=" + value
+}
+
+// encodeURITemplateValue percent-encode s, unreserved characters are kept,
+// reserved characters and pct-encoded triplets are also kept if allowReserved is true.
+func encodeURITemplateValue(s string, allowReserved bool) string {
+	var builder strings.Builder
+	for i := 0; i < len(s); i++ {
+		c := s[i]
+		switch {
+		case isUnreserved(c):
+			builder.WriteByte(c)
+		case allowReserved && isReserved(c):
+			builder.WriteByte(c)
+		case allowReserved && c == '%' && i+2 < len(s) && isHex(s[i+1]) && isHex(s[i+2]):
+			builder.WriteString(s[i : i+3])
+			i += 2
+		default:
+			fmt.Fprintf(&builder, "%%%02X", c)
+		}
+	}
+	return builder.String()
+}
+
+// encodeURITemplateLiteral encode the literal part of template, reserved and unreserved characters are kept.
+func encodeURITemplateLiteral(s string) string {
+	return encodeURITemplateValue(s, true)
+}
+
+func isAlphaNum(c byte) bool {
+	return 'a' <= c && c <= 'z' || 'A' <= c && c <= 'Z' || '0' <= c && c <= '9'
+}
+
+func isUnreserved(c byte) bool {
+	return isAlphaNum(c) || c == '-' || c == '.' || c == '_' || c == '~'
+}
+
+func isReserved(c byte) bool {
+	return strings.IndexByte(":/?#[]@!$&'()*+,;=", c) >= 0
+}
+
+func isHex(c byte) bool {
+	return '0' <= c && c <= '9' || 'a' <= c && c <= 'f' || 'A' <= c && c <= 'F'
+}
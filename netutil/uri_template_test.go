@@ -0,0 +1,76 @@
+package netutil
+
+import (
+	"testing"
+
+	"github.com/duke-git/lancet/v2/internal"
+)
+
+func TestExpandURITemplate(t *testing.T) {
+	t.Parallel()
+
+	assert := internal.NewAssert(t, "TestExpandURITemplate")
+
+	// examples from RFC 6570
+	vars := map[string]any{
+		"var":   "value",
+		"hello": "Hello World!",
+		"path":  "/foo/bar",
+		"empty": "",
+		"x":     1024,
+		"y":     768,
+		"list":  []string{"red", "green", "blue"},
+		"keys":  map[string]string{"semi": ";", "dot": ".", "comma": ","},
+		"undef": nil,
+	}
+
+	tests := []struct {
+		template string
+		expected string
+	}{
+		{"{var}", "value"},
+		{"{hello}", "Hello%20World%21"},
+		{"{+hello}", "Hello%20World!"},
+		{"{+path}/here", "/foo/bar/here"},
+		{"here?ref={+path}", "here?ref=/foo/bar"},
+		{"{#var}", "#value"},
+		{"{#hello}", "#Hello%20World!"},
+		{"map?{x,y}", "map?1024,768"},
+		{"{x,hello,y}", "1024,Hello%20World%21,768"},
+		{"{var:3}", "val"},
+		{"{list}", "red,green,blue"},
+		{"{list*}", "red,green,blue"},
+		{"{keys}", "comma,%2C,dot,.,semi,%3B"},
+		{"{keys*}", "comma=%2C,dot=.,semi=%3B"},
+		{"X{.var}", "X.value"},
+		{"X{.list*}", "X.red.green.blue"},
+		{"{/var,x}/here", "/value/1024/here"},
+		{"{/list*,path:4}", "/red/green/blue/%2Ffoo"},
+		{"{;x,y,empty}", ";x=1024;y=768;empty"},
+		{"{;list*}", ";list=red;list=green;list=blue"},
+		{"{?x,y,empty}", "?x=1024&y=768&empty="},
+		{"{?list}", "?list=red,green,blue"},
+		{"{?keys*}", "?comma=%2C&dot=.&semi=%3B"},
+		{"?fixed=yes{&x}", "?fixed=yes&x=1024"},
+		{"{?undef}", ""},
+		{"{?var,undef}", "?var=value"},
+		{"/users/{x}{?list*}", "/users/1024?list=red&list=green&list=blue"},
+	}
+
+	for _, tt := range tests {
+		actual, err := ExpandURITemplate(tt.template, vars)
+		assert.IsNil(err)
+		assert.Equal(tt.expected, actual)
+	}
+
+	_, err := ExpandURITemplate("{var", vars)
+	assert.IsNotNil(err)
+	_, err = ExpandURITemplate("{}", vars)
+	assert.IsNotNil(err)
+	_, err = ExpandURITemplate("{=var}", vars)
+	assert.IsNotNil(err)
+	_, err = ExpandURITemplate("{list:2}", vars)
+	assert.IsNotNil(err)
+	_, err = ExpandURITemplate("{var:abc}", vars)
+	assert.IsNotNil(err)
+}
@@ -0,0 +1,51 @@
+// Copyright 2023 dudaodong@gmail.com. All rights reserved.
+// Use of this source code is governed by MIT license.
+
+package fake
+
+var (
+	firstNames = []string{
+		"James", "Mary", "John", "Patricia", "Robert", "Jennifer", "Michael", "Linda", "William", "Elizabeth",
+		"David", "Barbara", "Richard", "Susan", "Joseph", "Jessica", "Thomas", "Sarah", "Charles", "Karen",
+		"Daniel", "Nancy", "Matthew", "Lisa", "Anthony", "Betty", "Mark", "Sandra", "Steven", "Ashley",
+		"Wei", "Fang", "Min", "Jing", "Lei", "Yan", "Hao", "Ling", "Yu", "Xin",
+	}
+
+	lastNames = []string{
+		"Smith", "Johnson", "Williams", "Brown", "Jones", "Garcia", "Miller", "Davis", "Rodriguez", "Martinez",
+		"Hernandez", "Lopez", "Wilson", "Anderson", "Thomas", "Taylor", "Moore", "Jackson", "Martin", "Lee",
+		"Thompson", "White", "Harris", "Clark", "Lewis", "Walker", "Young", "Allen", "King", "Scott",
+		"Wang", "Li", "Zhang", "Liu", "Chen", "Yang", "Zhao", "Huang", "Zhou", "Wu",
+	}
+
+	words = []string{
+		"alpha", "bridge", "cloud", "delta", "echo", "forest", "garden", "harbor", "island", "jungle",
+		"kernel", "lemon", "meadow", "nebula", "ocean", "planet", "quartz", "river", "summit", "timber",
+		"umbrella", "valley", "window", "xenon", "yellow", "zephyr", "anchor", "beacon", "canyon", "dragon",
+		"ember", "falcon", "glacier", "horizon", "ivory", "jasper", "lantern", "marble", "orbit", "pepper",
+		"rocket", "silver", "thunder", "velvet", "willow", "amber", "breeze", "copper", "dune", "lotus",
+	}
+
+	cities = []string{
+		"New York", "London", "Paris", "Tokyo", "Beijing", "Shanghai", "Berlin", "Madrid", "Rome", "Sydney",
+		"Toronto", "Chicago", "Seoul", "Singapore", "Hangzhou", "Shenzhen", "Amsterdam", "Vienna", "Dublin", "Austin",
+	}
+
+	countries = []string{
+		"United States", "United Kingdom", "France", "Japan", "China", "Germany", "Spain", "Italy", "Australia",
+		"Canada", "South Korea", "Singapore", "Netherlands", "Austria", "Ireland", "Brazil", "India", "Sweden",
+	}
+
+	topLevelDomains = []string{"com", "net", "org", "io", "dev", "info"}
+
+	// creditCardPrefixes is the prefixes and lengths of visa, master card and american express
+	creditCardPrefixes = []struct {
+		prefix string
+		length int
+	}{
+		{"4", 16}, {"51", 16}, {"52", 16}, {"53", 16}, {"54", 16}, {"55", 16}, {"34", 15}, {"37", 15},
+	}
+
+	// mobilePrefixes is the prefixes of chinese mobile numbers
+	mobilePrefixes = []string{"13", "18", "19"}
+)
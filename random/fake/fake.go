@@ -0,0 +1,699 @@
+// Copyright 2023 dudaodong@gmail.com. All rights reserved.
+// Use of this source code is governed by MIT license.
+
+// Package fake generates fake data for tests and fixtures, it fills struct by `fake` tag, eg.
+//
+//	type User struct {
+//		Name  string `fake:"name"`
+//		Email string `fake:"email"`
+//		Age   int    `fake:"int,min=18,max=60"`
+//		Tags  []string `fake:"word,len=3"`
+//	}
+//
+// The generated data is reproducible with the same seed, and passes the matching checks of validator package.
+package fake
+
+import (
+	"encoding/base64"
+	"encoding/hex"
+	"errors"
+	"fmt"
+	"math"
+	"reflect"
+	"strconv"
+	"strings"
+	"time"
+
+	"github.com/duke-git/lancet/v2/random"
+)
+
+// maxDepth limits the nesting of pointers, slices and maps, so self-referencing types are filled finitely
+const maxDepth = 8
+
+var (
+	timeType     = reflect.TypeOf(time.Time{})
+	durationType = reflect.TypeOf(time.Duration(0))
+
+	// baseTime is the start of generated time, it is fixed to make the result reproducible
+	baseTime = time.Date(2000, 1, 1, 0, 0, 0, 0, time.UTC)
+
+	defaultFaker = New(time.Now().UnixNano())
+)
+
+// Faker generates fake data from a random source. It is safe for concurrent use,
+// but the data is reproducible only if the calls are in the same order.
+type Faker struct {
+	source *random.Source
+}
+
+// New creates a Faker with seed.
+func New(seed int64) *Faker {
+	return NewWithSource(random.NewSource(seed))
+}
+
+// NewWithSource creates a Faker with the random source.
+func NewWithSource(source *random.Source) *Faker {
+	return &Faker{source: source}
+}
+
+// Fill fills the struct pointed by v with fake data, see Faker.Fill.
+func Fill(v any) error {
+	return defaultFaker.Fill(v)
+}
+
+// Fill fills the exported fields of struct pointed by v with fake data.
+// The `fake` tag is the kind of data followed by options, eg. `fake:"int,min=1,max=9"`, `fake:"-"` skips the field.
+//
+// Kinds of string: name, first_name, last_name, username, email, phone, domain, url, ip, ipv4, ipv6, uuid,
+// credit_card, word, sentence, paragraph, city, country, alpha, alphanumeric, int, number, float, hex,
+// base64, json, date and time. Numeric fields support kind int, uint and float.
+//
+// Options: min=n and max=n are the range of number or the length of string, len=n is the length of string,
+// slice and map, oneof=a|b|c picks one of the values.
+//
+// The field without `fake` tag is filled according to its `validate` tag, eg. `validate:"email"`, or its name
+// if the name is a kind, eg. field `Email`. Otherwise, it gets a sensible value of its type.
+// Nested structs, pointers, slices, arrays and maps are filled recursively.
+func (f *Faker) Fill(v any) error {
+	rv := reflect.ValueOf(v)
+	if rv.Kind() != reflect.Ptr || rv.IsNil() {
+		return errors.New("fake: Fill requires a non-nil pointer")
+	}
+
+	return f.fill(rv.Elem(), spec{length: -1}, 0)
+}
+
+// spec is the parsed tag of field
+type spec struct {
+	kind     string
+	min, max float64
+	hasMin   bool
+	hasMax   bool
+	length   int
+	oneof    []string
+	// validate is true if the spec is parsed from `validate` tag, the min, max and len of slice and map
+	// are the size of container like validator, otherwise they are for the elements
+	validate bool
+}
+
+// parseFakeTag parses `fake` tag, eg. "int,min=1,max=9" or "oneof=a|b"
+func parseFakeTag(tag string) (spec, error) {
+	return parseSpec(strings.Split(tag, ","), "|")
+}
+
+// parseValidateTag parses `validate` tag of validator package, eg. "required,email" or "oneof=a b"
+func parseValidateTag(tag string) spec {
+	s, err := parseSpec(strings.Split(tag, ","), " ")
+	if err != nil {
+		return spec{length: -1}
+	}
+	if _, ok := stringKinds[s.kind]; !ok && !basicKinds[s.kind] {
+		s.kind = ""
+	}
+	s.validate = true
+	return s
+}
+
+func parseSpec(items []string, oneofSep string) (spec, error) {
+	s := spec{length: -1}
+
+	for _, item := range items {
+		name, value, hasValue := strings.Cut(strings.TrimSpace(item), "=")
+		if !hasValue {
+			if name != "" && name != "required" && name != "omitempty" {
+				s.kind = name
+			}
+			continue
+		}
+
+		switch name {
+		case "min", "max", "len":
+			n, err := strconv.ParseFloat(value, 64)
+			if err != nil {
+				return s, fmt.Errorf("fake: invalid option %q", item)
+			}
+			switch name {
+			case "min":
+				s.min, s.hasMin = n, true
+			case "max":
+				s.max, s.hasMax = n, true
+			default:
+				s.length = int(n)
+			}
+		case "oneof":
+			for _, option := range strings.Split(value, oneofSep) {
+				if option != "" {
+					s.oneof = append(s.oneof, option)
+				}
+			}
+		default:
+			return s, fmt.Errorf("fake: unknown option %q", item)
+		}
+	}
+
+	return s, nil
+}
+
+func (f *Faker) fill(value reflect.Value, s spec, depth int) error {
+	if s.kind == "-" {
+		return nil
+	}
+
+	kind := value.Kind()
+	if len(s.oneof) > 0 && kind != reflect.Slice && kind != reflect.Array && kind != reflect.Map {
+		return setString(value, s.oneof[f.source.Intn(len(s.oneof))])
+	}
+
+	if value.Type() == timeType {
+		value.Set(reflect.ValueOf(f.Time()))
+		return nil
+	}
+
+	switch kind {
+	case reflect.Ptr:
+		if depth >= maxDepth {
+			return nil
+		}
+		elem := reflect.New(value.Type().Elem())
+		if err := f.fill(elem.Elem(), s, depth+1); err != nil {
+			return err
+		}
+		value.Set(elem)
+
+	case reflect.Struct:
+		return f.fillStruct(value, depth)
+
+	case reflect.Slice:
+		if depth >= maxDepth {
+			return nil
+		}
+		n := f.size(s)
+		if value.Type().Elem().Kind() == reflect.Uint8 && s.kind == "" && len(s.oneof) == 0 {
+			value.SetBytes(f.source.RandBytes(n))
+			return nil
+		}
+		slice := reflect.MakeSlice(value.Type(), n, n)
+		if err := f.fillElems(slice, s, depth); err != nil {
+			return err
+		}
+		value.Set(slice)
+
+	case reflect.Array:
+		return f.fillElems(value, s, depth)
+
+	case reflect.Map:
+		if depth >= maxDepth {
+			return nil
+		}
+		return f.fillMap(value, s, depth)
+
+	case reflect.String:
+		str, err := f.stringValue(s)
+		if err != nil {
+			return err
+		}
+		value.SetString(str)
+
+	case reflect.Bool:
+		if s.kind != "" && s.kind != "bool" {
+			return unsupportedKind(s.kind, value)
+		}
+		value.SetBool(f.source.RandBool())
+
+	case reflect.Int, reflect.Int8, reflect.Int16, reflect.Int32, reflect.Int64:
+		if s.kind != "" && s.kind != "int" {
+			return unsupportedKind(s.kind, value)
+		}
+		lo, hi := int64(0), int64(999)
+		if value.Type() == durationType {
+			lo, hi = int64(time.Second), int64(time.Hour)
+		}
+		bits := value.Type().Bits()
+		n, err := f.intValue(s, lo, hi, -1<<(bits-1), 1<<(bits-1)-1)
+		if err != nil {
+			return err
+		}
+		value.SetInt(n)
+
+	case reflect.Uint, reflect.Uint8, reflect.Uint16, reflect.Uint32, reflect.Uint64, reflect.Uintptr:
+		if s.kind != "" && s.kind != "int" && s.kind != "uint" {
+			return unsupportedKind(s.kind, value)
+		}
+		maxValue := int64(math.MaxInt64)
+		if bits := value.Type().Bits(); bits < 64 {
+			maxValue = 1<<bits - 1
+		}
+		n, err := f.intValue(s, 0, 999, 0, maxValue)
+		if err != nil {
+			return err
+		}
+		value.SetUint(uint64(n))
+
+	case reflect.Float32, reflect.Float64:
+		if s.kind != "" && s.kind != "float" && s.kind != "int" {
+			return unsupportedKind(s.kind, value)
+		}
+		n, err := f.floatValue(s)
+		if err != nil {
+			return err
+		}
+		if s.kind == "int" {
+			n = math.Floor(n)
+		}
+		value.SetFloat(n)
+	}
+
+	// interface, chan, func and unsafe pointer are left as zero value
+	return nil
+}
+
+func (f *Faker) fillStruct(value reflect.Value, depth int) error {
+	rt := value.Type()
+	for i := 0; i < rt.NumField(); i++ {
+		field := rt.Field(i)
+		if !field.IsExported() {
+			continue
+		}
+
+		var s spec
+		if tag, ok := field.Tag.Lookup("fake"); ok {
+			var err error
+			if s, err = parseFakeTag(tag); err != nil {
+				return fmt.Errorf("%w of field %s", err, field.Name)
+			}
+		} else {
+			s = parseValidateTag(field.Tag.Get("validate"))
+			if s.kind == "" {
+				s.kind = kindOfName(field.Name, field.Type)
+			}
+		}
+
+		if err := f.fill(value.Field(i), s, depth+1); err != nil {
+			return fmt.Errorf("%w of field %s", err, field.Name)
+		}
+	}
+
+	return nil
+}
+
+// fillElems fills the elements of slice or array with spec
+func (f *Faker) fillElems(value reflect.Value, s spec, depth int) error {
+	elemSpec := s.elem()
+	for i := 0; i < value.Len(); i++ {
+		if err := f.fill(value.Index(i), elemSpec, depth+1); err != nil {
+			return err
+		}
+	}
+	return nil
+}
+
+// fillMap fills map with spec of values, the keys get the default value of its type
+func (f *Faker) fillMap(value reflect.Value, s spec, depth int) error {
+	rt := value.Type()
+	n := f.size(s)
+
+	elemSpec := s.elem()
+
+	m := reflect.MakeMapWithSize(rt, n)
+	for attempts := 0; m.Len() < n && attempts < n*10; attempts++ {
+		key := reflect.New(rt.Key()).Elem()
+		if err := f.fill(key, spec{length: -1}, depth+1); err != nil {
+			return err
+		}
+		elem := reflect.New(rt.Elem()).Elem()
+		if err := f.fill(elem, elemSpec, depth+1); err != nil {
+			return err
+		}
+		m.SetMapIndex(key, elem)
+	}
+	value.Set(m)
+
+	return nil
+}
+
+// size returns the length of slice and map, the default is 1 to 3
+func (f *Faker) size(s spec) int {
+	if s.length >= 0 {
+		return s.length
+	}
+	if s.validate && (s.hasMin || s.hasMax) {
+		return f.stringLength(s, 3)
+	}
+	return f.source.RandInt(1, 4)
+}
+
+// elem returns the spec of elements of slice, array and map
+func (s spec) elem() spec {
+	elemSpec := s
+	elemSpec.length = -1
+	if s.validate {
+		elemSpec.hasMin, elemSpec.hasMax = false, false
+	}
+	return elemSpec
+}
+
+func (f *Faker) intValue(s spec, lo, hi, typeMin, typeMax int64) (int64, error) {
+	if s.length >= 0 {
+		// len of number is the value in validator
+		s.min, s.max, s.hasMin, s.hasMax = float64(s.length), float64(s.length), true, true
+	}
+	if s.hasMin {
+		lo = int64(s.min)
+		if !s.hasMax && hi < lo {
+			hi = lo + 999
+		}
+	}
+	if s.hasMax {
+		hi = int64(s.max)
+		if !s.hasMin && lo > hi {
+			lo = hi - 999
+		}
+	}
+	if lo < typeMin {
+		lo = typeMin
+	}
+	if hi > typeMax || hi < lo && !s.hasMax {
+		hi = typeMax
+	}
+	if lo > hi {
+		return 0, fmt.Errorf("fake: invalid range [%d, %d]", lo, hi)
+	}
+
+	span := uint64(hi-lo) + 1
+	if span == 0 || span > math.MaxInt64 {
+		return lo + int64(f.source.Float64()*float64(uint64(hi-lo))), nil
+	}
+
+	return lo + int64(f.source.RandInt(0, int(span))), nil
+}
+
+// floatValue returns float of 2 decimal places
+func (f *Faker) floatValue(s spec) (float64, error) {
+	if s.length >= 0 {
+		return float64(s.length), nil
+	}
+
+	lo, hi := 0.0, 1000.0
+	if s.hasMin {
+		lo = s.min
+		if !s.hasMax && hi < lo {
+			hi = lo + 1000
+		}
+	}
+	if s.hasMax {
+		hi = s.max
+		if !s.hasMin && lo > hi {
+			lo = hi - 1000
+		}
+	}
+	if lo > hi {
+		return 0, fmt.Errorf("fake: invalid range [%g, %g]", lo, hi)
+	}
+
+	n := math.Round((lo+f.source.Float64()*(hi-lo))*100) / 100
+
+	return math.Max(lo, math.Min(hi, n)), nil
+}
+
+// stringValue returns string of kind, min, max and len are the length of string
+func (f *Faker) stringValue(s spec) (string, error) {
+	switch s.kind {
+	case "", "alpha":
+		if s.kind == "" && s.length < 0 && !s.hasMin && !s.hasMax {
+			return f.Word(), nil
+		}
+		return f.source.RandString(f.stringLength(s, 8)), nil
+	case "alphanumeric":
+		return f.source.RandNumeralOrLetter(f.stringLength(s, 8)), nil
+	case "int", "number":
+		n := f.stringLength(s, 6)
+		if n == 0 {
+			return "", nil
+		}
+		return strconv.Itoa(f.source.RandInt(1, 10)) + f.source.RandNumeral(n-1), nil
+	case "float":
+		n, err := f.floatValue(spec{})
+		return strconv.FormatFloat(n, 'f', 2, 64), err
+	case "hex":
+		n := f.stringLength(s, 16)
+		return hex.EncodeToString(f.source.RandBytes((n + 1) / 2))[:n], nil
+	case "base64":
+		return base64.StdEncoding.EncodeToString(f.source.RandBytes(12)), nil
+	}
+
+	generate, ok := stringKinds[s.kind]
+	if !ok {
+		return "", fmt.Errorf("fake: unknown kind %q", s.kind)
+	}
+
+	return generate(f), nil
+}
+
+// stringLength returns the length by options, the default length is used if there is no option
+func (f *Faker) stringLength(s spec, defaultLength int) int {
+	if s.length >= 0 {
+		return s.length
+	}
+
+	lo, hi := defaultLength, defaultLength
+	if s.hasMin {
+		lo = int(s.min)
+		if hi < lo {
+			hi = lo
+		}
+	}
+	if s.hasMax {
+		hi = int(s.max)
+		if lo > hi {
+			lo = hi
+		}
+	}
+	if lo < 0 {
+		lo = 0
+	}
+
+	return f.source.RandInt(lo, hi+1)
+}
+
+// basicKinds is the kinds which are generated by the type of field and options
+var basicKinds = map[string]bool{
+	"alpha": true, "alphanumeric": true, "int": true, "uint": true, "number": true, "float": true,
+	"bool": true, "hex": true, "base64": true,
+}
+
+// stringKinds is the generators of string kind without options
+var stringKinds = map[string]func(f *Faker) string{
+	"name":        (*Faker).Name,
+	"first_name":  (*Faker).FirstName,
+	"last_name":   (*Faker).LastName,
+	"username":    (*Faker).Username,
+	"email":       (*Faker).Email,
+	"phone":       (*Faker).Phone,
+	"domain":      (*Faker).Domain,
+	"dns":         (*Faker).Domain,
+	"url":         (*Faker).URL,
+	"ip":          (*Faker).IPv4,
+	"ipv4":        (*Faker).IPv4,
+	"ipv6":        (*Faker).IPv6,
+	"uuid":        (*Faker).UUID,
+	"credit_card": (*Faker).CreditCard,
+	"word":        (*Faker).Word,
+	"sentence":    (*Faker).Sentence,
+	"paragraph":   (*Faker).Paragraph,
+	"city":        (*Faker).City,
+	"country":     (*Faker).Country,
+	"json":        func(f *Faker) string { return fmt.Sprintf(`{"%s":"%s"}`, f.Word(), f.Word()) },
+	"date":        func(f *Faker) string { return f.Time().Format("2006-01-02") },
+	"time":        func(f *Faker) string { return f.Time().Format(time.RFC3339) },
+}
+
+// kindOfName returns the kind if the field name is a string kind, eg. Email, FirstName or IPv4
+func kindOfName(name string, rt reflect.Type) string {
+	for rt.Kind() == reflect.Ptr || rt.Kind() == reflect.Slice {
+		rt = rt.Elem()
+	}
+	if rt.Kind() != reflect.String {
+		return ""
+	}
+
+	normalized := strings.ToLower(name)
+	for kind := range stringKinds {
+		if strings.ReplaceAll(kind, "_", "") == normalized && kind != "json" && kind != "dns" {
+			return kind
+		}
+	}
+	return ""
+}
+
+func setString(value reflect.Value, s string) error {
+	for value.Kind() == reflect.Ptr {
+		if value.IsNil() {
+			value.Set(reflect.New(value.Type().Elem()))
+		}
+		value = value.Elem()
+	}
+
+	var err error
+	switch value.Kind() {
+	case reflect.String:
+		value.SetString(s)
+		return nil
+	case reflect.Bool:
+		var b bool
+		if b, err = strconv.ParseBool(s); err == nil {
+			value.SetBool(b)
+		}
+	case reflect.Int, reflect.Int8, reflect.Int16, reflect.Int32, reflect.Int64:
+		var n int64
+		if n, err = strconv.ParseInt(s, 10, value.Type().Bits()); err == nil {
+			value.SetInt(n)
+		}
+	case reflect.Uint, reflect.Uint8, reflect.Uint16, reflect.Uint32, reflect.Uint64, reflect.Uintptr:
+		var n uint64
+		if n, err = strconv.ParseUint(s, 10, value.Type().Bits()); err == nil {
+			value.SetUint(n)
+		}
+	case reflect.Float32, reflect.Float64:
+		var n float64
+		if n, err = strconv.ParseFloat(s, value.Type().Bits()); err == nil {
+			value.SetFloat(n)
+		}
+	default:
+		return fmt.Errorf("fake: oneof does not support %s", value.Type())
+	}
+
+	if err != nil {
+		return fmt.Errorf("fake: invalid oneof value %q for %s", s, value.Type())
+	}
+	return nil
+}
+
+func unsupportedKind(kind string, value reflect.Value) error {
+	return fmt.Errorf("fake: kind %q does not support %s", kind, value.Type())
+}
+
+// FirstName returns a random first name.
+func (f *Faker) FirstName() string {
+	return random.RandFromGivenSliceWith(f.source, firstNames)
+}
+
+// LastName returns a random last name.
+func (f *Faker) LastName() string {
+	return random.RandFromGivenSliceWith(f.source, lastNames)
+}
+
+// Name returns a random full name, eg. "John Smith".
+func (f *Faker) Name() string {
+	return f.FirstName() + " " + f.LastName()
+}
+
+// Username returns a random lower case username, eg. "john_smith42".
+func (f *Faker) Username() string {
+	return strings.ToLower(f.FirstName()+"_"+f.LastName()) + strconv.Itoa(f.source.RandInt(1, 100))
+}
+
+// Email returns a random email address which passes validator.IsEmail.
+func (f *Faker) Email() string {
+	return strings.ToLower(f.FirstName()+"."+f.LastName()) + strconv.Itoa(f.source.RandInt(1, 100)) + "@" + f.Domain()
+}
+
+// Phone returns a random chinese mobile number which passes validator.IsChineseMobile.
+func (f *Faker) Phone() string {
+	return random.RandFromGivenSliceWith(f.source, mobilePrefixes) + f.source.RandNumeral(9)
+}
+
+// Domain returns a random domain name which passes validator.IsDns, eg. "ocean-river.com".
+func (f *Faker) Domain() string {
+	return f.Word() + "-" + f.Word() + "." + random.RandFromGivenSliceWith(f.source, topLevelDomains)
+}
+
+// URL returns a random https url which passes validator.IsUrl.
+func (f *Faker) URL() string {
+	return "https://www." + f.Domain() + "/" + f.Word()
+}
+
+// IPv4 returns a random public ipv4 address.
+func (f *Faker) IPv4() string {
+	return fmt.Sprintf("%d.%d.%d.%d", f.source.RandInt(1, 224), f.source.RandInt(0, 256),
+		f.source.RandInt(0, 256), f.source.RandInt(1, 255))
+}
+
+// IPv6 returns a random global unicast ipv6 address.
+func (f *Faker) IPv6() string {
+	groups := make([]string, 8)
+	groups[0] = strconv.FormatInt(int64(f.source.RandInt(0x2000, 0x4000)), 16)
+	for i := 1; i < len(groups); i++ {
+		groups[i] = strconv.FormatInt(int64(f.source.RandInt(0, 0x10000)), 16)
+	}
+	return strings.Join(groups, ":")
+}
+
+// UUID returns a random uuid of version 4.
+func (f *Faker) UUID() string {
+	uuid, _ := f.source.UUIdV4()
+	return uuid
+}
+
+// CreditCard returns a random visa, master card or american express card number with valid Luhn check digit,
+// it passes validator.IsCreditCard.
+func (f *Faker) CreditCard() string {
+	card := random.RandFromGivenSliceWith(f.source, creditCardPrefixes)
+
+	digits := []byte(card.prefix + f.source.RandNumeral(card.length-len(card.prefix)-1))
+
+	// the check digit makes the Luhn sum a multiple of 10
+	sum := 0
+	for i := len(digits) - 1; i >= 0; i-- {
+		d := int(digits[i] - '0')
+		if (len(digits)-i)%2 == 1 {
+			d *= 2
+			if d > 9 {
+				d -= 9
+			}
+		}
+		sum += d
+	}
+
+	return string(digits) + strconv.Itoa((10-sum%10)%10)
+}
+
+// Word returns a random lower case word.
+func (f *Faker) Word() string {
+	return random.RandFromGivenSliceWith(f.source, words)
+}
+
+// Sentence returns a random sentence of 4 to 10 words.
+func (f *Faker) Sentence() string {
+	items := make([]string, f.source.RandInt(4, 11))
+	for i := range items {
+		items[i] = f.Word()
+	}
+	items[0] = strings.ToUpper(items[0][:1]) + items[0][1:]
+
+	return strings.Join(items, " ") + "."
+}
+
+// Paragraph returns a random paragraph of 3 to 5 sentences.
+func (f *Faker) Paragraph() string {
+	items := make([]string, f.source.RandInt(3, 6))
+	for i := range items {
+		items[i] = f.Sentence()
+	}
+	return strings.Join(items, " ")
+}
+
+// City returns a random city name.
+func (f *Faker) City() string {
+	return random.RandFromGivenSliceWith(f.source, cities)
+}
+
+// Country returns a random country name.
+func (f *Faker) Country() string {
+	return random.RandFromGivenSliceWith(f.source, countries)
+}
+
+// Time returns a random time in UTC between 2000-01-01 and 2030-01-01 in second precision.
+func (f *Faker) Time() time.Time {
+	seconds := f.source.RandInt(0, int(baseTime.AddDate(30, 0, 0).Sub(baseTime)/time.Second))
+	return baseTime.Add(time.Duration(seconds) * time.Second)
+}
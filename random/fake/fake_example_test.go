@@ -0,0 +1,50 @@
+package fake
+
+import (
+	"fmt"
+
+	"github.com/duke-git/lancet/v2/validator"
+)
+
+func ExampleFaker_Fill() {
+	type User struct {
+		Name  string   `fake:"name"`
+		Email string   `fake:"email"`
+		Age   int      `fake:"int,min=18,max=60"`
+		Role  string   `fake:"oneof=admin|user"`
+		Tags  []string `fake:"word,len=3"`
+	}
+
+	var user1, user2 User
+	_ = New(2023).Fill(&user1)
+	_ = New(2023).Fill(&user2)
+
+	fmt.Println(validator.IsEmail(user1.Email))
+	fmt.Println(user1.Age >= 18 && user1.Age <= 60)
+	fmt.Println(len(user1.Tags))
+	fmt.Println(user1.Name == user2.Name && user1.Email == user2.Email)
+
+	// Output:
+	// true
+	// true
+	// 3
+	// true
+}
+
+func ExampleFill() {
+	type Account struct {
+		Email string `validate:"required,email"`
+		Host  string `validate:"dns"`
+		Level int    `validate:"min=1,max=5"`
+	}
+
+	var account Account
+	err := Fill(&account)
+
+	fmt.Println(err)
+	fmt.Println(len(validator.ValidateStruct(account)))
+
+	// Output:
+	// <nil>
+	// 0
+}
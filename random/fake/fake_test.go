@@ -0,0 +1,255 @@
+package fake
+
+import (
+	"regexp"
+	"strconv"
+	"strings"
+	"testing"
+	"time"
+
+	"github.com/duke-git/lancet/v2/internal"
+	"github.com/duke-git/lancet/v2/random"
+	"github.com/duke-git/lancet/v2/validator"
+)
+
+type testAddress struct {
+	City    string `fake:"city"`
+	Country string `fake:"country"`
+	Zip     string `fake:"number,len=6"`
+}
+
+type testUser struct {
+	ID         string   `fake:"uuid"`
+	Name       string   `fake:"name"`
+	Username   string   `fake:"username"`
+	Email      string   `fake:"email"`
+	Phone      string   `fake:"phone"`
+	IP         string   `fake:"ipv4"`
+	IPv6       string   `fake:"ipv6"`
+	Website    string   `fake:"url"`
+	Card       string   `fake:"credit_card"`
+	Age        int      `fake:"int,min=1,max=9"`
+	Score      float64  `fake:"float,min=60,max=100"`
+	Level      uint8    `fake:"oneof=1|2|3"`
+	Role       string   `fake:"oneof=admin|user"`
+	Tags       []string `fake:"word,len=3"`
+	Bio        string   `fake:"sentence"`
+	Code       string   `fake:"alphanumeric,len=10"`
+	Ignored    string   `fake:"-"`
+	Active     bool
+	Count      int
+	Ratio      float32
+	Timeout    time.Duration
+	CreatedAt  time.Time
+	Address    testAddress
+	Previous   *testAddress
+	Addresses  []testAddress `fake:"len=2"`
+	Attributes map[string]int
+	Data       []byte
+	Matrix     [2][2]int
+	Any        any
+	unexported string
+}
+
+func TestFill(t *testing.T) {
+	t.Parallel()
+	assert := internal.NewAssert(t, "TestFill")
+
+	var user testUser
+	assert.IsNil(New(1).Fill(&user))
+
+	assert.Equal(true, regexp.MustCompile(`^[0-9a-f]{8}-[0-9a-f]{4}-4[0-9a-f]{3}-[89ab][0-9a-f]{3}-[0-9a-f]{12}$`).MatchString(user.ID))
+	assert.Equal(2, len(strings.Fields(user.Name)))
+	assert.Equal(true, validator.IsEmail(user.Email))
+	assert.Equal(true, validator.IsChineseMobile(user.Phone))
+	assert.Equal(true, validator.IsIpV4(user.IP))
+	assert.Equal(true, validator.IsIpV6(user.IPv6))
+	assert.Equal(true, validator.IsUrl(user.Website))
+	assert.Equal(true, validator.IsCreditCard(user.Card))
+	assert.Equal(true, luhn(user.Card))
+	assert.Equal(true, user.Age >= 1 && user.Age <= 9)
+	assert.Equal(true, user.Score >= 60 && user.Score <= 100)
+	assert.Equal(true, user.Level >= 1 && user.Level <= 3)
+	assert.Equal(true, user.Role == "admin" || user.Role == "user")
+	assert.Equal(3, len(user.Tags))
+	assert.Equal(true, strings.HasSuffix(user.Bio, "."))
+	assert.Equal(true, validator.IsAlphaNumeric(user.Code) && len(user.Code) == 10)
+	assert.Equal("", user.Ignored)
+	assert.Equal("", user.unexported)
+	assert.Equal(true, user.Timeout >= time.Second && user.Timeout <= time.Hour)
+	assert.Equal(true, user.CreatedAt.Year() >= 2000 && user.CreatedAt.Year() < 2030)
+
+	assert.NotEqual("", user.Address.City)
+	assert.Equal(6, len(user.Address.Zip))
+	assert.IsNotNil(user.Previous)
+	assert.NotEqual("", user.Previous.Country)
+	assert.Equal(2, len(user.Addresses))
+	assert.NotEqual("", user.Addresses[1].City)
+	assert.Equal(true, len(user.Attributes) >= 1 && len(user.Attributes) <= 3)
+	assert.Equal(true, len(user.Data) >= 1)
+	assert.IsNil(user.Any)
+}
+
+func TestFillReproducible(t *testing.T) {
+	t.Parallel()
+	assert := internal.NewAssert(t, "TestFillReproducible")
+
+	var user1, user2, user3 testUser
+	assert.IsNil(New(42).Fill(&user1))
+	assert.IsNil(NewWithSource(random.NewSource(42)).Fill(&user2))
+	assert.IsNil(New(43).Fill(&user3))
+
+	assert.Equal(user1, user2)
+	assert.NotEqual(user1, user3)
+}
+
+type testValidated struct {
+	Email    string   `json:"email" validate:"required,email"`
+	Homepage string   `validate:"url"`
+	Host     string   `validate:"dns"`
+	IP       string   `validate:"ip"`
+	Name     string   `validate:"required,alpha,min=3,max=5"`
+	Code     string   `validate:"alphanumeric,len=8"`
+	Amount   string   `validate:"number"`
+	Price    string   `validate:"float"`
+	Hex      string   `validate:"hex"`
+	Payload  string   `validate:"json"`
+	Encoded  string   `validate:"base64"`
+	Age      int      `validate:"min=18,max=60"`
+	Rate     float64  `validate:"min=0.5,max=1"`
+	Exact    int      `validate:"len=7"`
+	Status   string   `validate:"oneof=active disabled"`
+	Items    []string `validate:"required,min=2,max=4"`
+	Nested   struct {
+		IPv4 string `validate:"ipv4"`
+		IPv6 string `validate:"ipv6"`
+	}
+	Optional *string `validate:"email"`
+}
+
+func TestFillPassesValidateStruct(t *testing.T) {
+	t.Parallel()
+	assert := internal.NewAssert(t, "TestFillPassesValidateStruct")
+
+	faker := New(7)
+	for i := 0; i < 200; i++ {
+		var v testValidated
+		assert.IsNil(faker.Fill(&v))
+		assert.Equal(0, len(validator.ValidateStruct(v)))
+	}
+}
+
+func TestFillByFieldName(t *testing.T) {
+	t.Parallel()
+	assert := internal.NewAssert(t, "TestFillByFieldName")
+
+	var v struct {
+		Email     string
+		FirstName string
+		IPv4      string
+		URL       *string
+		Other     string
+	}
+	assert.IsNil(Fill(&v))
+
+	assert.Equal(true, validator.IsEmail(v.Email))
+	assert.Equal(true, validator.IsIpV4(v.IPv4))
+	assert.Equal(true, validator.IsUrl(*v.URL))
+	assert.Equal(true, validator.IsAlpha(v.FirstName))
+	assert.Equal(true, validator.IsAllLower(v.Other))
+}
+
+func TestFillSelfReference(t *testing.T) {
+	t.Parallel()
+	assert := internal.NewAssert(t, "TestFillSelfReference")
+
+	type node struct {
+		Value    int
+		Next     *node
+		Children []node
+	}
+
+	var n node
+	assert.IsNil(New(1).Fill(&n))
+
+	depth := 0
+	for p := &n; p != nil; p = p.Next {
+		depth++
+	}
+	assert.Equal(true, depth > 1 && depth <= maxDepth)
+}
+
+func TestFillErrors(t *testing.T) {
+	t.Parallel()
+	assert := internal.NewAssert(t, "TestFillErrors")
+
+	faker := New(1)
+
+	var v struct {
+		A string `fake:"unknown"`
+	}
+	assert.IsNotNil(faker.Fill(&v))
+	assert.IsNotNil(faker.Fill(v))
+	assert.IsNotNil(faker.Fill(nil))
+
+	var invalidOption struct {
+		A int `fake:"int,min=a"`
+	}
+	assert.IsNotNil(faker.Fill(&invalidOption))
+
+	var invalidKind struct {
+		A int `fake:"email"`
+	}
+	assert.IsNotNil(faker.Fill(&invalidKind))
+
+	var invalidRange struct {
+		A int `fake:"int,min=9,max=1"`
+	}
+	assert.IsNotNil(faker.Fill(&invalidRange))
+
+	var invalidOneof struct {
+		A int `fake:"oneof=a|b"`
+	}
+	assert.IsNotNil(faker.Fill(&invalidOneof))
+
+	// the unknown rule of validate tag is ignored
+	var unknownRule struct {
+		A string `validate:"jwt"`
+	}
+	assert.IsNil(faker.Fill(&unknownRule))
+}
+
+func TestGenerators(t *testing.T) {
+	t.Parallel()
+	assert := internal.NewAssert(t, "TestGenerators")
+
+	faker := New(time.Now().UnixNano())
+	for i := 0; i < 1000; i++ {
+		assert.Equal(true, validator.IsEmail(faker.Email()))
+		assert.Equal(true, validator.IsUrl(faker.URL()))
+		assert.Equal(true, validator.IsDns(faker.Domain()))
+		assert.Equal(true, validator.IsIpV4(faker.IPv4()))
+		assert.Equal(true, validator.IsIpV6(faker.IPv6()))
+		assert.Equal(true, validator.IsChineseMobile(faker.Phone()))
+
+		card := faker.CreditCard()
+		assert.Equal(true, validator.IsCreditCard(card))
+		assert.Equal(true, luhn(card))
+	}
+}
+
+// luhn checks the Luhn check digit of number
+func luhn(number string) bool {
+	sum := 0
+	for i := len(number) - 1; i >= 0; i-- {
+		d, _ := strconv.Atoi(number[i : i+1])
+		if (len(number)-i)%2 == 0 {
+			d *= 2
+			if d > 9 {
+				d -= 9
+			}
+		}
+		sum += d
+	}
+	return sum%10 == 0
+}
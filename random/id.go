@@ -0,0 +1,370 @@
+// Copyright 2023 dudaodong@gmail.com. All rights reserved.
+// Use of this source code is governed by MIT license.
+
+package random
+
+import (
+	crand "crypto/rand"
+	"encoding/binary"
+	"encoding/hex"
+	"errors"
+	"fmt"
+	"io"
+	"math/bits"
+	"strings"
+	"sync"
+	"time"
+)
+
+const (
+	// NanoIDAlphabet is the default url-safe alphabet of NanoID.
+	NanoIDAlphabet = "_-" + Numeral + LowwerLetters + UpperLetters
+	// NanoIDSize is the default size of NanoID.
+	NanoIDSize = 21
+
+	// crockfordBase32 is the alphabet of ULID
+	crockfordBase32 = "0123456789ABCDEFGHJKMNPQRSTVWXYZ"
+	// ksuidEpoch is the epoch of KSUID timestamp in unix seconds
+	ksuidEpoch = 1400000000
+)
+
+var (
+	// ErrInvalidID is returned when the id can not be parsed.
+	ErrInvalidID = errors.New("random: invalid id")
+
+	// uuidV7State keeps the last timestamp and counter of UUIdV7 for monotonicity in the same millisecond
+	uuidV7State struct {
+		sync.Mutex
+		millis  int64
+		counter uint16
+	}
+)
+
+// UUIdV7 generates a time-ordered UUID of version 7 according to RFC 9562.
+// The first 48 bits are unix milliseconds, the uuids generated in the same millisecond are increasing by
+// a 12 bits counter which starts from a random value.
+func UUIdV7() (string, error) {
+	uuid := make([]byte, 16)
+	if _, err := io.ReadFull(crand.Reader, uuid); err != nil {
+		return "", err
+	}
+
+	uuidV7State.Lock()
+	millis := time.Now().UnixMilli()
+	if millis <= uuidV7State.millis {
+		uuidV7State.counter++
+		millis = uuidV7State.millis
+		if uuidV7State.counter > 0xfff {
+			// the counter overflows, borrow the next millisecond
+			millis++
+			uuidV7State.counter = uint16(uuid[6]) << 8 & 0x700
+		}
+	} else {
+		// the highest bit of counter is 0 to leave space for increasing
+		uuidV7State.counter = binary.BigEndian.Uint16(uuid[6:8]) & 0x7ff
+	}
+	uuidV7State.millis = millis
+	counter := uuidV7State.counter
+	uuidV7State.Unlock()
+
+	putUint48(uuid, uint64(millis))
+	binary.BigEndian.PutUint16(uuid[6:8], 0x7000|counter)
+	uuid[8] = uuid[8]&^0xc0 | 0x80
+
+	return fmt.Sprintf("%x-%x-%x-%x-%x", uuid[0:4], uuid[4:6], uuid[6:8], uuid[8:10], uuid[10:]), nil
+}
+
+// ParseUUIdV7Time returns the timestamp of UUIdV7 in millisecond precision.
+func ParseUUIdV7Time(uuid string) (time.Time, error) {
+	if len(uuid) != 36 || uuid[8] != '-' || uuid[13] != '-' || uuid[18] != '-' || uuid[23] != '-' {
+		return time.Time{}, ErrInvalidID
+	}
+
+	b, err := hex.DecodeString(strings.ReplaceAll(uuid, "-", ""))
+	if err != nil || b[6]>>4 != 7 {
+		return time.Time{}, ErrInvalidID
+	}
+
+	return time.UnixMilli(int64(uint48(b))), nil
+}
+
+// ULID generates a Universally Unique Lexicographically Sortable Identifier, it is 26 characters of
+// Crockford's base32 encoding of 48 bits unix milliseconds and 80 bits crypto-secure randomness.
+// The ulids generated in the same millisecond are not ordered, use ULIDGenerator for monotonic ulids.
+func ULID() (string, error) {
+	id := make([]byte, 16)
+	if _, err := io.ReadFull(crand.Reader, id[6:]); err != nil {
+		return "", err
+	}
+	putUint48(id, uint64(time.Now().UnixMilli()))
+
+	return encodeULID(id), nil
+}
+
+// ULIDGenerator generates monotonic ULIDs, the random part of the ulid generated in the same millisecond
+// is the previous one plus one, so the ulids are strictly increasing. It is safe for concurrent use.
+type ULIDGenerator struct {
+	mu     sync.Mutex
+	millis uint64
+	last   [16]byte
+}
+
+// NewULIDGenerator creates a monotonic ULIDGenerator.
+func NewULIDGenerator() *ULIDGenerator {
+	return &ULIDGenerator{}
+}
+
+// Next returns the next ulid, error is returned if the random part overflows in a millisecond which is
+// almost impossible.
+func (g *ULIDGenerator) Next() (string, error) {
+	g.mu.Lock()
+	defer g.mu.Unlock()
+
+	millis := uint64(time.Now().UnixMilli())
+	if millis <= g.millis {
+		// increase the 80 bits random part as big-endian number
+		for i := 15; i >= 6; i-- {
+			g.last[i]++
+			if g.last[i] != 0 {
+				return encodeULID(g.last[:]), nil
+			}
+		}
+		return "", errors.New("random: ulid random part overflows")
+	}
+
+	if _, err := io.ReadFull(crand.Reader, g.last[6:]); err != nil {
+		return "", err
+	}
+	g.millis = millis
+	putUint48(g.last[:], millis)
+
+	return encodeULID(g.last[:]), nil
+}
+
+// ParseULIDTime returns the timestamp of ULID in millisecond precision, the ulid is case insensitive.
+func ParseULIDTime(ulid string) (time.Time, error) {
+	id, err := decodeULID(ulid)
+	if err != nil {
+		return time.Time{}, err
+	}
+	return time.UnixMilli(int64(uint48(id))), nil
+}
+
+// encodeULID encodes the 128 bits id to 26 characters, the id is left padded with 2 zero bits
+func encodeULID(id []byte) string {
+	hi, lo := binary.BigEndian.Uint64(id[:8]), binary.BigEndian.Uint64(id[8:])
+
+	result := make([]byte, 26)
+	for i := range result {
+		var v uint64
+		shift := uint(125 - 5*i)
+		switch {
+		case shift >= 64:
+			v = hi >> (shift - 64)
+		case shift+5 <= 64:
+			v = lo >> shift
+		default:
+			v = hi<<(64-shift) | lo>>shift
+		}
+		result[i] = crockfordBase32[v&31]
+	}
+
+	return string(result)
+}
+
+func decodeULID(s string) ([]byte, error) {
+	if len(s) != 26 || s[0] > '7' {
+		return nil, ErrInvalidID
+	}
+
+	var hi, lo uint64
+	for i := 0; i < len(s); i++ {
+		v := strings.IndexByte(crockfordBase32, upper(s[i]))
+		if v < 0 {
+			return nil, ErrInvalidID
+		}
+		hi = hi<<5 | lo>>59
+		lo = lo<<5 | uint64(v)
+	}
+
+	id := make([]byte, 16)
+	binary.BigEndian.PutUint64(id, hi)
+	binary.BigEndian.PutUint64(id[8:], lo)
+
+	return id, nil
+}
+
+func upper(c byte) byte {
+	if c >= 'a' && c <= 'z' {
+		return c - 'a' + 'A'
+	}
+	return c
+}
+
+// KSUID generates a K-Sortable Unique IDentifier, it is 27 characters of base62 encoding of
+// 32 bits timestamp in seconds since 2014-05-13 and 128 bits crypto-secure randomness.
+func KSUID() (string, error) {
+	id := make([]byte, 20)
+	if _, err := io.ReadFull(crand.Reader, id[4:]); err != nil {
+		return "", err
+	}
+	binary.BigEndian.PutUint32(id, uint32(time.Now().Unix()-ksuidEpoch))
+
+	return encodeBase62(id, 27), nil
+}
+
+// ParseKSUIDTime returns the timestamp of KSUID in second precision.
+func ParseKSUIDTime(ksuid string) (time.Time, error) {
+	if len(ksuid) != 27 {
+		return time.Time{}, ErrInvalidID
+	}
+
+	id, err := decodeBase62(ksuid, 20)
+	if err != nil {
+		return time.Time{}, ErrInvalidID
+	}
+
+	return time.Unix(int64(binary.BigEndian.Uint32(id))+ksuidEpoch, 0), nil
+}
+
+// NanoID generates a crypto-secure random id of size characters from alphabet,
+// the default size is NanoIDSize and the default alphabet is NanoIDAlphabet.
+// len(alphabet) should be between 2 and 256.
+func NanoID(size int, alphabet ...string) (string, error) {
+	chars := NanoIDAlphabet
+	if len(alphabet) > 0 && alphabet[0] != "" {
+		chars = alphabet[0]
+	}
+	if size <= 0 {
+		size = NanoIDSize
+	}
+	if len(chars) < 2 || len(chars) > 256 {
+		return "", errors.New("random: alphabet length should be between 2 and 256")
+	}
+
+	// the random byte is masked to the nearest power of two and rejected if it is out of alphabet,
+	// so each character is uniformly distributed
+	mask := byte(1<<bits.Len(uint(len(chars)-1)) - 1)
+	step := 1.6 * float64(int(mask)+1) * float64(size) / float64(len(chars))
+
+	result := make([]byte, 0, size)
+	buf := make([]byte, int(step)+1)
+	for {
+		if _, err := io.ReadFull(crand.Reader, buf); err != nil {
+			return "", err
+		}
+		for _, b := range buf {
+			if idx := int(b & mask); idx < len(chars) {
+				result = append(result, chars[idx])
+				if len(result) == size {
+					return string(result), nil
+				}
+			}
+		}
+	}
+}
+
+// SnowflakeOptions is the options of Snowflake.
+type SnowflakeOptions struct {
+	// Epoch is the start time of timestamp, default is the twitter epoch 2010-11-04 01:42:54.657 UTC.
+	Epoch time.Time
+	// NodeBits is the bits of node id, default is 10.
+	NodeBits uint8
+	// StepBits is the bits of sequence in a millisecond, default is 12.
+	StepBits uint8
+}
+
+// Snowflake generates 63 bits time-ordered ids of timestamp, node id and sequence, it is safe for concurrent use.
+type Snowflake struct {
+	mu       sync.Mutex
+	epoch    int64
+	node     int64
+	nodeBits uint8
+	stepBits uint8
+	millis   int64
+	step     int64
+}
+
+// NewSnowflake creates a Snowflake generator of node, the node should be in [0, 1<<NodeBits).
+// NodeBits + StepBits should be less than 32, the rest bits are the milliseconds since epoch.
+func NewSnowflake(node int64, options ...SnowflakeOptions) (*Snowflake, error) {
+	opts := SnowflakeOptions{}
+	if len(options) > 0 {
+		opts = options[0]
+	}
+	if opts.Epoch.IsZero() {
+		opts.Epoch = time.UnixMilli(1288834974657)
+	}
+	if opts.NodeBits == 0 {
+		opts.NodeBits = 10
+	}
+	if opts.StepBits == 0 {
+		opts.StepBits = 12
+	}
+
+	if opts.NodeBits+opts.StepBits >= 32 {
+		return nil, errors.New("random: node bits and step bits should be less than 32")
+	}
+	if node < 0 || node >= 1<<opts.NodeBits {
+		return nil, fmt.Errorf("random: node should be in [0, %d)", 1<<opts.NodeBits)
+	}
+	if opts.Epoch.After(time.Now()) {
+		return nil, errors.New("random: epoch should not be in the future")
+	}
+
+	return &Snowflake{
+		epoch:    opts.Epoch.UnixMilli(),
+		node:     node,
+		nodeBits: opts.NodeBits,
+		stepBits: opts.StepBits,
+	}, nil
+}
+
+// Next returns the next id, it waits for the next millisecond if the sequence is exhausted.
+// Error is returned if the clock moves backwards or the timestamp overflows.
+func (s *Snowflake) Next() (int64, error) {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+
+	millis := time.Now().UnixMilli() - s.epoch
+	if millis < s.millis {
+		return 0, fmt.Errorf("random: clock moved backwards %dms", s.millis-millis)
+	}
+
+	if millis == s.millis {
+		s.step = (s.step + 1) & (1<<s.stepBits - 1)
+		if s.step == 0 {
+			for millis <= s.millis {
+				time.Sleep(time.Until(time.UnixMilli(s.epoch + s.millis + 1)))
+				millis = time.Now().UnixMilli() - s.epoch
+			}
+		}
+	} else {
+		s.step = 0
+	}
+	s.millis = millis
+
+	if millis >= 1<<(63-s.nodeBits-s.stepBits) {
+		return 0, errors.New("random: snowflake timestamp overflows")
+	}
+
+	return millis<<(s.nodeBits+s.stepBits) | s.node<<s.stepBits | s.step, nil
+}
+
+// Parse returns the timestamp, node and sequence of the id generated by the snowflake with same options.
+func (s *Snowflake) Parse(id int64) (time.Time, int64, int64) {
+	millis := id >> (s.nodeBits + s.stepBits)
+	node := id >> s.stepBits & (1<<s.nodeBits - 1)
+	step := id & (1<<s.stepBits - 1)
+
+	return time.UnixMilli(s.epoch + millis), node, step
+}
+
+func putUint48(b []byte, v uint64) {
+	b[0], b[1], b[2] = byte(v>>40), byte(v>>32), byte(v>>24)
+	b[3], b[4], b[5] = byte(v>>16), byte(v>>8), byte(v)
+}
+
+func uint48(b []byte) uint64 {
+	return uint64(b[0])<<40 | uint64(b[1])<<32 | uint64(b[2])<<24 | uint64(b[3])<<16 | uint64(b[4])<<8 | uint64(b[5])
+}
@@ -0,0 +1,248 @@
+package random
+
+import (
+	"regexp"
+	"sort"
+	"strings"
+	"sync"
+	"testing"
+	"time"
+
+	"github.com/duke-git/lancet/v2/internal"
+)
+
+func TestRandToken(t *testing.T) {
+	t.Parallel()
+	assert := internal.NewAssert(t, "TestRandToken")
+
+	token, err := RandToken(16)
+	assert.IsNil(err)
+	assert.Equal(true, regexp.MustCompile(`^[0-9a-f]{32}$`).MatchString(token))
+
+	token, err = RandTokenBase32(20)
+	assert.IsNil(err)
+	assert.Equal(true, regexp.MustCompile(`^[A-Z2-7]{32}$`).MatchString(token))
+
+	token, err = RandTokenBase62(32)
+	assert.IsNil(err)
+	assert.Equal(true, regexp.MustCompile(`^[0-9A-Za-z]{43}$`).MatchString(token))
+
+	another, _ := RandTokenBase62(32)
+	assert.NotEqual(token, another)
+
+	_, err = RandToken(0)
+	assert.IsNotNil(err)
+}
+
+func TestBase62(t *testing.T) {
+	t.Parallel()
+	assert := internal.NewAssert(t, "TestBase62")
+
+	for _, b := range [][]byte{{0, 0, 0}, {0xff, 0xff, 0xff}, {1, 2, 3, 4, 5}} {
+		encoded := encodeBase62(b, 10)
+		decoded, err := decodeBase62(encoded, len(b))
+		assert.IsNil(err)
+		assert.Equal(b, decoded)
+	}
+
+	_, err := decodeBase62("zzzzzz", 2)
+	assert.IsNotNil(err)
+	_, err = decodeBase62("a-b", 2)
+	assert.IsNotNil(err)
+}
+
+func TestUUIdV7(t *testing.T) {
+	t.Parallel()
+	assert := internal.NewAssert(t, "TestUUIdV7")
+
+	isUUIdV7 := regexp.MustCompile(`^[0-9a-f]{8}-[0-9a-f]{4}-7[0-9a-f]{3}-[89ab][0-9a-f]{3}-[0-9a-f]{12}$`)
+
+	before := time.Now().Truncate(time.Millisecond)
+	ids := make([]string, 5000)
+	for i := range ids {
+		id, err := UUIdV7()
+		assert.IsNil(err)
+		assert.Equal(true, isUUIdV7.MatchString(id))
+		ids[i] = id
+	}
+
+	assert.Equal(true, sort.StringsAreSorted(ids))
+	assert.Equal(len(ids), len(uniqueStrings(ids)))
+
+	ts, err := ParseUUIdV7Time(ids[0])
+	assert.IsNil(err)
+	assert.Equal(false, ts.Before(before))
+	assert.Equal(true, ts.Before(time.Now().Add(time.Second)))
+
+	// RFC 9562 appendix A.6
+	ts, err = ParseUUIdV7Time("017f22e2-79b0-7cc3-98c4-dc0c0c07398f")
+	assert.IsNil(err)
+	assert.Equal(int64(1645557742000), ts.UnixMilli())
+
+	_, err = ParseUUIdV7Time("f81d4fae-7dec-11d0-a765-00a0c91e6bf6")
+	assert.Equal(ErrInvalidID, err)
+}
+
+func TestULID(t *testing.T) {
+	t.Parallel()
+	assert := internal.NewAssert(t, "TestULID")
+
+	isULID := regexp.MustCompile(`^[0-7][0-9A-HJKMNP-TV-Z]{25}$`)
+
+	id, err := ULID()
+	assert.IsNil(err)
+	assert.Equal(true, isULID.MatchString(id))
+
+	ts, err := ParseULIDTime(strings.ToLower(id))
+	assert.IsNil(err)
+	assert.Equal(true, time.Since(ts) < time.Second)
+
+	// the example of ulid spec
+	ts, err = ParseULIDTime("01ARZ3NDEKTSV4RRFFQ69G5FAV")
+	assert.IsNil(err)
+	assert.Equal(int64(1469922850259), ts.UnixMilli())
+
+	decoded, _ := decodeULID("01ARZ3NDEKTSV4RRFFQ69G5FAV")
+	assert.Equal("01ARZ3NDEKTSV4RRFFQ69G5FAV", encodeULID(decoded))
+	assert.Equal("7ZZZZZZZZZZZZZZZZZZZZZZZZZ", encodeULID([]byte(strings.Repeat("\xff", 16))))
+
+	_, err = ParseULIDTime("8ZZZZZZZZZZZZZZZZZZZZZZZZZ")
+	assert.Equal(ErrInvalidID, err)
+	_, err = ParseULIDTime("01ARZ3NDEKTSV4RRFFQ69G5FAU")
+	assert.Equal(ErrInvalidID, err)
+}
+
+func TestULIDGenerator(t *testing.T) {
+	t.Parallel()
+	assert := internal.NewAssert(t, "TestULIDGenerator")
+
+	generator := NewULIDGenerator()
+
+	var mu sync.Mutex
+	var wg sync.WaitGroup
+	ids := make([]string, 0, 4000)
+	for i := 0; i < 4; i++ {
+		wg.Add(1)
+		go func() {
+			defer wg.Done()
+			for j := 0; j < 1000; j++ {
+				id, err := generator.Next()
+				assert.IsNil(err)
+				mu.Lock()
+				ids = append(ids, id)
+				mu.Unlock()
+			}
+		}()
+	}
+	wg.Wait()
+
+	assert.Equal(len(ids), len(uniqueStrings(ids)))
+
+	// the ids of single goroutine are strictly increasing
+	sequence := make([]string, 1000)
+	for i := range sequence {
+		sequence[i], _ = generator.Next()
+		if i > 0 {
+			assert.Equal(true, sequence[i-1] < sequence[i])
+		}
+	}
+}
+
+func TestKSUID(t *testing.T) {
+	t.Parallel()
+	assert := internal.NewAssert(t, "TestKSUID")
+
+	id, err := KSUID()
+	assert.IsNil(err)
+	assert.Equal(true, regexp.MustCompile(`^[0-9A-Za-z]{27}$`).MatchString(id))
+
+	ts, err := ParseKSUIDTime(id)
+	assert.IsNil(err)
+	assert.Equal(true, time.Since(ts) < 2*time.Second)
+
+	// the example of ksuid readme
+	ts, err = ParseKSUIDTime("0ujtsYcgvSTl8PAuAdqWYSMnLOv")
+	assert.IsNil(err)
+	assert.Equal(int64(1507608047), ts.Unix())
+
+	_, err = ParseKSUIDTime("0ujtsYcgvSTl8PAuAdqWYSMnLO")
+	assert.Equal(ErrInvalidID, err)
+}
+
+func TestNanoID(t *testing.T) {
+	t.Parallel()
+	assert := internal.NewAssert(t, "TestNanoID")
+
+	id, err := NanoID(0)
+	assert.IsNil(err)
+	assert.Equal(true, regexp.MustCompile(`^[0-9A-Za-z_-]{21}$`).MatchString(id))
+
+	id, err = NanoID(10, "abc")
+	assert.IsNil(err)
+	assert.Equal(true, regexp.MustCompile(`^[abc]{10}$`).MatchString(id))
+
+	// each character is uniformly distributed
+	counts := map[rune]int{}
+	for i := 0; i < 300; i++ {
+		id, _ := NanoID(100, "0123456789")
+		for _, c := range id {
+			counts[c]++
+		}
+	}
+	assert.Equal(10, len(counts))
+	for _, count := range counts {
+		assert.Equal(true, count > 2500 && count < 3500)
+	}
+
+	_, err = NanoID(10, "a")
+	assert.IsNotNil(err)
+}
+
+func TestSnowflake(t *testing.T) {
+	t.Parallel()
+	assert := internal.NewAssert(t, "TestSnowflake")
+
+	epoch := time.Date(2020, 1, 1, 0, 0, 0, 0, time.UTC)
+	snowflake, err := NewSnowflake(5, SnowflakeOptions{Epoch: epoch, NodeBits: 5, StepBits: 8})
+	assert.IsNil(err)
+
+	before := time.Now().Truncate(time.Millisecond)
+
+	// the sequence is exhausted in a millisecond
+	ids := make([]int64, 2000)
+	for i := range ids {
+		ids[i], err = snowflake.Next()
+		assert.IsNil(err)
+		if i > 0 {
+			assert.Equal(true, ids[i-1] < ids[i])
+		}
+	}
+
+	ts, node, _ := snowflake.Parse(ids[0])
+	assert.Equal(int64(5), node)
+	assert.Equal(false, ts.Before(before))
+	assert.Equal(true, time.Since(ts) < time.Second)
+
+	_, _, step := snowflake.Parse(ids[1])
+	assert.Equal(true, step <= 255)
+
+	_, err = NewSnowflake(32, SnowflakeOptions{NodeBits: 5})
+	assert.IsNotNil(err)
+	_, err = NewSnowflake(0, SnowflakeOptions{NodeBits: 20, StepBits: 12})
+	assert.IsNotNil(err)
+
+	defaultSnowflake, err := NewSnowflake(1023)
+	assert.IsNil(err)
+	id, err := defaultSnowflake.Next()
+	assert.IsNil(err)
+	_, node, _ = defaultSnowflake.Parse(id)
+	assert.Equal(int64(1023), node)
+}
+
+func uniqueStrings(s []string) map[string]struct{} {
+	set := make(map[string]struct{}, len(s))
+	for _, item := range s {
+		set[item] = struct{}{}
+	}
+	return set
+}
@@ -9,12 +9,6 @@ import (
 	"fmt"
 	"io"
 	"math"
-	"math/rand"
-	"os"
-	"time"
-	"unsafe"
-
-	"github.com/duke-git/lancet/v2/mathutil"
 )
 
 const (
@@ -27,136 +21,47 @@ const (
 	AllChars        = Numeral + LowwerLetters + UpperLetters + SymbolChars
 )
 
-var rn = rand.NewSource(time.Now().UnixNano())
-
-func init() {
-	rand.Seed(time.Now().UnixNano())
-}
-
 // RandBool generates a random boolean value (true or false).
 // Play: https://go.dev/play/p/to6BLc26wBv
 func RandBool() bool {
-	return rand.Intn(2) == 1
+	return defaultSource.RandBool()
 }
 
 // RandBoolSlice generates a random boolean slice of specified length.
 // Play: https://go.dev/play/p/o-VSjPjnILI
 func RandBoolSlice(length int) []bool {
-	if length <= 0 {
-		return []bool{}
-	}
-
-	result := make([]bool, length)
-	for i := range result {
-		result[i] = RandBool()
-	}
-
-	return result
+	return defaultSource.RandBoolSlice(length)
 }
 
 // RandInt generate random int between [min, max).
 // Play: https://go.dev/play/p/pXyyAAI5YxD
 func RandInt(min, max int) int {
-	if min == max {
-		return min
-	}
-
-	if max < min {
-		min, max = max, min
-	}
-
-	if min == 0 && max == math.MaxInt {
-		return rand.Int()
-	}
-
-	return rand.Intn(max-min) + min
+	return defaultSource.RandInt(min, max)
 }
 
 // RandIntSlice generates a slice of random integers.
 // The generated integers are between min and max (exclusive).
 // Play: https://go.dev/play/p/GATTQ5xTEG8
 func RandIntSlice(length, min, max int) []int {
-	if length <= 0 || min > max {
-		return []int{}
-	}
-
-	result := make([]int, length)
-	for i := range result {
-		result[i] = RandInt(min, max)
-	}
-
-	return result
+	return defaultSource.RandIntSlice(length, min, max)
 }
 
 // RandUniqueIntSlice generate a slice of random int of length that do not repeat.
 // Play: https://go.dev/play/p/uBkRSOz73Ec
 func RandUniqueIntSlice(length, min, max int) []int {
-	if min > max {
-		return []int{}
-	}
-	if length > max-min {
-		length = max - min
-	}
-
-	nums := make([]int, length)
-	used := make(map[int]struct{}, length)
-	for i := 0; i < length; {
-		r := RandInt(min, max)
-		if _, use := used[r]; use {
-			continue
-		}
-		used[r] = struct{}{}
-		nums[i] = r
-		i++
-	}
-
-	return nums
+	return defaultSource.RandUniqueIntSlice(length, min, max)
 }
 
 // RandFloat generate random float64 number between [min, max) with specific precision.
 // Play: https://go.dev/play/p/zbD_tuobJtr
 func RandFloat(min, max float64, precision int) float64 {
-	if min == max {
-		return min
-	}
-
-	if max < min {
-		min, max = max, min
-	}
-
-	n := rand.Float64()*(max-min) + min
-
-	return mathutil.FloorToFloat(n, precision)
+	return defaultSource.RandFloat(min, max, precision)
 }
 
 // RandFloats generate a slice of random float64 numbers of length that do not repeat.
 // Play: https://go.dev/play/p/I3yndUQ-rhh
 func RandFloats(length int, min, max float64, precision int) []float64 {
-	if max < min {
-		min, max = max, min
-	}
-
-	maxLength := int((max - min) * math.Pow10(precision))
-	if maxLength == 0 {
-		maxLength = 1
-	}
-	if length > maxLength {
-		length = maxLength
-	}
-
-	nums := make([]float64, length)
-	used := make(map[float64]struct{}, length)
-	for i := 0; i < length; {
-		r := RandFloat(min, max, precision)
-		if _, use := used[r]; use {
-			continue
-		}
-		used[r] = struct{}{}
-		nums[i] = r
-		i++
-	}
-
-	return nums
+	return defaultSource.RandFloats(length, min, max, precision)
 }
 
 // RandBytes generate random byte slice.
@@ -177,7 +82,7 @@ func RandBytes(length int) []byte {
 // RandString generate random alphabeta string of specified length.
 // Play: https://go.dev/play/p/W2xvRUXA7Mi
 func RandString(length int) string {
-	return random(Letters, length)
+	return defaultSource.RandString(length)
 }
 
 // RandString generate a slice of random string of length strLen based on charset.
@@ -185,27 +90,13 @@ func RandString(length int) string {
 // random.Letters, random.SymbolChars, random.AllChars. or a combination of them.
 // Play: https://go.dev/play/p/2_-PiDv3tGn
 func RandStringSlice(charset string, sliceLen, strLen int) []string {
-	if sliceLen <= 0 || strLen <= 0 {
-		return []string{}
-	}
-
-	result := make([]string, sliceLen)
-
-	for i := range result {
-		result[i] = random(charset, strLen)
-	}
-
-	return result
+	return defaultSource.RandStringSlice(charset, sliceLen, strLen)
 }
 
 // RandFromGivenSlice generate a random element from given slice.
 // Play: https://go.dev/play/p/UrkWueF6yYo
 func RandFromGivenSlice[T any](slice []T) T {
-	if len(slice) == 0 {
-		var zero T
-		return zero
-	}
-	return slice[rand.Intn(len(slice))]
+	return RandFromGivenSliceWith(defaultSource, slice)
 }
 
 // RandSliceFromGivenSlice generate a random slice of length num from given slice.
@@ -213,59 +104,38 @@ func RandFromGivenSlice[T any](slice []T) T {
 //
 // Play: https://go.dev/play/p/68UikN9d6VT
 func RandSliceFromGivenSlice[T any](slice []T, num int, repeatable bool) []T {
-	if num <= 0 || len(slice) == 0 {
-		return slice
-	}
-
-	if !repeatable && num > len(slice) {
-		num = len(slice)
-	}
-
-	result := make([]T, num)
-	if repeatable {
-		for i := range result {
-			result[i] = slice[rand.Intn(len(slice))]
-		}
-	} else {
-		shuffled := make([]T, len(slice))
-		copy(shuffled, slice)
-		rand.Shuffle(len(shuffled), func(i, j int) {
-			shuffled[i], shuffled[j] = shuffled[j], shuffled[i]
-		})
-		result = shuffled[:num]
-	}
-	return result
+	return RandSliceFromGivenSliceWith(defaultSource, slice, num, repeatable)
 }
 
 // RandUpper generate a random upper case string of specified length.
 // Play: https://go.dev/play/p/29QfOh0DVuh
 func RandUpper(length int) string {
-	return random(UpperLetters, length)
+	return defaultSource.RandUpper(length)
 }
 
 // RandLower generate a random lower case string of specified length.
 // Play: https://go.dev/play/p/XJtZ471cmtI
 func RandLower(length int) string {
-	return random(LowwerLetters, length)
+	return defaultSource.RandLower(length)
 }
 
 // RandNumeral generate a random numeral string of specified length.
 // Play: https://go.dev/play/p/g4JWVpHsJcf
 func RandNumeral(length int) string {
-	return random(Numeral, length)
+	return defaultSource.RandNumeral(length)
 }
 
 // RandNumeralOrLetter generate a random numeral or alpha string of specified length.
 // Play: https://go.dev/play/p/19CEQvpx2jD
 func RandNumeralOrLetter(length int) string {
-	return random(Numeral+Letters, length)
+	return defaultSource.RandNumeralOrLetter(length)
 }
 
 // RandSymbolChar generate a random symbol char of specified length.
 // symbol chars: !@#$%^&*()_+-=[]{}|;':\",./<>?.
 // Play: https://go.dev/play/p/Im6ZJxAykOm
 func RandSymbolChar(length int) string {
-	return random(SymbolChars, length)
+	return defaultSource.RandSymbolChar(length)
 }
 
 // nearestPowerOfTwo 返回一个大于等于cap的最近的2的整数次幂，参考java8的hashmap的tableSizeFor函数
@@ -284,52 +154,6 @@ func nearestPowerOfTwo(cap int) int {
 	return n + 1
 }
 
-// random generate a random string based on given string range.
-func random(s string, length int) string {
-	// 确保随机数生成器的种子是动态的
-	pid := os.Getpid()
-	timestamp := time.Now().UnixNano()
-	rand.Seed(int64(pid) + timestamp)
-
-	// 仿照strings.Builder
-	// 创建一个长度为 length 的字节切片
-	bytes := make([]byte, length)
-	strLength := len(s)
-	if strLength <= 0 {
-		return ""
-	} else if strLength == 1 {
-		for i := 0; i < length; i++ {
-			bytes[i] = s[0]
-		}
-		return *(*string)(unsafe.Pointer(&bytes))
-	}
-	// s的字符需要使用多少个比特位数才能表示完
-	// letterIdBits := int(math.Ceil(math.Log2(strLength))),下面比上面的代码快
-	letterIdBits := int(math.Log2(float64(nearestPowerOfTwo(strLength))))
-	// 最大的字母id掩码
-	var letterIdMask int64 = 1<<letterIdBits - 1
-	// 可用次数的最大值
-	letterIdMax := 63 / letterIdBits
-	// 循环生成随机字符串
-	for i, cache, remain := length-1, rn.Int63(), letterIdMax; i >= 0; {
-		// 检查随机数生成器是否用尽所有随机数
-		if remain == 0 {
-			cache, remain = rn.Int63(), letterIdMax
-		}
-		// 从可用字符的字符串中随机选择一个字符
-		if idx := int(cache & letterIdMask); idx < strLength {
-			bytes[i] = s[idx]
-			i--
-		}
-		// 右移比特位数，为下次选择字符做准备
-		cache >>= letterIdBits
-		remain--
-	}
-	// 仿照strings.Builder用unsafe包返回一个字符串，避免拷贝
-	// 将字节切片转换为字符串并返回
-	return *(*string)(unsafe.Pointer(&bytes))
-}
-
 // UUIdV4 generate a random UUID of version 4 according to RFC 4122.
 // Play: https://go.dev/play/p/_Z9SFmr28ft
 func UUIdV4() (string, error) {
@@ -349,9 +173,21 @@ func UUIdV4() (string, error) {
 // RandNumberOfLength 生成一个长度为len的随机数
 // Play: https://go.dev/play/p/oyZbuV7bu7b
 func RandNumberOfLength(len int) int {
-	m := int(math.Pow10(len) - 1)
-	i := int(math.Pow10(len - 1))
-	ret := rand.Intn(m-i+1) + i
+	return defaultSource.RandNumberOfLength(len)
+}
+
+// RandGaussian generates a normally distributed float64 with mean and standard deviation stddev.
+func RandGaussian(mean, stddev float64) float64 {
+	return defaultSource.RandGaussian(mean, stddev)
+}
+
+// RandExponential generates an exponentially distributed float64 with rate parameter rate, the mean is 1/rate.
+func RandExponential(rate float64) float64 {
+	return defaultSource.RandExponential(rate)
+}
 
-	return ret
+// RandZipf generates a Zipf distributed uint64 in [0, imax], the probability of k is proportional to
+// (v + k) ** (-exp). It returns error if exp <= 1 or v < 1.
+func RandZipf(exp, v float64, imax uint64) (uint64, error) {
+	return defaultSource.RandZipf(exp, v, imax)
 }
@@ -200,3 +200,76 @@ func ExampleRandFloats() {
 	// true
 	// 5
 }
+
+func ExampleRandToken() {
+	token, err := RandToken(16)
+	if err != nil {
+		return
+	}
+
+	fmt.Println(len(token))
+
+	// Output:
+	// 32
+}
+
+func ExampleNanoID() {
+	id, err := NanoID(8, "0123456789abcdef")
+	if err != nil {
+		return
+	}
+
+	fmt.Println(regexp.MustCompile(`^[0-9a-f]{8}$`).MatchString(id))
+
+	// Output:
+	// true
+}
+
+func ExampleParseULIDTime() {
+	ts, err := ParseULIDTime("01ARZ3NDEKTSV4RRFFQ69G5FAV")
+
+	fmt.Println(ts.UnixMilli(), err)
+
+	// Output:
+	// 1469922850259 <nil>
+}
+
+func ExampleSnowflake() {
+	snowflake, err := NewSnowflake(1, SnowflakeOptions{NodeBits: 8, StepBits: 10})
+	if err != nil {
+		return
+	}
+
+	id, err := snowflake.Next()
+	if err != nil {
+		return
+	}
+
+	_, node, step := snowflake.Parse(id)
+
+	fmt.Println(node, step)
+
+	// Output:
+	// 1 0
+}
+
+func ExampleNewSource() {
+	source1 := NewSource(42)
+	source2 := NewSource(42)
+
+	fmt.Println(source1.RandString(8) == source2.RandString(8))
+	fmt.Println(source1.RandInt(0, 100) == source2.RandInt(0, 100))
+
+	// Output:
+	// true
+	// true
+}
+
+func ExampleRandWeighted() {
+	item, err := RandWeighted([]string{"a", "b", "c"}, []float64{0, 1, 0})
+
+	fmt.Println(item, err)
+
+	// Output:
+	// b <nil>
+}
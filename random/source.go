@@ -0,0 +1,442 @@
+// Copyright 2023 dudaodong@gmail.com. All rights reserved.
+// Use of this source code is governed by MIT license.
+
+package random
+
+import (
+	"errors"
+	"fmt"
+	"math"
+	"math/rand"
+	"sync"
+	"time"
+	"unsafe"
+
+	"github.com/duke-git/lancet/v2/iterator"
+	"github.com/duke-git/lancet/v2/mathutil"
+)
+
+// defaultSource is the source of the package level functions
+var defaultSource = NewSource(time.Now().UnixNano())
+
+// Source is a pseudo-random generator which exposes the api of random package as methods.
+// The sources created with the same seed generate the same sequence of values, so it is useful for
+// reproducible tests and fixtures. It is safe for concurrent use, but the sequence is reproducible
+// only if the calls are in the same order. It is not crypto-secure, use RandToken for secrets.
+type Source struct {
+	rnd *rand.Rand
+}
+
+// NewSource creates a Source with seed.
+func NewSource(seed int64) *Source {
+	return &Source{rnd: rand.New(&lockedSource{src: rand.NewSource(seed).(rand.Source64)})}
+}
+
+// lockedSource makes rand.Source safe for concurrent use like the global source of math/rand
+type lockedSource struct {
+	mu  sync.Mutex
+	src rand.Source64
+}
+
+func (ls *lockedSource) Int63() int64 {
+	ls.mu.Lock()
+	defer ls.mu.Unlock()
+	return ls.src.Int63()
+}
+
+func (ls *lockedSource) Uint64() uint64 {
+	ls.mu.Lock()
+	defer ls.mu.Unlock()
+	return ls.src.Uint64()
+}
+
+func (ls *lockedSource) Seed(seed int64) {
+	ls.mu.Lock()
+	defer ls.mu.Unlock()
+	ls.src.Seed(seed)
+}
+
+// Intn returns a random int in [0, n), it panics if n <= 0.
+func (s *Source) Intn(n int) int {
+	return s.rnd.Intn(n)
+}
+
+// Float64 returns a random float64 in [0.0, 1.0).
+func (s *Source) Float64() float64 {
+	return s.rnd.Float64()
+}
+
+// Perm returns a random permutation of [0, n).
+func (s *Source) Perm(n int) []int {
+	return s.rnd.Perm(n)
+}
+
+// Shuffle shuffles n elements by swap, see rand.Shuffle.
+func (s *Source) Shuffle(n int, swap func(i, j int)) {
+	s.rnd.Shuffle(n, swap)
+}
+
+// RandBool generates a random boolean value (true or false).
+func (s *Source) RandBool() bool {
+	return s.rnd.Intn(2) == 1
+}
+
+// RandBoolSlice generates a random boolean slice of specified length.
+func (s *Source) RandBoolSlice(length int) []bool {
+	if length <= 0 {
+		return []bool{}
+	}
+
+	result := make([]bool, length)
+	for i := range result {
+		result[i] = s.RandBool()
+	}
+
+	return result
+}
+
+// RandInt generate random int between [min, max).
+func (s *Source) RandInt(min, max int) int {
+	if min == max {
+		return min
+	}
+
+	if max < min {
+		min, max = max, min
+	}
+
+	if min == 0 && max == math.MaxInt {
+		return s.rnd.Int()
+	}
+
+	return s.rnd.Intn(max-min) + min
+}
+
+// RandIntSlice generates a slice of random integers between [min, max).
+func (s *Source) RandIntSlice(length, min, max int) []int {
+	if length <= 0 || min > max {
+		return []int{}
+	}
+
+	result := make([]int, length)
+	for i := range result {
+		result[i] = s.RandInt(min, max)
+	}
+
+	return result
+}
+
+// RandUniqueIntSlice generate a slice of random int of length that do not repeat.
+func (s *Source) RandUniqueIntSlice(length, min, max int) []int {
+	if min > max {
+		return []int{}
+	}
+	if length > max-min {
+		length = max - min
+	}
+
+	nums := make([]int, length)
+	used := make(map[int]struct{}, length)
+	for i := 0; i < length; {
+		r := s.RandInt(min, max)
+		if _, use := used[r]; use {
+			continue
+		}
+		used[r] = struct{}{}
+		nums[i] = r
+		i++
+	}
+
+	return nums
+}
+
+// RandFloat generate random float64 number between [min, max) with specific precision.
+func (s *Source) RandFloat(min, max float64, precision int) float64 {
+	if min == max {
+		return min
+	}
+
+	if max < min {
+		min, max = max, min
+	}
+
+	n := s.rnd.Float64()*(max-min) + min
+
+	return mathutil.FloorToFloat(n, precision)
+}
+
+// RandFloats generate a slice of random float64 numbers of length that do not repeat.
+func (s *Source) RandFloats(length int, min, max float64, precision int) []float64 {
+	if max < min {
+		min, max = max, min
+	}
+
+	maxLength := int((max - min) * math.Pow10(precision))
+	if maxLength == 0 {
+		maxLength = 1
+	}
+	if length > maxLength {
+		length = maxLength
+	}
+
+	nums := make([]float64, length)
+	used := make(map[float64]struct{}, length)
+	for i := 0; i < length; {
+		r := s.RandFloat(min, max, precision)
+		if _, use := used[r]; use {
+			continue
+		}
+		used[r] = struct{}{}
+		nums[i] = r
+		i++
+	}
+
+	return nums
+}
+
+// RandBytes generate random byte slice, unlike the package level RandBytes, it is not crypto-secure.
+func (s *Source) RandBytes(length int) []byte {
+	if length < 1 {
+		return []byte{}
+	}
+
+	b := make([]byte, length)
+	for i := range b {
+		b[i] = byte(s.rnd.Intn(256))
+	}
+
+	return b
+}
+
+// RandString generate random alphabeta string of specified length.
+func (s *Source) RandString(length int) string {
+	return s.random(Letters, length)
+}
+
+// RandStringSlice generate a slice of random string of length strLen based on charset.
+func (s *Source) RandStringSlice(charset string, sliceLen, strLen int) []string {
+	if sliceLen <= 0 || strLen <= 0 {
+		return []string{}
+	}
+
+	result := make([]string, sliceLen)
+	for i := range result {
+		result[i] = s.random(charset, strLen)
+	}
+
+	return result
+}
+
+// RandUpper generate a random upper case string of specified length.
+func (s *Source) RandUpper(length int) string {
+	return s.random(UpperLetters, length)
+}
+
+// RandLower generate a random lower case string of specified length.
+func (s *Source) RandLower(length int) string {
+	return s.random(LowwerLetters, length)
+}
+
+// RandNumeral generate a random numeral string of specified length.
+func (s *Source) RandNumeral(length int) string {
+	return s.random(Numeral, length)
+}
+
+// RandNumeralOrLetter generate a random numeral or alpha string of specified length.
+func (s *Source) RandNumeralOrLetter(length int) string {
+	return s.random(Numeral+Letters, length)
+}
+
+// RandSymbolChar generate a random symbol char of specified length.
+func (s *Source) RandSymbolChar(length int) string {
+	return s.random(SymbolChars, length)
+}
+
+// RandNumberOfLength generate a random int number of length len.
+func (s *Source) RandNumberOfLength(len int) int {
+	m := int(math.Pow10(len) - 1)
+	i := int(math.Pow10(len - 1))
+
+	return s.rnd.Intn(m-i+1) + i
+}
+
+// UUIdV4 generate a UUID of version 4 from the source, unlike the package level UUIdV4, it is not crypto-secure.
+func (s *Source) UUIdV4() (string, error) {
+	uuid := s.RandBytes(16)
+
+	uuid[8] = uuid[8]&^0xc0 | 0x80
+	uuid[6] = uuid[6]&^0xf0 | 0x40
+
+	return fmt.Sprintf("%x-%x-%x-%x-%x", uuid[0:4], uuid[4:6], uuid[6:8], uuid[8:10], uuid[10:]), nil
+}
+
+// RandGaussian generates a normally distributed float64 with mean and standard deviation stddev.
+func (s *Source) RandGaussian(mean, stddev float64) float64 {
+	return s.rnd.NormFloat64()*stddev + mean
+}
+
+// RandExponential generates an exponentially distributed float64 with rate parameter (lambda) rate,
+// the mean is 1/rate. eg. the intervals of requests which arrive rate times per second.
+func (s *Source) RandExponential(rate float64) float64 {
+	return s.rnd.ExpFloat64() / rate
+}
+
+// RandZipf generates a Zipf distributed uint64 in [0, imax], the probability of k is proportional to
+// (v + k) ** (-exp). It is useful to simulate hot keys. It returns error if exp <= 1 or v < 1.
+func (s *Source) RandZipf(exp, v float64, imax uint64) (uint64, error) {
+	zipf := rand.NewZipf(s.rnd, exp, v, imax)
+	if zipf == nil {
+		return 0, errors.New("random: invalid zipf parameters, exp should be > 1 and v should be >= 1")
+	}
+	return zipf.Uint64(), nil
+}
+
+// random generate a random string based on given string range.
+func (s *Source) random(chars string, length int) string {
+	// 创建一个长度为 length 的字节切片
+	bytes := make([]byte, length)
+	strLength := len(chars)
+	if strLength <= 0 {
+		return ""
+	} else if strLength == 1 {
+		for i := 0; i < length; i++ {
+			bytes[i] = chars[0]
+		}
+		return *(*string)(unsafe.Pointer(&bytes))
+	}
+	// chars的字符需要使用多少个比特位数才能表示完
+	letterIdBits := int(math.Log2(float64(nearestPowerOfTwo(strLength))))
+	// 最大的字母id掩码
+	var letterIdMask int64 = 1<<letterIdBits - 1
+	// 可用次数的最大值
+	letterIdMax := 63 / letterIdBits
+	// 循环生成随机字符串
+	for i, cache, remain := length-1, s.rnd.Int63(), letterIdMax; i >= 0; {
+		// 检查随机数生成器是否用尽所有随机数
+		if remain == 0 {
+			cache, remain = s.rnd.Int63(), letterIdMax
+		}
+		// 从可用字符的字符串中随机选择一个字符
+		if idx := int(cache & letterIdMask); idx < strLength {
+			bytes[i] = chars[idx]
+			i--
+		}
+		// 右移比特位数，为下次选择字符做准备
+		cache >>= letterIdBits
+		remain--
+	}
+	// 用unsafe包返回一个字符串，避免拷贝
+	return *(*string)(unsafe.Pointer(&bytes))
+}
+
+// RandFromGivenSliceWith generate a random element from given slice with source.
+func RandFromGivenSliceWith[T any](source *Source, slice []T) T {
+	if len(slice) == 0 {
+		var zero T
+		return zero
+	}
+	return slice[source.rnd.Intn(len(slice))]
+}
+
+// RandSliceFromGivenSliceWith generate a random slice of length num from given slice with source.
+//   - If repeatable is true, the generated slice may contain duplicate elements.
+func RandSliceFromGivenSliceWith[T any](source *Source, slice []T, num int, repeatable bool) []T {
+	if num <= 0 || len(slice) == 0 {
+		return slice
+	}
+
+	if !repeatable && num > len(slice) {
+		num = len(slice)
+	}
+
+	result := make([]T, num)
+	if repeatable {
+		for i := range result {
+			result[i] = slice[source.rnd.Intn(len(slice))]
+		}
+	} else {
+		shuffled := make([]T, len(slice))
+		copy(shuffled, slice)
+		source.rnd.Shuffle(len(shuffled), func(i, j int) {
+			shuffled[i], shuffled[j] = shuffled[j], shuffled[i]
+		})
+		result = shuffled[:num]
+	}
+	return result
+}
+
+// RandWeighted returns a random element of items, the probability of items[i] is weights[i] / sum(weights).
+// Error is returned if the length of weights is not equal to items, any weight is negative or the sum is 0.
+func RandWeighted[T any](items []T, weights []float64) (T, error) {
+	return RandWeightedWith(defaultSource, items, weights)
+}
+
+// RandWeightedWith is RandWeighted with source.
+func RandWeightedWith[T any](source *Source, items []T, weights []float64) (T, error) {
+	var zero T
+
+	if len(items) == 0 || len(items) != len(weights) {
+		return zero, errors.New("random: the length of items and weights should be equal and positive")
+	}
+
+	total := 0.0
+	for _, w := range weights {
+		if w < 0 || math.IsNaN(w) || math.IsInf(w, 0) {
+			return zero, errors.New("random: weight should be non-negative and finite")
+		}
+		total += w
+	}
+	if total == 0 {
+		return zero, errors.New("random: the sum of weights should be positive")
+	}
+
+	r := source.rnd.Float64() * total
+	last := 0
+	for i, w := range weights {
+		if w == 0 {
+			continue
+		}
+		if r < w {
+			return items[i], nil
+		}
+		r -= w
+		last = i
+	}
+
+	// the floating point error makes r a little bigger than the sum
+	return items[last], nil
+}
+
+// ReservoirSample returns k random elements of the iterator with equal probability by reservoir sampling,
+// the iterator is consumed and only k elements are kept in memory. If the iterator has less than k elements,
+// all of them are returned in random order.
+func ReservoirSample[T any](iter iterator.Iterator[T], k int) []T {
+	return ReservoirSampleWith(defaultSource, iter, k)
+}
+
+// ReservoirSampleWith is ReservoirSample with source.
+func ReservoirSampleWith[T any](source *Source, iter iterator.Iterator[T], k int) []T {
+	if k <= 0 {
+		return []T{}
+	}
+
+	reservoir := make([]T, 0, k)
+	for n := 0; ; n++ {
+		item, ok := iter.Next()
+		if !ok {
+			break
+		}
+
+		if n < k {
+			reservoir = append(reservoir, item)
+		} else if j := source.rnd.Intn(n + 1); j < k {
+			reservoir[j] = item
+		}
+	}
+
+	source.rnd.Shuffle(len(reservoir), func(i, j int) {
+		reservoir[i], reservoir[j] = reservoir[j], reservoir[i]
+	})
+
+	return reservoir
+}
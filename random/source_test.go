@@ -0,0 +1,180 @@
+package random
+
+import (
+	"math"
+	"regexp"
+	"testing"
+
+	"github.com/duke-git/lancet/v2/internal"
+	"github.com/duke-git/lancet/v2/iterator"
+)
+
+func TestSourceReproducible(t *testing.T) {
+	t.Parallel()
+	assert := internal.NewAssert(t, "TestSourceReproducible")
+
+	generate := func(s *Source) []any {
+		uuid, _ := s.UUIdV4()
+		zipf, _ := s.RandZipf(1.5, 1, 100)
+		return []any{
+			s.RandBool(),
+			s.RandInt(0, 100),
+			s.RandIntSlice(5, 0, 10),
+			s.RandUniqueIntSlice(5, 0, 10),
+			s.RandFloat(0, 1, 4),
+			s.RandFloats(3, 0, 10, 2),
+			s.RandBytes(8),
+			s.RandString(10),
+			s.RandStringSlice(AllChars, 2, 5),
+			s.RandUpper(5),
+			s.RandLower(5),
+			s.RandNumeral(5),
+			s.RandNumeralOrLetter(5),
+			s.RandSymbolChar(5),
+			s.RandNumberOfLength(6),
+			uuid,
+			RandFromGivenSliceWith(s, []string{"a", "b", "c"}),
+			RandSliceFromGivenSliceWith(s, []int{1, 2, 3, 4, 5}, 3, false),
+			s.RandGaussian(0, 1),
+			s.RandExponential(1),
+			zipf,
+		}
+	}
+
+	result1 := generate(NewSource(2023))
+	result2 := generate(NewSource(2023))
+	assert.Equal(result1, result2)
+
+	result3 := generate(NewSource(2024))
+	assert.NotEqual(result1, result3)
+}
+
+func TestSourceValues(t *testing.T) {
+	t.Parallel()
+	assert := internal.NewAssert(t, "TestSourceValues")
+
+	s := NewSource(1)
+
+	for i := 0; i < 100; i++ {
+		n := s.RandInt(-5, 5)
+		assert.Equal(true, n >= -5 && n < 5)
+	}
+	assert.Equal(3, s.RandInt(3, 3))
+
+	assert.Equal(true, regexp.MustCompile(`^[a-zA-Z]{12}$`).MatchString(s.RandString(12)))
+	assert.Equal(6, len(s.RandUniqueIntSlice(10, 0, 6)))
+	assert.Equal(6, len(s.RandNumeral(6)))
+
+	uuid, err := s.UUIdV4()
+	assert.IsNil(err)
+	assert.Equal(true, regexp.MustCompile(`^[0-9a-f]{8}-[0-9a-f]{4}-4[0-9a-f]{3}-[89ab][0-9a-f]{3}-[0-9a-f]{12}$`).MatchString(uuid))
+}
+
+func TestRandDistributions(t *testing.T) {
+	t.Parallel()
+	assert := internal.NewAssert(t, "TestRandDistributions")
+
+	s := NewSource(10)
+	n := 100000
+
+	var sum, sumSquare float64
+	for i := 0; i < n; i++ {
+		v := s.RandGaussian(10, 2)
+		sum += v
+		sumSquare += v * v
+	}
+	mean := sum / float64(n)
+	stddev := math.Sqrt(sumSquare/float64(n) - mean*mean)
+	assert.Equal(true, math.Abs(mean-10) < 0.05)
+	assert.Equal(true, math.Abs(stddev-2) < 0.05)
+
+	sum = 0
+	for i := 0; i < n; i++ {
+		v := s.RandExponential(4)
+		assert.Equal(true, v >= 0)
+		sum += v
+	}
+	assert.Equal(true, math.Abs(sum/float64(n)-0.25) < 0.01)
+
+	counts := make([]int, 11)
+	for i := 0; i < n; i++ {
+		k, err := s.RandZipf(2, 1, 10)
+		assert.IsNil(err)
+		counts[k]++
+	}
+	// the probability decreases with k
+	for k := 1; k < 4; k++ {
+		assert.Equal(true, counts[k-1] > counts[k])
+	}
+
+	assert.Equal(true, RandExponential(1) >= 0)
+	k, err := RandZipf(1.1, 1, 5)
+	assert.IsNil(err)
+	assert.Equal(true, k <= 5)
+	_, err = RandZipf(1, 1, 5)
+	assert.IsNotNil(err)
+	_, err = s.RandZipf(2, 0.5, 5)
+	assert.IsNotNil(err)
+	assert.Equal(false, math.IsNaN(RandGaussian(0, 1)))
+}
+
+func TestRandWeighted(t *testing.T) {
+	t.Parallel()
+	assert := internal.NewAssert(t, "TestRandWeighted")
+
+	s := NewSource(3)
+	items := []string{"a", "b", "c", "d"}
+	weights := []float64{1, 3, 0, 6}
+
+	counts := map[string]int{}
+	for i := 0; i < 100000; i++ {
+		item, err := RandWeightedWith(s, items, weights)
+		assert.IsNil(err)
+		counts[item]++
+	}
+
+	assert.Equal(0, counts["c"])
+	assert.Equal(true, math.Abs(float64(counts["a"])/100000-0.1) < 0.01)
+	assert.Equal(true, math.Abs(float64(counts["b"])/100000-0.3) < 0.01)
+	assert.Equal(true, math.Abs(float64(counts["d"])/100000-0.6) < 0.01)
+
+	item, err := RandWeighted([]int{7}, []float64{0.5})
+	assert.IsNil(err)
+	assert.Equal(7, item)
+
+	_, err = RandWeighted(items, []float64{1, 2})
+	assert.IsNotNil(err)
+	_, err = RandWeighted(items, []float64{1, -1, 1, 1})
+	assert.IsNotNil(err)
+	_, err = RandWeighted(items, []float64{0, 0, 0, 0})
+	assert.IsNotNil(err)
+	_, err = RandWeighted([]int{}, []float64{})
+	assert.IsNotNil(err)
+}
+
+func TestReservoirSample(t *testing.T) {
+	t.Parallel()
+	assert := internal.NewAssert(t, "TestReservoirSample")
+
+	s := NewSource(5)
+
+	// each element is sampled with probability k/n
+	counts := make([]int, 10)
+	for i := 0; i < 20000; i++ {
+		for _, v := range ReservoirSampleWith[int](s, iterator.FromRange(0, 10, 1), 3) {
+			counts[v]++
+		}
+	}
+	for _, count := range counts {
+		assert.Equal(true, math.Abs(float64(count)/20000-0.3) < 0.02)
+	}
+
+	sample := ReservoirSample[int](iterator.FromSlice([]int{1, 2}), 5)
+	assert.Equal(2, len(sample))
+
+	assert.Equal([]int{}, ReservoirSample[int](iterator.FromSlice([]int{1, 2}), 0))
+
+	result1 := ReservoirSampleWith[int](NewSource(9), iterator.FromRange(0, 1000, 1), 5)
+	result2 := ReservoirSampleWith[int](NewSource(9), iterator.FromRange(0, 1000, 1), 5)
+	assert.Equal(result1, result2)
+}
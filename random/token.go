@@ -0,0 +1,103 @@
+// Copyright 2023 dudaodong@gmail.com. All rights reserved.
+// Use of this source code is governed by MIT license.
+
+package random
+
+import (
+	crand "crypto/rand"
+	"encoding/base32"
+	"encoding/hex"
+	"errors"
+	"io"
+	"math"
+	"math/big"
+)
+
+const (
+	// Base62Chars is the alphabet of base62 encoding used by RandTokenBase62 and KSUID.
+	Base62Chars = Numeral + UpperLetters + LowwerLetters
+)
+
+// RandToken generates a hex encoded token of n crypto-secure random bytes, the length of token is 2*n.
+// It is suitable for session ids, api keys and password reset tokens.
+func RandToken(n int) (string, error) {
+	b, err := secureBytes(n)
+	if err != nil {
+		return "", err
+	}
+	return hex.EncodeToString(b), nil
+}
+
+// RandTokenBase32 generates a base32 (RFC 4648, without padding) encoded token of n crypto-secure random bytes.
+func RandTokenBase32(n int) (string, error) {
+	b, err := secureBytes(n)
+	if err != nil {
+		return "", err
+	}
+	return base32.StdEncoding.WithPadding(base32.NoPadding).EncodeToString(b), nil
+}
+
+// RandTokenBase62 generates a base62 encoded token of n crypto-secure random bytes, it only contains [0-9A-Za-z].
+// The length of token is fixed for the same n.
+func RandTokenBase62(n int) (string, error) {
+	b, err := secureBytes(n)
+	if err != nil {
+		return "", err
+	}
+	width := int(math.Ceil(float64(n*8) / math.Log2(62)))
+	return encodeBase62(b, width), nil
+}
+
+func secureBytes(n int) ([]byte, error) {
+	if n <= 0 {
+		return nil, errors.New("random: token size should be positive")
+	}
+
+	b := make([]byte, n)
+	if _, err := io.ReadFull(crand.Reader, b); err != nil {
+		return nil, err
+	}
+	return b, nil
+}
+
+// encodeBase62 encodes b as a big-endian number, the result is left padded with '0' to width
+func encodeBase62(b []byte, width int) string {
+	n := new(big.Int).SetBytes(b)
+	base := big.NewInt(62)
+	mod := new(big.Int)
+
+	result := make([]byte, width)
+	for i := width - 1; i >= 0; i-- {
+		n.DivMod(n, base, mod)
+		result[i] = Base62Chars[mod.Int64()]
+	}
+
+	return string(result)
+}
+
+// decodeBase62 decodes s to size bytes
+func decodeBase62(s string, size int) ([]byte, error) {
+	n := new(big.Int)
+	base := big.NewInt(62)
+
+	for i := 0; i < len(s); i++ {
+		var v int
+		switch c := s[i]; {
+		case c >= '0' && c <= '9':
+			v = int(c - '0')
+		case c >= 'A' && c <= 'Z':
+			v = int(c-'A') + 10
+		case c >= 'a' && c <= 'z':
+			v = int(c-'a') + 36
+		default:
+			return nil, errors.New("random: invalid base62 character")
+		}
+		n.Mul(n, base).Add(n, big.NewInt(int64(v)))
+	}
+
+	if n.BitLen() > size*8 {
+		return nil, errors.New("random: base62 value overflow")
+	}
+
+	return n.FillBytes(make([]byte, size)), nil
+}
@@ -64,9 +64,7 @@ func RetryWithLinearBackoff(interval time.Duration) Option {
 	}
 
 	return func(rc *RetryConfig) {
-		rc.backoffStrategy = &linear{
-			interval: interval,
-		}
+		rc.backoffStrategy = NewLinearBackoff(interval)
 	}
 }
 
@@ -81,22 +79,8 @@ func RetryWithExponentialWithJitterBackoff(interval time.Duration, base uint64,
 		panic("programming error: retry maxJitter should not be lower to 0")
 	}
 
-	if base%2 == 0 {
-		return func(rc *RetryConfig) {
-			rc.backoffStrategy = &shiftExponentialWithJitter{
-				interval:  interval,
-				maxJitter: maxJitter,
-				shifter:   uint64(math.Log2(float64(base))),
-			}
-		}
-	}
-
 	return func(rc *RetryConfig) {
-		rc.backoffStrategy = &exponentialWithJitter{
-			interval:  interval,
-			base:      time.Duration(base),
-			maxJitter: maxJitter,
-		}
+		rc.backoffStrategy = NewExponentialWithJitterBackoff(interval, base, maxJitter)
 	}
 }
 
@@ -155,6 +139,45 @@ type BackoffStrategy interface {
 	CalculateInterval() time.Duration
 }
 
+// NewLinearBackoff return a BackoffStrategy which waits the same interval between retries.
+// The strategy is stateless, it could be shared between retries.
+func NewLinearBackoff(interval time.Duration) BackoffStrategy {
+	if interval <= 0 {
+		panic("programming error: retry interval should not be lower or equal to 0")
+	}
+
+	return &linear{
+		interval: interval,
+	}
+}
+
+// NewExponentialWithJitterBackoff return a BackoffStrategy which multiplies the interval by base after each retry,
+// and adds a random jitter up to maxJitter.
+// The strategy is stateful, a new one should be created for each sequence of retries.
+func NewExponentialWithJitterBackoff(interval time.Duration, base uint64, maxJitter time.Duration) BackoffStrategy {
+	if interval <= 0 {
+		panic("programming error: retry interval should not be lower or equal to 0")
+	}
+
+	if maxJitter < 0 {
+		panic("programming error: retry maxJitter should not be lower to 0")
+	}
+
+	if base%2 == 0 {
+		return &shiftExponentialWithJitter{
+			interval:  interval,
+			maxJitter: maxJitter,
+			shifter:   uint64(math.Log2(float64(base))),
+		}
+	}
+
+	return &exponentialWithJitter{
+		interval:  interval,
+		base:      time.Duration(base),
+		maxJitter: maxJitter,
+	}
+}
+
 // linear is a struct that implements the BackoffStrategy interface using a linear backoff strategy.
 type linear struct {
 	// interval specifies the fixed duration to wait between retry attempts.
@@ -256,3 +256,27 @@ func TestCancelRetry(t *testing.T) {
 	assert.IsNotNil(err)
 	assert.Equal(4, number)
 }
+
+func TestNewBackoff(t *testing.T) {
+	t.Parallel()
+
+	assert := internal.NewAssert(t, "TestNewBackoff")
+
+	linear := NewLinearBackoff(time.Second)
+	assert.Equal(time.Second, linear.CalculateInterval())
+	assert.Equal(time.Second, linear.CalculateInterval())
+
+	exponential := NewExponentialWithJitterBackoff(time.Second, 3, 0)
+	assert.Equal(time.Second, exponential.CalculateInterval())
+	assert.Equal(3*time.Second, exponential.CalculateInterval())
+	assert.Equal(9*time.Second, exponential.CalculateInterval())
+
+	shift := NewExponentialWithJitterBackoff(time.Second, 2, 0)
+	assert.Equal(time.Second, shift.CalculateInterval())
+	assert.Equal(2*time.Second, shift.CalculateInterval())
+
+	jitter := NewExponentialWithJitterBackoff(time.Second, 2, time.Millisecond)
+	interval := jitter.CalculateInterval()
+	assert.Greater(interval, time.Second)
+	assert.LessOrEqual(interval, time.Second+time.Millisecond)
+}
@@ -1087,6 +1087,15 @@ func ShuffleCopy[T any](slice []T) []T {
 	return result
 }
 
+// ShuffleWith shuffle the slice with the random source, the result is reproducible for the source of same seed.
+func ShuffleWith[T any](source *random.Source, slice []T) []T {
+	source.Shuffle(len(slice), func(i, j int) {
+		slice[i], slice[j] = slice[j], slice[i]
+	})
+
+	return slice
+}
+
 // IsAscending checks if a slice is ascending order.
 // Play: https://go.dev/play/p/9CtsFjet4SH
 func IsAscending[T constraints.Ordered](slice []T) bool {
@@ -1465,6 +1474,16 @@ func Random[T any](slice []T) (val T, idx int) {
 	return slice[idx], idx
 }
 
+// RandomWith get a random item of slice with the random source, return idx=-1 when slice is empty
+func RandomWith[T any](source *random.Source, slice []T) (val T, idx int) {
+	if len(slice) == 0 {
+		return val, -1
+	}
+
+	idx = source.Intn(len(slice))
+	return slice[idx], idx
+}
+
 // RightPadding adds padding to the right end of a slice.
 // Play: https://go.dev/play/p/0_2rlLEMBXL
 func RightPadding[T any](slice []T, paddingValue T, paddingLength int) []T {
@@ -11,6 +11,7 @@ import (
 	"testing"
 
 	"github.com/duke-git/lancet/v2/internal"
+	"github.com/duke-git/lancet/v2/random"
 )
 
 func TestContain(t *testing.T) {
@@ -1353,6 +1354,22 @@ func TestShuffleCopy(t *testing.T) {
 	assert.Equal([]int{1, 2, 3, 4, 5}, numbers)
 }
 
+func TestShuffleWith(t *testing.T) {
+	t.Parallel()
+
+	assert := internal.NewAssert(t, "TestShuffleWith")
+
+	result1 := ShuffleWith(random.NewSource(42), []int{1, 2, 3, 4, 5, 6, 7, 8})
+	result2 := ShuffleWith(random.NewSource(42), []int{1, 2, 3, 4, 5, 6, 7, 8})
+
+	assert.Equal(result1, result2)
+	assert.Equal(8, len(result1))
+
+	sorted := ShuffleCopy(result1)
+	Sort(sorted)
+	assert.Equal([]int{1, 2, 3, 4, 5, 6, 7, 8}, sorted)
+}
+
 func TestIndexOf(t *testing.T) {
 	t.Parallel()
 
@@ -1544,6 +1561,21 @@ func TestPartition(t *testing.T) {
 	assert.Equal([][]int{{1, 2}, {3, 4}, {5}}, Partition([]int{1, 2, 3, 4, 5}, func(n int) bool { return n == 1 || n == 2 }, func(n int) bool { return n == 2 || n == 3 || n == 4 }))
 }
 
+func TestRandomWith(t *testing.T) {
+	t.Parallel()
+	assert := internal.NewAssert(t, "TestRandomWith")
+
+	_, idx := RandomWith(random.NewSource(1), []int{})
+	assert.Equal(-1, idx)
+
+	arr := []string{"a", "b", "c", "d"}
+	val1, idx1 := RandomWith(random.NewSource(7), arr)
+	val2, idx2 := RandomWith(random.NewSource(7), arr)
+	assert.Equal(val1, val2)
+	assert.Equal(idx1, idx2)
+	assert.Equal(arr[idx1], val1)
+}
+
 func TestRandom(t *testing.T) {
 	t.Parallel()
 	assert := internal.NewAssert(t, "TestRandom")
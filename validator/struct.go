@@ -0,0 +1,187 @@
+// Copyright 2023 dudaodong@gmail.com. All rights reserved.
+// Use of this source code is governed by MIT license
+
+package validator
+
+import (
+	"fmt"
+	"reflect"
+	"strconv"
+	"strings"
+	"time"
+	"unicode/utf8"
+)
+
+// stringRules are the `validate` tag rules which check a string with a validate function
+var stringRules = map[string]func(string) bool{
+	"email":        IsEmail,
+	"url":          IsUrl,
+	"dns":          IsDns,
+	"ip":           IsIp,
+	"ipv4":         IsIpV4,
+	"ipv6":         IsIpV6,
+	"alpha":        IsAlpha,
+	"alphanumeric": IsAlphaNumeric,
+	"number":       IsNumberStr,
+	"int":          IsIntStr,
+	"float":        IsFloatStr,
+	"json":         IsJSON,
+	"base64":       IsBase64,
+	"hex":          IsHex,
+	"jwt":          IsJWT,
+}
+
+// ValidateStruct validate the exported fields of struct by `validate` tag, return the error message of each invalid field.
+// The key of result is the json name of field, nested struct fields are joined with dot, eg. `address.city`.
+// Rules are separated by comma, eg. `validate:"required,email"`. Supported rules:
+// required, email, url, dns, ip, ipv4, ipv6, alpha, alphanumeric, number, int, float, json, base64, hex, jwt,
+// min=n, max=n, len=n (length of string, slice and map or value of number), oneof=a b c.
+// Empty value is only checked by `required`. It returns nil if all fields are valid or v is not a struct.
+func ValidateStruct(v any) map[string]string {
+	rv := reflect.ValueOf(v)
+	for rv.Kind() == reflect.Ptr {
+		if rv.IsNil() {
+			return nil
+		}
+		rv = rv.Elem()
+	}
+	if rv.Kind() != reflect.Struct {
+		return nil
+	}
+
+	result := map[string]string{}
+	validateStruct(rv, "", result)
+	if len(result) == 0 {
+		return nil
+	}
+
+	return result
+}
+
+func validateStruct(rv reflect.Value, prefix string, result map[string]string) {
+	rt := rv.Type()
+	for i := 0; i < rt.NumField(); i++ {
+		field := rt.Field(i)
+		if !field.IsExported() {
+			continue
+		}
+
+		name := fieldName(field)
+		if name == "-" {
+			continue
+		}
+		name = prefix + name
+
+		value := rv.Field(i)
+		if msg := validateField(value, field.Tag.Get("validate")); msg != "" {
+			result[name] = msg
+			continue
+		}
+
+		for value.Kind() == reflect.Ptr && !value.IsNil() {
+			value = value.Elem()
+		}
+		if value.Kind() == reflect.Struct && value.Type() != reflect.TypeOf(time.Time{}) {
+			validateStruct(value, name+".", result)
+		}
+	}
+}
+
+// fieldName return the json name of field
+func fieldName(field reflect.StructField) string {
+	if tag, ok := field.Tag.Lookup("json"); ok {
+		name := strings.Split(tag, ",")[0]
+		if name != "" {
+			return name
+		}
+	}
+	return field.Name
+}
+
+// validateField return the error message of the first failed rule, empty string if all rules pass.
+func validateField(value reflect.Value, tag string) string {
+	if tag == "" {
+		return ""
+	}
+
+	for value.Kind() == reflect.Ptr && !value.IsNil() {
+		value = value.Elem()
+	}
+	empty := IsZeroValue(value.Interface())
+
+	for _, rule := range strings.Split(tag, ",") {
+		name, param, _ := strings.Cut(strings.TrimSpace(rule), "=")
+
+		if name == "required" {
+			if empty {
+				return "is required"
+			}
+			continue
+		}
+		if empty || name == "" {
+			continue
+		}
+
+		if check, ok := stringRules[name]; ok {
+			if value.Kind() != reflect.String || !check(value.String()) {
+				return "should be a valid " + name
+			}
+			continue
+		}
+
+		switch name {
+		case "min", "max", "len":
+			limit, err := strconv.ParseFloat(param, 64)
+			if err != nil {
+				return fmt.Sprintf("has invalid rule %q", rule)
+			}
+			size, ok := fieldSize(value)
+			if !ok {
+				return fmt.Sprintf("does not support rule %q", rule)
+			}
+			if name == "min" && size < limit {
+				return "should be at least " + param
+			}
+			if name == "max" && size > limit {
+				return "should be at most " + param
+			}
+			if name == "len" && size != limit {
+				return "should be " + param + " in length"
+			}
+		case "oneof":
+			options := strings.Fields(param)
+			actual := fmt.Sprintf("%v", value.Interface())
+			found := false
+			for _, option := range options {
+				if option == actual {
+					found = true
+					break
+				}
+			}
+			if !found {
+				return "should be one of [" + strings.Join(options, " ") + "]"
+			}
+		default:
+			return fmt.Sprintf("has unknown rule %q", name)
+		}
+	}
+
+	return ""
+}
+
+// fieldSize return the length of string, slice, array and map, or the value of number
+func fieldSize(value reflect.Value) (float64, bool) {
+	switch value.Kind() {
+	case reflect.String:
+		return float64(utf8.RuneCountInString(value.String())), true
+	case reflect.Slice, reflect.Array, reflect.Map:
+		return float64(value.Len()), true
+	case reflect.Int, reflect.Int8, reflect.Int16, reflect.Int32, reflect.Int64:
+		return float64(value.Int()), true
+	case reflect.Uint, reflect.Uint8, reflect.Uint16, reflect.Uint32, reflect.Uint64, reflect.Uintptr:
+		return float64(value.Uint()), true
+	case reflect.Float32, reflect.Float64:
+		return value.Float(), true
+	}
+	return 0, false
+}
@@ -924,3 +924,58 @@ func TestIsAlphaNumeric(t *testing.T) {
 		assert.Equal(tt.expected, IsAlphaNumeric(tt.input))
 	}
 }
+
+func TestValidateStruct(t *testing.T) {
+	t.Parallel()
+
+	assert := internal.NewAssert(t, "TestValidateStruct")
+
+	type address struct {
+		City string `json:"city" validate:"required"`
+		Zip  string `json:"zip" validate:"number,len=6"`
+	}
+	type user struct {
+		Name    string   `json:"name" validate:"required,min=2,max=10"`
+		Email   string   `json:"email" validate:"required,email"`
+		Age     int      `json:"age" validate:"min=18"`
+		Role    string   `json:"role" validate:"oneof=admin user"`
+		Tags    []string `json:"tags" validate:"max=2"`
+		Website string   `validate:"url"`
+		Address *address `json:"address"`
+		ignored string   `validate:"required"`
+	}
+
+	valid := user{
+		Name:    "lancet",
+		Email:   "lancet@example.com",
+		Age:     18,
+		Role:    "admin",
+		Website: "https://github.com",
+		Address: &address{City: "beijing", Zip: "100000"},
+	}
+	assert.Equal(0, len(ValidateStruct(valid)))
+	assert.Equal(0, len(ValidateStruct(&valid)))
+	assert.Equal(0, len(ValidateStruct("not struct")))
+
+	invalid := user{
+		Name:    "a",
+		Email:   "invalid",
+		Age:     17,
+		Role:    "guest",
+		Tags:    []string{"a", "b", "c"},
+		Website: "invalid",
+		Address: &address{Zip: "abc"},
+	}
+	assert.Equal(map[string]string{
+		"name":         "should be at least 2",
+		"email":        "should be a valid email",
+		"age":          "should be at least 18",
+		"role":         "should be one of [admin user]",
+		"tags":         "should be at most 2",
+		"Website":      "should be a valid url",
+		"address.city": "is required",
+		"address.zip":  "should be a valid number",
+	}, ValidateStruct(invalid))
+
+	assert.Equal(map[string]string{"name": "is required", "email": "is required"}, ValidateStruct(user{}))
+}